@@ -18,6 +18,7 @@ package common
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	types "github.com/oam-dev/terraform-controller/api/types/crossplane-runtime"
@@ -89,8 +90,22 @@ type Schematic struct {
 	HELM *Helm `json:"helm,omitempty"`
 
 	Terraform *Terraform `json:"terraform,omitempty"`
+
+	Kustomize *Kustomize `json:"kustomize,omitempty"`
+
+	K8sObjects *K8sObjects `json:"k8sObjects,omitempty"`
 }
 
+// HelmMode specifies how a Helm module is rendered
+type HelmMode string
+
+const (
+	// HelmModeFluxCD renders a Helm module into a HelmRelease and a HelmRepository which are reconciled by FluxCD
+	HelmModeFluxCD HelmMode = "fluxcd"
+	// HelmModeNative renders the chart of a Helm module into plain manifests in-process
+	HelmModeNative HelmMode = "native"
+)
+
 // A Helm represents resources used by a Helm module
 type Helm struct {
 	// Release records a Helm release used by a Helm module workload.
@@ -98,8 +113,19 @@ type Helm struct {
 	Release runtime.RawExtension `json:"release"`
 
 	// HelmRelease records a Helm repository used by a Helm module workload.
+	// It's not required if the chart is loaded from LocalChart.
 	// +kubebuilder:pruning:PreserveUnknownFields
-	Repository runtime.RawExtension `json:"repository"`
+	Repository runtime.RawExtension `json:"repository,omitempty"`
+
+	// Mode specifies how the Helm module is rendered, the chart is either installed by FluxCD which requires
+	// the fluxcd addon, or rendered into plain manifests by KubeVela so that traits can patch them.
+	// The fluxcd mode is used if not specified.
+	// +kubebuilder:validation:Enum:=fluxcd;native
+	Mode HelmMode `json:"mode,omitempty"`
+
+	// LocalChart is the path of a chart directory or archive on the host rendering the chart, it's only used
+	// in the native mode if it's allowed explicitly, e.g. in the tests
+	LocalChart string `json:"localChart,omitempty"`
 }
 
 // Terraform is the struct to describe cloud resources managed by Hashicorp Terraform
@@ -116,6 +142,60 @@ type Terraform struct {
 	ProviderReference *types.Reference `json:"providerRef,omitempty"`
 }
 
+// Kustomize describes the resources rendered from a kustomization
+type Kustomize struct {
+	// Source specifies where the kustomization is fetched from
+	Source KustomizeSource `json:"source"`
+
+	// Path is the directory of the kustomization within the source, the root of the source is used if not specified
+	Path string `json:"path,omitempty"`
+}
+
+// KustomizeSource specifies where a kustomization is fetched from, only one of the sources should be set
+type KustomizeSource struct {
+	// Git specifies a Git repository
+	Git *KustomizeGitSource `json:"git,omitempty"`
+
+	// OCI specifies an OCI artifact, its layers are extracted as the files of the kustomization
+	OCI *KustomizeOCISource `json:"oci,omitempty"`
+
+	// Files specifies the files of the kustomization inline, keyed by their paths
+	Files map[string]string `json:"files,omitempty"`
+
+	// LocalPath specifies a directory on the host rendering the kustomization, it's meant for offline tests
+	// and is only used if it's allowed explicitly
+	LocalPath string `json:"localPath,omitempty"`
+}
+
+// KustomizeGitSource specifies a Git repository
+type KustomizeGitSource struct {
+	// URL of the Git repository
+	URL string `json:"url"`
+
+	// Ref is the commit hash to check out, branches and tags are not supported as they could change the rendered
+	// resources without a new revision of the application
+	Ref string `json:"ref,omitempty"`
+}
+
+// KustomizeOCISource specifies an OCI artifact
+type KustomizeOCISource struct {
+	// Ref is the reference of the artifact pinned to a digest, e.g. ghcr.io/oam-dev/config@sha256:<digest>
+	Ref string `json:"ref"`
+}
+
+// K8sObjects describes a list of raw Kubernetes objects, the objects property of the component is appended to
+// the objects of the definition, and the overlays property of the component is applied to them. The namespaced
+// objects are always created in the namespace of the application
+type K8sObjects struct {
+	// Objects are the raw Kubernetes objects preset by the definition
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Objects []runtime.RawExtension `json:"objects,omitempty"`
+	// AllowedClusterScopedKinds are the kinds of the cluster-scoped objects allowed to be created, in the format
+	// of kind.group, e.g. ClusterRole.rbac.authorization.k8s.io, or the kind alone for the core group, e.g.
+	// Namespace. The cluster-scoped objects of the other kinds are rejected
+	AllowedClusterScopedKinds []string `json:"allowedClusterScopedKinds,omitempty"`
+}
+
 // A WorkloadTypeDescriptor refer to a Workload Type
 type WorkloadTypeDescriptor struct {
 	// Type ref to a WorkloadDefinition via name
@@ -186,6 +266,8 @@ const (
 	ApplicationRunning ApplicationPhase = "running"
 	// ApplicationUnhealthy means the app finished rendering and applied result to the cluster, but still unhealthy
 	ApplicationUnhealthy ApplicationPhase = "unhealthy"
+	// ApplicationDeleting means the app is running the pre-delete workflow before its resources are released
+	ApplicationDeleting ApplicationPhase = "deleting"
 )
 
 // WorkflowState is a string that mark the workflow state
@@ -294,6 +376,22 @@ type AppStatus struct {
 
 	// AppliedResources record the resources that the  workflow step apply.
 	AppliedResources []ClusterObjectReference `json:"appliedResources,omitempty"`
+
+	// Rollback record the revision the application is rolled back to
+	// +optional
+	Rollback *AppRollbackStatus `json:"rollback,omitempty"`
+
+	// Health summarizes the health of the components
+	// +optional
+	Health *AppHealthStatus `json:"health,omitempty"`
+
+	// Drift record the resources drifted from the rendered manifests, it is reported by the drift-detection policy
+	// +optional
+	Drift *DriftStatus `json:"drift,omitempty"`
+
+	// PreDelete record the status of the pre-delete workflow run when the application is deleted
+	// +optional
+	PreDelete *PreDeleteStatus `json:"preDelete,omitempty"`
 }
 
 // WorkflowStatus record the status of workflow
@@ -457,3 +555,60 @@ type ClusterObjectReference struct {
 	Creator                ResourceCreatorRole `json:"creator,omitempty"`
 	corev1.ObjectReference `json:",inline"`
 }
+
+// AppRollbackStatus records the application is rolled back to a previous revision, the rollback ends once the spec
+// of the application is changed
+type AppRollbackStatus struct {
+	// Revision is the name of the ApplicationRevision the application is rolled back to
+	Revision string `json:"revision"`
+	// Generation is the generation of the application when it's rolled back
+	Generation   int64       `json:"generation"`
+	RollbackTime metav1.Time `json:"rollbackTime,omitempty"`
+	// SpecHash is the hash of the spec of the application without the pause when it's rolled back, the rollback is
+	// kept while only the pause of the application is changed
+	SpecHash string `json:"specHash,omitempty"`
+}
+
+// PreDeleteStatus records the status of the pre-delete workflow of the application
+type PreDeleteStatus struct {
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// Finished is true once all the steps succeed, the workflow is terminated or it times out, the resources of the
+	// application are released after it is finished
+	Finished bool   `json:"finished"`
+	Message  string `json:"message,omitempty"`
+
+	Workflow *WorkflowStatus `json:"workflow,omitempty"`
+}
+
+// AppHealthStatus summarizes the health of the application by the app-health policy
+type AppHealthStatus struct {
+	// Healthy is true if all the critical components are healthy for the consecutive checks of the healthy threshold
+	Healthy bool `json:"healthy"`
+	// Score is the percentage of the weight of the healthy components
+	Score int `json:"score"`
+	// InGracePeriod means the application is in the startup grace period, it is not reported unhealthy
+	InGracePeriod bool `json:"inGracePeriod,omitempty"`
+	// ConsecutiveHealthyChecks is the number of the consecutive checks that all the critical components are healthy
+	ConsecutiveHealthyChecks int `json:"consecutiveHealthyChecks,omitempty"`
+	// UnhealthyComponents are the critical components which are unhealthy at the last check
+	UnhealthyComponents []string    `json:"unhealthyComponents,omitempty"`
+	LastCheckTime       metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+// DriftStatus record the result of the latest drift detection
+type DriftStatus struct {
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+	// Resources are the resources drifted from the rendered manifests at the last check
+	Resources []DriftedResource `json:"resources,omitempty"`
+}
+
+// DriftedResource defines the resource drifted from the rendered manifest
+type DriftedResource struct {
+	ClusterObjectReference `json:",inline"`
+	// Fields are the paths of the drifted fields
+	Fields []string `json:"fields,omitempty"`
+	// Missing means the resource is deleted by others
+	Missing bool `json:"missing,omitempty"`
+	// Corrected means the resource has been re-applied with the rendered manifest
+	Corrected bool `json:"corrected,omitempty"`
+}
@@ -23,8 +23,46 @@ package common
 import (
 	crossplane_runtime "github.com/oam-dev/terraform-controller/api/types/crossplane-runtime"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppHealthStatus) DeepCopyInto(out *AppHealthStatus) {
+	*out = *in
+	if in.UnhealthyComponents != nil {
+		in, out := &in.UnhealthyComponents, &out.UnhealthyComponents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppHealthStatus.
+func (in *AppHealthStatus) DeepCopy() *AppHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AppHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppRollbackStatus) DeepCopyInto(out *AppRollbackStatus) {
+	*out = *in
+	in.RollbackTime.DeepCopyInto(&out.RollbackTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppRollbackStatus.
+func (in *AppRollbackStatus) DeepCopy() *AppRollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AppRollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AppRolloutStatus) DeepCopyInto(out *AppRolloutStatus) {
 	*out = *in
@@ -82,6 +120,26 @@ func (in *AppStatus) DeepCopyInto(out *AppStatus) {
 		*out = make([]ClusterObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(AppRollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(AppHealthStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(DriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreDelete != nil {
+		in, out := &in.PreDelete, &out.PreDelete
+		*out = new(PreDeleteStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppStatus.
@@ -322,6 +380,50 @@ func (in *Distribution) DeepCopy() *Distribution {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftStatus) DeepCopyInto(out *DriftStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]DriftedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftStatus.
+func (in *DriftStatus) DeepCopy() *DriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftedResource) DeepCopyInto(out *DriftedResource) {
+	*out = *in
+	out.ClusterObjectReference = in.ClusterObjectReference
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftedResource.
+func (in *DriftedResource) DeepCopy() *DriftedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Helm) DeepCopyInto(out *Helm) {
 	*out = *in
@@ -339,6 +441,33 @@ func (in *Helm) DeepCopy() *Helm {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K8sObjects) DeepCopyInto(out *K8sObjects) {
+	*out = *in
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowedClusterScopedKinds != nil {
+		in, out := &in.AllowedClusterScopedKinds, &out.AllowedClusterScopedKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K8sObjects.
+func (in *K8sObjects) DeepCopy() *K8sObjects {
+	if in == nil {
+		return nil
+	}
+	out := new(K8sObjects)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Kube) DeepCopyInto(out *Kube) {
 	*out = *in
@@ -392,6 +521,105 @@ func (in *KubeParameter) DeepCopy() *KubeParameter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Kustomize) DeepCopyInto(out *Kustomize) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kustomize.
+func (in *Kustomize) DeepCopy() *Kustomize {
+	if in == nil {
+		return nil
+	}
+	out := new(Kustomize)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KustomizeGitSource) DeepCopyInto(out *KustomizeGitSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KustomizeGitSource.
+func (in *KustomizeGitSource) DeepCopy() *KustomizeGitSource {
+	if in == nil {
+		return nil
+	}
+	out := new(KustomizeGitSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KustomizeOCISource) DeepCopyInto(out *KustomizeOCISource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KustomizeOCISource.
+func (in *KustomizeOCISource) DeepCopy() *KustomizeOCISource {
+	if in == nil {
+		return nil
+	}
+	out := new(KustomizeOCISource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KustomizeSource) DeepCopyInto(out *KustomizeSource) {
+	*out = *in
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(KustomizeGitSource)
+		**out = **in
+	}
+	if in.OCI != nil {
+		in, out := &in.OCI, &out.OCI
+		*out = new(KustomizeOCISource)
+		**out = **in
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KustomizeSource.
+func (in *KustomizeSource) DeepCopy() *KustomizeSource {
+	if in == nil {
+		return nil
+	}
+	out := new(KustomizeSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreDeleteStatus) DeepCopyInto(out *PreDeleteStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.Workflow != nil {
+		in, out := &in.Workflow, &out.Workflow
+		*out = new(WorkflowStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreDeleteStatus.
+func (in *PreDeleteStatus) DeepCopy() *PreDeleteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PreDeleteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RawComponent) DeepCopyInto(out *RawComponent) {
 	*out = *in
@@ -446,6 +674,16 @@ func (in *Schematic) DeepCopyInto(out *Schematic) {
 		*out = new(Terraform)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Kustomize != nil {
+		in, out := &in.Kustomize, &out.Kustomize
+		*out = new(Kustomize)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.K8sObjects != nil {
+		in, out := &in.K8sObjects, &out.K8sObjects
+		*out = new(K8sObjects)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Schematic.
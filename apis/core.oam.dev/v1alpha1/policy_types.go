@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// DriftDetectionPolicyType is the type of the built-in policy which detects the drift of the applied resources
+	DriftDetectionPolicyType = "drift-detection"
+)
+
+// DriftDetectionMode describes what to do when the drift of the applied resources is detected
+type DriftDetectionMode string
+
+const (
+	// DriftDetectionModeReport only reports the drifted fields in the application status and the events
+	DriftDetectionModeReport DriftDetectionMode = "report"
+	// DriftDetectionModeAutoCorrect re-applies the drifted resources with the rendered manifests
+	DriftDetectionModeAutoCorrect DriftDetectionMode = "auto-correct"
+)
+
+// DriftIgnoreRule describes the fields ignored by the drift detection.
+// The rule matches all the resources if the apiVersion, kind and name are all empty.
+type DriftIgnoreRule struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+	// Paths are the dot separated paths of the ignored fields, such as `spec.replicas`.
+	// All the sub fields of the path are ignored.
+	Paths []string `json:"paths"`
+}
+
+// DriftDetectionPolicySpec defines the spec of the drift-detection policy
+type DriftDetectionPolicySpec struct {
+	// Mode is report by default
+	Mode DriftDetectionMode `json:"mode,omitempty"`
+
+	// Interval is the duration between two checks, such as `5m`, it is 5 minutes by default
+	Interval string `json:"interval,omitempty"`
+
+	// IgnoreFields are the fields that are allowed to be changed by others
+	IgnoreFields []DriftIgnoreRule `json:"ignoreFields,omitempty"`
+}
+
+const (
+	// AppHealthPolicyType is the type of the built-in policy which aggregates the health of the components
+	AppHealthPolicyType = "app-health"
+)
+
+// ComponentCriticality describes how the health of the component affects the health of the application
+type ComponentCriticality string
+
+const (
+	// ComponentCriticalityCritical means the application is unhealthy if the component is unhealthy
+	ComponentCriticalityCritical ComponentCriticality = "critical"
+	// ComponentCriticalityOptional means the health of the component only affects the health score of the application
+	ComponentCriticalityOptional ComponentCriticality = "optional"
+)
+
+// ComponentHealthRule defines how the health of the component is aggregated
+type ComponentHealthRule struct {
+	Name string `json:"name"`
+	// Criticality is critical by default
+	Criticality ComponentCriticality `json:"criticality,omitempty"`
+	// Weight is the weight of the component in the health score, it is 1 by default
+	Weight int `json:"weight,omitempty"`
+}
+
+// AppHealthPolicySpec defines the spec of the app-health policy
+type AppHealthPolicySpec struct {
+	// Components are the rules of the components, the components not listed are critical with weight 1
+	Components []ComponentHealthRule `json:"components,omitempty"`
+
+	// StartupGracePeriod is the duration after the revision is created, such as `2m`, the application is not
+	// reported unhealthy during the period
+	StartupGracePeriod string `json:"startupGracePeriod,omitempty"`
+
+	// HealthyThreshold is the number of the consecutive healthy checks before the application is reported healthy,
+	// it is 1 by default
+	HealthyThreshold int `json:"healthyThreshold,omitempty"`
+
+	// CheckInterval is the minimum duration between two counted checks, it is 10s by default
+	CheckInterval string `json:"checkInterval,omitempty"`
+}
+
+const (
+	// GarbageCollectPolicyType is the type of the built-in policy which controls how the resources removed from the
+	// application are garbage collected
+	GarbageCollectPolicyType = "garbage-collect"
+)
+
+// GarbageCollectStrategy describes what to do with the resources removed from the application
+type GarbageCollectStrategy string
+
+const (
+	// GarbageCollectStrategyNeverDelete keeps the resources even if the application is deleted
+	GarbageCollectStrategyNeverDelete GarbageCollectStrategy = "never-delete"
+	// GarbageCollectStrategyOrphan keeps the resources removed from the application and stops tracking them, they are
+	// still deleted with the application if they are tracked when the application is deleted
+	GarbageCollectStrategyOrphan GarbageCollectStrategy = "orphan"
+	// GarbageCollectStrategyDeleteAfterDelay deletes the resources removed from the application after the delay, the
+	// deletion of the application waits for the delay as well
+	GarbageCollectStrategyDeleteAfterDelay GarbageCollectStrategy = "delete-after-delay"
+	// GarbageCollectStrategyKeepLastNRevisions deletes the resources removed from the application once they are not
+	// used by the last N revisions
+	GarbageCollectStrategyKeepLastNRevisions GarbageCollectStrategy = "keep-last-n-revisions"
+)
+
+// GarbageCollectSelector selects the resources the rule applies to. The resource must match all the non-empty fields,
+// and any of the values of the field.
+type GarbageCollectSelector struct {
+	// ComponentNames are the names of the components which render the resources
+	ComponentNames []string `json:"componentNames,omitempty"`
+	// TraitTypes are the types of the traits which render the resources
+	TraitTypes []string `json:"traitTypes,omitempty"`
+	// ResourceKinds are the kinds of the resources, such as `PersistentVolumeClaim`
+	ResourceKinds []string `json:"resourceKinds,omitempty"`
+	// ResourceNames are the names of the resources
+	ResourceNames []string `json:"resourceNames,omitempty"`
+}
+
+// GarbageCollectPolicyRule defines how the selected resources are garbage collected
+type GarbageCollectPolicyRule struct {
+	Selector GarbageCollectSelector `json:"selector"`
+	Strategy GarbageCollectStrategy `json:"strategy"`
+	// Delay is the duration to keep the resources, such as `1h`, it's required by the delete-after-delay strategy
+	Delay string `json:"delay,omitempty"`
+	// Revisions is the number of the revisions to keep the resources, it's required by the keep-last-n-revisions
+	// strategy
+	Revisions int `json:"revisions,omitempty"`
+}
+
+// GarbageCollectPolicySpec defines the spec of the garbage-collect policy
+type GarbageCollectPolicySpec struct {
+	// Rules are matched in order, the first matched rule applies. The resources matching no rule are deleted once
+	// they are removed from the application.
+	Rules []GarbageCollectPolicyRule `json:"rules"`
+}
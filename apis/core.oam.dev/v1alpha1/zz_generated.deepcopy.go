@@ -27,6 +27,26 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppHealthPolicySpec) DeepCopyInto(out *AppHealthPolicySpec) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]ComponentHealthRule, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppHealthPolicySpec.
+func (in *AppHealthPolicySpec) DeepCopy() *AppHealthPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AppHealthPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AppTemplate) DeepCopyInto(out *AppTemplate) {
 	*out = *in
@@ -58,6 +78,21 @@ func (in *ClusterDecision) DeepCopy() *ClusterDecision {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentHealthRule) DeepCopyInto(out *ComponentHealthRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentHealthRule.
+func (in *ComponentHealthRule) DeepCopy() *ComponentHealthRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentHealthRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapReference) DeepCopyInto(out *ConfigMapReference) {
 	*out = *in
@@ -73,6 +108,48 @@ func (in *ConfigMapReference) DeepCopy() *ConfigMapReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftDetectionPolicySpec) DeepCopyInto(out *DriftDetectionPolicySpec) {
+	*out = *in
+	if in.IgnoreFields != nil {
+		in, out := &in.IgnoreFields, &out.IgnoreFields
+		*out = make([]DriftIgnoreRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftDetectionPolicySpec.
+func (in *DriftDetectionPolicySpec) DeepCopy() *DriftDetectionPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftDetectionPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftIgnoreRule) DeepCopyInto(out *DriftIgnoreRule) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftIgnoreRule.
+func (in *DriftIgnoreRule) DeepCopy() *DriftIgnoreRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftIgnoreRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvBinding) DeepCopyInto(out *EnvBinding) {
 	*out = *in
@@ -275,6 +352,79 @@ func (in *EnvSelector) DeepCopy() *EnvSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollectPolicyRule) DeepCopyInto(out *GarbageCollectPolicyRule) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollectPolicyRule.
+func (in *GarbageCollectPolicyRule) DeepCopy() *GarbageCollectPolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollectPolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollectPolicySpec) DeepCopyInto(out *GarbageCollectPolicySpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]GarbageCollectPolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollectPolicySpec.
+func (in *GarbageCollectPolicySpec) DeepCopy() *GarbageCollectPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollectPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollectSelector) DeepCopyInto(out *GarbageCollectSelector) {
+	*out = *in
+	if in.ComponentNames != nil {
+		in, out := &in.ComponentNames, &out.ComponentNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TraitTypes != nil {
+		in, out := &in.TraitTypes, &out.TraitTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceKinds != nil {
+		in, out := &in.ResourceKinds, &out.ResourceKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceNames != nil {
+		in, out := &in.ResourceNames, &out.ResourceNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollectSelector.
+func (in *GarbageCollectSelector) DeepCopy() *GarbageCollectSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollectSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespaceSelector) DeepCopyInto(out *NamespaceSelector) {
 	*out = *in
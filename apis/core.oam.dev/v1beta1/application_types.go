@@ -69,6 +69,28 @@ type Workflow struct {
 	Steps []WorkflowStep `json:"steps,omitempty"`
 }
 
+// PreDeleteWorkflow defines the workflow steps run before the resources of the application are released on deletion
+type PreDeleteWorkflow struct {
+	Steps []WorkflowStep `json:"steps,omitempty"`
+
+	// Timeout is the max duration to run the steps, such as `10m`, the resources are released once it times out even
+	// if the steps are not finished. It is 10 minutes by default.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ApplicationPause records who paused the application and why
+type ApplicationPause struct {
+	// By is who paused the application
+	By string `json:"by,omitempty"`
+
+	// Reason is why the application is paused
+	Reason string `json:"reason,omitempty"`
+
+	// Since is when the application is paused
+	// +optional
+	Since *metav1.Time `json:"since,omitempty"`
+}
+
 // ApplicationSpec is the spec of Application
 type ApplicationSpec struct {
 	Components []common.ApplicationComponent `json:"components"`
@@ -85,6 +107,16 @@ type ApplicationSpec struct {
 	// - should mark "finish" phase in status.conditions.
 	Workflow *Workflow `json:"workflow,omitempty"`
 
+	// PreDelete defines the workflow steps run when the application is deleted, such as draining the traffic or
+	// taking a snapshot of the data. The resources of the application are kept until the steps are finished.
+	// +optional
+	PreDelete *PreDeleteWorkflow `json:"preDelete,omitempty"`
+
+	// Pause stops the reconciliation of the application if it's set, the resources, workflow and garbage collection
+	// are all left untouched until it's unset, but the health status is still updated.
+	// +optional
+	Pause *ApplicationPause `json:"pause,omitempty"`
+
 	// TODO(wonderflow): we should have application level scopes supported here
 
 	// RolloutPlan is the details on how to rollout the resources
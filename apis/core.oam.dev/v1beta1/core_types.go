@@ -139,6 +139,16 @@ type TraitDefinitionSpec struct {
 	// +optional
 	ConflictsWith []string `json:"conflictsWith,omitempty"`
 
+	// DependsOn specifies the list of traits(Definition name) which must be applied to the same workloads
+	// before this trait. The parser orders traits so that the dependencies are rendered first.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Stage specifies when the trait is rendered, traits in the PreProcess stage are rendered before the ones
+	// in the Default stage, and traits in the PostProcess stage are rendered at last.
+	// +optional
+	Stage TraitStageType `json:"stage,omitempty"`
+
 	// Schematic defines the data format and template of the encapsulation of the trait
 	// +optional
 	Schematic *common.Schematic `json:"schematic,omitempty"`
@@ -160,6 +170,19 @@ type TraitDefinitionSpec struct {
 	SkipRevisionAffect bool `json:"skipRevisionAffect,omitempty"`
 }
 
+// TraitStageType describes the stage in which the trait is rendered.
+// +kubebuilder:validation:Enum=PreProcess;Default;PostProcess
+type TraitStageType string
+
+const (
+	// PreStage represents the trait is rendered before the traits in the default stage
+	PreStage TraitStageType = "PreProcess"
+	// DefaultStage represents the trait is rendered in the order the user lists it
+	DefaultStage TraitStageType = "Default"
+	// PostStage represents the trait is rendered after the traits in the default stage
+	PostStage TraitStageType = "PostProcess"
+)
+
 // TraitDefinitionStatus is the status of TraitDefinition
 type TraitDefinitionStatus struct {
 	// ConditionedStatus reflects the observed status of a resource
@@ -275,6 +298,21 @@ type ResourceTracker struct {
 // ResourceTrackerStatus define the status of resourceTracker
 type ResourceTrackerStatus struct {
 	TrackedResources []corev1.ObjectReference `json:"trackedResources,omitempty"`
+	// RetainedResources are the resources removed from the application but retained by the garbage-collect policy,
+	// they are deleted once they expire
+	RetainedResources []RetainedResource `json:"retainedResources,omitempty"`
+}
+
+// RetainedResource is a resource retained by the garbage-collect policy after it's removed from the application
+type RetainedResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// DeleteAfter is the time after which the resource is deleted
+	DeleteAfter *metav1.Time `json:"deleteAfter,omitempty"`
+	// DeleteAtRevision is the revision of the application from which the resource is deleted
+	DeleteAtRevision int `json:"deleteAtRevision,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -315,6 +315,25 @@ func (in *ApplicationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationPause) DeepCopyInto(out *ApplicationPause) {
+	*out = *in
+	if in.Since != nil {
+		in, out := &in.Since, &out.Since
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationPause.
+func (in *ApplicationPause) DeepCopy() *ApplicationPause {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationPause)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationRevision) DeepCopyInto(out *ApplicationRevision) {
 	*out = *in
@@ -469,6 +488,16 @@ func (in *ApplicationSpec) DeepCopyInto(out *ApplicationSpec) {
 		*out = new(Workflow)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PreDelete != nil {
+		in, out := &in.PreDelete, &out.PreDelete
+		*out = new(PreDeleteWorkflow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pause != nil {
+		in, out := &in.Pause, &out.Pause
+		*out = new(ApplicationPause)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.RolloutPlan != nil {
 		in, out := &in.RolloutPlan, &out.RolloutPlan
 		*out = new(v1alpha1.RolloutPlan)
@@ -1085,6 +1114,28 @@ func (in *PolicyDefinitionStatus) DeepCopy() *PolicyDefinitionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreDeleteWorkflow) DeepCopyInto(out *PreDeleteWorkflow) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]WorkflowStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreDeleteWorkflow.
+func (in *PreDeleteWorkflow) DeepCopy() *PreDeleteWorkflow {
+	if in == nil {
+		return nil
+	}
+	out := new(PreDeleteWorkflow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceTracker) DeepCopyInto(out *ResourceTracker) {
 	*out = *in
@@ -1151,6 +1202,13 @@ func (in *ResourceTrackerStatus) DeepCopyInto(out *ResourceTrackerStatus) {
 		*out = make([]corev1.ObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.RetainedResources != nil {
+		in, out := &in.RetainedResources, &out.RetainedResources
+		*out = make([]RetainedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTrackerStatus.
@@ -1163,6 +1221,25 @@ func (in *ResourceTrackerStatus) DeepCopy() *ResourceTrackerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetainedResource) DeepCopyInto(out *RetainedResource) {
+	*out = *in
+	if in.DeleteAfter != nil {
+		in, out := &in.DeleteAfter, &out.DeleteAfter
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetainedResource.
+func (in *RetainedResource) DeepCopy() *RetainedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(RetainedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScopeDefinition) DeepCopyInto(out *ScopeDefinition) {
 	*out = *in
@@ -1347,6 +1424,11 @@ func (in *TraitDefinitionSpec) DeepCopyInto(out *TraitDefinitionSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Schematic != nil {
 		in, out := &in.Schematic, &out.Schematic
 		*out = new(common.Schematic)
@@ -93,6 +93,10 @@ const (
 	KubeCategory CapabilityCategory = "kube"
 
 	CUECategory CapabilityCategory = "cue"
+
+	KustomizeCategory CapabilityCategory = "kustomize"
+
+	K8sObjectsCategory CapabilityCategory = "k8s-objects"
 )
 
 // Parameter defines a parameter for cli from capability template
@@ -18,22 +18,30 @@ package types
 
 // reason for Application
 const (
-	ReasonParsed      = "Parsed"
-	ReasonRendered    = "Rendered"
-	ReasonRevisoned   = "Revisioned"
-	ReasonApplied     = "Applied"
-	ReasonHealthCheck = "HealthChecked"
-	ReasonDeployed    = "Deployed"
-	ReasonRollout     = "Rollout"
-
-	ReasonFailedParse       = "FailedParse"
-	ReasonFailedRender      = "FailedRender"
-	ReasonFailedRevision    = "FailedRevision"
-	ReasonFailedWorkflow    = "FailedWorkflow"
-	ReasonFailedApply       = "FailedApply"
-	ReasonFailedHealthCheck = "FailedHealthCheck"
-	ReasonFailedGC          = "FailedGC"
-	ReasonFailedRollout     = "FailedRollout"
+	ReasonParsed         = "Parsed"
+	ReasonRendered       = "Rendered"
+	ReasonRevisoned      = "Revisioned"
+	ReasonApplied        = "Applied"
+	ReasonHealthCheck    = "HealthChecked"
+	ReasonDeployed       = "Deployed"
+	ReasonRollout        = "Rollout"
+	ReasonDriftCorrected = "DriftCorrected"
+	ReasonPaused         = "Paused"
+	ReasonRolledBack     = "RolledBack"
+	ReasonPreDeleted     = "PreDeleted"
+
+	ReasonFailedParse          = "FailedParse"
+	ReasonFailedRender         = "FailedRender"
+	ReasonFailedRevision       = "FailedRevision"
+	ReasonFailedWorkflow       = "FailedWorkflow"
+	ReasonFailedApply          = "FailedApply"
+	ReasonFailedHealthCheck    = "FailedHealthCheck"
+	ReasonFailedGC             = "FailedGC"
+	ReasonFailedRollout        = "FailedRollout"
+	ReasonDriftDetected        = "DriftDetected"
+	ReasonFailedDriftDetection = "FailedDriftDetection"
+	ReasonFailedRollback       = "FailedRollback"
+	ReasonFailedPreDelete      = "FailedPreDelete"
 )
 
 // event message for Application
@@ -46,6 +54,10 @@ const (
 	MessageHealthCheck      = "Health checked healthy"
 	MessageDeployed         = "Deployed successfully"
 	MessageRollout          = "Rollout successfully"
+	MessageDriftCorrected   = "Drifted resources corrected: %s"
+	MessagePaused           = "Paused by %s, reason: %s"
+	MessageRolledBack       = "Rolled back to revision %s"
+	MessagePreDeleted       = "Pre-delete workflow finished: %s"
 
 	MessageFailedParse       = "fail to parse application, err: %v"
 	MessageFailedRender      = "fail to render application, err: %v"
@@ -53,4 +65,5 @@ const (
 	MessageFailedApply       = "fail to apply component, err: %v"
 	MessageFailedHealthCheck = "fail to health check, err: %v"
 	MessageFailedGC          = "fail to garbage collection, err: %v"
+	MessageDriftDetected     = "Resources drifted from the rendered manifests: %s"
 )
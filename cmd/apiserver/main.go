@@ -22,10 +22,12 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/oam-dev/kubevela/pkg/apiserver/log"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/audit"
 	"github.com/oam-dev/kubevela/version"
 )
 
@@ -36,7 +38,18 @@ func main() {
 	flag.StringVar(&s.restCfg.Datastore.Type, "datastore-type", "kubeapi", "Metadata storage driver type, support kubeapi and mongodb")
 	flag.StringVar(&s.restCfg.Datastore.Database, "datastore-database", "kubevela", "Metadata storage database name, takes effect when the storage driver is mongodb.")
 	flag.StringVar(&s.restCfg.Datastore.URL, "datastore-url", "", "Metadata storage database url,takes effect when the storage driver is mongodb.")
+	flag.StringVar(&s.restCfg.Auth.TokenAuthFile, "token-auth-file", "", "The csv file of static tokens to authenticate the requests, each line is: token,user[,group1|group2].")
+	flag.StringVar(&s.restCfg.Auth.OIDCIssuerURL, "oidc-issuer-url", "", "The issuer of the OIDC id token.")
+	flag.StringVar(&s.restCfg.Auth.OIDCClientID, "oidc-client-id", "", "The client id (audience) of the OIDC id token.")
+	flag.StringVar(&s.restCfg.Auth.OIDCJWKSFile, "oidc-jwks-file", "", "The local JWKS file to verify the OIDC id token, OIDC authentication is enabled if specified.")
+	flag.StringVar(&s.restCfg.Auth.OIDCUsernameClaim, "oidc-username-claim", "sub", "The claim of the OIDC id token used as the user name.")
+	flag.StringVar(&s.restCfg.Auth.OIDCGroupsClaim, "oidc-groups-claim", "groups", "The claim of the OIDC id token used as the user groups.")
+	flag.BoolVar(&s.restCfg.Auth.EnableTokenReview, "enable-token-review", false, "Authenticate the requests by the TokenReview api of the kubernetes.")
+	superUserGroups := flag.String("super-user-groups", "system:masters", "The comma separated groups whose users skip all the permission checks.")
+	flag.StringVar(&s.restCfg.Audit.LogFile, "audit-log-file", "", "The JSON-lines file the audit records are appended to, besides the datastore.")
+	flag.DurationVar(&s.restCfg.Audit.Retention, "audit-retention", audit.DefaultRetention, "The duration the audit records are kept in the datastore, they are kept forever if it is zero.")
 	flag.Parse()
+	s.restCfg.Auth.SuperUserGroups = strings.Split(*superUserGroups, ",")
 
 	srvc := make(chan struct{})
 
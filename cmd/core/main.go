@@ -37,15 +37,20 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/appfile/helm"
+	"github.com/oam-dev/kubevela/pkg/appfile/kustomize"
 	standardcontroller "github.com/oam-dev/kubevela/pkg/controller"
 	commonconfig "github.com/oam-dev/kubevela/pkg/controller/common"
 	oamcontroller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	oamv1alpha2 "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2"
 	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/cue/definition"
 	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/cue/task"
 	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 	"github.com/oam-dev/kubevela/pkg/utils/system"
 	oamwebhook "github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev"
@@ -83,6 +88,8 @@ func main() {
 	var renewDeadline time.Duration
 	var retryPeriod time.Duration
 	var enableClusterGateway bool
+	var serverSideApplyControllers string
+	var processingAllowedHosts string
 
 	flag.BoolVar(&useWebhook, "use-webhook", false, "Enable Admission Webhook")
 	flag.StringVar(&certDir, "webhook-cert-dir", "/k8s-webhook-server/serving-certs", "Admission webhook cert/key dir.")
@@ -113,6 +120,14 @@ func main() {
 		"controller shared informer lister full re-sync period")
 	flag.StringVar(&oam.SystemDefinitonNamespace, "system-definition-namespace", "vela-system", "define the namespace of the system-level definition")
 	flag.IntVar(&controllerArgs.ConcurrentReconciles, "concurrent-reconciles", 4, "concurrent-reconciles is the concurrent reconcile number of the controller. The default value is 4")
+	flag.Float64Var(&controllerArgs.AppNamespaceQPS, "application-namespace-qps", 10, "the qps of the applications enqueued to the application controller in each namespace, "+
+		"events of high priority applications are not limited. Set it to 0 to disable the limit.")
+	flag.IntVar(&controllerArgs.AppNamespaceBurst, "application-namespace-burst", 20, "the burst of the applications enqueued to the application controller in each namespace.")
+	flag.Float64Var(&controllerArgs.AppLowPriorityQPS, "application-low-priority-qps", 5, "the qps of the low priority applications enqueued to the application controller in all namespaces. "+
+		"Set it to 0 to disable the limit.")
+	flag.IntVar(&controllerArgs.AppLowPriorityBurst, "application-low-priority-burst", 10, "the burst of the low priority applications enqueued to the application controller in all namespaces.")
+	flag.BoolVar(&controllerArgs.DefinitionCompatibilityCheck, "definition-compatibility-check", false, "Deny the updates of component and trait definitions "+
+		"whose parameter changes would break the existing applications, it requires the admission webhook.")
 	flag.Float64Var(&qps, "kube-api-qps", 50, "the qps for reconcile clients. Low qps may lead to low throughput. High qps may give stress to api-server. Raise this value if concurrent-reconciles is set to be high.")
 	flag.IntVar(&burst, "kube-api-burst", 100, "the burst for reconcile clients. Recommend setting it qps*2.")
 	flag.DurationVar(&controllerArgs.DependCheckWait, "depend-check-wait", 30*time.Second, "depend-check-wait is the time to wait for ApplicationConfiguration's dependent-resource ready."+
@@ -128,14 +143,54 @@ func main() {
 	flag.DurationVar(&retryPeriod, "leader-election-retry-period", 2*time.Second,
 		"The duration the LeaderElector clients should wait between tries of actions")
 	flag.BoolVar(&enableClusterGateway, "enable-cluster-gateway", false, "Enable cluster-gateway to use multicluster, disabled by default.")
+	flag.StringVar(&serverSideApplyControllers, "server-side-apply-controllers", "", "The comma separated list of the controllers applying resources by server-side apply instead of "+
+		"the last-applied-configuration annotation, available options: application, approllout, envbinding, applicationconfiguration, rollout or * for all. "+
+		"The annotation is removed from the resources once they are applied by server-side apply.")
+	flag.StringVar(&controllerArgs.ApplyFieldManager, "server-side-apply-field-manager", apply.DefaultFieldManager, "The field manager of the server-side apply.")
+	flag.BoolVar(&controllerArgs.ApplyForceConflicts, "server-side-apply-force-conflicts", true, "Take over the conflicting fields owned by other field managers in the server-side apply, "+
+		"the apply fails on conflicts if disabled.")
+	flag.DurationVar(&definition.RenderTimeout, "render-timeout", 30*time.Second, "The max duration of rendering the template of a definition, "+
+		"the rendering is aborted with an error once it is reached. The CUE evaluation cannot be interrupted, so a timed-out evaluation keeps running in the background "+
+		"until it returns, holding one of the max-concurrent-renders slots, and its definition cannot be rendered again meanwhile. Set it to 0 to disable the timeout.")
+	flag.IntVar(&definition.MaxConcurrentRenders, "max-concurrent-renders", 64, "The max number of the templates of definitions rendered at the same time, "+
+		"including the timed-out evaluations still running in the background. The rendering fails fast once it is reached. Set it to 0 to disable the limit.")
+	flag.IntVar(&definition.MaxRenderOutputSize, "max-render-output-size", 4*1024*1024, "The max size in bytes of the resources rendered by the template of a definition. "+
+		"Set it to 0 to disable the limit.")
+	flag.IntVar(&definition.RenderCacheSize, "render-cache-size", 1024, "The max number of the rendered results of definitions cached in memory, "+
+		"a definition is evaluated again only if its template, parameters or context changes. Set it to 0 to disable the cache.")
+	flag.IntVar(&definition.RenderCacheMaxBytes, "render-cache-max-bytes", 128*1024*1024, "The max total size in bytes of the rendered results of definitions cached in memory, "+
+		"the least recently used results are evicted once it is exceeded. Set it to 0 to disable the limit.")
+	flag.BoolVar(&task.ProcessingDisabled, "disable-template-processing", false, "Disable the http processing in the templates of definitions.")
+	flag.StringVar(&processingAllowedHosts, "template-processing-allowed-hosts", "", "The comma separated list of hosts the http processing in the templates of definitions can request, "+
+		"e.g. 'auth.example.com,*.internal.example.com'. All hosts are allowed if it is empty.")
+	flag.DurationVar(&kustomize.FetchTimeout, "kustomize-fetch-timeout", time.Minute, "The max duration of fetching the Git or OCI source of a kustomize schematic.")
+	flag.Int64Var(&kustomize.MaxSourceSize, "kustomize-max-source-size", 32*1024*1024, "The max size in bytes of the Git objects or OCI layers fetched for a kustomize schematic, "+
+		"and of the files extracted from them. Set it to 0 to disable the limit.")
+	flag.IntVar(&kustomize.SourceCacheSize, "kustomize-source-cache-size", 16, "The max number of the fetched Git or OCI sources of kustomize schematics cached in memory, "+
+		"the sources are pinned to a commit or a digest so they are only fetched once. Set it to 0 to disable the cache.")
+	flag.DurationVar(&helm.FetchTimeout, "helm-fetch-timeout", time.Minute, "The max duration of fetching the repository index or the chart of a Helm module in the native mode.")
+	flag.Int64Var(&helm.MaxChartSize, "helm-max-chart-size", 16*1024*1024, "The max size in bytes of the repository index or the chart archive fetched for a Helm module "+
+		"in the native mode. Set it to 0 to disable the limit.")
+	flag.DurationVar(&helm.ChartURLCacheTTL, "helm-chart-url-cache-ttl", 5*time.Minute, "How long the chart URL resolved from the repository index is cached for a Helm module "+
+		"in the native mode, so the index is not fetched every time a chart without version is rendered. Set it to 0 to disable the cache.")
 
 	flag.Parse()
+	if processingAllowedHosts != "" {
+		task.ProcessingAllowedHosts = strings.Split(processingAllowedHosts, ",")
+	}
 	// setup logging
 	klog.InitFlags(nil)
 	if logDebug {
 		_ = flag.Set("v", strconv.Itoa(int(commonconfig.LogDebug)))
 	}
 
+	ssaControllers, err := oamcontroller.ParseServerSideApplyControllers(serverSideApplyControllers)
+	if err != nil {
+		klog.ErrorS(err, "Invalid server-side-apply-controllers")
+		os.Exit(1)
+	}
+	controllerArgs.ServerSideApplyControllers = ssaControllers
+
 	if pprofAddr != "" {
 		// Start pprof server if enabled
 		mux := http.NewServeMux()
@@ -18,6 +18,14 @@ package datastore
 
 import (
 	"context"
+	"errors"
+)
+
+var (
+	// ErrRecordExist represents the data record already exists
+	ErrRecordExist = errors.New("data record already exists")
+	// ErrRecordNotExist represents the data record does not exist
+	ErrRecordNotExist = errors.New("data record does not exist")
 )
 
 // Config datastore config
@@ -42,9 +50,19 @@ type DataStore interface {
 
 	FindOne(ctx context.Context, kind, name string) (Iterator, error)
 
+	// FindByIndex executes a find command filtered by the datastore and returns an iterator over the items whose
+	// indexes equal all the given values, see Indexer.
+	FindByIndex(ctx context.Context, kind string, index map[string]string) (Iterator, error)
+
 	IsExist(ctx context.Context, kind, name string) (bool, error)
 }
 
+// Indexer is implemented by the data models which can be found by FindByIndex. The indexes are recorded when the
+// entity is added or put, and the index names must be the field names of the entity stored in mongodb.
+type Indexer interface {
+	Index() map[string]string
+}
+
 // Iterator dataset query
 type Iterator interface {
 	// Next gets the next item for this cursor.
@@ -18,50 +18,241 @@ package kubeapi
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
 )
 
+const (
+	// labelDataKind the label key records the kind of the data model stored in the configmap
+	labelDataKind = "datastore.oam.dev/kind"
+	// labelDataName the label key records the name of the data model stored in the configmap
+	labelDataName = "datastore.oam.dev/name"
+	// dataKey the configmap data key of the data model
+	dataKey = "data"
+	// labelIndexPrefix the prefix of the label keys record the indexes of the data model, the label values are the
+	// hash of the index values as the index values may be invalid label values
+	labelIndexPrefix = "index.datastore.oam.dev/"
+)
+
+// kubeapi stores every data model as one ConfigMap in the namespace specified by the database config
 type kubeapi struct {
-	// kubeclient client.Client
+	kubeclient client.Client
+	namespace  string
 }
 
 // New new kubeapi datastore instance
-func New(ctx context.Context, cfg datastore.Config) (datastore.DataStore, error) {
-	return &kubeapi{}, nil
+func New(ctx context.Context, cfg datastore.Config, kubeClient client.Client) (datastore.DataStore, error) {
+	if cfg.Database == "" {
+		return nil, fmt.Errorf("the database (namespace) of kubeapi datastore can not be empty")
+	}
+	ns := &corev1.Namespace{}
+	if err := kubeClient.Get(ctx, types.NamespacedName{Name: cfg.Database}, ns); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		ns.Name = cfg.Database
+		if err := kubeClient.Create(ctx, ns); err != nil && !apierrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+	}
+	return &kubeapi{kubeclient: kubeClient, namespace: cfg.Database}, nil
+}
+
+func configMapName(kind, name string) string {
+	return strings.ToLower(fmt.Sprintf("%s-%s", kind, name))
+}
+
+func entityName(entity interface{}) (string, []byte, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return "", nil, err
+	}
+	meta := struct {
+		Name string `json:"name"`
+	}{}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return "", nil, err
+	}
+	if meta.Name == "" {
+		return "", nil, fmt.Errorf("the name of the data model can not be empty")
+	}
+	return meta.Name, data, nil
+}
+
+func indexLabels(entity interface{}) map[string]string {
+	labels := map[string]string{}
+	if indexer, ok := entity.(datastore.Indexer); ok {
+		for k, v := range indexer.Index() {
+			labels[labelIndexPrefix+k] = indexLabelValue(v)
+		}
+	}
+	return labels
+}
+
+func indexLabelValue(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])[:40]
+}
+
+func (m *kubeapi) newConfigMap(kind, name string, data []byte, index map[string]string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName(kind, name),
+			Namespace: m.namespace,
+			Labels: map[string]string{
+				labelDataKind: strings.ToLower(kind),
+				labelDataName: name,
+			},
+		},
+		Data: map[string]string{dataKey: string(data)},
+	}
+	for k, v := range index {
+		cm.Labels[k] = v
+	}
+	return cm
+}
+
+func (m *kubeapi) getConfigMap(ctx context.Context, kind, name string) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	if err := m.kubeclient.Get(ctx, types.NamespacedName{Namespace: m.namespace, Name: configMapName(kind, name)}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, datastore.ErrRecordNotExist
+		}
+		return nil, err
+	}
+	return cm, nil
 }
 
 // Add add data model
 func (m *kubeapi) Add(ctx context.Context, kind string, entity interface{}) error {
+	name, data, err := entityName(entity)
+	if err != nil {
+		return err
+	}
+	if err := m.kubeclient.Create(ctx, m.newConfigMap(kind, name, data, indexLabels(entity))); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return datastore.ErrRecordExist
+		}
+		return err
+	}
 	return nil
 }
 
 // Get get data model
 func (m *kubeapi) Get(ctx context.Context, kind, name string, decodeTo interface{}) error {
-	return nil
+	cm, err := m.getConfigMap(ctx, kind, name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(cm.Data[dataKey]), decodeTo)
 }
 
 // Put update data model
 func (m *kubeapi) Put(ctx context.Context, kind, name string, entity interface{}) error {
-	return nil
+	cm, err := m.getConfigMap(ctx, kind, name)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	cm.Data = map[string]string{dataKey: string(data)}
+	for k := range cm.Labels {
+		if strings.HasPrefix(k, labelIndexPrefix) {
+			delete(cm.Labels, k)
+		}
+	}
+	for k, v := range indexLabels(entity) {
+		cm.Labels[k] = v
+	}
+	return m.kubeclient.Update(ctx, cm)
 }
 
 // Find find data model
 func (m *kubeapi) Find(ctx context.Context, kind string) (datastore.Iterator, error) {
-	return nil, nil
+	return m.list(ctx, client.MatchingLabels{labelDataKind: strings.ToLower(kind)})
 }
 
 // FindOne find one data model
 func (m *kubeapi) FindOne(ctx context.Context, kind, name string) (datastore.Iterator, error) {
-	return nil, nil
+	return m.list(ctx, client.MatchingLabels{labelDataKind: strings.ToLower(kind), labelDataName: name})
+}
+
+// FindByIndex find the data models by the index labels
+func (m *kubeapi) FindByIndex(ctx context.Context, kind string, index map[string]string) (datastore.Iterator, error) {
+	selector := client.MatchingLabels{labelDataKind: strings.ToLower(kind)}
+	for k, v := range index {
+		selector[labelIndexPrefix+k] = indexLabelValue(v)
+	}
+	return m.list(ctx, selector)
+}
+
+func (m *kubeapi) list(ctx context.Context, selector client.MatchingLabels) (datastore.Iterator, error) {
+	cms := &corev1.ConfigMapList{}
+	if err := m.kubeclient.List(ctx, cms, client.InNamespace(m.namespace), selector); err != nil {
+		return nil, err
+	}
+	it := &Iterator{index: -1}
+	for _, cm := range cms.Items {
+		it.items = append(it.items, []byte(cm.Data[dataKey]))
+	}
+	return it, nil
 }
 
 // IsExist determine whether data exists.
 func (m *kubeapi) IsExist(ctx context.Context, kind, name string) (bool, error) {
-	return true, nil
+	_, err := m.getConfigMap(ctx, kind, name)
+	if err == nil {
+		return true, nil
+	}
+	if err == datastore.ErrRecordNotExist {
+		return false, nil
+	}
+	return false, err
 }
 
 // Delete delete data
 func (m *kubeapi) Delete(ctx context.Context, kind, name string) error {
+	cm, err := m.getConfigMap(ctx, kind, name)
+	if err != nil {
+		return err
+	}
+	return client.IgnoreNotFound(m.kubeclient.Delete(ctx, cm))
+}
+
+// Iterator kubeapi iterator implementation
+type Iterator struct {
+	items [][]byte
+	index int
+}
+
+// Next read next data
+func (i *Iterator) Next(ctx context.Context) bool {
+	i.index++
+	return i.index < len(i.items)
+}
+
+// Decode decode data
+func (i *Iterator) Decode(entity interface{}) error {
+	if i.index < 0 || i.index >= len(i.items) {
+		return datastore.ErrRecordNotExist
+	}
+	return json.Unmarshal(i.items[i.index], entity)
+}
+
+// Close iterator close
+func (i *Iterator) Close(ctx context.Context) error {
 	return nil
 }
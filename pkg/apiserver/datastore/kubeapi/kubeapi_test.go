@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestKubeAPIDataStore(t *testing.T) {
+	ctx := context.Background()
+	ds, err := New(ctx, datastore.Config{Type: "kubeapi", Database: "kubevela"}, fake.NewClientBuilder().WithScheme(common.Scheme).Build())
+	require.NoError(t, err)
+
+	require.NoError(t, ds.Add(ctx, model.ClusterKind, &model.Cluster{Name: "c1", Description: "first"}))
+	require.NoError(t, ds.Add(ctx, model.ClusterKind, &model.Cluster{Name: "c2"}))
+	assert.Equal(t, datastore.ErrRecordExist, ds.Add(ctx, model.ClusterKind, &model.Cluster{Name: "c1"}))
+
+	cluster := &model.Cluster{}
+	require.NoError(t, ds.Get(ctx, model.ClusterKind, "c1", cluster))
+	assert.Equal(t, "first", cluster.Description)
+	assert.Equal(t, datastore.ErrRecordNotExist, ds.Get(ctx, model.ClusterKind, "c3", cluster))
+
+	require.NoError(t, ds.Put(ctx, model.ClusterKind, "c1", &model.Cluster{Name: "c1", Description: "updated"}))
+	require.NoError(t, ds.Get(ctx, model.ClusterKind, "c1", cluster))
+	assert.Equal(t, "updated", cluster.Description)
+
+	it, err := ds.Find(ctx, model.ClusterKind)
+	require.NoError(t, err)
+	var names []string
+	for it.Next(ctx) {
+		item := &model.Cluster{}
+		require.NoError(t, it.Decode(item))
+		names = append(names, item.Name)
+	}
+	assert.ElementsMatch(t, []string{"c1", "c2"}, names)
+
+	exist, err := ds.IsExist(ctx, model.ClusterKind, "c2")
+	require.NoError(t, err)
+	assert.True(t, exist)
+	require.NoError(t, ds.Delete(ctx, model.ClusterKind, "c2"))
+	exist, err = ds.IsExist(ctx, model.ClusterKind, "c2")
+	require.NoError(t, err)
+	assert.False(t, exist)
+}
+
+func TestKubeAPIDataStoreFindByIndex(t *testing.T) {
+	ctx := context.Background()
+	ds, err := New(ctx, datastore.Config{Type: "kubeapi", Database: "kubevela"}, fake.NewClientBuilder().WithScheme(common.Scheme).Build())
+	require.NoError(t, err)
+
+	require.NoError(t, ds.Add(ctx, model.AuditRecordKind, &model.AuditRecord{Name: "r1", User: "alice@example.com", Date: "20211001"}))
+	require.NoError(t, ds.Add(ctx, model.AuditRecordKind, &model.AuditRecord{Name: "r2", User: "bob", Date: "20211001"}))
+	require.NoError(t, ds.Add(ctx, model.AuditRecordKind, &model.AuditRecord{Name: "r3", User: "alice@example.com", Date: "20211002"}))
+
+	find := func(index map[string]string) []string {
+		it, err := ds.FindByIndex(ctx, model.AuditRecordKind, index)
+		require.NoError(t, err)
+		var names []string
+		for it.Next(ctx) {
+			item := &model.AuditRecord{}
+			require.NoError(t, it.Decode(item))
+			names = append(names, item.Name)
+		}
+		return names
+	}
+	assert.ElementsMatch(t, []string{"r1", "r3"}, find(map[string]string{"user": "alice@example.com"}))
+	assert.ElementsMatch(t, []string{"r1"}, find(map[string]string{"user": "alice@example.com", "date": "20211001"}))
+
+	// the index labels are refreshed by the update
+	require.NoError(t, ds.Put(ctx, model.AuditRecordKind, "r2", &model.AuditRecord{Name: "r2", User: "alice@example.com", Date: "20211002"}))
+	assert.ElementsMatch(t, []string{"r2", "r3"}, find(map[string]string{"date": "20211002"}))
+	assert.Empty(t, find(map[string]string{"user": "bob"}))
+}
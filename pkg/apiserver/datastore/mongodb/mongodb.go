@@ -65,7 +65,11 @@ func (m *mongodb) Add(ctx context.Context, kind string, entity interface{}) erro
 // Get get data model
 func (m *mongodb) Get(ctx context.Context, kind, name string, decodeTo interface{}) error {
 	collection := m.client.Database(m.database).Collection(kind)
-	return collection.FindOne(ctx, makeNameFilter(name)).Decode(decodeTo)
+	err := collection.FindOne(ctx, makeNameFilter(name)).Decode(decodeTo)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return datastore.ErrRecordNotExist
+	}
+	return err
 }
 
 // Put update data model
@@ -101,6 +105,20 @@ func (m *mongodb) FindOne(ctx context.Context, kind, name string) (datastore.Ite
 	return &Iterator{cur: cur}, nil
 }
 
+// FindByIndex find the data models whose fields equal the index values
+func (m *mongodb) FindByIndex(ctx context.Context, kind string, index map[string]string) (datastore.Iterator, error) {
+	collection := m.client.Database(m.database).Collection(kind)
+	filter := bson.M{}
+	for k, v := range index {
+		filter[k] = v
+	}
+	cur, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &Iterator{cur: cur}, nil
+}
+
 // IsExist determine whether data exists.
 func (m *mongodb) IsExist(ctx context.Context, kind, name string) (bool, error) {
 	collection := m.client.Database(m.database).Collection(kind)
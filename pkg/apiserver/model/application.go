@@ -0,0 +1,42 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+// ApplicationKind the datastore kind of the Application model
+const ApplicationKind = "application"
+
+// Application defines the data model of an application managed by the api server
+type Application struct {
+	// ID is the datastore key of the application, it's <namespace>.<name> as the name is unique in its namespace.
+	// The applications stored before have the bare name as the ID.
+	ID string `json:"name"`
+	// Name is the name of the deployed application, it's unique in the namespace.
+	Name string `json:"app_name,omitempty"`
+	// Project is the project the application belongs to, every application belongs to exactly one project.
+	Project     string            `json:"project"`
+	Namespace   string            `json:"namespace"`
+	Description string            `json:"description,omitempty"`
+	Icon        string            `json:"icon,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	ClusterList []string          `json:"cluster_list,omitempty"`
+	// YamlConfig is the yaml of the v1beta1 Application deployed to the kubernetes.
+	YamlConfig string `json:"yaml_config,omitempty"`
+	// CreatedAt is the unix time when the application is created.
+	CreatedAt int64 `json:"created_at,omitempty"`
+	// UpdatedAt is the unix time of the last time when the application is updated.
+	UpdatedAt int64 `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+// AuditRecordKind the datastore kind of the AuditRecord model
+const AuditRecordKind = "auditrecord"
+
+// AuditRecord defines the data model of the audit record of a mutating request
+type AuditRecord struct {
+	// Name is the unique id of the record.
+	Name string `json:"name"`
+	// User is the principal of the request, empty if the request is not authenticated.
+	User   string   `json:"user,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	Method string   `json:"method"`
+	// Path is the URL path of the request.
+	Path string `json:"path"`
+	// ResourceType and ResourceName are the target resource parsed from the path, such as clusters and the cluster name.
+	ResourceType string `json:"resource_type,omitempty"`
+	ResourceName string `json:"resource_name,omitempty"`
+	// RequestBodyHash is the sha256 hash of the request body, or of its first bytes if it is truncated.
+	RequestBodyHash string `json:"request_body_hash,omitempty"`
+	// RequestBodyTruncated is true if the request body is too large to be hashed completely.
+	RequestBodyTruncated bool `json:"request_body_truncated,omitempty"`
+	StatusCode           int  `json:"status_code"`
+	// Outcome is success if the status code is less than 400, otherwise failure.
+	Outcome string `json:"outcome"`
+	// LatencyMilliseconds is the time taken to handle the request.
+	LatencyMilliseconds int64  `json:"latency_ms"`
+	RemoteAddr          string `json:"remote_addr,omitempty"`
+	// Timestamp is the unix time in nanoseconds when the request is received.
+	Timestamp int64 `json:"timestamp"`
+	// Date is the UTC date of the timestamp in the AuditRecordDateFormat, it is indexed to filter the records by day.
+	Date string `json:"date"`
+}
+
+// AuditRecordDateFormat the format of the date of the audit record
+const AuditRecordDateFormat = "20060102"
+
+// Index the records are found by the user and the date
+func (a *AuditRecord) Index() map[string]string {
+	return map[string]string{"user": a.User, "date": a.Date}
+}
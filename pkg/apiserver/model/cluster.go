@@ -0,0 +1,34 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+// ClusterKind the datastore kind of the Cluster model
+const ClusterKind = "cluster"
+
+// Cluster defines the data model of a managed cluster.
+// The credential of the cluster is kept in the cluster-gateway secret, only the
+// descriptive metadata is stored in the datastore.
+type Cluster struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Icon        string            `json:"icon,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	// CreatedAt is the unix time when the cluster is created.
+	CreatedAt int64 `json:"created_at,omitempty"`
+	// UpdatedAt is the unix time of the last time when the cluster is updated.
+	UpdatedAt int64 `json:"updated_at,omitempty"`
+}
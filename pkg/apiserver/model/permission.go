@@ -0,0 +1,35 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+// PermissionKind the datastore kind of the Permission model
+const PermissionKind = "permission"
+
+// Permission defines the data model of a role granted to a user or a group.
+// The role is identified by the verb (read, write or admin) on the scope (project, namespace or cluster).
+type Permission struct {
+	// Name is generated from the subject and the scope, so one subject has at most one verb on a scope.
+	Name  string `json:"name"`
+	User  string `json:"user,omitempty"`
+	Group string `json:"group,omitempty"`
+	Scope string `json:"scope"`
+	// ScopeName is the name of the project, namespace or cluster, `*` matches all of them.
+	ScopeName string `json:"scope_name"`
+	Verb      string `json:"verb"`
+	// CreatedAt is the unix time when the permission is granted.
+	CreatedAt int64 `json:"created_at,omitempty"`
+}
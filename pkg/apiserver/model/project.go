@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+const (
+	// ProjectKind the datastore kind of the Project model
+	ProjectKind = "project"
+	// EnvironmentKind the datastore kind of the Environment model
+	EnvironmentKind = "environment"
+)
+
+// Project defines the data model of a project, which groups applications, environments and members
+type Project struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Members     []ProjectMember `json:"members,omitempty"`
+	Quota       ProjectQuota    `json:"quota"`
+	// CreatedAt is the unix time when the project is created.
+	CreatedAt int64 `json:"created_at,omitempty"`
+	// UpdatedAt is the unix time of the last time when the project is updated.
+	UpdatedAt int64 `json:"updated_at,omitempty"`
+}
+
+// ProjectMember the member of the project and the verb (read, write or admin) granted in the project
+type ProjectMember struct {
+	User string `json:"user"`
+	Verb string `json:"verb"`
+}
+
+// ProjectQuota limits the resources a project can use, zero means unlimited
+type ProjectQuota struct {
+	// MaxApplications is the max number of the applications in the project.
+	MaxApplications int `json:"max_applications,omitempty"`
+	// MaxClusters is the max number of the distinct clusters targeted by the environments of the project.
+	MaxClusters int `json:"max_clusters,omitempty"`
+}
+
+// Environment defines the data model of an environment, which is the deploy target of the applications in a project
+type Environment struct {
+	// Name is unique across all the projects.
+	Name        string `json:"name"`
+	Project     string `json:"project"`
+	Description string `json:"description,omitempty"`
+	Namespace   string `json:"namespace"`
+	// Clusters are the names of the target clusters, the local cluster is used if empty.
+	Clusters []string `json:"clusters,omitempty"`
+	// CreatedAt is the unix time when the environment is created.
+	CreatedAt int64 `json:"created_at,omitempty"`
+}
@@ -95,13 +95,21 @@ type AddonStatusResponse struct {
 	Phase AddonPhase `json:"phase"`
 }
 
+const (
+	// ClusterStatusHealthy the cluster is reachable through the cluster-gateway
+	ClusterStatusHealthy = "Healthy"
+	// ClusterStatusUnhealthy the cluster is unreachable, the reason is recorded in ClusterBase.Reason
+	ClusterStatusUnhealthy = "Unhealthy"
+)
+
 // CreateClusterRequest request parameters to create a cluster
 type CreateClusterRequest struct {
-	Name             string            `json:"name" validate:"required"`
-	Description      string            `json:"description,omitempty"`
-	Icon             string            `json:"icon"`
-	KubeConfig       string            `json:"kubeConfig" validate:"required_without=kubeConfigSecret"`
-	KubeConfigSecret string            `json:"kubeConfigSecret,omitempty" validate:"required_without=kubeConfig"`
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description,omitempty"`
+	Icon        string `json:"icon"`
+	KubeConfig  string `json:"kubeConfig" validate:"required_without=KubeConfigSecret"`
+	// KubeConfigSecret is the name of the secret in the cluster-gateway namespace, which stores the kubeconfig in the `kubeconfig` key
+	KubeConfigSecret string            `json:"kubeConfigSecret,omitempty" validate:"required_without=KubeConfig"`
 	Labels           map[string]string `json:"labels,omitempty"`
 }
 
@@ -140,6 +148,14 @@ type ClusterBase struct {
 	Reason      string            `json:"reason"`
 }
 
+// ListApplicationOptions the filters to list applications
+type ListApplicationOptions struct {
+	Project   string
+	Namespace string
+	Cluster   string
+	Query     string
+}
+
 // ListApplicationResponse list applications by query params
 type ListApplicationResponse struct {
 	Applications []*ApplicationBase `json:"applications"`
@@ -148,6 +164,7 @@ type ListApplicationResponse struct {
 // ApplicationBase application base model
 type ApplicationBase struct {
 	Name            string            `json:"name"`
+	Project         string            `json:"project"`
 	Namespace       string            `json:"namespace"`
 	Description     string            `json:"description"`
 	CreateTime      time.Time         `json:"createTime"`
@@ -181,12 +198,15 @@ type GatewayRule struct {
 // CreateApplicationRequest create application request body
 type CreateApplicationRequest struct {
 	Name        string            `json:"name" validate:"required"`
+	Project     string            `json:"project" validate:"required"`
 	Namespace   string            `json:"namespace" validate:"required"`
 	Description string            `json:"description"`
 	Icon        string            `json:"icon"`
 	Labels      map[string]string `json:"labels,omitempty"`
-	ClusterList []string          `json:"clusterList,omitempty"`
-	YamlConfig  string            `json:"yamlConfig,omitempty"`
+	// ClusterList must be the subset of the clusters of the environment the namespace belongs to,
+	// all the clusters of the environment are used if empty
+	ClusterList []string `json:"clusterList,omitempty"`
+	YamlConfig  string   `json:"yamlConfig,omitempty"`
 }
 
 // DetailApplicationResponse application detail
@@ -198,6 +218,23 @@ type DetailApplicationResponse struct {
 	WorkflowStatus []WorkflowStepStatus    `json:"workflowStatus"`
 }
 
+// ApplicationRevisionDiffResponse the differences between two revisions of the application
+type ApplicationRevisionDiffResponse struct {
+	From string                        `json:"from"`
+	To   string                        `json:"to"`
+	Diff *ApplicationRevisionDiffEntry `json:"diff"`
+}
+
+// ApplicationRevisionDiffEntry the differences of the application, a component or a trait, the lines of the
+// manifest are prefixed with "+" if added, "-" if removed and " " if unchanged
+type ApplicationRevisionDiffEntry struct {
+	Name     string                          `json:"name"`
+	Kind     string                          `json:"kind"`
+	DiffType string                          `json:"diffType,omitempty"`
+	Lines    []string                        `json:"lines,omitempty"`
+	Subs     []*ApplicationRevisionDiffEntry `json:"subs,omitempty"`
+}
+
 // WorkflowStepStatus workflow step status model
 type WorkflowStepStatus struct {
 	Name     string        `json:"name"`
@@ -306,3 +343,170 @@ type Param struct {
 	Type         string      `json:"type"`
 	Description  string      `json:"description"`
 }
+
+// CreatePermissionRequest grant the verb on the scope to the user or the group
+type CreatePermissionRequest struct {
+	User      string `json:"user,omitempty" validate:"required_without=Group"`
+	Group     string `json:"group,omitempty" validate:"required_without=User"`
+	Scope     string `json:"scope" validate:"oneof=project namespace cluster"`
+	ScopeName string `json:"scopeName" validate:"required"`
+	Verb      string `json:"verb" validate:"oneof=read write admin"`
+}
+
+// PermissionBase permission base model
+type PermissionBase struct {
+	Name       string    `json:"name"`
+	User       string    `json:"user,omitempty"`
+	Group      string    `json:"group,omitempty"`
+	Scope      string    `json:"scope"`
+	ScopeName  string    `json:"scopeName"`
+	Verb       string    `json:"verb"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+// ListPermissionResponse list permissions
+type ListPermissionResponse struct {
+	Permissions []PermissionBase `json:"permissions"`
+}
+
+// ListAuditRecordOptions the filters to list audit records
+type ListAuditRecordOptions struct {
+	User  string
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// AuditRecordBase audit record model
+type AuditRecordBase struct {
+	Name            string        `json:"name"`
+	User            string        `json:"user,omitempty"`
+	Groups          []string      `json:"groups,omitempty"`
+	Method          string        `json:"method"`
+	Path            string        `json:"path"`
+	ResourceType    string        `json:"resourceType,omitempty"`
+	ResourceName    string        `json:"resourceName,omitempty"`
+	RequestBodyHash string        `json:"requestBodyHash,omitempty"`
+	StatusCode      int           `json:"statusCode"`
+	Outcome         string        `json:"outcome"`
+	Latency         time.Duration `json:"latency"`
+	RemoteAddr      string        `json:"remoteAddr,omitempty"`
+	Time            time.Time     `json:"time"`
+}
+
+// ListAuditRecordResponse list audit records, the latest record is the first
+type ListAuditRecordResponse struct {
+	Records []AuditRecordBase `json:"records"`
+}
+
+// ApplicationEventType the type of the application status event
+type ApplicationEventType string
+
+const (
+	// ApplicationEventPhase the phase of the application is changed
+	ApplicationEventPhase ApplicationEventType = "phase"
+	// ApplicationEventWorkflowStep the status of a workflow step is changed
+	ApplicationEventWorkflowStep ApplicationEventType = "workflow-step"
+	// ApplicationEventComponentHealth the health of a component is changed
+	ApplicationEventComponentHealth ApplicationEventType = "component-health"
+	// ApplicationEventDeleted the application is deleted
+	ApplicationEventDeleted ApplicationEventType = "deleted"
+)
+
+// ApplicationEvent the status change of the application pushed by the watch stream
+type ApplicationEvent struct {
+	Type            ApplicationEventType `json:"type"`
+	Name            string               `json:"name"`
+	Namespace       string               `json:"namespace"`
+	ResourceVersion string               `json:"resourceVersion"`
+	// Phase is set for the phase event
+	Phase string `json:"phase,omitempty"`
+	// WorkflowStep is set for the workflow-step event
+	WorkflowStep *ApplicationWorkflowStepEvent `json:"workflowStep,omitempty"`
+	// Component is set for the component-health event
+	Component *ApplicationComponentHealthEvent `json:"component,omitempty"`
+}
+
+// ApplicationWorkflowStepEvent the status of the workflow step
+type ApplicationWorkflowStepEvent struct {
+	Name    string `json:"name"`
+	Type    string `json:"type,omitempty"`
+	Phase   string `json:"phase"`
+	Message string `json:"message,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ApplicationComponentHealthEvent the health of the component
+type ApplicationComponentHealthEvent struct {
+	Name    string `json:"name"`
+	Env     string `json:"env,omitempty"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+// ProjectMember the user and the verb granted in the project
+type ProjectMember struct {
+	User string `json:"user" validate:"required"`
+	Verb string `json:"verb" validate:"oneof=read write admin"`
+}
+
+// ProjectQuota the quota of the project, zero means unlimited
+type ProjectQuota struct {
+	MaxApplications int `json:"maxApplications,omitempty" validate:"gte=0"`
+	MaxClusters     int `json:"maxClusters,omitempty" validate:"gte=0"`
+}
+
+// CreateProjectRequest create project request body
+type CreateProjectRequest struct {
+	Name        string          `json:"name" validate:"required"`
+	Description string          `json:"description,omitempty"`
+	Members     []ProjectMember `json:"members,omitempty" validate:"dive"`
+	Quota       ProjectQuota    `json:"quota"`
+}
+
+// UpdateProjectRequest update project request body, the members and the quota are replaced
+type UpdateProjectRequest struct {
+	Description string          `json:"description,omitempty"`
+	Members     []ProjectMember `json:"members,omitempty" validate:"dive"`
+	Quota       ProjectQuota    `json:"quota"`
+}
+
+// ProjectBase project base model
+type ProjectBase struct {
+	Name             string          `json:"name"`
+	Description      string          `json:"description"`
+	Members          []ProjectMember `json:"members"`
+	Quota            ProjectQuota    `json:"quota"`
+	ApplicationCount int             `json:"applicationCount"`
+	CreateTime       time.Time       `json:"createTime"`
+	UpdateTime       time.Time       `json:"updateTime"`
+}
+
+// ListProjectResponse list projects
+type ListProjectResponse struct {
+	Projects []ProjectBase `json:"projects"`
+}
+
+// CreateEnvironmentRequest create environment request body
+type CreateEnvironmentRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description,omitempty"`
+	Namespace   string `json:"namespace" validate:"required"`
+	// Clusters are the target clusters of the environment, the local cluster is used if empty
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// EnvironmentBase environment base model
+type EnvironmentBase struct {
+	Name        string    `json:"name"`
+	Project     string    `json:"project"`
+	Description string    `json:"description"`
+	Namespace   string    `json:"namespace"`
+	Clusters    []string  `json:"clusters"`
+	CreateTime  time.Time `json:"createTime"`
+}
+
+// ListEnvironmentResponse list environments of a project
+type ListEnvironmentResponse struct {
+	Environments []EnvironmentBase `json:"environments"`
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
+)
+
+const (
+	// OutcomeSuccess the request is handled successfully
+	OutcomeSuccess = "success"
+	// OutcomeFailure the request is failed
+	OutcomeFailure = "failure"
+
+	// MaxRequestBodySize the max bytes of the request body hashed in the audit record, the rest of the body is
+	// passed to the handler without being buffered
+	MaxRequestBodySize = 1 << 20
+	// DefaultRetention the default duration the audit records are kept in the datastore
+	DefaultRetention = 30 * 24 * time.Hour
+	// collectInterval the interval to delete the expired audit records
+	collectInterval = time.Hour
+)
+
+// Config config for the audit of the api server
+type Config struct {
+	// LogFile is the JSON-lines file the audit records are appended to, disabled if empty
+	LogFile string
+	// Retention is the duration the audit records are kept in the datastore, they are kept forever if it is zero.
+	// The records in the log file are not affected.
+	Retention time.Duration
+}
+
+// Sink the destination of the audit records
+type Sink interface {
+	Write(ctx context.Context, record *model.AuditRecord) error
+}
+
+type datastoreSink struct {
+	ds datastore.DataStore
+}
+
+// NewDatastoreSink create the sink persists the audit records through the datastore
+func NewDatastoreSink(ds datastore.DataStore) Sink {
+	return &datastoreSink{ds: ds}
+}
+
+// Write add the record to the datastore
+func (d *datastoreSink) Write(ctx context.Context, record *model.AuditRecord) error {
+	return d.ds.Add(ctx, model.AuditRecordKind, record)
+}
+
+type fileSink struct {
+	mutex sync.Mutex
+	file  io.Writer
+}
+
+// NewFileSink create the sink appends the audit records to the file in JSON-lines format
+func NewFileSink(path string) (Sink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{file: file}, nil
+}
+
+// Write append the record as one line
+func (f *fileSink) Write(ctx context.Context, record *model.AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	_, err = f.file.Write(append(data, '\n'))
+	return err
+}
+
+// Auditor records the mutating requests to the sinks
+type Auditor struct {
+	sinks     []Sink
+	ds        datastore.DataStore
+	retention time.Duration
+	now       func() time.Time
+}
+
+// New create the auditor with the config, the records are always persisted through the datastore
+func New(cfg Config, ds datastore.DataStore) (*Auditor, error) {
+	a := &Auditor{sinks: []Sink{NewDatastoreSink(ds)}, ds: ds, retention: cfg.Retention, now: time.Now}
+	if cfg.LogFile != "" {
+		sink, err := NewFileSink(cfg.LogFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log file: %w", err)
+		}
+		a.sinks = append(a.sinks, sink)
+	}
+	return a, nil
+}
+
+// Start deletes the expired records from the datastore periodically until the context is done
+func (a *Auditor) Start(ctx context.Context) {
+	if a.retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(collectInterval)
+	defer ticker.Stop()
+	for {
+		if err := a.CollectExpiredRecords(ctx); err != nil {
+			log.Logger.Errorf("failed to collect expired audit records: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CollectExpiredRecords deletes the records older than the retention from the datastore
+func (a *Auditor) CollectExpiredRecords(ctx context.Context) error {
+	if a.retention <= 0 {
+		return nil
+	}
+	expiry := a.now().Add(-a.retention).UnixNano()
+	it, err := a.ds.Find(ctx, model.AuditRecordKind)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer it.Close(ctx)
+	var expired []string
+	for it.Next(ctx) {
+		record := &model.AuditRecord{}
+		if err := it.Decode(record); err != nil {
+			return err
+		}
+		if record.Timestamp < expiry {
+			expired = append(expired, record.Name)
+		}
+	}
+	for _, name := range expired {
+		if err := a.ds.Delete(ctx, model.AuditRecordKind, name); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record writes the record to all the sinks, the failures are logged
+func (a *Auditor) Record(ctx context.Context, record *model.AuditRecord) {
+	for _, sink := range a.sinks {
+		if err := sink.Write(ctx, record); err != nil {
+			log.Logger.Errorf("failed to write audit record %s %s: %s", record.Method, record.Path, err.Error())
+		}
+	}
+}
+
+// NewRecord creates the record of the request received at the time, the name is unique
+func NewRecord(method, path string, t time.Time) *model.AuditRecord {
+	record := &model.AuditRecord{
+		Name:      newRecordName(t),
+		Method:    method,
+		Path:      path,
+		Timestamp: t.UnixNano(),
+		Date:      t.UTC().Format(model.AuditRecordDateFormat),
+	}
+	record.ResourceType, record.ResourceName = parseResource(path)
+	return record
+}
+
+// HashBody sets the hash of the body to the record
+func HashBody(record *model.AuditRecord, body []byte, truncated bool) {
+	if len(body) == 0 {
+		return
+	}
+	hash := sha256.Sum256(body)
+	record.RequestBodyHash = hex.EncodeToString(hash[:])
+	record.RequestBodyTruncated = truncated
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// Filter is the container filter records the mutating requests. It should be added before the authentication
+// filter, so the requests rejected by the authentication are recorded too.
+func (a *Auditor) Filter(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+	if !isMutating(req.Request.Method) {
+		chain.ProcessFilter(req, res)
+		return
+	}
+	start := a.now()
+	record := NewRecord(req.Request.Method, req.Request.URL.Path, start)
+	record.RemoteAddr = req.Request.RemoteAddr
+	if req.Request.Body != nil {
+		// only the first bytes of a large body are buffered, the handler reads the rest from the original body
+		head, err := io.ReadAll(io.LimitReader(req.Request.Body, MaxRequestBodySize+1))
+		if err != nil {
+			log.Logger.Errorf("failed to read request body for audit: %s", err.Error())
+		}
+		req.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(head), req.Request.Body), req.Request.Body}
+		if len(head) > MaxRequestBodySize {
+			HashBody(record, head[:MaxRequestBodySize], true)
+		} else {
+			HashBody(record, head, false)
+		}
+	}
+
+	chain.ProcessFilter(req, res)
+
+	if user, ok := auth.UserFromRequest(req); ok {
+		record.User = user.Name
+		record.Groups = user.Groups
+	}
+	record.StatusCode = res.StatusCode()
+	record.Outcome = OutcomeSuccess
+	if record.StatusCode >= http.StatusBadRequest {
+		record.Outcome = OutcomeFailure
+	}
+	record.LatencyMilliseconds = a.now().Sub(start).Milliseconds()
+	// the record should be written even if the request is canceled
+	a.Record(context.Background(), record)
+}
+
+// parseResource parse the target resource from the path like /api/v1/{resourceType}/{resourceName}/...
+func parseResource(path string) (string, string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) >= 2 && segments[0] == "api" {
+		segments = segments[2:]
+	} else if len(segments) >= 1 && segments[0] == "v1" {
+		segments = segments[1:]
+	}
+	switch len(segments) {
+	case 0:
+		return "", ""
+	case 1:
+		return segments[0], ""
+	default:
+		return segments[0], segments[1]
+	}
+}
+
+func newRecordName(t time.Time) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%d-%s", t.UnixNano(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore/kubeapi"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestParseResource(t *testing.T) {
+	testCases := map[string][2]string{
+		"/api/v1/clusters":                    {"clusters", ""},
+		"/api/v1/clusters/prod":               {"clusters", "prod"},
+		"/api/v1/applications/app/components": {"applications", "app"},
+		"/v1/addons/fluxcd/enable":            {"addons", "fluxcd"},
+		"/":                                   {"", ""},
+	}
+	for path, expected := range testCases {
+		resourceType, resourceName := parseResource(path)
+		assert.Equal(t, expected, [2]string{resourceType, resourceName}, path)
+	}
+}
+
+func TestAuditorFilter(t *testing.T) {
+	ctx := context.Background()
+	ds, err := kubeapi.New(ctx, datastore.Config{Type: "kubeapi", Database: "kubevela"}, fake.NewClientBuilder().WithScheme(common.Scheme).Build())
+	require.NoError(t, err)
+	logFile := filepath.Join(t.TempDir(), "audit.log")
+	auditor, err := New(Config{LogFile: logFile}, ds)
+	require.NoError(t, err)
+
+	container := restful.NewContainer()
+	container.Filter(auditor.Filter)
+	ws := new(restful.WebService).Path("/api/v1/clusters")
+	var receivedBody string
+	ws.Route(ws.GET("/").To(func(req *restful.Request, res *restful.Response) {}))
+	ws.Route(ws.POST("/").To(func(req *restful.Request, res *restful.Response) {
+		body, _ := io.ReadAll(req.Request.Body)
+		receivedBody = string(body)
+		res.WriteHeader(http.StatusCreated)
+	}))
+	ws.Route(ws.DELETE("/{name}").To(func(req *restful.Request, res *restful.Response) {
+		res.WriteHeader(http.StatusNotFound)
+	}))
+	container.Add(ws)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/clusters", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/clusters", strings.NewReader(`{"name":"prod"}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/clusters/prod", nil),
+	} {
+		container.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	// the body is still readable by the handler
+	assert.Equal(t, `{"name":"prod"}`, receivedBody)
+
+	it, err := ds.Find(ctx, model.AuditRecordKind)
+	require.NoError(t, err)
+	records := map[string]*model.AuditRecord{}
+	for it.Next(ctx) {
+		record := &model.AuditRecord{}
+		require.NoError(t, it.Decode(record))
+		records[record.Method] = record
+	}
+	require.Equal(t, 2, len(records))
+	assert.Equal(t, OutcomeSuccess, records[http.MethodPost].Outcome)
+	assert.Equal(t, http.StatusCreated, records[http.MethodPost].StatusCode)
+	assert.Equal(t, "clusters", records[http.MethodPost].ResourceType)
+	assert.NotEmpty(t, records[http.MethodPost].RequestBodyHash)
+	assert.Equal(t, OutcomeFailure, records[http.MethodDelete].Outcome)
+	assert.Equal(t, "prod", records[http.MethodDelete].ResourceName)
+	assert.Empty(t, records[http.MethodDelete].RequestBodyHash)
+
+	file, err := os.Open(logFile)
+	require.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	var lines int
+	for scanner.Scan() {
+		record := &model.AuditRecord{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), record))
+		assert.Equal(t, records[record.Method].Name, record.Name)
+		lines++
+	}
+	assert.Equal(t, 2, lines)
+}
+
+func TestAuditorFilterTruncatesLargeBody(t *testing.T) {
+	ctx := context.Background()
+	ds, err := kubeapi.New(ctx, datastore.Config{Type: "kubeapi", Database: "kubevela"}, fake.NewClientBuilder().WithScheme(common.Scheme).Build())
+	require.NoError(t, err)
+	auditor, err := New(Config{}, ds)
+	require.NoError(t, err)
+
+	container := restful.NewContainer()
+	container.Filter(auditor.Filter)
+	ws := new(restful.WebService).Path("/api/v1/clusters")
+	var receivedBody []byte
+	ws.Route(ws.POST("/").To(func(req *restful.Request, res *restful.Response) {
+		receivedBody, _ = io.ReadAll(req.Request.Body)
+	}))
+	container.Add(ws)
+
+	body := strings.Repeat("a", MaxRequestBodySize+10)
+	container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/clusters", strings.NewReader(body)))
+	// the whole body is passed to the handler
+	assert.Equal(t, body, string(receivedBody))
+
+	it, err := ds.Find(ctx, model.AuditRecordKind)
+	require.NoError(t, err)
+	require.True(t, it.Next(ctx))
+	record := &model.AuditRecord{}
+	require.NoError(t, it.Decode(record))
+	assert.True(t, record.RequestBodyTruncated)
+	expected := &model.AuditRecord{}
+	HashBody(expected, []byte(body[:MaxRequestBodySize]), true)
+	assert.Equal(t, expected.RequestBodyHash, record.RequestBodyHash)
+}
+
+func TestCollectExpiredRecords(t *testing.T) {
+	ctx := context.Background()
+	ds, err := kubeapi.New(ctx, datastore.Config{Type: "kubeapi", Database: "kubevela"}, fake.NewClientBuilder().WithScheme(common.Scheme).Build())
+	require.NoError(t, err)
+	now := time.Now()
+	auditor, err := New(Config{Retention: time.Hour}, ds)
+	require.NoError(t, err)
+	auditor.now = func() time.Time { return now }
+
+	expired := NewRecord(http.MethodPost, "/api/v1/clusters", now.Add(-2*time.Hour))
+	kept := NewRecord(http.MethodPost, "/api/v1/clusters", now.Add(-time.Minute))
+	auditor.Record(ctx, expired)
+	auditor.Record(ctx, kept)
+	require.NoError(t, auditor.CollectExpiredRecords(ctx))
+
+	it, err := ds.Find(ctx, model.AuditRecordKind)
+	require.NoError(t, err)
+	var names []string
+	for it.Next(ctx) {
+		record := &model.AuditRecord{}
+		require.NoError(t, it.Decode(record))
+		names = append(names, record.Name)
+	}
+	assert.Equal(t, []string{kept.Name}, names)
+}
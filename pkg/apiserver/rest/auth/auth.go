@@ -0,0 +1,203 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	restful "github.com/emicklei/go-restful/v3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+type contextKey string
+
+const (
+	// userContextKey the context key of the authenticated user
+	userContextKey = contextKey("user")
+	// userAttributeKey the request attribute key of the authenticated user
+	userAttributeKey = "user"
+)
+
+// Config config for the authentication of the api server
+type Config struct {
+	// TokenAuthFile is the csv file of static tokens, each line is `token,user[,group1|group2]`
+	TokenAuthFile string
+	// OIDCIssuerURL is the expected issuer of the OIDC id token
+	OIDCIssuerURL string
+	// OIDCClientID is the expected audience of the OIDC id token
+	OIDCClientID string
+	// OIDCJWKSFile is the local JWKS file used to verify the signature of the OIDC id token
+	OIDCJWKSFile string
+	// OIDCUsernameClaim is the claim used as the user name, default to `sub`
+	OIDCUsernameClaim string
+	// OIDCGroupsClaim is the claim used as the groups of the user, default to `groups`
+	OIDCGroupsClaim string
+	// EnableTokenReview authenticates the token by the TokenReview api of the kubernetes
+	EnableTokenReview bool
+	// SuperUserGroups the users in these groups skip all permission checks
+	SuperUserGroups []string
+}
+
+// Enabled whether any authenticator is configured
+func (c Config) Enabled() bool {
+	return c.TokenAuthFile != "" || c.OIDCJWKSFile != "" || c.EnableTokenReview
+}
+
+// UserInfo the authenticated user
+type UserInfo struct {
+	Name   string   `json:"name"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// InGroups whether the user belongs to any of the groups
+func (u *UserInfo) InGroups(groups []string) bool {
+	for _, g := range groups {
+		for _, ug := range u.Groups {
+			if g == ug {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authenticator authenticates the bearer token of the request.
+// It returns false without an error if the token is not recognized by this authenticator.
+type Authenticator interface {
+	AuthenticateToken(ctx context.Context, token string) (*UserInfo, bool, error)
+}
+
+// WithUser returns a copy of ctx in which the user is set
+func WithUser(ctx context.Context, user *UserInfo) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFrom get the authenticated user from the context
+func UserFrom(ctx context.Context) (*UserInfo, bool) {
+	user, ok := ctx.Value(userContextKey).(*UserInfo)
+	return user, ok && user != nil
+}
+
+// UserFromRequest get the authenticated user from the request
+func UserFromRequest(req *restful.Request) (*UserInfo, bool) {
+	user, ok := req.Attribute(userAttributeKey).(*UserInfo)
+	return user, ok && user != nil
+}
+
+// Authentication the union of all configured authenticators
+type Authentication struct {
+	cfg            Config
+	authenticators []Authenticator
+}
+
+// New create the authentication with config, kubeClient is used by the TokenReview authenticator
+func New(cfg Config, kubeClient client.Client) (*Authentication, error) {
+	a := &Authentication{cfg: cfg}
+	if cfg.TokenAuthFile != "" {
+		tokenAuth, err := NewTokenFileAuthenticator(cfg.TokenAuthFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load token auth file: %w", err)
+		}
+		a.authenticators = append(a.authenticators, tokenAuth)
+	}
+	if cfg.OIDCJWKSFile != "" {
+		oidcAuth, err := NewOIDCAuthenticator(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create oidc authenticator: %w", err)
+		}
+		a.authenticators = append(a.authenticators, oidcAuth)
+	}
+	if cfg.EnableTokenReview {
+		a.authenticators = append(a.authenticators, NewTokenReviewAuthenticator(kubeClient))
+	}
+	return a, nil
+}
+
+// Enabled whether the authentication is enabled
+func (a *Authentication) Enabled() bool {
+	return a != nil && len(a.authenticators) > 0
+}
+
+// IsSuperUser whether the user skips all permission checks
+func (a *Authentication) IsSuperUser(user *UserInfo) bool {
+	return a.Enabled() && user.InGroups(a.cfg.SuperUserGroups)
+}
+
+// Authenticate authenticate the request by the authenticators in order
+func (a *Authentication) Authenticate(req *http.Request) (*UserInfo, error) {
+	token := bearerToken(req)
+	if token == "" {
+		return nil, bcode.ErrUnauthenticated
+	}
+	var errs []string
+	for _, authenticator := range a.authenticators {
+		user, ok, err := authenticator.AuthenticateToken(req.Context(), token)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if ok {
+			return user, nil
+		}
+	}
+	if len(errs) != 0 {
+		log.Logger.Infof("failed to authenticate request %s %s: %s", req.Method, req.URL.Path, strings.Join(errs, "; "))
+	}
+	return nil, bcode.ErrUnauthenticated
+}
+
+// Filter is the container filter authenticates every request except the excluded paths
+func (a *Authentication) Filter(excludePaths ...string) restful.FilterFunction {
+	return func(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+		if !a.Enabled() {
+			chain.ProcessFilter(req, res)
+			return
+		}
+		for _, path := range excludePaths {
+			if req.Request.URL.Path == path {
+				chain.ProcessFilter(req, res)
+				return
+			}
+		}
+		user, err := a.Authenticate(req.Request)
+		if err != nil {
+			bcode.ReturnError(req, res, err)
+			return
+		}
+		req.SetAttribute(userAttributeKey, user)
+		req.Request = req.Request.WithContext(WithUser(req.Request.Context(), user))
+		chain.ProcessFilter(req, res)
+	}
+}
+
+func bearerToken(req *http.Request) string {
+	header := strings.TrimSpace(req.Header.Get("Authorization"))
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+var errInvalidToken = errors.New("invalid token")
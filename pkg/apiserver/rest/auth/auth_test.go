@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenFileAuthenticator(t *testing.T) {
+	_, err := newTokenAuthenticator(strings.NewReader("token-only\n"))
+	assert.Error(t, err)
+
+	authenticator, err := newTokenAuthenticator(strings.NewReader("# comment\ntoken-a,alice,dev|ops\ntoken-b,bob\n"))
+	require.NoError(t, err)
+	user, ok, err := authenticator.AuthenticateToken(context.Background(), "token-a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, &UserInfo{Name: "alice", Groups: []string{"dev", "ops"}}, user)
+	_, ok, err = authenticator.AuthenticateToken(context.Background(), "token-c")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(jwtHeader{Alg: "RS256", Kid: kid})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks, err := json.Marshal(jsonWebKeySet{Keys: []jsonWebKey{{
+		Kty: "RSA",
+		Kid: "key-1",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	keys, err := parseJWKS(jwks)
+	require.NoError(t, err)
+	authenticator := &oidcAuthenticator{
+		issuer:        "https://issuer.example.com",
+		clientID:      "kubevela",
+		usernameClaim: "email",
+		groupsClaim:   defaultGroupsClaim,
+		keys:          keys,
+		now:           time.Now,
+	}
+	ctx := context.Background()
+	claims := map[string]interface{}{
+		"iss":    "https://issuer.example.com",
+		"aud":    []string{"kubevela"},
+		"email":  "alice@example.com",
+		"groups": []string{"dev"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+
+	user, ok, err := authenticator.AuthenticateToken(ctx, signRS256(t, key, "key-1", claims))
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, &UserInfo{Name: "alice@example.com", Groups: []string{"dev"}}, user)
+
+	_, ok, err = authenticator.AuthenticateToken(ctx, "not-a-jwt")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = authenticator.AuthenticateToken(ctx, signRS256(t, key, "key-2", claims))
+	assert.Error(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, _, err = authenticator.AuthenticateToken(ctx, signRS256(t, otherKey, "key-1", claims))
+	assert.Error(t, err)
+
+	claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, _, err = authenticator.AuthenticateToken(ctx, signRS256(t, key, "key-1", claims))
+	assert.Error(t, err)
+
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	claims["aud"] = "others"
+	_, _, err = authenticator.AuthenticateToken(ctx, signRS256(t, key, "key-1", claims))
+	assert.Error(t, err)
+
+	claims["iss"] = "kubernetes/serviceaccount"
+	_, ok, err = authenticator.AuthenticateToken(ctx, signRS256(t, key, "key-1", claims))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+type fakeChecker struct {
+	allowed map[string]Verb
+}
+
+func (f *fakeChecker) CheckPermission(ctx context.Context, user *UserInfo, scope Scope, scopeName string, verb Verb) (bool, error) {
+	return f.allowed[user.Name+"/"+string(scope)+"/"+scopeName].Allows(verb), nil
+}
+
+func TestAuthorize(t *testing.T) {
+	tokenAuth, err := newTokenAuthenticator(strings.NewReader("token-a,alice\ntoken-r,root,system:masters\n"))
+	require.NoError(t, err)
+	authentication := &Authentication{cfg: Config{SuperUserGroups: []string{"system:masters"}}, authenticators: []Authenticator{tokenAuth}}
+	authorizer := NewAuthorizer(authentication, &fakeChecker{allowed: map[string]Verb{"alice/namespace/dev": VerbWrite}})
+
+	restful.DefaultResponseContentType(restful.MIME_JSON)
+	container := restful.NewContainer()
+	container.Filter(authentication.Filter("/public"))
+	ws := new(restful.WebService).Produces(restful.MIME_JSON)
+	ok := func(req *restful.Request, res *restful.Response) {
+		user, _ := UserFrom(req.Request.Context())
+		_, _ = res.Write([]byte(user.Name))
+	}
+	ws.Route(ws.GET("/namespaces/{namespace}").To(ok).Filter(authorizer.Authorize(ScopeNamespace, "namespace", VerbRead)))
+	ws.Route(ws.DELETE("/namespaces/{namespace}").To(ok).Filter(authorizer.Authorize(ScopeNamespace, "namespace", VerbAdmin)))
+	container.Add(ws)
+
+	testCases := map[string]struct {
+		method string
+		path   string
+		token  string
+		code   int
+	}{
+		"no token":          {method: http.MethodGet, path: "/namespaces/dev", code: http.StatusUnauthorized},
+		"invalid token":     {method: http.MethodGet, path: "/namespaces/dev", token: "token-x", code: http.StatusUnauthorized},
+		"allowed":           {method: http.MethodGet, path: "/namespaces/dev", token: "token-a", code: http.StatusOK},
+		"other namespace":   {method: http.MethodGet, path: "/namespaces/prod", token: "token-a", code: http.StatusForbidden},
+		"insufficient verb": {method: http.MethodDelete, path: "/namespaces/dev", token: "token-a", code: http.StatusForbidden},
+		"super user":        {method: http.MethodDelete, path: "/namespaces/prod", token: "token-r", code: http.StatusOK},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			container.ServeHTTP(rec, req)
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
+
+func TestVerbAllows(t *testing.T) {
+	assert.True(t, VerbAdmin.Allows(VerbWrite))
+	assert.True(t, VerbWrite.Allows(VerbRead))
+	assert.False(t, VerbRead.Allows(VerbWrite))
+	assert.False(t, Verb("").Allows(VerbRead))
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultUsernameClaim = "sub"
+	defaultGroupsClaim   = "groups"
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type oidcAuthenticator struct {
+	issuer        string
+	clientID      string
+	usernameClaim string
+	groupsClaim   string
+	keys          map[string]crypto.PublicKey
+	now           func() time.Time
+}
+
+// NewOIDCAuthenticator create the authenticator validates the OIDC id token with the keys in the local JWKS file
+func NewOIDCAuthenticator(cfg Config) (Authenticator, error) {
+	data, err := os.ReadFile(cfg.OIDCJWKSFile)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	a := &oidcAuthenticator{
+		issuer:        cfg.OIDCIssuerURL,
+		clientID:      cfg.OIDCClientID,
+		usernameClaim: cfg.OIDCUsernameClaim,
+		groupsClaim:   cfg.OIDCGroupsClaim,
+		keys:          keys,
+		now:           time.Now,
+	}
+	if a.usernameClaim == "" {
+		a.usernameClaim = defaultUsernameClaim
+	}
+	if a.groupsClaim == "" {
+		a.groupsClaim = defaultGroupsClaim
+	}
+	return a, nil
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	jwks := jsonWebKeySet{}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to parse jwks: %w", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing key found in jwks")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// AuthenticateToken validate the signature and the claims of the id token
+func (o *oidcAuthenticator) AuthenticateToken(ctx context.Context, token string) (*UserInfo, bool, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false, nil
+	}
+	header := jwtHeader{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, false, nil
+	}
+	claims := map[string]interface{}{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, false, nil
+	}
+	// the token may be issued by others, such as the kubernetes service account token
+	if iss, _ := claims["iss"].(string); iss != o.issuer {
+		return nil, false, nil
+	}
+	if err := o.verifySignature(header, parts); err != nil {
+		return nil, false, err
+	}
+	if err := o.verifyClaims(claims); err != nil {
+		return nil, false, err
+	}
+	name, _ := claims[o.usernameClaim].(string)
+	if name == "" {
+		return nil, false, fmt.Errorf("claim %s is not found in id token", o.usernameClaim)
+	}
+	user := &UserInfo{Name: name}
+	switch groups := claims[o.groupsClaim].(type) {
+	case string:
+		user.Groups = []string{groups}
+	case []interface{}:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				user.Groups = append(user.Groups, s)
+			}
+		}
+	}
+	return user, true, nil
+}
+
+func (o *oidcAuthenticator) verifySignature(header jwtHeader, parts []string) error {
+	key, ok := o.keys[header.Kid]
+	if !ok {
+		return fmt.Errorf("signing key %s is not found", header.Kid)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errInvalidToken
+	}
+	var hash crypto.Hash
+	switch header.Alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signing algorithm %s", header.Alg)
+	}
+	hasher := hash.New()
+	hasher.Write([]byte(parts[0] + "." + parts[1]))
+	digest := hasher.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(header.Alg, "RS") {
+			return fmt.Errorf("algorithm %s does not match the rsa key", header.Alg)
+		}
+		if err := rsa.VerifyPKCS1v15(pub, hash, digest, signature); err != nil {
+			return errInvalidToken
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(header.Alg, "ES") {
+			return fmt.Errorf("algorithm %s does not match the ecdsa key", header.Alg)
+		}
+		size := len(signature) / 2
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errInvalidToken
+		}
+	}
+	return nil
+}
+
+func (o *oidcAuthenticator) verifyClaims(claims map[string]interface{}) error {
+	now := o.now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("claim exp is required")
+	}
+	if now.After(time.Unix(int64(exp), 0)) {
+		return fmt.Errorf("token is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("token is not valid yet")
+	}
+	if o.clientID == "" {
+		return nil
+	}
+	switch aud := claims["aud"].(type) {
+	case string:
+		if aud == o.clientID {
+			return nil
+		}
+	case []interface{}:
+		for _, a := range aud {
+			if a == o.clientID {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("token audience does not match client id %s", o.clientID)
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+// Scope the scope type of a role
+type Scope string
+
+const (
+	// ScopeProject the role takes effect in a project
+	ScopeProject Scope = "project"
+	// ScopeNamespace the role takes effect in a namespace
+	ScopeNamespace Scope = "namespace"
+	// ScopeCluster the role takes effect in a cluster
+	ScopeCluster Scope = "cluster"
+)
+
+// AllScopeNames the scope name matches all the scopes of the same type
+const AllScopeNames = "*"
+
+// Verb the verb of a role
+type Verb string
+
+const (
+	// VerbRead allows to get and list resources
+	VerbRead Verb = "read"
+	// VerbWrite allows to create, update and delete resources, includes VerbRead
+	VerbWrite Verb = "write"
+	// VerbAdmin allows to manage the permissions, includes VerbWrite
+	VerbAdmin Verb = "admin"
+)
+
+var verbLevel = map[Verb]int{
+	VerbRead:  1,
+	VerbWrite: 2,
+	VerbAdmin: 3,
+}
+
+// Allows whether the verb includes the required verb
+func (v Verb) Allows(required Verb) bool {
+	return verbLevel[v] != 0 && verbLevel[v] >= verbLevel[required]
+}
+
+// PermissionChecker check the permission of a user
+type PermissionChecker interface {
+	CheckPermission(ctx context.Context, user *UserInfo, scope Scope, scopeName string, verb Verb) (bool, error)
+}
+
+// Authorizer checks the permission of the authenticated user for each route
+type Authorizer struct {
+	authentication *Authentication
+	checker        PermissionChecker
+}
+
+// NewAuthorizer create the authorizer, all the checks pass if the authentication is not enabled
+func NewAuthorizer(authentication *Authentication, checker PermissionChecker) *Authorizer {
+	return &Authorizer{authentication: authentication, checker: checker}
+}
+
+// Authorize returns the route filter requires the verb on the scope, the scope name is read from the path
+// parameter or the query parameter named scopeParam. If the parameter is empty, the verb is required on all the scopes.
+func (a *Authorizer) Authorize(scope Scope, scopeParam string, verb Verb) restful.FilterFunction {
+	return func(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+		scopeName := req.PathParameter(scopeParam)
+		if scopeName == "" {
+			scopeName = req.QueryParameter(scopeParam)
+		}
+		if err := a.Check(req, scope, scopeName, verb); err != nil {
+			bcode.ReturnError(req, res, err)
+			return
+		}
+		chain.ProcessFilter(req, res)
+	}
+}
+
+// Check whether the authenticated user of the request is granted the verb on the scope, it is used by the handlers
+// if the scope name is only known after the request body is read or the resource is loaded.
+func (a *Authorizer) Check(req *restful.Request, scope Scope, scopeName string, verb Verb) error {
+	if a == nil || !a.authentication.Enabled() {
+		return nil
+	}
+	user, ok := UserFromRequest(req)
+	if !ok {
+		return bcode.ErrUnauthenticated
+	}
+	if a.authentication.IsSuperUser(user) {
+		return nil
+	}
+	if scopeName == "" {
+		scopeName = AllScopeNames
+	}
+	allowed, err := a.checker.CheckPermission(req.Request.Context(), user, scope, scopeName, verb)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		log.Logger.Infof("user %s is forbidden to %s %s %s", user.Name, verb, scope, scopeName)
+		return bcode.ErrForbidden
+	}
+	return nil
+}
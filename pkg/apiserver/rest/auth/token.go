@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type tokenFileAuthenticator struct {
+	tokens map[string]*UserInfo
+}
+
+// NewTokenFileAuthenticator load the static tokens from the csv file, each line is `token,user[,group1|group2]`
+func NewTokenFileAuthenticator(path string) (Authenticator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer file.Close()
+	return newTokenAuthenticator(file)
+}
+
+func newTokenAuthenticator(r io.Reader) (Authenticator, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	tokens := make(map[string]*UserInfo, len(records))
+	for i, record := range records {
+		if len(record) < 2 || record[0] == "" || record[1] == "" {
+			return nil, fmt.Errorf("line %d: token and user are required", i+1)
+		}
+		if _, exist := tokens[record[0]]; exist {
+			return nil, fmt.Errorf("line %d: duplicate token", i+1)
+		}
+		user := &UserInfo{Name: record[1]}
+		if len(record) > 2 && record[2] != "" {
+			user.Groups = strings.Split(record[2], "|")
+		}
+		tokens[record[0]] = user
+	}
+	return &tokenFileAuthenticator{tokens: tokens}, nil
+}
+
+// AuthenticateToken authenticate the token by the static tokens
+func (t *tokenFileAuthenticator) AuthenticateToken(ctx context.Context, token string) (*UserInfo, bool, error) {
+	for known, user := range t.tokens {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			return user, true, nil
+		}
+	}
+	return nil, false, nil
+}
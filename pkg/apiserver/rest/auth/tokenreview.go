@@ -0,0 +1,49 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type tokenReviewAuthenticator struct {
+	kubeClient client.Client
+}
+
+// NewTokenReviewAuthenticator create the authenticator validates the token by the TokenReview api of the kubernetes
+func NewTokenReviewAuthenticator(kubeClient client.Client) Authenticator {
+	return &tokenReviewAuthenticator{kubeClient: kubeClient}
+}
+
+// AuthenticateToken authenticate the token by creating a TokenReview
+func (t *tokenReviewAuthenticator) AuthenticateToken(ctx context.Context, token string) (*UserInfo, bool, error) {
+	review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := t.kubeClient.Create(ctx, review); err != nil {
+		return nil, false, fmt.Errorf("failed to create token review: %w", err)
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return nil, false, fmt.Errorf("token review failed: %s", review.Status.Error)
+		}
+		return nil, false, nil
+	}
+	return &UserInfo{Name: review.Status.User.Username, Groups: review.Status.User.Groups}, true, nil
+}
@@ -24,16 +24,29 @@ import (
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
 	restful "github.com/emicklei/go-restful/v3"
 	"github.com/go-openapi/spec"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
+	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
 	"github.com/oam-dev/kubevela/pkg/apiserver/datastore/kubeapi"
 	"github.com/oam-dev/kubevela/pkg/apiserver/datastore/mongodb"
 	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/audit"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/usecase"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/webservice"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
 )
 
 var _ APIServer = &restServer{}
 
+const apiDocPath = "/apidocs.json"
+
 // Config config for server
 type Config struct {
 	// api server bind address
@@ -43,6 +56,12 @@ type Config struct {
 
 	// Datastore config
 	Datastore datastore.Config
+
+	// Auth config
+	Auth auth.Config
+
+	// Audit config
+	Audit audit.Config
 }
 
 // APIServer interface for call api server
@@ -51,13 +70,37 @@ type APIServer interface {
 }
 
 type restServer struct {
-	webContainer *restful.Container
-	cfg          Config
-	dataStore    datastore.DataStore
+	webContainer   *restful.Container
+	cfg            Config
+	dataStore      datastore.DataStore
+	kubeClient     client.WithWatch
+	dm             discoverymapper.DiscoveryMapper
+	pd             *packages.PackageDiscover
+	authentication *auth.Authentication
+	auditor        *audit.Auditor
 }
 
 // New create restserver with config data
 func New(cfg Config) (a APIServer, err error) {
+	restConfig, err := config.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("get kubernetes config failure %w", err)
+	}
+	dm, err := discoverymapper.New(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create discovery mapper failure %w", err)
+	}
+	pd, err := packages.NewPackageDiscover(restConfig)
+	if err != nil {
+		if !packages.IsCUEParseErr(err) {
+			return nil, fmt.Errorf("create package discover failure %w", err)
+		}
+		log.Logger.Warnf("failed to parse some CUE packages from the cluster: %s", err.Error())
+	}
+	kubeClient, err := newKubeClient(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes client failure %w", err)
+	}
 	var ds datastore.DataStore
 	switch cfg.Datastore.Type {
 	case "mongodb":
@@ -66,23 +109,62 @@ func New(cfg Config) (a APIServer, err error) {
 			return nil, fmt.Errorf("create mongodb datastore instance failure %w", err)
 		}
 	case "kubeapi":
-		ds, err = kubeapi.New(context.Background(), cfg.Datastore)
+		ds, err = kubeapi.New(context.Background(), cfg.Datastore, kubeClient)
 		if err != nil {
-			return nil, fmt.Errorf("create mongodb datastore instance failure %w", err)
+			return nil, fmt.Errorf("create kubeapi datastore instance failure %w", err)
 		}
+		// the datastore namespace can not be bound to the environments
+		usecase.SystemNamespaces = append(usecase.SystemNamespaces, cfg.Datastore.Database)
 	default:
 		return nil, fmt.Errorf("not support datastore type %s", cfg.Datastore.Type)
 	}
+	authentication, err := auth.New(cfg.Auth, kubeClient)
+	if err != nil {
+		return nil, fmt.Errorf("create authentication failure %w", err)
+	}
+	if !authentication.Enabled() {
+		log.Logger.Warnf("no authenticator is configured, all the apis are served without authentication")
+	}
+	auditor, err := audit.New(cfg.Audit, ds)
+	if err != nil {
+		return nil, fmt.Errorf("create auditor failure %w", err)
+	}
 	s := &restServer{
-		webContainer: restful.NewContainer(),
-		cfg:          cfg,
-		dataStore:    ds,
+		webContainer:   restful.NewContainer(),
+		cfg:            cfg,
+		dataStore:      ds,
+		kubeClient:     kubeClient,
+		dm:             dm,
+		pd:             pd,
+		authentication: authentication,
+		auditor:        auditor,
 	}
 	return s, nil
 }
 
+// newKubeClient create the kubernetes client which can access the managed clusters through the cluster-gateway
+func newKubeClient(restConfig *rest.Config) (client.WithWatch, error) {
+	restConfig = rest.CopyConfig(restConfig)
+	restConfig.Wrap(multicluster.NewSecretModeMultiClusterRoundTripper)
+	kubeClient, err := client.NewWithWatch(restConfig, client.Options{Scheme: common.Scheme})
+	if err != nil {
+		return nil, err
+	}
+	svc, err := multicluster.GetClusterGatewayService(context.Background(), kubeClient)
+	if err != nil {
+		log.Logger.Warnf("cluster gateway service is not ready, managed clusters may be unavailable: %s", err.Error())
+	}
+	if svc != nil {
+		multicluster.ClusterGatewaySecretNamespace = svc.Namespace
+	} else {
+		multicluster.ClusterGatewaySecretNamespace = types.DefaultKubeVelaNS
+	}
+	return kubeClient, nil
+}
+
 func (s *restServer) Run(ctx context.Context) error {
-	webservice.Init(ctx)
+	webservice.Init(ctx, s.dataStore, s.kubeClient, s.dm, s.pd, s.authentication)
+	go s.auditor.Start(ctx)
 	err := s.registerServices()
 	if err != nil {
 		return err
@@ -91,6 +173,8 @@ func (s *restServer) Run(ctx context.Context) error {
 }
 
 func (s *restServer) registerServices() error {
+	// The errors returned by the container filters are written before any route is selected
+	restful.DefaultResponseContentType(restful.MIME_JSON)
 
 	/* **************************************************************  */
 	/* *************       Open API Route Group     *****************  */
@@ -99,7 +183,7 @@ func (s *restServer) registerServices() error {
 	// Add container filter to enable CORS
 	cors := restful.CrossOriginResourceSharing{
 		ExposeHeaders:  []string{},
-		AllowedHeaders: []string{"Content-Type", "Accept"},
+		AllowedHeaders: []string{"Content-Type", "Accept", "Authorization"},
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
 		CookiesAllowed: true,
 		Container:      s.webContainer}
@@ -108,6 +192,12 @@ func (s *restServer) registerServices() error {
 	// Add container filter to respond to OPTIONS
 	s.webContainer.Filter(s.webContainer.OPTIONSFilter)
 
+	// Add container filter to record the mutating requests
+	s.webContainer.Filter(s.auditor.Filter)
+
+	// Add container filter to authenticate the requests, the api doc is public
+	s.webContainer.Filter(s.authentication.Filter(apiDocPath))
+
 	// Regist all custom webservice
 	for _, handler := range webservice.GetRegistedWebService() {
 		s.webContainer.Add(handler.GetWebService())
@@ -115,7 +205,7 @@ func (s *restServer) registerServices() error {
 
 	config := restfulspec.Config{
 		WebServices:                   s.webContainer.RegisteredWebServices(), // you control what services are visible
-		APIPath:                       "/apidocs.json",
+		APIPath:                       apiDocPath,
 		PostBuildSwaggerObjectHandler: enrichSwaggerObject}
 	s.webContainer.Add(restfulspec.NewOpenAPIService(config))
 	return nil
@@ -0,0 +1,532 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aryann/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/references/appfile/dryrun"
+)
+
+const (
+	// labelProject the label of the deployed application records the project it belongs to
+	labelProject = "project.oam.dev/name"
+	// policyTypeEnvBinding the type of the policy places the components to the clusters
+	policyTypeEnvBinding = "env-binding"
+	// stepTypeDeploy2Env the type of the workflow step deploys the env of the env-binding policy
+	stepTypeDeploy2Env = "deploy2env"
+	// stepTypeApplyObject the type of the workflow step applies the object to the local cluster
+	stepTypeApplyObject = "apply-object"
+)
+
+// localStepTypes the types of the workflow steps apply the components or the application to the local cluster
+var localStepTypes = map[string]bool{"apply-component": true, "apply-remaining": true, "apply-application": true}
+
+// placementFreeStepTypes the types of the workflow steps apply no resource
+var placementFreeStepTypes = map[string]bool{"suspend": true, "webhook-notification": true, "depends-on-app": true}
+
+// placementFreePolicyTypes the types of the policies apply no resource out of the namespace of the application,
+// besides the builtin policies handled by the application controller
+var placementFreePolicyTypes = map[string]bool{"health": true}
+
+// ApplicationUsecase application manage
+type ApplicationUsecase interface {
+	ListApplications(context.Context, apis.ListApplicationOptions) (*apis.ListApplicationResponse, error)
+	GetApplicationBase(ctx context.Context, namespace, name string) (*apis.ApplicationBase, error)
+	CreateApplication(context.Context, apis.CreateApplicationRequest) (*apis.ApplicationBase, error)
+	GetApplication(ctx context.Context, namespace, name string) (*apis.DetailApplicationResponse, error)
+	DeleteApplication(ctx context.Context, namespace, name string) (*apis.ApplicationBase, error)
+	DeployApplication(ctx context.Context, namespace, name string) (*apis.ApplicationBase, error)
+	CompareApplicationRevisions(ctx context.Context, namespace, name, from, to string) (*apis.ApplicationRevisionDiffResponse, error)
+}
+
+type applicationUsecaseImpl struct {
+	ds           datastore.DataStore
+	kubeClient   client.Client
+	revisionDiff *dryrun.RevisionDiffOption
+}
+
+// NewApplicationUsecase new application usecase
+func NewApplicationUsecase(ds datastore.DataStore, kubeClient client.Client, dm discoverymapper.DiscoveryMapper, pd *packages.PackageDiscover) ApplicationUsecase {
+	return &applicationUsecaseImpl{ds: ds, kubeClient: kubeClient, revisionDiff: dryrun.NewRevisionDiffOption(kubeClient, dm, pd)}
+}
+
+// ListApplications list the applications matched all the filters
+func (a *applicationUsecaseImpl) ListApplications(ctx context.Context, options apis.ListApplicationOptions) (*apis.ListApplicationResponse, error) {
+	apps, err := listApplicationModels(ctx, a.ds)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apis.ListApplicationResponse{Applications: []*apis.ApplicationBase{}}
+	for _, app := range apps {
+		if options.Project != "" && app.Project != options.Project {
+			continue
+		}
+		if options.Namespace != "" && app.Namespace != options.Namespace {
+			continue
+		}
+		if options.Cluster != "" && !stringInSlice(options.Cluster, app.ClusterList) {
+			continue
+		}
+		if options.Query != "" && !strings.Contains(app.Name, options.Query) && !strings.Contains(app.Description, options.Query) {
+			continue
+		}
+		resp.Applications = append(resp.Applications, convertApplicationBase(app))
+	}
+	return resp, nil
+}
+
+// GetApplicationBase get the stored application, its project and namespace are used to check the permission.
+// The namespace can be empty if the name is used in only one namespace.
+func (a *applicationUsecaseImpl) GetApplicationBase(ctx context.Context, namespace, name string) (*apis.ApplicationBase, error) {
+	app, err := a.getApplicationModel(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return convertApplicationBase(app), nil
+}
+
+// CreateApplication create the application in the project, the application is not deployed until DeployApplication is called
+func (a *applicationUsecaseImpl) CreateApplication(ctx context.Context, req apis.CreateApplicationRequest) (*apis.ApplicationBase, error) {
+	now := time.Now().Unix()
+	app := &model.Application{
+		ID:          applicationID(req.Namespace, req.Name),
+		Name:        req.Name,
+		Project:     req.Project,
+		Namespace:   req.Namespace,
+		Description: req.Description,
+		Icon:        req.Icon,
+		Labels:      req.Labels,
+		ClusterList: req.ClusterList,
+		YamlConfig:  req.YamlConfig,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := a.getApplicationModel(ctx, app.Namespace, app.Name); err == nil {
+		return nil, bcode.ErrApplicationExist
+	} else if !errors.Is(err, bcode.ErrApplicationNotExist) {
+		return nil, err
+	}
+	if err := checkApplicationInProject(ctx, a.ds, app, true); err != nil {
+		return nil, err
+	}
+	if app.YamlConfig != "" {
+		if _, err := a.renderApplication(ctx, app); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.ds.Add(ctx, model.ApplicationKind, app); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrApplicationExist
+		}
+		return nil, err
+	}
+	return convertApplicationBase(app), nil
+}
+
+// GetApplication get the application with the status of the deployed application
+func (a *applicationUsecaseImpl) GetApplication(ctx context.Context, namespace, name string) (*apis.DetailApplicationResponse, error) {
+	app, err := a.getApplicationModel(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apis.DetailApplicationResponse{ApplicationBase: *convertApplicationBase(app)}
+	deployed := &v1beta1.Application{}
+	if err := a.kubeClient.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, deployed); err != nil {
+		if apierrors.IsNotFound(err) {
+			return resp, nil
+		}
+		return nil, err
+	}
+	resp.Status = string(deployed.Status.Phase)
+	resp.ApplicationBase.Status = resp.Status
+	resp.ResourceInfo.ComponentNum = len(deployed.Spec.Components)
+	for _, policy := range deployed.Spec.Policies {
+		resp.Policies = append(resp.Policies, policy.Name)
+	}
+	if deployed.Status.Workflow != nil {
+		for _, step := range deployed.Status.Workflow.Steps {
+			resp.WorkflowStatus = append(resp.WorkflowStatus, apis.WorkflowStepStatus{Name: step.Name, Status: string(step.Phase)})
+		}
+	}
+	return resp, nil
+}
+
+// DeleteApplication delete the application and the deployed application
+func (a *applicationUsecaseImpl) DeleteApplication(ctx context.Context, namespace, name string) (*apis.ApplicationBase, error) {
+	app, err := a.getApplicationModel(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	deployed := &v1beta1.Application{}
+	deployed.Name, deployed.Namespace = app.Name, app.Namespace
+	if err := a.kubeClient.Delete(ctx, deployed); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err := a.ds.Delete(ctx, model.ApplicationKind, app.ID); err != nil {
+		return nil, err
+	}
+	return convertApplicationBase(app), nil
+}
+
+// DeployApplication create or update the application in the kubernetes. The environments and the quota of the
+// project are checked again, because they may be changed after the application is created. The existing application
+// is only updated if it's managed by the project of the application.
+func (a *applicationUsecaseImpl) DeployApplication(ctx context.Context, namespace, name string) (*apis.ApplicationBase, error) {
+	app, err := a.getApplicationModel(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkApplicationInProject(ctx, a.ds, app, false); err != nil {
+		return nil, err
+	}
+	rendered, err := a.renderApplication(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	existing := &v1beta1.Application{}
+	if err := a.kubeClient.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err := a.kubeClient.Create(ctx, rendered); err != nil {
+			return nil, err
+		}
+	} else {
+		switch existing.Labels[labelProject] {
+		case app.Project:
+		case "":
+			log.Logger.Warnf("application %s/%s is not managed by any project", app.Namespace, app.Name)
+			return nil, bcode.ErrApplicationNotManaged
+		default:
+			log.Logger.Warnf("application %s/%s is managed by project %s rather than %s", app.Namespace, app.Name, existing.Labels[labelProject], app.Project)
+			return nil, bcode.ErrApplicationOwnedByOtherProject
+		}
+		existing.Labels = rendered.Labels
+		existing.Annotations = rendered.Annotations
+		existing.Spec = rendered.Spec
+		if err := a.kubeClient.Update(ctx, existing); err != nil {
+			return nil, err
+		}
+	}
+	app.UpdatedAt = time.Now().Unix()
+	if err := a.ds.Put(ctx, model.ApplicationKind, app.ID, app); err != nil {
+		return nil, err
+	}
+	return convertApplicationBase(app), nil
+}
+
+// CompareApplicationRevisions compares two revisions of the deployed application, the latest revision is compared
+// with the one before it if the revisions are not specified
+func (a *applicationUsecaseImpl) CompareApplicationRevisions(ctx context.Context, namespace, name, from, to string) (*apis.ApplicationRevisionDiffResponse, error) {
+	app, err := a.getApplicationModel(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	deployed := &v1beta1.Application{}
+	if err := a.kubeClient.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, deployed); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, bcode.ErrApplicationRevisionNotExist
+		}
+		return nil, err
+	}
+	from, to, err = dryrun.RevisionDiffRange(deployed, from, to)
+	if err != nil {
+		return nil, bcode.ErrApplicationRevisionNotExist
+	}
+	var revisions []*v1beta1.ApplicationRevision
+	for _, revision := range []string{from, to} {
+		appRev, err := a.revisionDiff.GetRevision(ctx, app.Namespace, app.Name, revision)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, bcode.ErrApplicationRevisionNotExist
+			}
+			return nil, err
+		}
+		revisions = append(revisions, appRev)
+	}
+	diff, err := a.revisionDiff.Diff(revisions[0], revisions[1])
+	if err != nil {
+		return nil, err
+	}
+	return &apis.ApplicationRevisionDiffResponse{
+		From: revisions[0].Name,
+		To:   revisions[1].Name,
+		Diff: convertRevisionDiffEntry(diff),
+	}, nil
+}
+
+// renderApplication parse the yaml config to the application, and check the placement of the application is
+// resolved to its namespace and clusters
+func (a *applicationUsecaseImpl) renderApplication(ctx context.Context, app *model.Application) (*v1beta1.Application, error) {
+	rendered := &v1beta1.Application{}
+	if err := yaml.Unmarshal([]byte(app.YamlConfig), rendered); err != nil {
+		log.Logger.Infof("failed to parse the yaml config of application %s: %s", app.Name, err.Error())
+		return nil, bcode.ErrInvalidApplicationConfig
+	}
+	rendered.Name = app.Name
+	rendered.Namespace = app.Namespace
+	if rendered.Labels == nil {
+		rendered.Labels = map[string]string{}
+	}
+	for k, v := range app.Labels {
+		rendered.Labels[k] = v
+	}
+	rendered.Labels[labelProject] = app.Project
+	rendered.Labels[oam.LabelAppName] = app.Name
+	if err := validatePlacement(app, rendered); err != nil {
+		return nil, err
+	}
+	return rendered, nil
+}
+
+// validatePlacement resolves the namespaces and the clusters the application is deployed to by its policies and
+// workflow steps, they must be the namespace and the clusters of the application. Anything can't be resolved
+// statically is rejected, e.g. the label selectors and the unknown types of the policies and the workflow steps.
+func validatePlacement(app *model.Application, rendered *v1beta1.Application) error {
+	envBindings := map[string]map[string]bool{}
+	for _, policy := range rendered.Spec.Policies {
+		switch {
+		case policy.Type == policyTypeEnvBinding:
+			envs, err := validateEnvBinding(app, policy)
+			if err != nil {
+				return err
+			}
+			envBindings[policy.Name] = envs
+		case placementFreePolicyTypes[policy.Type] || appfile.IsBuiltinPolicy(policy.Type):
+		default:
+			log.Logger.Infof("the placement of policy %s of type %s in application %s can not be resolved", policy.Name, policy.Type, app.Name)
+			return bcode.ErrApplicationPlacementUnresolvable
+		}
+	}
+
+	var steps []v1beta1.WorkflowStep
+	if rendered.Spec.Workflow != nil {
+		steps = append(steps, rendered.Spec.Workflow.Steps...)
+	}
+	if rendered.Spec.PreDelete != nil {
+		steps = append(steps, rendered.Spec.PreDelete.Steps...)
+	}
+	// the components are applied to the local cluster if there is no workflow
+	if rendered.Spec.Workflow == nil || len(rendered.Spec.Workflow.Steps) == 0 {
+		if err := checkLocalCluster(app); err != nil {
+			return err
+		}
+	}
+	for _, step := range steps {
+		if err := validateStepPlacement(app, step, envBindings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateEnvBinding checks the placement of the envs of the env-binding policy, and returns the names of the envs
+func validateEnvBinding(app *model.Application, policy v1beta1.AppPolicy) (map[string]bool, error) {
+	spec := &struct {
+		Engine v1alpha1.ClusterManagementEngine `json:"clusterManagementEngine,omitempty"`
+		Envs   []v1alpha1.EnvConfig             `json:"envs"`
+	}{}
+	if policy.Properties.Raw != nil {
+		if err := json.Unmarshal(policy.Properties.Raw, spec); err != nil {
+			return nil, bcode.ErrInvalidApplicationConfig
+		}
+	}
+	// only the cluster-gateway engine places the components by the cluster names
+	if spec.Engine != "" && spec.Engine != v1alpha1.ClusterGatewayEngine {
+		log.Logger.Infof("the clusters of engine %s of policy %s in application %s can not be resolved", spec.Engine, policy.Name, app.Name)
+		return nil, bcode.ErrApplicationPlacementUnresolvable
+	}
+	envs := map[string]bool{}
+	for _, env := range spec.Envs {
+		clusterSelector, namespaceSelector := env.Placement.ClusterSelector, env.Placement.NamespaceSelector
+		if clusterSelector == nil || clusterSelector.Name == "" || len(clusterSelector.Labels) != 0 ||
+			(namespaceSelector != nil && len(namespaceSelector.Labels) != 0) {
+			log.Logger.Infof("the placement of env %s of policy %s in application %s can not be resolved", env.Name, policy.Name, app.Name)
+			return nil, bcode.ErrApplicationPlacementUnresolvable
+		}
+		if !stringInSlice(clusterSelector.Name, app.ClusterList) {
+			log.Logger.Infof("cluster %s of policy %s is not in the cluster list of application %s", clusterSelector.Name, policy.Name, app.Name)
+			return nil, bcode.ErrClusterNotInEnvironment
+		}
+		if namespaceSelector != nil && namespaceSelector.Name != "" && namespaceSelector.Name != app.Namespace {
+			log.Logger.Infof("namespace %s of policy %s is not the namespace of application %s", namespaceSelector.Name, policy.Name, app.Name)
+			return nil, bcode.ErrNamespaceNotInProject
+		}
+		envs[env.Name] = true
+	}
+	return envs, nil
+}
+
+// validateStepPlacement checks the namespace and the cluster the workflow step applies the resources to
+func validateStepPlacement(app *model.Application, step v1beta1.WorkflowStep, envBindings map[string]map[string]bool) error {
+	switch {
+	case placementFreeStepTypes[step.Type]:
+		return nil
+	case localStepTypes[step.Type]:
+		return checkLocalCluster(app)
+	case step.Type == stepTypeDeploy2Env:
+		target := struct {
+			Policy string `json:"policy"`
+			Env    string `json:"env"`
+		}{}
+		if step.Properties.Raw != nil {
+			if err := json.Unmarshal(step.Properties.Raw, &target); err != nil {
+				return bcode.ErrInvalidApplicationConfig
+			}
+		}
+		if !envBindings[target.Policy][target.Env] {
+			log.Logger.Infof("env %s of policy %s deployed by step %s is not in application %s", target.Env, target.Policy, step.Name, app.Name)
+			return bcode.ErrApplicationPlacementUnresolvable
+		}
+		return nil
+	case step.Type == stepTypeApplyObject:
+		obj := &unstructured.Unstructured{}
+		if step.Properties.Raw != nil {
+			if err := json.Unmarshal(step.Properties.Raw, &obj.Object); err != nil {
+				return bcode.ErrInvalidApplicationConfig
+			}
+		}
+		if ns := obj.GetNamespace(); ns != "" && ns != app.Namespace {
+			log.Logger.Infof("namespace %s of step %s is not the namespace of application %s", ns, step.Name, app.Name)
+			return bcode.ErrNamespaceNotInProject
+		}
+		return checkLocalCluster(app)
+	}
+	log.Logger.Infof("the placement of step %s of type %s in application %s can not be resolved", step.Name, step.Type, app.Name)
+	return bcode.ErrApplicationPlacementUnresolvable
+}
+
+func checkLocalCluster(app *model.Application) error {
+	if !stringInSlice(multicluster.ClusterLocalName, app.ClusterList) {
+		log.Logger.Infof("the local cluster is not in the cluster list of application %s", app.Name)
+		return bcode.ErrClusterNotInEnvironment
+	}
+	return nil
+}
+
+// applicationID the datastore key of the application, the namespace never contains the dot
+func applicationID(namespace, name string) string {
+	return namespace + "." + name
+}
+
+// getApplicationModel find the application by the namespace and the name, the namespace can be empty if the name
+// is used in only one namespace
+func (a *applicationUsecaseImpl) getApplicationModel(ctx context.Context, namespace, name string) (*model.Application, error) {
+	apps, err := listApplicationModels(ctx, a.ds)
+	if err != nil {
+		return nil, err
+	}
+	var found *model.Application
+	for _, app := range apps {
+		if app.Name != name || (namespace != "" && app.Namespace != namespace) {
+			continue
+		}
+		if found != nil {
+			return nil, bcode.ErrApplicationAmbiguous
+		}
+		found = app
+	}
+	if found == nil {
+		return nil, bcode.ErrApplicationNotExist
+	}
+	return found, nil
+}
+
+func listApplicationModels(ctx context.Context, ds datastore.DataStore) ([]*model.Application, error) {
+	it, err := ds.Find(ctx, model.ApplicationKind)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer it.Close(ctx)
+	var apps []*model.Application
+	for it.Next(ctx) {
+		app := &model.Application{}
+		if err := it.Decode(app); err != nil {
+			return nil, err
+		}
+		// the applications stored before are keyed by the bare name
+		if app.Name == "" {
+			app.Name = app.ID
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+func convertApplicationBase(app *model.Application) *apis.ApplicationBase {
+	base := &apis.ApplicationBase{
+		Name:        app.Name,
+		Project:     app.Project,
+		Namespace:   app.Namespace,
+		Description: app.Description,
+		Icon:        app.Icon,
+		Labels:      app.Labels,
+		CreateTime:  time.Unix(app.CreatedAt, 0),
+		UpdateTime:  time.Unix(app.UpdatedAt, 0),
+	}
+	for _, cluster := range app.ClusterList {
+		base.ClusterBindList = append(base.ClusterBindList, apis.ClusterBase{Name: cluster})
+	}
+	return base
+}
+
+func convertRevisionDiffEntry(entry *dryrun.DiffEntry) *apis.ApplicationRevisionDiffEntry {
+	resp := &apis.ApplicationRevisionDiffEntry{
+		Name:     entry.Name,
+		Kind:     string(entry.Kind),
+		DiffType: string(entry.DiffType),
+	}
+	for _, d := range entry.Diffs {
+		switch d.Delta {
+		case difflib.LeftOnly:
+			resp.Lines = append(resp.Lines, "-"+d.Payload)
+		case difflib.RightOnly:
+			resp.Lines = append(resp.Lines, "+"+d.Payload)
+		default:
+			resp.Lines = append(resp.Lines, " "+d.Payload)
+		}
+	}
+	for _, sub := range entry.Subs {
+		resp.Subs = append(resp.Subs, convertRevisionDiffEntry(sub))
+	}
+	return resp
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const testApplicationYaml = `apiVersion: core.oam.dev/v1beta1
+kind: Application
+spec:
+  components:
+  - name: web
+    type: webservice
+    properties:
+      image: nginx
+  policies:
+  - name: placement
+    type: env-binding
+    properties:
+      envs:
+      - name: prod
+        placement:
+          clusterSelector:
+            name: prod-1
+  workflow:
+    steps:
+    - name: deploy-prod
+      type: deploy2env
+      properties:
+        policy: placement
+        env: prod
+`
+
+func TestValidatePlacement(t *testing.T) {
+	app := &model.Application{Name: "web", Namespace: "a-prod", ClusterList: []string{"prod-1"}}
+	testCases := map[string]struct {
+		yaml string
+		err  error
+	}{
+		"env-binding with workflow": {yaml: testApplicationYaml},
+		"cluster label selector": {yaml: `
+spec:
+  policies:
+  - name: placement
+    type: env-binding
+    properties:
+      envs:
+      - name: prod
+        placement:
+          clusterSelector:
+            labels:
+              region: hangzhou
+  workflow:
+    steps:
+    - {name: deploy, type: deploy2env, properties: {policy: placement, env: prod}}
+`, err: bcode.ErrApplicationPlacementUnresolvable},
+		"namespace selector of other namespace": {yaml: `
+spec:
+  policies:
+  - name: placement
+    type: env-binding
+    properties:
+      envs:
+      - name: prod
+        placement:
+          clusterSelector: {name: prod-1}
+          namespaceSelector: {name: kube-system}
+  workflow:
+    steps:
+    - {name: deploy, type: deploy2env, properties: {policy: placement, env: prod}}
+`, err: bcode.ErrNamespaceNotInProject},
+		"ocm engine": {yaml: `
+spec:
+  policies:
+  - name: placement
+    type: env-binding
+    properties:
+      clusterManagementEngine: ocm
+      envs:
+      - {name: prod, placement: {clusterSelector: {name: prod-1}}}
+`, err: bcode.ErrApplicationPlacementUnresolvable},
+		"unknown policy": {yaml: `
+spec:
+  policies:
+  - {name: custom, type: my-placement}
+`, err: bcode.ErrApplicationPlacementUnresolvable},
+		"deploy the undefined env": {yaml: `
+spec:
+  workflow:
+    steps:
+    - {name: deploy, type: deploy2env, properties: {policy: placement, env: prod}}
+`, err: bcode.ErrApplicationPlacementUnresolvable},
+		"unknown step": {yaml: `
+spec:
+  workflow:
+    steps:
+    - {name: deploy, type: my-deploy}
+`, err: bcode.ErrApplicationPlacementUnresolvable},
+		"apply to the local cluster without workflow": {yaml: `
+spec:
+  components:
+  - {name: web, type: webservice}
+`, err: bcode.ErrClusterNotInEnvironment},
+		"apply object to other namespace": {yaml: `
+spec:
+  workflow:
+    steps:
+    - name: apply
+      type: apply-object
+      properties: {apiVersion: v1, kind: ConfigMap, metadata: {name: config, namespace: kube-system}}
+`, err: bcode.ErrNamespaceNotInProject},
+		"suspend and notify": {yaml: `
+spec:
+  workflow:
+    steps:
+    - {name: suspend, type: suspend}
+    - {name: notify, type: webhook-notification}
+`},
+	}
+	for _, typ := range []string{v1alpha1.DriftDetectionPolicyType, v1alpha1.AppHealthPolicyType, v1alpha1.GarbageCollectPolicyType} {
+		testCases["builtin policy "+typ] = struct {
+			yaml string
+			err  error
+		}{yaml: `
+spec:
+  policies:
+  - name: placement
+    type: env-binding
+    properties:
+      envs:
+      - {name: prod, placement: {clusterSelector: {name: prod-1}}}
+  - {name: builtin, type: ` + typ + `}
+  workflow:
+    steps:
+    - {name: deploy, type: deploy2env, properties: {policy: placement, env: prod}}
+`}
+	}
+	for name, tc := range testCases {
+		rendered := &v1beta1.Application{}
+		require.NoError(t, yaml.Unmarshal([]byte(tc.yaml), rendered), name)
+		assert.Equal(t, tc.err, validatePlacement(app, rendered), name)
+	}
+}
+
+func TestApplicationUsecase(t *testing.T) {
+	oldNamespace := multicluster.ClusterGatewaySecretNamespace
+	multicluster.ClusterGatewaySecretNamespace = "vela-system"
+	defer func() {
+		multicluster.ClusterGatewaySecretNamespace = oldNamespace
+	}()
+	ctx := context.Background()
+	_, kubeClient, ds := newTestClusterUsecase(t, newTestClusterSecret("prod-1"), newTestClusterSecret("prod-2"))
+	projectUsecase := NewProjectUsecase(ds, kubeClient)
+	usecase := NewApplicationUsecase(ds, kubeClient, nil, &packages.PackageDiscover{})
+
+	_, err := projectUsecase.CreateProject(ctx, apis.CreateProjectRequest{Name: "team-a", Quota: apis.ProjectQuota{MaxApplications: 2}})
+	require.NoError(t, err)
+	_, err = projectUsecase.CreateEnvironment(ctx, "team-a", apis.CreateEnvironmentRequest{Name: "a-prod", Namespace: "a-prod", Clusters: []string{"prod-1", "prod-2"}})
+	require.NoError(t, err)
+
+	_, err = usecase.CreateApplication(ctx, apis.CreateApplicationRequest{Name: "web", Project: "missing", Namespace: "a-prod"})
+	assert.Equal(t, bcode.ErrProjectNotExist, err)
+	_, err = usecase.CreateApplication(ctx, apis.CreateApplicationRequest{Name: "web", Project: "team-a", Namespace: "default"})
+	assert.Equal(t, bcode.ErrNamespaceNotInProject, err)
+	_, err = usecase.CreateApplication(ctx, apis.CreateApplicationRequest{Name: "web", Project: "team-a", Namespace: "a-prod", ClusterList: []string{"other"}})
+	assert.Equal(t, bcode.ErrClusterNotInEnvironment, err)
+	_, err = usecase.CreateApplication(ctx, apis.CreateApplicationRequest{Name: "web", Project: "team-a", Namespace: "a-prod", ClusterList: []string{"prod-2"}, YamlConfig: testApplicationYaml})
+	assert.Equal(t, bcode.ErrClusterNotInEnvironment, err)
+	_, err = usecase.CreateApplication(ctx, apis.CreateApplicationRequest{Name: "web", Project: "team-a", Namespace: "a-prod", YamlConfig: "{invalid"})
+	assert.Equal(t, bcode.ErrInvalidApplicationConfig, err)
+
+	app, err := usecase.CreateApplication(ctx, apis.CreateApplicationRequest{Name: "web", Project: "team-a", Namespace: "a-prod", YamlConfig: testApplicationYaml})
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(app.ClusterBindList))
+	_, err = usecase.CreateApplication(ctx, apis.CreateApplicationRequest{Name: "web", Project: "team-a", Namespace: "a-prod"})
+	assert.Equal(t, bcode.ErrApplicationExist, err)
+	_, err = usecase.CreateApplication(ctx, apis.CreateApplicationRequest{Name: "api", Project: "team-a", Namespace: "a-prod"})
+	require.NoError(t, err)
+	_, err = usecase.CreateApplication(ctx, apis.CreateApplicationRequest{Name: "worker", Project: "team-a", Namespace: "a-prod"})
+	assert.Equal(t, bcode.ErrApplicationQuotaExceeded, err)
+
+	list, err := usecase.ListApplications(ctx, apis.ListApplicationOptions{Project: "team-a", Query: "we"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(list.Applications))
+
+	// the application name is unique in the namespace
+	_, err = projectUsecase.CreateProject(ctx, apis.CreateProjectRequest{Name: "team-b"})
+	require.NoError(t, err)
+	_, err = projectUsecase.CreateEnvironment(ctx, "team-b", apis.CreateEnvironmentRequest{Name: "b-prod", Namespace: "b-prod"})
+	require.NoError(t, err)
+	_, err = usecase.CreateApplication(ctx, apis.CreateApplicationRequest{Name: "web", Project: "team-b", Namespace: "b-prod"})
+	require.NoError(t, err)
+	_, err = usecase.GetApplicationBase(ctx, "", "web")
+	assert.Equal(t, bcode.ErrApplicationAmbiguous, err)
+	base, err := usecase.GetApplicationBase(ctx, "b-prod", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "team-b", base.Project)
+	base, err = usecase.GetApplicationBase(ctx, "", "api")
+	require.NoError(t, err)
+	assert.Equal(t, "a-prod", base.Namespace)
+
+	// the existing application not managed by the project is not overwritten
+	require.NoError(t, kubeClient.Create(ctx, &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "b-prod"}}))
+	_, err = usecase.DeployApplication(ctx, "b-prod", "web")
+	assert.Equal(t, bcode.ErrApplicationNotManaged, err)
+	existing := &v1beta1.Application{}
+	require.NoError(t, kubeClient.Get(ctx, types.NamespacedName{Namespace: "b-prod", Name: "web"}, existing))
+	existing.Labels = map[string]string{labelProject: "team-a"}
+	require.NoError(t, kubeClient.Update(ctx, existing))
+	_, err = usecase.DeployApplication(ctx, "b-prod", "web")
+	assert.Equal(t, bcode.ErrApplicationOwnedByOtherProject, err)
+
+	_, err = usecase.DeployApplication(ctx, "a-prod", "web")
+	require.NoError(t, err)
+	deployed := &v1beta1.Application{}
+	require.NoError(t, kubeClient.Get(ctx, types.NamespacedName{Namespace: "a-prod", Name: "web"}, deployed))
+	assert.Equal(t, "team-a", deployed.Labels[labelProject])
+	assert.Equal(t, 1, len(deployed.Spec.Components))
+	// deploy again updates the application
+	_, err = usecase.DeployApplication(ctx, "a-prod", "web")
+	require.NoError(t, err)
+
+	// the quota is lowered after the application is created
+	_, err = projectUsecase.UpdateProject(ctx, "team-a", apis.UpdateProjectRequest{Quota: apis.ProjectQuota{MaxClusters: 1}})
+	require.NoError(t, err)
+	_, err = usecase.DeployApplication(ctx, "a-prod", "web")
+	assert.Equal(t, bcode.ErrClusterQuotaExceeded, err)
+
+	detail, err := usecase.GetApplication(ctx, "a-prod", "web")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"placement"}, detail.Policies)
+	assert.Equal(t, 1, detail.ResourceInfo.ComponentNum)
+
+	_, err = usecase.CompareApplicationRevisions(ctx, "a-prod", "web", "", "")
+	assert.Equal(t, bcode.ErrApplicationRevisionNotExist, err)
+	require.NoError(t, kubeClient.Create(ctx, newTestAppRevision("web-v1", "nginx:1.20")))
+	require.NoError(t, kubeClient.Create(ctx, newTestAppRevision("web-v2", "nginx:1.21")))
+	require.NoError(t, kubeClient.Get(ctx, types.NamespacedName{Namespace: "a-prod", Name: "web"}, deployed))
+	deployed.Status.LatestRevision = &common.Revision{Name: "web-v2", Revision: 2}
+	require.NoError(t, kubeClient.Status().Update(ctx, deployed))
+	revDiff, err := usecase.CompareApplicationRevisions(ctx, "a-prod", "web", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "web-v1", revDiff.From)
+	assert.Equal(t, "web-v2", revDiff.To)
+	assert.Equal(t, "MODIFY", revDiff.Diff.DiffType)
+	assert.Contains(t, revDiff.Diff.Lines, "-      image: nginx:1.20")
+	assert.Contains(t, revDiff.Diff.Lines, "+      image: nginx:1.21")
+	_, err = usecase.CompareApplicationRevisions(ctx, "a-prod", "web", "3", "")
+	assert.Equal(t, bcode.ErrApplicationRevisionNotExist, err)
+
+	_, err = usecase.DeleteApplication(ctx, "a-prod", "web")
+	require.NoError(t, err)
+	err = kubeClient.Get(ctx, types.NamespacedName{Namespace: "a-prod", Name: "web"}, deployed)
+	assert.Error(t, err)
+	_, err = usecase.GetApplication(ctx, "a-prod", "web")
+	assert.Equal(t, bcode.ErrApplicationNotExist, err)
+}
+
+func newTestAppRevision(name, image string) *v1beta1.ApplicationRevision {
+	appRev := &v1beta1.ApplicationRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "a-prod",
+			Labels:    map[string]string{oam.LabelAppName: "web"},
+		},
+	}
+	appRev.Spec.Application.Spec.Components = []common.ApplicationComponent{{
+		Name:       "web",
+		Type:       "webservice",
+		Properties: runtime.RawExtension{Raw: []byte(`{"image":"` + image + `"}`)},
+	}}
+	appRev.Spec.ComponentDefinitions = map[string]v1beta1.ComponentDefinition{
+		"webservice": {Spec: v1beta1.ComponentDefinitionSpec{
+			Schematic: &common.Schematic{CUE: &common.CUE{
+				Template: "output: {\n\tapiVersion: \"apps/v1\"\n\tkind: \"Deployment\"\n\tspec: template: spec: containers: [{image: parameter.image}]\n}\nparameter: image: string\n",
+			}},
+		}},
+	}
+	return appRev
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+// subscriberBufferSize is the max number of the events buffered for a subscriber,
+// the slow subscriber is closed if the buffer is full and it should resume from the last resourceVersion.
+const subscriberBufferSize = 128
+
+// ApplicationWatchUsecase watch the status changes of the applications
+type ApplicationWatchUsecase interface {
+	// Start run the application informer until the context is done
+	Start(context.Context)
+	// Subscribe the status changes of the application. The snapshot of the current status is sent first if the
+	// resourceVersion is empty, otherwise the changes since the resourceVersion are replayed by a watch of the API
+	// server started at it, and bcode.ErrApplicationResourceVersionExpired is returned if it's compacted.
+	// The channel is closed after the cancel function is called or the subscriber is too slow.
+	Subscribe(namespace, name, resourceVersion string) (<-chan apis.ApplicationEvent, func(), error)
+}
+
+type subscriber struct {
+	namespace string
+	name      string
+	ch        chan apis.ApplicationEvent
+}
+
+type applicationWatchUsecaseImpl struct {
+	kubeClient client.WithWatch
+	informer   toolscache.SharedIndexInformer
+
+	mutex       sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// NewApplicationWatchUsecase new application watch usecase backed by the informer of all applications
+func NewApplicationWatchUsecase(kubeClient client.WithWatch) ApplicationWatchUsecase {
+	lw := &toolscache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			apps := &v1beta1.ApplicationList{}
+			err := kubeClient.List(context.Background(), apps, &client.ListOptions{Raw: &options})
+			return apps, err
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.Watch(context.Background(), &v1beta1.ApplicationList{}, &client.ListOptions{Raw: &options})
+		},
+	}
+	a := &applicationWatchUsecaseImpl{
+		kubeClient:  kubeClient,
+		informer:    toolscache.NewSharedIndexInformer(lw, &v1beta1.Application{}, 0, toolscache.Indexers{}),
+		subscribers: map[*subscriber]struct{}{},
+	}
+	a.informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if app, ok := obj.(*v1beta1.Application); ok {
+				a.dispatch(app, diffApplicationStatus(nil, app))
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldApp, ok := oldObj.(*v1beta1.Application)
+			if !ok {
+				return
+			}
+			if newApp, ok := newObj.(*v1beta1.Application); ok {
+				a.dispatch(newApp, diffApplicationStatus(oldApp, newApp))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if app, ok := obj.(*v1beta1.Application); ok {
+				a.dispatch(app, []apis.ApplicationEvent{newApplicationEvent(app, apis.ApplicationEventDeleted)})
+			}
+		},
+	})
+	return a
+}
+
+func (a *applicationWatchUsecaseImpl) Start(ctx context.Context) {
+	a.informer.Run(ctx.Done())
+}
+
+func (a *applicationWatchUsecaseImpl) Subscribe(namespace, name, resourceVersion string) (<-chan apis.ApplicationEvent, func(), error) {
+	if !a.informer.HasSynced() {
+		return nil, nil, bcode.ErrApplicationWatcherNotReady
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	obj, exists, err := a.informer.GetStore().GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, bcode.ErrApplicationNotExist
+	}
+	app := obj.(*v1beta1.Application)
+	if resourceVersion != "" && resourceVersion != app.ResourceVersion {
+		return a.resume(namespace, name, resourceVersion)
+	}
+	sub := &subscriber{namespace: namespace, name: name, ch: make(chan apis.ApplicationEvent, subscriberBufferSize)}
+	a.subscribers[sub] = struct{}{}
+	if resourceVersion == "" {
+		for _, event := range diffApplicationStatus(nil, app) {
+			if !a.send(sub, event) {
+				break
+			}
+		}
+	}
+	cancel := func() {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		a.unsubscribe(sub)
+	}
+	return sub.ch, cancel, nil
+}
+
+// errWatchStopped the subscriber is canceled or the application is deleted
+var errWatchStopped = errors.New("the watch of the application is stopped")
+
+// resume replays the changes of the application since the resourceVersion by a watch of the API server, the watch
+// is restarted from the last resourceVersion when it's closed by the server. If the resourceVersion expires while
+// watching, the current status from the informer is sent as the changes since the last event to resync.
+func (a *applicationWatchUsecaseImpl) resume(namespace, name, resourceVersion string) (<-chan apis.ApplicationEvent, func(), error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := a.watch(ctx, namespace, name, resourceVersion)
+	if err != nil {
+		cancel()
+		if apierrors.IsGone(err) || apierrors.IsResourceExpired(err) {
+			return nil, nil, bcode.ErrApplicationResourceVersionExpired
+		}
+		return nil, nil, err
+	}
+	ch := make(chan apis.ApplicationEvent, subscriberBufferSize)
+	go func() {
+		defer close(ch)
+		// the status at the resourceVersion is unknown, the first change is sent with the full status
+		var last *v1beta1.Application
+		for {
+			err := a.replay(ctx, w, &last, ch)
+			w.Stop()
+			switch {
+			case err == nil:
+			case apierrors.IsGone(err) || apierrors.IsResourceExpired(err):
+				obj, exists, getErr := a.informer.GetStore().GetByKey(namespace + "/" + name)
+				if getErr != nil || !exists {
+					return
+				}
+				current := obj.(*v1beta1.Application)
+				if !sendEvents(ctx, ch, diffApplicationStatus(last, current)) {
+					return
+				}
+				last = current
+			default:
+				return
+			}
+			if last != nil {
+				resourceVersion = last.ResourceVersion
+			}
+			if w, err = a.watch(ctx, namespace, name, resourceVersion); err != nil {
+				log.Logger.Warnf("cannot resume the watch of application %s/%s: %s", namespace, name, err.Error())
+				return
+			}
+		}
+	}()
+	return ch, cancel, nil
+}
+
+func (a *applicationWatchUsecaseImpl) watch(ctx context.Context, namespace, name, resourceVersion string) (watch.Interface, error) {
+	return a.kubeClient.Watch(ctx, &v1beta1.ApplicationList{}, &client.ListOptions{
+		Namespace: namespace,
+		Raw: &metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+			ResourceVersion: resourceVersion,
+		},
+	})
+}
+
+// replay sends the changes of the watch to the channel, it returns nil when the watch is closed by the server and
+// the error of the error event, e.g. the resourceVersion is expired
+func (a *applicationWatchUsecaseImpl) replay(ctx context.Context, w watch.Interface, last **v1beta1.Application, ch chan<- apis.ApplicationEvent) error {
+	for {
+		var e watch.Event
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return errWatchStopped
+		case e, ok = <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+		}
+		switch e.Type {
+		case watch.Added, watch.Modified:
+			app, isApp := e.Object.(*v1beta1.Application)
+			if !isApp {
+				continue
+			}
+			if !sendEvents(ctx, ch, diffApplicationStatus(*last, app)) {
+				return errWatchStopped
+			}
+			*last = app
+		case watch.Deleted:
+			if app, isApp := e.Object.(*v1beta1.Application); isApp {
+				sendEvents(ctx, ch, []apis.ApplicationEvent{newApplicationEvent(app, apis.ApplicationEventDeleted)})
+			}
+			return errWatchStopped
+		case watch.Error:
+			return apierrors.FromObject(e.Object)
+		}
+	}
+}
+
+// sendEvents sends the events of the resumed watch, it blocks until they are sent or the subscriber is canceled
+func sendEvents(ctx context.Context, ch chan<- apis.ApplicationEvent, events []apis.ApplicationEvent) bool {
+	for _, event := range events {
+		select {
+		case ch <- event:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// dispatch send the events to the subscribers of the application
+func (a *applicationWatchUsecaseImpl) dispatch(app *v1beta1.Application, events []apis.ApplicationEvent) {
+	if len(events) == 0 {
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for sub := range a.subscribers {
+		if sub.namespace != app.Namespace || sub.name != app.Name {
+			continue
+		}
+		for _, event := range events {
+			if !a.send(sub, event) {
+				break
+			}
+		}
+	}
+}
+
+// send the event to the subscriber without blocking, the subscriber is closed if its buffer is full
+func (a *applicationWatchUsecaseImpl) send(sub *subscriber, event apis.ApplicationEvent) bool {
+	select {
+	case sub.ch <- event:
+		return true
+	default:
+		log.Logger.Warnf("subscriber of application %s/%s is too slow, close it", sub.namespace, sub.name)
+		a.unsubscribe(sub)
+		return false
+	}
+}
+
+func (a *applicationWatchUsecaseImpl) unsubscribe(sub *subscriber) {
+	if _, ok := a.subscribers[sub]; ok {
+		delete(a.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+func newApplicationEvent(app *v1beta1.Application, eventType apis.ApplicationEventType) apis.ApplicationEvent {
+	return apis.ApplicationEvent{
+		Type:            eventType,
+		Name:            app.Name,
+		Namespace:       app.Namespace,
+		ResourceVersion: app.ResourceVersion,
+	}
+}
+
+// diffApplicationStatus generate the events for the phase, workflow step and component health changes,
+// all the status is regarded as changed if the old application is nil
+func diffApplicationStatus(oldApp, newApp *v1beta1.Application) []apis.ApplicationEvent {
+	var events []apis.ApplicationEvent
+	oldStatus := common.AppStatus{}
+	if oldApp != nil {
+		oldStatus = oldApp.Status
+	}
+	newStatus := newApp.Status
+	if oldApp == nil || oldStatus.Phase != newStatus.Phase {
+		event := newApplicationEvent(newApp, apis.ApplicationEventPhase)
+		event.Phase = string(newStatus.Phase)
+		events = append(events, event)
+	}
+
+	oldSteps := map[string]common.WorkflowStepStatus{}
+	if oldStatus.Workflow != nil {
+		for _, step := range oldStatus.Workflow.Steps {
+			oldSteps[step.Name] = step
+		}
+	}
+	if newStatus.Workflow != nil {
+		for _, step := range newStatus.Workflow.Steps {
+			if oldStep, ok := oldSteps[step.Name]; ok && oldStep.Phase == step.Phase && oldStep.Message == step.Message && oldStep.Reason == step.Reason {
+				continue
+			}
+			event := newApplicationEvent(newApp, apis.ApplicationEventWorkflowStep)
+			event.WorkflowStep = &apis.ApplicationWorkflowStepEvent{
+				Name:    step.Name,
+				Type:    step.Type,
+				Phase:   string(step.Phase),
+				Message: step.Message,
+				Reason:  step.Reason,
+			}
+			events = append(events, event)
+		}
+	}
+
+	oldServices := map[string]common.ApplicationComponentStatus{}
+	for _, svc := range oldStatus.Services {
+		oldServices[svc.Env+"/"+svc.Name] = svc
+	}
+	for _, svc := range newStatus.Services {
+		if oldSvc, ok := oldServices[svc.Env+"/"+svc.Name]; ok && oldSvc.Healthy == svc.Healthy && oldSvc.Message == svc.Message {
+			continue
+		}
+		event := newApplicationEvent(newApp, apis.ApplicationEventComponentHealth)
+		event.Component = &apis.ApplicationComponentHealthEvent{
+			Name:    svc.Name,
+			Env:     svc.Env,
+			Healthy: svc.Healthy,
+			Message: svc.Message,
+		}
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	velacommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func receiveEvent(t *testing.T, events <-chan apis.ApplicationEvent) apis.ApplicationEvent {
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "event channel is closed")
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for application event")
+	}
+	return apis.ApplicationEvent{}
+}
+
+func TestDiffApplicationStatus(t *testing.T) {
+	app := &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", ResourceVersion: "1"}}
+	app.Status.Phase = common.ApplicationRunningWorkflow
+	app.Status.Workflow = &common.WorkflowStatus{Steps: []common.WorkflowStepStatus{{Name: "deploy", Phase: common.WorkflowStepPhaseRunning}}}
+	app.Status.Services = []common.ApplicationComponentStatus{{Name: "web", Healthy: false}}
+	assert.Equal(t, 3, len(diffApplicationStatus(nil, app)))
+
+	newApp := app.DeepCopy()
+	newApp.ResourceVersion = "2"
+	assert.Equal(t, 0, len(diffApplicationStatus(app, newApp)))
+
+	newApp.Status.Phase = common.ApplicationRunning
+	newApp.Status.Workflow.Steps[0].Phase = common.WorkflowStepPhaseSucceeded
+	newApp.Status.Services = append(newApp.Status.Services, common.ApplicationComponentStatus{Name: "db", Healthy: true})
+	events := diffApplicationStatus(app, newApp)
+	require.Equal(t, 3, len(events))
+	assert.Equal(t, apis.ApplicationEventPhase, events[0].Type)
+	assert.Equal(t, string(common.ApplicationRunning), events[0].Phase)
+	assert.Equal(t, apis.ApplicationEventWorkflowStep, events[1].Type)
+	assert.Equal(t, string(common.WorkflowStepPhaseSucceeded), events[1].WorkflowStep.Phase)
+	assert.Equal(t, apis.ApplicationEventComponentHealth, events[2].Type)
+	assert.Equal(t, "db", events[2].Component.Name)
+	assert.Equal(t, "2", events[2].ResourceVersion)
+}
+
+func TestApplicationWatchUsecase(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	app := &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+	app.Status.Phase = common.ApplicationRendering
+	kubeClient := fake.NewClientBuilder().WithScheme(velacommon.Scheme).WithObjects(app).Build()
+	usecase := NewApplicationWatchUsecase(kubeClient)
+	_, _, err := usecase.Subscribe("default", "app", "")
+	assert.Equal(t, bcode.ErrApplicationWatcherNotReady, err)
+
+	go usecase.Start(ctx)
+	require.Eventually(t, func() bool {
+		_, _, err = usecase.Subscribe("default", "not-exist", "")
+		return err == bcode.ErrApplicationNotExist
+	}, 5*time.Second, 50*time.Millisecond)
+
+	current := &v1beta1.Application{}
+	require.NoError(t, kubeClient.Get(ctx, client.ObjectKeyFromObject(app), current))
+	// the subscriber with the current resourceVersion gets no snapshot
+	upToDate, cancelUpToDate, err := usecase.Subscribe("default", "app", current.ResourceVersion)
+	require.NoError(t, err)
+	defer cancelUpToDate()
+	events, cancelEvents, err := usecase.Subscribe("default", "app", "")
+	require.NoError(t, err)
+	event := receiveEvent(t, events)
+	assert.Equal(t, apis.ApplicationEventPhase, event.Type)
+	assert.Equal(t, string(common.ApplicationRendering), event.Phase)
+
+	current.Status.Phase = common.ApplicationRunningWorkflow
+	current.Status.Workflow = &common.WorkflowStatus{Steps: []common.WorkflowStepStatus{{Name: "deploy", Phase: common.WorkflowStepPhaseRunning}}}
+	require.NoError(t, kubeClient.Status().Update(ctx, current))
+	for _, s := range []<-chan apis.ApplicationEvent{events, upToDate} {
+		event = receiveEvent(t, s)
+		assert.Equal(t, string(common.ApplicationRunningWorkflow), event.Phase)
+		event = receiveEvent(t, s)
+		assert.Equal(t, "deploy", event.WorkflowStep.Name)
+	}
+
+	cancelEvents()
+	_, ok := <-events
+	assert.False(t, ok)
+
+	require.NoError(t, kubeClient.Delete(ctx, current))
+	event = receiveEvent(t, upToDate)
+	assert.Equal(t, apis.ApplicationEventDeleted, event.Type)
+}
+
+// resumableClient replaces the watches of a single application with the fake watchers to replay the changes since
+// a resourceVersion, the fake client doesn't replay them
+type resumableClient struct {
+	client.WithWatch
+	resourceVersions chan string
+	watchers         chan *watch.FakeWatcher
+	err              error
+}
+
+func (c *resumableClient) Watch(ctx context.Context, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.Raw == nil || listOpts.Raw.FieldSelector == "" {
+		return c.WithWatch.Watch(ctx, list, opts...)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.resourceVersions <- listOpts.Raw.ResourceVersion
+	w := watch.NewFake()
+	c.watchers <- w
+	return w, nil
+}
+
+func TestResumeApplicationWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	app := &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+	app.Status.Phase = common.ApplicationRunning
+	kubeClient := &resumableClient{
+		WithWatch:        fake.NewClientBuilder().WithScheme(velacommon.Scheme).WithObjects(app).Build(),
+		resourceVersions: make(chan string, 10),
+		watchers:         make(chan *watch.FakeWatcher, 10),
+	}
+	usecase := NewApplicationWatchUsecase(kubeClient)
+	go usecase.Start(ctx)
+	require.Eventually(t, func() bool {
+		_, cancelEvents, err := usecase.Subscribe("default", "app", "")
+		if err == nil {
+			cancelEvents()
+		}
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// reconnect with the last event id, the missed changes are replayed
+	events, cancelEvents, err := usecase.Subscribe("default", "app", "1")
+	require.NoError(t, err)
+	defer cancelEvents()
+	assert.Equal(t, "1", <-kubeClient.resourceVersions)
+	w := <-kubeClient.watchers
+	missed := app.DeepCopy()
+	missed.ResourceVersion = "2"
+	missed.Status.Phase = common.ApplicationRunningWorkflow
+	w.Modify(missed)
+	event := receiveEvent(t, events)
+	assert.Equal(t, string(common.ApplicationRunningWorkflow), event.Phase)
+	assert.Equal(t, "2", event.ResourceVersion)
+	next := missed.DeepCopy()
+	next.ResourceVersion = "3"
+	next.Status.Workflow = &common.WorkflowStatus{Steps: []common.WorkflowStepStatus{{Name: "deploy", Phase: common.WorkflowStepPhaseRunning}}}
+	w.Modify(next)
+	event = receiveEvent(t, events)
+	assert.Equal(t, apis.ApplicationEventWorkflowStep, event.Type)
+	assert.Equal(t, "3", event.ResourceVersion)
+
+	// the watch closed by the server is restarted from the last resourceVersion
+	w.Stop()
+	assert.Equal(t, "3", <-kubeClient.resourceVersions)
+	w = <-kubeClient.watchers
+
+	// the resourceVersion expires while watching, the status is resynced from the informer
+	w.Error(&apierrors.NewResourceExpired("too old resource version").ErrStatus)
+	event = receiveEvent(t, events)
+	assert.Equal(t, apis.ApplicationEventPhase, event.Type)
+	assert.Equal(t, string(common.ApplicationRunning), event.Phase)
+	<-kubeClient.resourceVersions
+	w = <-kubeClient.watchers
+	w.Delete(next)
+	event = receiveEvent(t, events)
+	assert.Equal(t, apis.ApplicationEventDeleted, event.Type)
+	_, ok := <-events
+	assert.False(t, ok)
+
+	// the resourceVersion is expired when the watch starts
+	kubeClient.err = apierrors.NewGone("too old resource version")
+	_, _, err = usecase.Subscribe("default", "app", "1")
+	assert.Equal(t, bcode.ErrApplicationResourceVersionExpired, err)
+}
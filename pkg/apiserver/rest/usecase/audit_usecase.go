@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+)
+
+// maxIndexedDays the max days of the time range whose records are found by the date index, the records of a longer
+// range are found by the user index only
+const maxIndexedDays = 31
+
+// AuditUsecase audit record query
+type AuditUsecase interface {
+	ListAuditRecords(context.Context, apis.ListAuditRecordOptions) (*apis.ListAuditRecordResponse, error)
+}
+
+type auditUsecaseImpl struct {
+	ds datastore.DataStore
+}
+
+// NewAuditUsecase new audit usecase
+func NewAuditUsecase(ds datastore.DataStore) AuditUsecase {
+	return &auditUsecaseImpl{ds: ds}
+}
+
+// ListAuditRecords list the audit records matching the user and the time range, the latest record is the first
+func (a *auditUsecaseImpl) ListAuditRecords(ctx context.Context, options apis.ListAuditRecordOptions) (*apis.ListAuditRecordResponse, error) {
+	var records []*model.AuditRecord
+	for _, index := range auditRecordIndexes(options, time.Now()) {
+		found, err := a.findAuditRecords(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range found {
+			recordTime := time.Unix(0, record.Timestamp)
+			if !options.Since.IsZero() && recordTime.Before(options.Since) {
+				continue
+			}
+			if !options.Until.IsZero() && recordTime.After(options.Until) {
+				continue
+			}
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp > records[j].Timestamp
+	})
+	if options.Limit > 0 && len(records) > options.Limit {
+		records = records[:options.Limit]
+	}
+	resp := &apis.ListAuditRecordResponse{Records: []apis.AuditRecordBase{}}
+	for _, record := range records {
+		resp.Records = append(resp.Records, apis.AuditRecordBase{
+			Name:            record.Name,
+			User:            record.User,
+			Groups:          record.Groups,
+			Method:          record.Method,
+			Path:            record.Path,
+			ResourceType:    record.ResourceType,
+			ResourceName:    record.ResourceName,
+			RequestBodyHash: record.RequestBodyHash,
+			StatusCode:      record.StatusCode,
+			Outcome:         record.Outcome,
+			Latency:         time.Duration(record.LatencyMilliseconds) * time.Millisecond,
+			RemoteAddr:      record.RemoteAddr,
+			Time:            time.Unix(0, record.Timestamp),
+		})
+	}
+	return resp, nil
+}
+
+// auditRecordIndexes returns the indexes to find the records in the datastore, the records of a time range are found
+// day by day through the date index unless the range is longer than maxIndexedDays
+func auditRecordIndexes(options apis.ListAuditRecordOptions, now time.Time) []map[string]string {
+	newIndex := func() map[string]string {
+		index := map[string]string{}
+		if options.User != "" {
+			index["user"] = options.User
+		}
+		return index
+	}
+	if !options.Since.IsZero() {
+		until := options.Until
+		if until.IsZero() {
+			until = now
+		}
+		since := options.Since.UTC()
+		var indexes []map[string]string
+		for day := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC); !day.After(until); day = day.AddDate(0, 0, 1) {
+			index := newIndex()
+			index["date"] = day.Format(model.AuditRecordDateFormat)
+			indexes = append(indexes, index)
+			if len(indexes) > maxIndexedDays {
+				return []map[string]string{newIndex()}
+			}
+		}
+		return indexes
+	}
+	return []map[string]string{newIndex()}
+}
+
+func (a *auditUsecaseImpl) findAuditRecords(ctx context.Context, index map[string]string) ([]*model.AuditRecord, error) {
+	var it datastore.Iterator
+	var err error
+	if len(index) == 0 {
+		it, err = a.ds.Find(ctx, model.AuditRecordKind)
+	} else {
+		it, err = a.ds.FindByIndex(ctx, model.AuditRecordKind, index)
+	}
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer it.Close(ctx)
+	var records []*model.AuditRecord
+	for it.Next(ctx) {
+		record := &model.AuditRecord{}
+		if err := it.Decode(record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+)
+
+func TestAuditUsecase(t *testing.T) {
+	ctx := context.Background()
+	_, _, ds := newTestClusterUsecase(t)
+	usecase := NewAuditUsecase(ds)
+
+	base := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i, user := range []string{"alice", "bob", "alice", "alice", "alice"} {
+		ts := base.Add(time.Duration(i) * time.Hour)
+		if i == 4 {
+			ts = base.AddDate(0, 0, 1)
+		}
+		require.NoError(t, ds.Add(ctx, model.AuditRecordKind, &model.AuditRecord{
+			Name:      fmt.Sprintf("record-%d", i),
+			User:      user,
+			Method:    "POST",
+			Timestamp: ts.UnixNano(),
+			Date:      ts.Format(model.AuditRecordDateFormat),
+		}))
+	}
+
+	resp, err := usecase.ListAuditRecords(ctx, apis.ListAuditRecordOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 5, len(resp.Records))
+	assert.Equal(t, "record-4", resp.Records[0].Name)
+
+	resp, err = usecase.ListAuditRecords(ctx, apis.ListAuditRecordOptions{User: "alice", Since: base.Add(30 * time.Minute), Until: base.Add(150 * time.Minute)})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(resp.Records))
+	assert.Equal(t, "record-2", resp.Records[0].Name)
+
+	// the records of the days in the range are found by the date index
+	resp, err = usecase.ListAuditRecords(ctx, apis.ListAuditRecordOptions{Since: base.Add(150 * time.Minute), Until: base.AddDate(0, 0, 2)})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(resp.Records))
+	assert.Equal(t, "record-4", resp.Records[0].Name)
+	assert.Equal(t, "record-3", resp.Records[1].Name)
+
+	resp, err = usecase.ListAuditRecords(ctx, apis.ListAuditRecordOptions{User: "alice", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(resp.Records))
+}
+
+func TestAuditRecordIndexes(t *testing.T) {
+	now := time.Date(2021, 10, 3, 12, 0, 0, 0, time.UTC)
+	indexes := auditRecordIndexes(apis.ListAuditRecordOptions{User: "alice", Since: now.AddDate(0, 0, -2)}, now)
+	assert.Equal(t, []map[string]string{
+		{"user": "alice", "date": "20211001"},
+		{"user": "alice", "date": "20211002"},
+		{"user": "alice", "date": "20211003"},
+	}, indexes)
+
+	indexes = auditRecordIndexes(apis.ListAuditRecordOptions{Since: now.AddDate(0, -3, 0)}, now)
+	assert.Equal(t, []map[string]string{{}}, indexes)
+}
@@ -18,25 +18,301 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
 	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+)
+
+const (
+	// kubeConfigSecretKey the data key of the kubeconfig in the secret referenced by CreateClusterRequest.KubeConfigSecret
+	kubeConfigSecretKey = "kubeconfig"
+
+	labelNodeRoleMaster       = "node-role.kubernetes.io/master"
+	labelNodeRoleControlPlane = "node-role.kubernetes.io/control-plane"
+	resourceNvidiaGPU         = corev1.ResourceName("nvidia.com/gpu")
+
+	// clusterProbeTimeout the max duration of checking the connectivity of a cluster
+	clusterProbeTimeout = 5 * time.Second
 )
 
 // ClusterUsecase cluster manage
 type ClusterUsecase interface {
+	ListKubeClusters(context.Context, string) (*apis.ListClusterResponse, error)
 	CreateKubeCluster(context.Context, apis.CreateClusterRequest) (*apis.ClusterBase, error)
+	GetKubeCluster(context.Context, string) (*apis.DetailClusterResponse, error)
+	DeleteKubeCluster(context.Context, string) (*apis.ClusterBase, error)
 }
 
 type clusterUsecaseImpl struct {
-	ds datastore.DataStore
+	ds         datastore.DataStore
+	kubeClient client.Client
 }
 
 // NewClusterUsecase new cluster usecase
-func NewClusterUsecase(ds datastore.DataStore) ClusterUsecase {
-	return &clusterUsecaseImpl{ds: ds}
+func NewClusterUsecase(ds datastore.DataStore, kubeClient client.Client) ClusterUsecase {
+	return &clusterUsecaseImpl{ds: ds, kubeClient: kubeClient}
+}
+
+// ListKubeClusters list the local cluster and all the managed clusters, filtered by the fuzzy query of name or description
+func (c *clusterUsecaseImpl) ListKubeClusters(ctx context.Context, query string) (*apis.ListClusterResponse, error) {
+	secrets, err := multicluster.ListClusterSecrets(ctx, c.kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{multicluster.ClusterLocalName}
+	for _, secret := range secrets {
+		names = append(names, secret.Name)
+	}
+	var bases []*apis.ClusterBase
+	for _, name := range names {
+		cluster, err := c.getClusterModel(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if query != "" && !strings.Contains(cluster.Name, query) && !strings.Contains(cluster.Description, query) {
+			continue
+		}
+		bases = append(bases, convertClusterBase(cluster))
+	}
+	// probe the clusters in parallel so that an unreachable cluster does not hang the list
+	var wg sync.WaitGroup
+	for _, base := range bases {
+		wg.Add(1)
+		go func(base *apis.ClusterBase) {
+			defer wg.Done()
+			setClusterStatus(base, c.probeCluster(ctx, base.Name))
+		}(base)
+	}
+	wg.Wait()
+	resp := &apis.ListClusterResponse{Clusters: []apis.ClusterBase{}}
+	for _, base := range bases {
+		resp.Clusters = append(resp.Clusters, *base)
+	}
+	return resp, nil
+}
+
+// CreateKubeCluster join the cluster with the kubeconfig in the cluster-gateway secret format used by `vela cluster join`
+func (c *clusterUsecaseImpl) CreateKubeCluster(ctx context.Context, req apis.CreateClusterRequest) (*apis.ClusterBase, error) {
+	if req.Name == multicluster.ClusterLocalName {
+		return nil, bcode.ErrLocalClusterReserved
+	}
+	if err := multicluster.EnsureClusterNotExists(ctx, c.kubeClient, req.Name); err != nil {
+		return nil, bcode.ErrClusterExist
+	}
+	kubeConfig, err := c.loadKubeConfig(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	config, err := clientcmd.Load(kubeConfig)
+	if err != nil {
+		log.Logger.Warnf("failed to load kubeconfig of cluster %s: %s", req.Name, err.Error())
+		return nil, bcode.ErrInvalidKubeConfig
+	}
+	if _, err = multicluster.NewClusterSecretFromKubeConfig(config, req.Name); err != nil {
+		log.Logger.Warnf("invalid kubeconfig of cluster %s: %s", req.Name, err.Error())
+		return nil, bcode.ErrInvalidKubeConfig
+	}
+	if _, err = multicluster.JoinClusterByKubeConfig(ctx, c.kubeClient, config, req.Name); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	cluster := &model.Cluster{
+		Name:        req.Name,
+		Description: req.Description,
+		Icon:        req.Icon,
+		Labels:      req.Labels,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := c.ds.Add(ctx, model.ClusterKind, cluster); err != nil {
+		if !errors.Is(err, datastore.ErrRecordExist) {
+			return nil, err
+		}
+		// the record is left by a cluster detached by cli, overwrite it
+		if err := c.ds.Put(ctx, model.ClusterKind, cluster.Name, cluster); err != nil {
+			return nil, err
+		}
+	}
+	base := convertClusterBase(cluster)
+	setClusterStatus(base, c.probeCluster(ctx, cluster.Name))
+	return base, nil
+}
+
+// GetKubeCluster get the cluster detail with the resource info collected from the nodes and storage classes of the cluster
+func (c *clusterUsecaseImpl) GetKubeCluster(ctx context.Context, clusterName string) (*apis.DetailClusterResponse, error) {
+	if clusterName != multicluster.ClusterLocalName {
+		if _, err := multicluster.GetClusterSecret(ctx, c.kubeClient, clusterName); err != nil {
+			if apierrors.IsNotFound(err) || errors.Is(err, multicluster.ErrInvalidClusterSecret) {
+				return nil, bcode.ErrClusterNotExist
+			}
+			return nil, err
+		}
+	}
+	cluster, err := c.getClusterModel(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apis.DetailClusterResponse{ClusterBase: *convertClusterBase(cluster)}
+	info, err := c.getClusterResourceInfo(ctx, clusterName)
+	setClusterStatus(&resp.ClusterBase, err)
+	if info != nil {
+		resp.ResourceInfo = *info
+	}
+	return resp, nil
+}
+
+// DeleteKubeCluster detach the managed cluster if it is not used by any application
+func (c *clusterUsecaseImpl) DeleteKubeCluster(ctx context.Context, clusterName string) (*apis.ClusterBase, error) {
+	if clusterName == multicluster.ClusterLocalName {
+		return nil, bcode.ErrLocalClusterReserved
+	}
+	secret, err := multicluster.GetClusterSecret(ctx, c.kubeClient, clusterName)
+	if err != nil {
+		if apierrors.IsNotFound(err) || errors.Is(err, multicluster.ErrInvalidClusterSecret) {
+			return nil, bcode.ErrClusterNotExist
+		}
+		return nil, err
+	}
+	if err := c.checkClusterNotInUse(ctx, clusterName); err != nil {
+		return nil, err
+	}
+	cluster, err := c.getClusterModel(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.kubeClient.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err := c.ds.Delete(ctx, model.ClusterKind, clusterName); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	return convertClusterBase(cluster), nil
+}
+
+func (c *clusterUsecaseImpl) loadKubeConfig(ctx context.Context, req apis.CreateClusterRequest) ([]byte, error) {
+	if req.KubeConfig != "" {
+		return []byte(req.KubeConfig), nil
+	}
+	secret := &corev1.Secret{}
+	if err := c.kubeClient.Get(ctx, types.NamespacedName{Namespace: multicluster.ClusterGatewaySecretNamespace, Name: req.KubeConfigSecret}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, bcode.ErrInvalidKubeConfig
+		}
+		return nil, err
+	}
+	kubeConfig, ok := secret.Data[kubeConfigSecretKey]
+	if !ok {
+		return nil, bcode.ErrInvalidKubeConfig
+	}
+	return kubeConfig, nil
+}
+
+func (c *clusterUsecaseImpl) checkClusterNotInUse(ctx context.Context, clusterName string) error {
+	ebs := &v1alpha1.EnvBindingList{}
+	if err := c.kubeClient.List(ctx, ebs); err != nil {
+		return err
+	}
+	for _, eb := range ebs.Items {
+		for _, decision := range eb.Status.ClusterDecisions {
+			if decision.Cluster == clusterName {
+				log.Logger.Infof("cluster %s is used by envbinding %s/%s", clusterName, eb.Namespace, eb.Name)
+				return bcode.ErrClusterInUse
+			}
+		}
+	}
+	return nil
+}
+
+// getClusterModel get the metadata of the cluster, clusters joined by cli have no record in datastore
+func (c *clusterUsecaseImpl) getClusterModel(ctx context.Context, clusterName string) (*model.Cluster, error) {
+	cluster := &model.Cluster{}
+	if err := c.ds.Get(ctx, model.ClusterKind, clusterName, cluster); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, err
+		}
+		cluster.Name = clusterName
+	}
+	return cluster, nil
+}
+
+// probeCluster check the connectivity of the cluster through the cluster-gateway within clusterProbeTimeout
+func (c *clusterUsecaseImpl) probeCluster(ctx context.Context, clusterName string) error {
+	probeCtx, cancel := context.WithTimeout(ctx, clusterProbeTimeout)
+	defer cancel()
+	nodes := &corev1.NodeList{}
+	return c.kubeClient.List(multicluster.ContextWithClusterName(probeCtx, clusterName), nodes, client.Limit(1))
+}
+
+func setClusterStatus(base *apis.ClusterBase, err error) {
+	if err != nil {
+		base.Status = apis.ClusterStatusUnhealthy
+		base.Reason = err.Error()
+		return
+	}
+	base.Status = apis.ClusterStatusHealthy
+	base.Reason = ""
+}
+
+func (c *clusterUsecaseImpl) getClusterResourceInfo(ctx context.Context, clusterName string) (*apis.ClusterResourceInfo, error) {
+	remoteCtx := multicluster.ContextWithClusterName(ctx, clusterName)
+	nodes := &corev1.NodeList{}
+	if err := c.kubeClient.List(remoteCtx, nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes of cluster %s: %w", clusterName, err)
+	}
+	info := &apis.ClusterResourceInfo{}
+	for _, node := range nodes.Items {
+		if isMasterNode(node) {
+			info.MasterNumber++
+		} else {
+			info.WorkerNumber++
+		}
+		info.CPUCapacity += node.Status.Capacity.Cpu().Value()
+		info.MemoryCapacity += node.Status.Capacity.Memory().Value()
+		if gpu, ok := node.Status.Capacity[resourceNvidiaGPU]; ok {
+			info.GPUCapacity += gpu.Value()
+		}
+	}
+	storageClasses := &storagev1.StorageClassList{}
+	if err := c.kubeClient.List(remoteCtx, storageClasses); err != nil {
+		return info, fmt.Errorf("failed to list storage classes of cluster %s: %w", clusterName, err)
+	}
+	for _, sc := range storageClasses.Items {
+		info.StorageClassList = append(info.StorageClassList, sc.Name)
+	}
+	return info, nil
+}
+
+func isMasterNode(node corev1.Node) bool {
+	if _, ok := node.Labels[labelNodeRoleMaster]; ok {
+		return true
+	}
+	_, ok := node.Labels[labelNodeRoleControlPlane]
+	return ok
 }
 
-func (c *clusterUsecaseImpl) CreateKubeCluster(context.Context, apis.CreateClusterRequest) (*apis.ClusterBase, error) {
-	return nil, nil
+func convertClusterBase(cluster *model.Cluster) *apis.ClusterBase {
+	return &apis.ClusterBase{
+		Name:        cluster.Name,
+		Description: cluster.Description,
+		Icon:        cluster.Icon,
+		Labels:      cluster.Labels,
+	}
 }
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore/kubeapi"
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+    certificate-authority-data: Y2EK
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+    user: test-user
+  name: test-context
+current-context: test-context
+users:
+- name: test-user
+  user:
+    token: test-token
+`
+
+func newTestNode(name string, master bool, cpu, memory string) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+		Status: corev1.NodeStatus{Capacity: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}},
+	}
+	if master {
+		node.Labels[labelNodeRoleMaster] = ""
+	}
+	return node
+}
+
+func newTestClusterUsecase(t *testing.T, objs ...client.Object) (ClusterUsecase, client.Client, datastore.DataStore) {
+	objs = append(objs, &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "resourcetrackers.core.oam.dev"}})
+	kubeClient := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(objs...).Build()
+	ds, err := kubeapi.New(context.Background(), datastore.Config{Type: "kubeapi", Database: "kubevela"}, kubeClient)
+	require.NoError(t, err)
+	return NewClusterUsecase(ds, kubeClient), kubeClient, ds
+}
+
+func TestClusterUsecase(t *testing.T) {
+	oldNamespace := multicluster.ClusterGatewaySecretNamespace
+	multicluster.ClusterGatewaySecretNamespace = "vela-system"
+	defer func() {
+		multicluster.ClusterGatewaySecretNamespace = oldNamespace
+	}()
+	ctx := context.Background()
+	usecase, kubeClient, _ := newTestClusterUsecase(t,
+		newTestNode("master-1", true, "4", "8Gi"),
+		newTestNode("worker-1", false, "8", "16Gi"),
+		newTestNode("worker-2", false, "8", "16Gi"),
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}},
+	)
+
+	_, err := usecase.CreateKubeCluster(ctx, apis.CreateClusterRequest{Name: multicluster.ClusterLocalName, KubeConfig: testKubeConfig})
+	assert.Equal(t, bcode.ErrLocalClusterReserved, err)
+	_, err = usecase.CreateKubeCluster(ctx, apis.CreateClusterRequest{Name: "bad", KubeConfig: "invalid"})
+	assert.Equal(t, bcode.ErrInvalidKubeConfig, err)
+
+	base, err := usecase.CreateKubeCluster(ctx, apis.CreateClusterRequest{Name: "prod", Description: "production cluster", KubeConfig: testKubeConfig, Labels: map[string]string{"region": "hangzhou"}})
+	require.NoError(t, err)
+	assert.Equal(t, "prod", base.Name)
+	assert.Equal(t, apis.ClusterStatusHealthy, base.Status)
+	secret, err := multicluster.GetClusterSecret(ctx, kubeClient, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "https://127.0.0.1:6443", string(secret.Data["endpoint"]))
+	assert.Equal(t, "test-token", string(secret.Data["token"]))
+
+	_, err = usecase.CreateKubeCluster(ctx, apis.CreateClusterRequest{Name: "prod", KubeConfig: testKubeConfig})
+	assert.Equal(t, bcode.ErrClusterExist, err)
+
+	list, err := usecase.ListKubeClusters(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(list.Clusters))
+	list, err = usecase.ListKubeClusters(ctx, "production")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(list.Clusters))
+	assert.Equal(t, "hangzhou", list.Clusters[0].Labels["region"])
+
+	detail, err := usecase.GetKubeCluster(ctx, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, apis.ClusterResourceInfo{
+		WorkerNumber:     2,
+		MasterNumber:     1,
+		CPUCapacity:      20,
+		MemoryCapacity:   40 * 1024 * 1024 * 1024,
+		StorageClassList: []string{"standard"},
+	}, detail.ResourceInfo)
+	_, err = usecase.GetKubeCluster(ctx, "not-exist")
+	assert.Equal(t, bcode.ErrClusterNotExist, err)
+	// a secret without the credential type label is not a cluster
+	require.NoError(t, kubeClient.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "vela-system"}}))
+	_, err = usecase.GetKubeCluster(ctx, "plain")
+	assert.Equal(t, bcode.ErrClusterNotExist, err)
+
+	eb := &v1alpha1.EnvBinding{ObjectMeta: metav1.ObjectMeta{Name: "eb", Namespace: "default"}}
+	require.NoError(t, kubeClient.Create(ctx, eb))
+	eb.Status.ClusterDecisions = []v1alpha1.ClusterDecision{{Env: "prod", Cluster: "prod"}}
+	require.NoError(t, kubeClient.Status().Update(ctx, eb))
+	_, err = usecase.DeleteKubeCluster(ctx, "prod")
+	assert.Equal(t, bcode.ErrClusterInUse, err)
+	require.NoError(t, kubeClient.Delete(ctx, eb))
+
+	_, err = usecase.DeleteKubeCluster(ctx, "prod")
+	require.NoError(t, err)
+	_, err = usecase.DeleteKubeCluster(ctx, "prod")
+	assert.Equal(t, bcode.ErrClusterNotExist, err)
+	_, err = usecase.DeleteKubeCluster(ctx, multicluster.ClusterLocalName)
+	assert.Equal(t, bcode.ErrLocalClusterReserved, err)
+}
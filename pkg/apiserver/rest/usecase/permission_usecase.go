@@ -0,0 +1,171 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+// PermissionUsecase permission manage
+type PermissionUsecase interface {
+	auth.PermissionChecker
+	ListPermissions(context.Context) (*apis.ListPermissionResponse, error)
+	CreatePermission(context.Context, apis.CreatePermissionRequest) (*apis.PermissionBase, error)
+	DeletePermission(context.Context, string) (*apis.PermissionBase, error)
+}
+
+type permissionUsecaseImpl struct {
+	ds datastore.DataStore
+}
+
+// NewPermissionUsecase new permission usecase
+func NewPermissionUsecase(ds datastore.DataStore) PermissionUsecase {
+	return &permissionUsecaseImpl{ds: ds}
+}
+
+// ListPermissions list all the permissions
+func (p *permissionUsecaseImpl) ListPermissions(ctx context.Context) (*apis.ListPermissionResponse, error) {
+	permissions, err := p.listPermissionModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apis.ListPermissionResponse{Permissions: []apis.PermissionBase{}}
+	for _, permission := range permissions {
+		resp.Permissions = append(resp.Permissions, *convertPermissionBase(permission))
+	}
+	return resp, nil
+}
+
+// CreatePermission grant the verb on the scope to the subject, the verb granted before is replaced
+func (p *permissionUsecaseImpl) CreatePermission(ctx context.Context, req apis.CreatePermissionRequest) (*apis.PermissionBase, error) {
+	permission := &model.Permission{
+		User:      req.User,
+		Group:     req.Group,
+		Scope:     req.Scope,
+		ScopeName: req.ScopeName,
+		Verb:      req.Verb,
+		CreatedAt: time.Now().Unix(),
+	}
+	permission.Name = permissionName(permission)
+	if err := p.ds.Add(ctx, model.PermissionKind, permission); err != nil {
+		if !errors.Is(err, datastore.ErrRecordExist) {
+			return nil, err
+		}
+		if err := p.ds.Put(ctx, model.PermissionKind, permission.Name, permission); err != nil {
+			return nil, err
+		}
+	}
+	return convertPermissionBase(permission), nil
+}
+
+// DeletePermission revoke the permission
+func (p *permissionUsecaseImpl) DeletePermission(ctx context.Context, name string) (*apis.PermissionBase, error) {
+	permission := &model.Permission{}
+	if err := p.ds.Get(ctx, model.PermissionKind, name, permission); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrPermissionNotExist
+		}
+		return nil, err
+	}
+	if err := p.ds.Delete(ctx, model.PermissionKind, name); err != nil {
+		return nil, err
+	}
+	return convertPermissionBase(permission), nil
+}
+
+// CheckPermission whether the user or the groups of the user is granted the verb on the scope,
+// the members of a project are granted the verb of the membership in the project
+func (p *permissionUsecaseImpl) CheckPermission(ctx context.Context, user *auth.UserInfo, scope auth.Scope, scopeName string, verb auth.Verb) (bool, error) {
+	if scope == auth.ScopeProject && scopeName != auth.AllScopeNames {
+		project, err := getProjectModel(ctx, p.ds, scopeName)
+		if err != nil && !errors.Is(err, bcode.ErrProjectNotExist) {
+			return false, err
+		}
+		if project != nil {
+			for _, member := range project.Members {
+				if member.User == user.Name && auth.Verb(member.Verb).Allows(verb) {
+					return true, nil
+				}
+			}
+		}
+	}
+	permissions, err := p.listPermissionModels(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, permission := range permissions {
+		if permission.Scope != string(scope) {
+			continue
+		}
+		if permission.ScopeName != auth.AllScopeNames && permission.ScopeName != scopeName {
+			continue
+		}
+		if !auth.Verb(permission.Verb).Allows(verb) {
+			continue
+		}
+		if (permission.User != "" && permission.User == user.Name) || (permission.Group != "" && user.InGroups([]string{permission.Group})) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *permissionUsecaseImpl) listPermissionModels(ctx context.Context) ([]*model.Permission, error) {
+	it, err := p.ds.Find(ctx, model.PermissionKind)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer it.Close(ctx)
+	var permissions []*model.Permission
+	for it.Next(ctx) {
+		permission := &model.Permission{}
+		if err := it.Decode(permission); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, nil
+}
+
+func permissionName(permission *model.Permission) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s/%s", permission.User, permission.Group, permission.Scope, permission.ScopeName)))
+	return hex.EncodeToString(hash[:])[:16]
+}
+
+func convertPermissionBase(permission *model.Permission) *apis.PermissionBase {
+	return &apis.PermissionBase{
+		Name:       permission.Name,
+		User:       permission.User,
+		Group:      permission.Group,
+		Scope:      permission.Scope,
+		ScopeName:  permission.ScopeName,
+		Verb:       permission.Verb,
+		CreateTime: time.Unix(permission.CreatedAt, 0),
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+func TestPermissionUsecase(t *testing.T) {
+	ctx := context.Background()
+	_, _, ds := newTestClusterUsecase(t)
+	usecase := NewPermissionUsecase(ds)
+
+	alice := &auth.UserInfo{Name: "alice", Groups: []string{"dev"}}
+	allowed, err := usecase.CheckPermission(ctx, alice, auth.ScopeNamespace, "default", auth.VerbRead)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	_, err = usecase.CreatePermission(ctx, apis.CreatePermissionRequest{User: "alice", Scope: "namespace", ScopeName: "default", Verb: "read"})
+	require.NoError(t, err)
+	// grant again replaces the verb
+	permission, err := usecase.CreatePermission(ctx, apis.CreatePermissionRequest{User: "alice", Scope: "namespace", ScopeName: "default", Verb: "write"})
+	require.NoError(t, err)
+	_, err = usecase.CreatePermission(ctx, apis.CreatePermissionRequest{Group: "dev", Scope: "cluster", ScopeName: "*", Verb: "read"})
+	require.NoError(t, err)
+
+	list, err := usecase.ListPermissions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(list.Permissions))
+
+	allowed, err = usecase.CheckPermission(ctx, alice, auth.ScopeNamespace, "default", auth.VerbWrite)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = usecase.CheckPermission(ctx, alice, auth.ScopeNamespace, "default", auth.VerbAdmin)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	allowed, err = usecase.CheckPermission(ctx, alice, auth.ScopeNamespace, "prod", auth.VerbRead)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	allowed, err = usecase.CheckPermission(ctx, alice, auth.ScopeCluster, "any", auth.VerbRead)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	_, err = usecase.DeletePermission(ctx, permission.Name)
+	require.NoError(t, err)
+	_, err = usecase.DeletePermission(ctx, permission.Name)
+	assert.Equal(t, bcode.ErrPermissionNotExist, err)
+	allowed, err = usecase.CheckPermission(ctx, alice, auth.ScopeNamespace, "default", auth.VerbRead)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
@@ -0,0 +1,443 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+)
+
+// ProjectUsecase project and environment manage
+type ProjectUsecase interface {
+	ListProjects(context.Context) (*apis.ListProjectResponse, error)
+	CreateProject(context.Context, apis.CreateProjectRequest) (*apis.ProjectBase, error)
+	GetProject(context.Context, string) (*apis.ProjectBase, error)
+	UpdateProject(context.Context, string, apis.UpdateProjectRequest) (*apis.ProjectBase, error)
+	DeleteProject(context.Context, string) (*apis.ProjectBase, error)
+	ListEnvironments(context.Context, string) (*apis.ListEnvironmentResponse, error)
+	CreateEnvironment(context.Context, string, apis.CreateEnvironmentRequest) (*apis.EnvironmentBase, error)
+	DeleteEnvironment(context.Context, string, string) (*apis.EnvironmentBase, error)
+}
+
+// SystemNamespaces the namespaces can not be bound to the environments besides the kube-* namespaces and the
+// namespace of the cluster secrets, the api server adds the namespace of the kubeapi datastore
+var SystemNamespaces = []string{types.DefaultKubeVelaNS}
+
+type projectUsecaseImpl struct {
+	ds         datastore.DataStore
+	kubeClient client.Client
+}
+
+// NewProjectUsecase new project usecase
+func NewProjectUsecase(ds datastore.DataStore, kubeClient client.Client) ProjectUsecase {
+	return &projectUsecaseImpl{ds: ds, kubeClient: kubeClient}
+}
+
+// ListProjects list all the projects
+func (p *projectUsecaseImpl) ListProjects(ctx context.Context) (*apis.ListProjectResponse, error) {
+	projects, err := listProjectModels(ctx, p.ds)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := listApplicationModels(ctx, p.ds)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apis.ListProjectResponse{Projects: []apis.ProjectBase{}}
+	for _, project := range projects {
+		resp.Projects = append(resp.Projects, *convertProjectBase(project, filterApplications(apps, project.Name)))
+	}
+	return resp, nil
+}
+
+// CreateProject create the project, the name of the project is unique
+func (p *projectUsecaseImpl) CreateProject(ctx context.Context, req apis.CreateProjectRequest) (*apis.ProjectBase, error) {
+	now := time.Now().Unix()
+	project := &model.Project{
+		Name:        req.Name,
+		Description: req.Description,
+		Members:     convertProjectMembers(req.Members),
+		Quota:       model.ProjectQuota{MaxApplications: req.Quota.MaxApplications, MaxClusters: req.Quota.MaxClusters},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := p.ds.Add(ctx, model.ProjectKind, project); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrProjectExist
+		}
+		return nil, err
+	}
+	return convertProjectBase(project, nil), nil
+}
+
+// GetProject get the project with the count of its applications
+func (p *projectUsecaseImpl) GetProject(ctx context.Context, name string) (*apis.ProjectBase, error) {
+	project, err := getProjectModel(ctx, p.ds, name)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := listApplicationModels(ctx, p.ds)
+	if err != nil {
+		return nil, err
+	}
+	return convertProjectBase(project, filterApplications(apps, name)), nil
+}
+
+// UpdateProject replace the description, members and quota of the project. The quota lower than
+// the current usage is accepted, the new applications are rejected until the usage is reduced.
+func (p *projectUsecaseImpl) UpdateProject(ctx context.Context, name string, req apis.UpdateProjectRequest) (*apis.ProjectBase, error) {
+	project, err := getProjectModel(ctx, p.ds, name)
+	if err != nil {
+		return nil, err
+	}
+	project.Description = req.Description
+	project.Members = convertProjectMembers(req.Members)
+	project.Quota = model.ProjectQuota{MaxApplications: req.Quota.MaxApplications, MaxClusters: req.Quota.MaxClusters}
+	project.UpdatedAt = time.Now().Unix()
+	if err := p.ds.Put(ctx, model.ProjectKind, name, project); err != nil {
+		return nil, err
+	}
+	return p.GetProject(ctx, name)
+}
+
+// DeleteProject delete the project if it has no application and environment
+func (p *projectUsecaseImpl) DeleteProject(ctx context.Context, name string) (*apis.ProjectBase, error) {
+	project, err := getProjectModel(ctx, p.ds, name)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := listApplicationModels(ctx, p.ds)
+	if err != nil {
+		return nil, err
+	}
+	envs, err := listEnvironmentModels(ctx, p.ds, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(filterApplications(apps, name)) != 0 || len(envs) != 0 {
+		return nil, bcode.ErrProjectNotEmpty
+	}
+	if err := p.ds.Delete(ctx, model.ProjectKind, name); err != nil {
+		return nil, err
+	}
+	return convertProjectBase(project, nil), nil
+}
+
+// ListEnvironments list the environments of the project
+func (p *projectUsecaseImpl) ListEnvironments(ctx context.Context, projectName string) (*apis.ListEnvironmentResponse, error) {
+	if _, err := getProjectModel(ctx, p.ds, projectName); err != nil {
+		return nil, err
+	}
+	envs, err := listEnvironmentModels(ctx, p.ds, projectName)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apis.ListEnvironmentResponse{Environments: []apis.EnvironmentBase{}}
+	for _, env := range envs {
+		resp.Environments = append(resp.Environments, *convertEnvironmentBase(env))
+	}
+	return resp, nil
+}
+
+// CreateEnvironment create the environment in the project. The namespace can only be bound to one project and
+// can not be a system namespace, the clusters must exist and the distinct clusters of all the environments in the
+// project are limited by the cluster quota.
+func (p *projectUsecaseImpl) CreateEnvironment(ctx context.Context, projectName string, req apis.CreateEnvironmentRequest) (*apis.EnvironmentBase, error) {
+	project, err := getProjectModel(ctx, p.ds, projectName)
+	if err != nil {
+		return nil, err
+	}
+	env := &model.Environment{
+		Name:        req.Name,
+		Project:     projectName,
+		Description: req.Description,
+		Namespace:   req.Namespace,
+		Clusters:    req.Clusters,
+		CreatedAt:   time.Now().Unix(),
+	}
+	if len(env.Clusters) == 0 {
+		env.Clusters = []string{multicluster.ClusterLocalName}
+	}
+	if isSystemNamespace(env.Namespace) {
+		return nil, bcode.ErrSystemNamespace
+	}
+	for _, cluster := range env.Clusters {
+		if err := p.checkClusterExist(ctx, cluster); err != nil {
+			return nil, err
+		}
+	}
+	allEnvs, err := listEnvironmentModels(ctx, p.ds, "")
+	if err != nil {
+		return nil, err
+	}
+	clusters := map[string]struct{}{}
+	for _, cluster := range env.Clusters {
+		clusters[cluster] = struct{}{}
+	}
+	for _, other := range allEnvs {
+		if other.Namespace == env.Namespace && other.Project != projectName {
+			return nil, bcode.ErrNamespaceBoundToOtherProject
+		}
+		if other.Project == projectName {
+			for _, cluster := range other.Clusters {
+				clusters[cluster] = struct{}{}
+			}
+		}
+	}
+	if project.Quota.MaxClusters > 0 && len(clusters) > project.Quota.MaxClusters {
+		return nil, bcode.ErrClusterQuotaExceeded
+	}
+	if err := p.ds.Add(ctx, model.EnvironmentKind, env); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrEnvironmentExist
+		}
+		return nil, err
+	}
+	return convertEnvironmentBase(env), nil
+}
+
+// DeleteEnvironment delete the environment if no application of the project is in its namespace
+func (p *projectUsecaseImpl) DeleteEnvironment(ctx context.Context, projectName, envName string) (*apis.EnvironmentBase, error) {
+	env, err := getEnvironmentModel(ctx, p.ds, projectName, envName)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := listApplicationModels(ctx, p.ds)
+	if err != nil {
+		return nil, err
+	}
+	for _, app := range filterApplications(apps, projectName) {
+		if app.Namespace == env.Namespace {
+			log.Logger.Infof("environment %s is used by application %s", envName, app.Name)
+			return nil, bcode.ErrEnvironmentInUse
+		}
+	}
+	if err := p.ds.Delete(ctx, model.EnvironmentKind, envName); err != nil {
+		return nil, err
+	}
+	return convertEnvironmentBase(env), nil
+}
+
+func (p *projectUsecaseImpl) checkClusterExist(ctx context.Context, cluster string) error {
+	if cluster == multicluster.ClusterLocalName {
+		return nil
+	}
+	if _, err := multicluster.GetClusterSecret(ctx, p.kubeClient, cluster); err != nil {
+		if apierrors.IsNotFound(err) || errors.Is(err, multicluster.ErrInvalidClusterSecret) {
+			log.Logger.Infof("cluster %s of the environment does not exist", cluster)
+			return bcode.ErrClusterNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+func isSystemNamespace(namespace string) bool {
+	return strings.HasPrefix(namespace, "kube-") || namespace == multicluster.ClusterGatewaySecretNamespace ||
+		stringInSlice(namespace, SystemNamespaces)
+}
+
+// checkApplicationInProject check the application against the environments and the quota of its project.
+// The namespace of the application must belong to an environment of the project, and the clusters must be
+// the targets of the environment. If the cluster list is empty, all the clusters of the environment are used.
+func checkApplicationInProject(ctx context.Context, ds datastore.DataStore, app *model.Application, creating bool) error {
+	project, err := getProjectModel(ctx, ds, app.Project)
+	if err != nil {
+		return err
+	}
+	envs, err := listEnvironmentModels(ctx, ds, app.Project)
+	if err != nil {
+		return err
+	}
+	var env *model.Environment
+	for _, e := range envs {
+		if e.Namespace == app.Namespace {
+			env = e
+			break
+		}
+	}
+	if env == nil {
+		return bcode.ErrNamespaceNotInProject
+	}
+	if len(app.ClusterList) == 0 {
+		app.ClusterList = env.Clusters
+	}
+	for _, cluster := range app.ClusterList {
+		if !stringInSlice(cluster, env.Clusters) {
+			log.Logger.Infof("cluster %s is not the target of environment %s", cluster, env.Name)
+			return bcode.ErrClusterNotInEnvironment
+		}
+	}
+
+	apps, err := listApplicationModels(ctx, ds)
+	if err != nil {
+		return err
+	}
+	projectApps := filterApplications(apps, app.Project)
+	if creating && project.Quota.MaxApplications > 0 && len(projectApps) >= project.Quota.MaxApplications {
+		return bcode.ErrApplicationQuotaExceeded
+	}
+	if project.Quota.MaxClusters > 0 {
+		clusters := map[string]struct{}{}
+		for _, cluster := range app.ClusterList {
+			clusters[cluster] = struct{}{}
+		}
+		for _, other := range projectApps {
+			if other.ID == app.ID {
+				continue
+			}
+			for _, cluster := range other.ClusterList {
+				clusters[cluster] = struct{}{}
+			}
+		}
+		if len(clusters) > project.Quota.MaxClusters {
+			return bcode.ErrClusterQuotaExceeded
+		}
+	}
+	return nil
+}
+
+func getProjectModel(ctx context.Context, ds datastore.DataStore, name string) (*model.Project, error) {
+	project := &model.Project{}
+	if err := ds.Get(ctx, model.ProjectKind, name, project); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrProjectNotExist
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+func getEnvironmentModel(ctx context.Context, ds datastore.DataStore, projectName, name string) (*model.Environment, error) {
+	env := &model.Environment{}
+	if err := ds.Get(ctx, model.EnvironmentKind, name, env); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrEnvironmentNotExist
+		}
+		return nil, err
+	}
+	if env.Project != projectName {
+		return nil, bcode.ErrEnvironmentNotExist
+	}
+	return env, nil
+}
+
+func listProjectModels(ctx context.Context, ds datastore.DataStore) ([]*model.Project, error) {
+	it, err := ds.Find(ctx, model.ProjectKind)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer it.Close(ctx)
+	var projects []*model.Project
+	for it.Next(ctx) {
+		project := &model.Project{}
+		if err := it.Decode(project); err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+// listEnvironmentModels list the environments of the project, all the environments are returned if the project is empty
+func listEnvironmentModels(ctx context.Context, ds datastore.DataStore, projectName string) ([]*model.Environment, error) {
+	it, err := ds.Find(ctx, model.EnvironmentKind)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer it.Close(ctx)
+	var envs []*model.Environment
+	for it.Next(ctx) {
+		env := &model.Environment{}
+		if err := it.Decode(env); err != nil {
+			return nil, err
+		}
+		if projectName == "" || env.Project == projectName {
+			envs = append(envs, env)
+		}
+	}
+	return envs, nil
+}
+
+func filterApplications(apps []*model.Application, projectName string) []*model.Application {
+	var filtered []*model.Application
+	for _, app := range apps {
+		if app.Project == projectName {
+			filtered = append(filtered, app)
+		}
+	}
+	return filtered
+}
+
+func stringInSlice(s string, list []string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func convertProjectMembers(members []apis.ProjectMember) []model.ProjectMember {
+	var converted []model.ProjectMember
+	for _, member := range members {
+		converted = append(converted, model.ProjectMember{User: member.User, Verb: member.Verb})
+	}
+	return converted
+}
+
+func convertProjectBase(project *model.Project, apps []*model.Application) *apis.ProjectBase {
+	base := &apis.ProjectBase{
+		Name:             project.Name,
+		Description:      project.Description,
+		Members:          []apis.ProjectMember{},
+		Quota:            apis.ProjectQuota{MaxApplications: project.Quota.MaxApplications, MaxClusters: project.Quota.MaxClusters},
+		ApplicationCount: len(apps),
+		CreateTime:       time.Unix(project.CreatedAt, 0),
+		UpdateTime:       time.Unix(project.UpdatedAt, 0),
+	}
+	for _, member := range project.Members {
+		base.Members = append(base.Members, apis.ProjectMember{User: member.User, Verb: member.Verb})
+	}
+	return base
+}
+
+func convertEnvironmentBase(env *model.Environment) *apis.EnvironmentBase {
+	return &apis.EnvironmentBase{
+		Name:        env.Name,
+		Project:     env.Project,
+		Description: env.Description,
+		Namespace:   env.Namespace,
+		Clusters:    env.Clusters,
+		CreateTime:  time.Unix(env.CreatedAt, 0),
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+)
+
+// newTestClusterSecret the cluster-gateway credential secret of the managed cluster
+func newTestClusterSecret(name string) *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: multicluster.ClusterGatewaySecretNamespace,
+		Labels:    map[string]string{v1alpha1.LabelKeyClusterCredentialType: string(v1alpha1.CredentialTypeX509Certificate)},
+	}}
+}
+
+func TestProjectUsecase(t *testing.T) {
+	oldNamespace := multicluster.ClusterGatewaySecretNamespace
+	multicluster.ClusterGatewaySecretNamespace = "vela-system"
+	defer func() {
+		multicluster.ClusterGatewaySecretNamespace = oldNamespace
+	}()
+	ctx := context.Background()
+	_, kubeClient, ds := newTestClusterUsecase(t, newTestClusterSecret("prod-1"), newTestClusterSecret("prod-2"))
+	usecase := NewProjectUsecase(ds, kubeClient)
+
+	_, err := usecase.CreateProject(ctx, apis.CreateProjectRequest{
+		Name:    "team-a",
+		Members: []apis.ProjectMember{{User: "alice", Verb: "write"}},
+		Quota:   apis.ProjectQuota{MaxClusters: 2},
+	})
+	require.NoError(t, err)
+	_, err = usecase.CreateProject(ctx, apis.CreateProjectRequest{Name: "team-a"})
+	assert.Equal(t, bcode.ErrProjectExist, err)
+	_, err = usecase.CreateProject(ctx, apis.CreateProjectRequest{Name: "team-b"})
+	require.NoError(t, err)
+
+	env, err := usecase.CreateEnvironment(ctx, "team-a", apis.CreateEnvironmentRequest{Name: "a-dev", Namespace: "a-dev"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{multicluster.ClusterLocalName}, env.Clusters)
+	_, err = usecase.CreateEnvironment(ctx, "team-a", apis.CreateEnvironmentRequest{Name: "a-dev", Namespace: "a-test"})
+	assert.Equal(t, bcode.ErrEnvironmentExist, err)
+	_, err = usecase.CreateEnvironment(ctx, "team-b", apis.CreateEnvironmentRequest{Name: "b-dev", Namespace: "a-dev"})
+	assert.Equal(t, bcode.ErrNamespaceBoundToOtherProject, err)
+	_, err = usecase.CreateEnvironment(ctx, "team-a", apis.CreateEnvironmentRequest{Name: "a-prod", Namespace: "a-prod", Clusters: []string{"prod-1", "prod-2"}})
+	assert.Equal(t, bcode.ErrClusterQuotaExceeded, err)
+	_, err = usecase.CreateEnvironment(ctx, "team-a", apis.CreateEnvironmentRequest{Name: "a-prod", Namespace: "a-prod", Clusters: []string{"prod-3"}})
+	assert.Equal(t, bcode.ErrClusterNotExist, err)
+	for _, namespace := range []string{"kube-system", "kube-public", "vela-system"} {
+		_, err = usecase.CreateEnvironment(ctx, "team-a", apis.CreateEnvironmentRequest{Name: "a-system", Namespace: namespace})
+		assert.Equal(t, bcode.ErrSystemNamespace, err, namespace)
+	}
+	_, err = usecase.CreateEnvironment(ctx, "team-a", apis.CreateEnvironmentRequest{Name: "a-prod", Namespace: "a-prod", Clusters: []string{"prod-1"}})
+	require.NoError(t, err)
+	_, err = usecase.CreateEnvironment(ctx, "missing", apis.CreateEnvironmentRequest{Name: "x", Namespace: "x"})
+	assert.Equal(t, bcode.ErrProjectNotExist, err)
+
+	envs, err := usecase.ListEnvironments(ctx, "team-a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(envs.Environments))
+	_, err = usecase.DeleteEnvironment(ctx, "team-b", "a-dev")
+	assert.Equal(t, bcode.ErrEnvironmentNotExist, err)
+
+	project, err := usecase.UpdateProject(ctx, "team-a", apis.UpdateProjectRequest{Description: "team a", Quota: apis.ProjectQuota{MaxApplications: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, "team a", project.Description)
+	assert.Empty(t, project.Members)
+
+	_, err = usecase.DeleteProject(ctx, "team-a")
+	assert.Equal(t, bcode.ErrProjectNotEmpty, err)
+	_, err = usecase.DeleteEnvironment(ctx, "team-a", "a-dev")
+	require.NoError(t, err)
+	_, err = usecase.DeleteEnvironment(ctx, "team-a", "a-prod")
+	require.NoError(t, err)
+	_, err = usecase.DeleteProject(ctx, "team-a")
+	require.NoError(t, err)
+
+	list, err := usecase.ListProjects(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(list.Projects))
+	assert.Equal(t, "team-b", list.Projects[0].Name)
+}
+
+func TestCheckProjectMemberPermission(t *testing.T) {
+	ctx := context.Background()
+	_, kubeClient, ds := newTestClusterUsecase(t)
+	_, err := NewProjectUsecase(ds, kubeClient).CreateProject(ctx, apis.CreateProjectRequest{
+		Name:    "team-a",
+		Members: []apis.ProjectMember{{User: "alice", Verb: "write"}},
+	})
+	require.NoError(t, err)
+	checker := NewPermissionUsecase(ds)
+	alice := &auth.UserInfo{Name: "alice"}
+
+	allowed, err := checker.CheckPermission(ctx, alice, auth.ScopeProject, "team-a", auth.VerbWrite)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = checker.CheckPermission(ctx, alice, auth.ScopeProject, "team-a", auth.VerbAdmin)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	allowed, err = checker.CheckPermission(ctx, alice, auth.ScopeProject, "team-b", auth.VerbRead)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	allowed, err = checker.CheckPermission(ctx, alice, auth.ScopeProject, auth.AllScopeNames, auth.VerbRead)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bcode
+
+// ErrApplicationNotExist application does not exist
+var ErrApplicationNotExist = NewBcode(404, 13001, "application does not exist")
+
+// ErrApplicationWatcherNotReady the application informer is not synced yet
+var ErrApplicationWatcherNotReady = NewBcode(503, 13002, "application watcher is not ready, please retry later")
+
+// ErrApplicationExist the application name is used
+var ErrApplicationExist = NewBcode(400, 13003, "application already exists")
+
+// ErrInvalidApplicationConfig the yaml config of the application can not be parsed
+var ErrInvalidApplicationConfig = NewBcode(400, 13004, "the yaml config of the application is invalid")
+
+// ErrApplicationRevisionNotExist the revision of the application does not exist
+var ErrApplicationRevisionNotExist = NewBcode(404, 13005, "application revision does not exist")
+
+// ErrApplicationAmbiguous the application name is used in multiple namespaces and the namespace is not specified
+var ErrApplicationAmbiguous = NewBcode(400, 13006, "the application name is used in multiple namespaces, please specify the namespace")
+
+// ErrApplicationPlacementUnresolvable the placement of the application can not be resolved to the clusters and
+// namespaces of its environment, e.g. a label selector or an unknown policy or workflow step type is used
+var ErrApplicationPlacementUnresolvable = NewBcode(400, 13007, "the placement of the application can not be resolved")
+
+// ErrApplicationNotManaged the application in the namespace is not managed by any project
+var ErrApplicationNotManaged = NewBcode(400, 13008, "the application already exists in the namespace and is not managed by any project")
+
+// ErrApplicationOwnedByOtherProject the application in the namespace is managed by another project
+var ErrApplicationOwnedByOtherProject = NewBcode(400, 13009, "the application already exists in the namespace and belongs to another project")
+
+// ErrApplicationResourceVersionExpired the resourceVersion to resume the watch of the application from is compacted
+var ErrApplicationResourceVersionExpired = NewBcode(410, 13010, "the resourceVersion is too old to resume from, please watch again without it")
@@ -37,31 +37,46 @@ func (b *Bcode) Error() string {
 	return fmt.Sprintf("HTTPCode:%d BusinessCode:%d Message:%s", b.HTTPCode, b.BusinessCode, b.Message)
 }
 
+var bcodeMap map[int32]*Bcode
+
+// NewBcode new business code
+func NewBcode(httpCode, businessCode int32, message string) *Bcode {
+	if bcodeMap == nil {
+		bcodeMap = make(map[int32]*Bcode)
+	}
+	if _, exit := bcodeMap[businessCode]; exit {
+		panic("bcode business code is exist")
+	}
+	bcode := &Bcode{HTTPCode: httpCode, BusinessCode: businessCode, Message: message}
+	bcodeMap[businessCode] = bcode
+	return bcode
+}
+
 // ReturnError Unified handling of all types of errors, generating a standard return structure.
 func ReturnError(req *restful.Request, res *restful.Response, err error) {
 	var bcode *Bcode
 	if errors.As(err, &bcode) {
-		if err := res.WriteEntity(err); err != nil {
+		if err := res.WriteHeaderAndEntity(int(bcode.HTTPCode), bcode); err != nil {
 			log.Logger.Error("write entity failure %s", err.Error())
 		}
 		return
 	}
-	var restfulerr *restful.ServiceError
-	if errors.As(err, restfulerr) {
-		if err := res.WriteEntity(Bcode{HTTPCode: int32(restfulerr.Code), BusinessCode: int32(restfulerr.Code), Message: restfulerr.Message}); err != nil {
+	var restfulerr restful.ServiceError
+	if errors.As(err, &restfulerr) {
+		if err := res.WriteHeaderAndEntity(restfulerr.Code, Bcode{HTTPCode: int32(restfulerr.Code), BusinessCode: int32(restfulerr.Code), Message: restfulerr.Message}); err != nil {
 			log.Logger.Error("write entity failure %s", err.Error())
 		}
 		return
 	}
-	var validErr *validator.ValidationErrors
-	if errors.As(err, validErr) {
-		if err := res.WriteEntity(Bcode{HTTPCode: 400, BusinessCode: 400, Message: err.Error()}); err != nil {
+	var validErr validator.ValidationErrors
+	if errors.As(err, &validErr) {
+		if err := res.WriteHeaderAndEntity(400, Bcode{HTTPCode: 400, BusinessCode: 400, Message: err.Error()}); err != nil {
 			log.Logger.Error("write entity failure %s", err.Error())
 		}
 		return
 	}
 	log.Logger.Errorf("Business exceptions, message %s, path:%s method:%s", err.Error(), req.Request.URL, req.Request.Method)
-	if err := res.WriteEntity(Bcode{HTTPCode: 500, BusinessCode: 500, Message: err.Error()}); err != nil {
+	if err := res.WriteHeaderAndEntity(500, Bcode{HTTPCode: 500, BusinessCode: 500, Message: err.Error()}); err != nil {
 		log.Logger.Error("write entity failure %s", err.Error())
 	}
 }
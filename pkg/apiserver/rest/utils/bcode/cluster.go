@@ -0,0 +1,32 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bcode
+
+// ErrClusterExist cluster already exists
+var ErrClusterExist = NewBcode(400, 10001, "cluster already exists")
+
+// ErrClusterNotExist cluster does not exist
+var ErrClusterNotExist = NewBcode(404, 10002, "cluster does not exist")
+
+// ErrInvalidKubeConfig the kubeconfig of the cluster is invalid
+var ErrInvalidKubeConfig = NewBcode(400, 10003, "the kubeconfig of the cluster is invalid")
+
+// ErrClusterInUse the cluster is used by applications and can not be deleted
+var ErrClusterInUse = NewBcode(400, 10004, "the cluster is used by applications")
+
+// ErrLocalClusterReserved the local cluster is reserved and can not be changed
+var ErrLocalClusterReserved = NewBcode(400, 10005, "the local cluster is reserved")
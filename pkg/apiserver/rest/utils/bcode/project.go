@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bcode
+
+// ErrProjectExist the project name is used
+var ErrProjectExist = NewBcode(400, 14001, "project already exists")
+
+// ErrProjectNotExist the project does not exist
+var ErrProjectNotExist = NewBcode(404, 14002, "project does not exist")
+
+// ErrProjectNotEmpty the project can not be deleted until all its applications and environments are deleted
+var ErrProjectNotEmpty = NewBcode(400, 14003, "the project still has applications or environments")
+
+// ErrEnvironmentExist the environment name is used
+var ErrEnvironmentExist = NewBcode(400, 14004, "environment already exists")
+
+// ErrEnvironmentNotExist the environment does not exist in the project
+var ErrEnvironmentNotExist = NewBcode(404, 14005, "environment does not exist")
+
+// ErrEnvironmentInUse the environment is used by applications and can not be deleted
+var ErrEnvironmentInUse = NewBcode(400, 14006, "the environment is used by applications")
+
+// ErrNamespaceBoundToOtherProject the namespace is used by the environment of another project
+var ErrNamespaceBoundToOtherProject = NewBcode(400, 14007, "the namespace belongs to another project")
+
+// ErrNamespaceNotInProject the namespace does not belong to any environment of the project
+var ErrNamespaceNotInProject = NewBcode(400, 14008, "the namespace does not belong to any environment of the project")
+
+// ErrClusterNotInEnvironment the cluster is not the target of the environment
+var ErrClusterNotInEnvironment = NewBcode(400, 14009, "the cluster is not the target of the environment")
+
+// ErrApplicationQuotaExceeded the project reaches the max number of applications
+var ErrApplicationQuotaExceeded = NewBcode(403, 14010, "the application quota of the project is exceeded")
+
+// ErrClusterQuotaExceeded the project reaches the max number of clusters
+var ErrClusterQuotaExceeded = NewBcode(403, 14011, "the cluster quota of the project is exceeded")
+
+// ErrSystemNamespace the system namespaces can not be bound to an environment
+var ErrSystemNamespace = NewBcode(400, 14012, "the system namespace can not be bound to an environment")
@@ -21,9 +21,11 @@ import (
 	"github.com/emicklei/go-restful/v3"
 
 	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
 )
 
 type addonWebService struct {
+	authorizer *auth.Authorizer
 }
 
 func (c *addonWebService) GetWebService() *restful.WebService {
@@ -39,6 +41,7 @@ func (c *addonWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.GET("/").To(noop).
 		Doc("list all addons").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "cluster", auth.VerbRead)).
 		Param(ws.QueryParameter("cluster", "Cluster-based search").DataType("string")).
 		Writes(apis.ListAddonResponse{}).Do(returns200, returns500))
 
@@ -46,6 +49,7 @@ func (c *addonWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.POST("/").To(noop).
 		Doc("create an addon").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "", auth.VerbAdmin)).
 		Reads(apis.CreateAddonRequest{}).
 		Writes(apis.AddonMeta{}))
 
@@ -53,6 +57,7 @@ func (c *addonWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.DELETE("/{name}").To(noop).
 		Doc("delete an addon").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "", auth.VerbAdmin)).
 		Param(ws.PathParameter("name", "identifier of the addon").DataType("string")).
 		Writes(apis.AddonMeta{}))
 
@@ -60,6 +65,7 @@ func (c *addonWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.GET("/{name}").To(noop).
 		Doc("show details of an addon").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "cluster", auth.VerbRead)).
 		Param(ws.PathParameter("name", "identifier of the addon").DataType("string")).
 		Writes(apis.DetailAddonResponse{}))
 
@@ -67,6 +73,7 @@ func (c *addonWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.GET("/{name}/status").To(noop).
 		Doc("show status of an addon").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "cluster", auth.VerbRead)).
 		Param(ws.PathParameter("name", "identifier of the addon").DataType("string")).
 		Writes(apis.AddonStatusResponse{}))
 
@@ -74,6 +81,7 @@ func (c *addonWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.POST("/{name}/enable").To(noop).
 		Doc("enable an addon on a cluster").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "cluster", auth.VerbAdmin)).
 		Param(ws.QueryParameter("cluster", "cluster name").DataType("string")).
 		Writes(apis.AddonMeta{}))
 
@@ -81,6 +89,7 @@ func (c *addonWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.POST("/{name}/disable").To(noop).
 		Doc("disable an addon on a cluster").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "cluster", auth.VerbAdmin)).
 		Param(ws.QueryParameter("cluster", "cluster name").DataType("string")).
 		Writes(apis.AddonMeta{}))
 
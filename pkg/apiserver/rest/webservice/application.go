@@ -17,13 +17,33 @@ limitations under the License.
 package webservice
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
 	restful "github.com/emicklei/go-restful/v3"
 
 	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/usecase"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+const (
+	// mimeEventStream the content type of the server-sent events
+	mimeEventStream = "text/event-stream"
+	// heartbeatInterval the interval to send the comment line to keep the event stream alive
+	heartbeatInterval = 30 * time.Second
+	// applicationAttribute the attribute of the request records the stored application authorized by the filter
+	applicationAttribute = "application"
 )
 
 type applicationWebService struct {
+	applicationUsecase      usecase.ApplicationUsecase
+	applicationWatchUsecase usecase.ApplicationWatchUsecase
+	authorizer              *auth.Authorizer
 }
 
 func (c *applicationWebService) GetWebService() *restful.WebService {
@@ -35,56 +55,282 @@ func (c *applicationWebService) GetWebService() *restful.WebService {
 
 	tags := []string{"application"}
 
-	ws.Route(ws.GET("/").To(noop).
+	ws.Route(ws.GET("/").To(c.listApplications).
 		Doc("list all applications").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeProject, "project", auth.VerbRead)).
 		Param(ws.QueryParameter("query", "Fuzzy search based on name or description").DataType("string")).
+		Param(ws.QueryParameter("project", "Project-based search").DataType("string")).
 		Param(ws.QueryParameter("namespace", "Namespace-based search").DataType("string")).
 		Param(ws.QueryParameter("cluster", "Cluster-based search").DataType("string")).
 		Writes(apis.ListApplicationResponse{}))
 
-	ws.Route(ws.POST("/").To(noop).
-		Doc("create one application").
+	// the permission on the project of the application is checked by the handlers or the filters, the project and
+	// the namespace are loaded from the stored application rather than the parameters of the request
+	ws.Route(ws.POST("/").To(c.createApplication).
+		Doc("create one application in the project").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Reads(apis.CreateApplicationRequest{}).
 		Writes(apis.ApplicationBase{}))
 
-	ws.Route(ws.DELETE("/{name}").To(noop).
+	ws.Route(ws.DELETE("/{name}").To(c.deleteApplication).
 		Doc("delete one application").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Param(ws.PathParameter("name", "identifier of the application").DataType("string")).
+		Param(ws.QueryParameter("namespace", "the namespace of the application, required if the name is used in multiple namespaces").DataType("string")).
 		Writes(apis.ApplicationBase{}))
 
-	ws.Route(ws.GET("/{name}").To(noop).
+	ws.Route(ws.GET("/{name}").To(c.detailApplication).
 		Doc("detail one application").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Param(ws.PathParameter("name", "identifier of the application").DataType("string")).
+		Param(ws.QueryParameter("namespace", "the namespace of the application, required if the name is used in multiple namespaces").DataType("string")).
 		Writes(apis.DetailApplicationResponse{}))
 
+	ws.Route(ws.GET("/{name}/watch").To(c.watchApplication).
+		Doc("watch the phase, workflow step status and component health changes of the application as server-sent events, "+
+			"the event id is the resourceVersion of the application to resume the stream. "+
+			"It returns 410 if the resourceVersion is too old to resume from").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizeApplication(auth.VerbRead)).
+		Produces(mimeEventStream, restful.MIME_JSON).
+		Param(ws.PathParameter("name", "identifier of the application").DataType("string")).
+		Param(ws.QueryParameter("namespace", "the namespace of the application, required if the name is used in multiple namespaces").DataType("string")).
+		Param(ws.QueryParameter("resourceVersion", "resume from the resourceVersion, the Last-Event-ID header is used if not set").DataType("string")).
+		Writes(apis.ApplicationEvent{}))
+
 	ws.Route(ws.POST("/{name}/template").To(noop).
 		Doc("create one application template").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizeApplication(auth.VerbWrite)).
 		Param(ws.PathParameter("name", "identifier of the application").DataType("string")).
+		Param(ws.QueryParameter("namespace", "the namespace of the application, required if the name is used in multiple namespaces").DataType("string")).
 		Reads(apis.CreateApplicationTemplateRequest{}).
 		Writes(apis.ApplicationTemplateBase{}))
 
-	ws.Route(ws.POST("/{name}/deploy").To(noop).
-		Doc("deploy or update the application").
+	ws.Route(ws.POST("/{name}/deploy").To(c.deployApplication).
+		Doc("deploy or update the application, the environments and quota of the project are checked again").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Param(ws.PathParameter("name", "identifier of the application").DataType("string")).
+		Param(ws.QueryParameter("namespace", "the namespace of the application, required if the name is used in multiple namespaces").DataType("string")).
 		Writes(apis.ApplicationBase{}))
 
+	ws.Route(ws.GET("/{name}/revisions/compare").To(c.compareApplicationRevisions).
+		Doc("compare two revisions of the application, the application spec and the rendered manifests of the components "+
+			"and traits are compared, the latest revision is compared with the one before it by default").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("name", "identifier of the application").DataType("string")).
+		Param(ws.QueryParameter("namespace", "the namespace of the application, required if the name is used in multiple namespaces").DataType("string")).
+		Param(ws.QueryParameter("from", "the revision number or name to compare from").DataType("string")).
+		Param(ws.QueryParameter("to", "the revision number or name to compare to").DataType("string")).
+		Writes(apis.ApplicationRevisionDiffResponse{}))
+
 	ws.Route(ws.GET("/{name}/components").To(noop).
 		Doc("gets the component topology of the application").
 		Param(ws.PathParameter("name", "identifier of the application").DataType("string")).
+		Param(ws.QueryParameter("namespace", "the namespace of the application, required if the name is used in multiple namespaces").DataType("string")).
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizeApplication(auth.VerbRead)).
 		Writes(apis.ComponentListResponse{}))
 
 	ws.Route(ws.POST("/{name}/components").To(noop).
 		Doc("create component for application").
 		Param(ws.PathParameter("name", "identifier of the application").DataType("string")).
+		Param(ws.QueryParameter("namespace", "the namespace of the application, required if the name is used in multiple namespaces").DataType("string")).
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizeApplication(auth.VerbWrite)).
 		Reads(apis.CreateComponentRequest{}).
 		Writes(apis.ComponentBase{}))
 	return ws
 }
+
+func (c *applicationWebService) listApplications(req *restful.Request, res *restful.Response) {
+	apps, err := c.applicationUsecase.ListApplications(req.Request.Context(), apis.ListApplicationOptions{
+		Project:   req.QueryParameter("project"),
+		Namespace: req.QueryParameter("namespace"),
+		Cluster:   req.QueryParameter("cluster"),
+		Query:     req.QueryParameter("query"),
+	})
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apps); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *applicationWebService) createApplication(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateApplicationRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := c.authorizer.Check(req, auth.ScopeProject, createReq.Project, auth.VerbWrite); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app, err := c.applicationUsecase.CreateApplication(req.Request.Context(), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(app); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *applicationWebService) detailApplication(req *restful.Request, res *restful.Response) {
+	app, err := c.checkApplicationPermission(req, auth.VerbRead)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	detail, err := c.applicationUsecase.GetApplication(req.Request.Context(), app.Namespace, app.Name)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(detail); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *applicationWebService) deleteApplication(req *restful.Request, res *restful.Response) {
+	app, err := c.checkApplicationPermission(req, auth.VerbWrite)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	deleted, err := c.applicationUsecase.DeleteApplication(req.Request.Context(), app.Namespace, app.Name)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(deleted); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *applicationWebService) deployApplication(req *restful.Request, res *restful.Response) {
+	app, err := c.checkApplicationPermission(req, auth.VerbWrite)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	deployed, err := c.applicationUsecase.DeployApplication(req.Request.Context(), app.Namespace, app.Name)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(deployed); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *applicationWebService) compareApplicationRevisions(req *restful.Request, res *restful.Response) {
+	app, err := c.checkApplicationPermission(req, auth.VerbRead)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	diff, err := c.applicationUsecase.CompareApplicationRevisions(req.Request.Context(), app.Namespace, app.Name,
+		req.QueryParameter("from"), req.QueryParameter("to"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(diff); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+// checkApplicationPermission check the verb on the project of the application in the path, and returns the
+// stored application
+func (c *applicationWebService) checkApplicationPermission(req *restful.Request, verb auth.Verb) (*apis.ApplicationBase, error) {
+	app, err := c.applicationUsecase.GetApplicationBase(req.Request.Context(), req.QueryParameter("namespace"), req.PathParameter("name"))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.authorizer.Check(req, auth.ScopeProject, app.Project, verb); err != nil {
+		return nil, err
+	}
+	req.SetAttribute(applicationAttribute, app)
+	return app, nil
+}
+
+// authorizeApplication is the filter version of checkApplicationPermission, the handlers get the stored application
+// from the applicationAttribute of the request
+func (c *applicationWebService) authorizeApplication(verb auth.Verb) restful.FilterFunction {
+	return func(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+		if _, err := c.checkApplicationPermission(req, verb); err != nil {
+			bcode.ReturnError(req, res, err)
+			return
+		}
+		chain.ProcessFilter(req, res)
+	}
+}
+
+func (c *applicationWebService) watchApplication(req *restful.Request, res *restful.Response) {
+	app, ok := req.Attribute(applicationAttribute).(*apis.ApplicationBase)
+	if !ok {
+		bcode.ReturnError(req, res, bcode.ErrApplicationNotExist)
+		return
+	}
+	resourceVersion := req.QueryParameter("resourceVersion")
+	if resourceVersion == "" {
+		resourceVersion = req.HeaderParameter("Last-Event-ID")
+	}
+	events, cancel, err := c.applicationWatchUsecase.Subscribe(app.Namespace, app.Name, resourceVersion)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	defer cancel()
+	flusher, ok := res.ResponseWriter.(http.Flusher)
+	if !ok {
+		bcode.ReturnError(req, res, fmt.Errorf("streaming is not supported by the response writer"))
+		return
+	}
+	res.Header().Set("Content-Type", mimeEventStream)
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-req.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(res, "id: %s\nevent: %s\ndata: %s\n\n", event.ResourceVersion, event.Type, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webservice
+
+import (
+	"strconv"
+	"time"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	restful "github.com/emicklei/go-restful/v3"
+
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/usecase"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+type auditWebService struct {
+	auditUsecase usecase.AuditUsecase
+	authorizer   *auth.Authorizer
+}
+
+func (c *auditWebService) GetWebService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/audit").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for audit records of the mutating requests")
+
+	tags := []string{"audit"}
+
+	ws.Route(ws.GET("/").To(c.listAuditRecords).
+		Doc("list audit records, the latest record is the first").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "", auth.VerbAdmin)).
+		Param(ws.QueryParameter("user", "the principal of the requests").DataType("string")).
+		Param(ws.QueryParameter("since", "the start of the time range, RFC3339 format").DataType("string")).
+		Param(ws.QueryParameter("until", "the end of the time range, RFC3339 format").DataType("string")).
+		Param(ws.QueryParameter("limit", "the max number of the records").DataType("integer")).
+		Writes(apis.ListAuditRecordResponse{}).Do(returns200, returns500))
+	return ws
+}
+
+func (c *auditWebService) listAuditRecords(req *restful.Request, res *restful.Response) {
+	options := apis.ListAuditRecordOptions{User: req.QueryParameter("user")}
+	var err error
+	if since := req.QueryParameter("since"); since != "" {
+		if options.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			bcode.ReturnError(req, res, bcode.ErrInvalidAuditQuery)
+			return
+		}
+	}
+	if until := req.QueryParameter("until"); until != "" {
+		if options.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			bcode.ReturnError(req, res, bcode.ErrInvalidAuditQuery)
+			return
+		}
+	}
+	if limit := req.QueryParameter("limit"); limit != "" {
+		if options.Limit, err = strconv.Atoi(limit); err != nil || options.Limit <= 0 {
+			bcode.ReturnError(req, res, bcode.ErrInvalidAuditQuery)
+			return
+		}
+	}
+	records, err := c.auditUsecase.ListAuditRecords(req.Request.Context(), options)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(records); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
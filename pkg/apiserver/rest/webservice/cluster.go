@@ -21,12 +21,14 @@ import (
 	restful "github.com/emicklei/go-restful/v3"
 
 	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/usecase"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
 )
 
 type clusterWebService struct {
 	clusterUsecase usecase.ClusterUsecase
+	authorizer     *auth.Authorizer
 }
 
 func (c *clusterWebService) GetWebService() *restful.WebService {
@@ -38,24 +40,34 @@ func (c *clusterWebService) GetWebService() *restful.WebService {
 
 	tags := []string{"cluster"}
 
-	ws.Route(ws.GET("/").To(noop).
+	ws.Route(ws.GET("/").To(c.listKubeClusters).
 		Doc("list all clusters").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "", auth.VerbRead)).
 		Param(ws.QueryParameter("query", "Fuzzy search based on name or description").DataType("string")).
 		Writes(apis.ListClusterResponse{}).Do(returns200, returns500))
 
 	ws.Route(ws.POST("/").To(c.createKubeCluster).
 		Doc("create cluster").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "", auth.VerbWrite)).
 		Reads(&apis.CreateClusterRequest{}).
 		Writes(apis.ClusterBase{}))
 
-	ws.Route(ws.GET("/{clusterName}").To(noop).
+	ws.Route(ws.GET("/{clusterName}").To(c.getKubeCluster).
 		Doc("detail cluster info").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "clusterName", auth.VerbRead)).
 		Param(ws.PathParameter("clusterName", "identifier of the cluster").DataType("string")).
 		Writes(apis.DetailClusterResponse{}))
 
+	ws.Route(ws.DELETE("/{clusterName}").To(c.deleteKubeCluster).
+		Doc("delete cluster").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "clusterName", auth.VerbWrite)).
+		Param(ws.PathParameter("clusterName", "identifier of the cluster").DataType("string")).
+		Writes(apis.ClusterBase{}))
+
 	// Do not implement this dimension for now.
 	// ws.Route(ws.GET("/{clusterName}/addons").To(noop).
 	// 	Doc("list cluster addons info").
@@ -95,3 +107,39 @@ func (c *clusterWebService) createKubeCluster(req *restful.Request, res *restful
 		return
 	}
 }
+
+func (c *clusterWebService) listKubeClusters(req *restful.Request, res *restful.Response) {
+	clusters, err := c.clusterUsecase.ListKubeClusters(req.Request.Context(), req.QueryParameter("query"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(clusters); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *clusterWebService) getKubeCluster(req *restful.Request, res *restful.Response) {
+	cluster, err := c.clusterUsecase.GetKubeCluster(req.Request.Context(), req.PathParameter("clusterName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(cluster); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *clusterWebService) deleteKubeCluster(req *restful.Request, res *restful.Response) {
+	cluster, err := c.clusterUsecase.DeleteKubeCluster(req.Request.Context(), req.PathParameter("clusterName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(cluster); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -21,9 +21,11 @@ import (
 	restful "github.com/emicklei/go-restful/v3"
 
 	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
 )
 
 type componentDefinitionWebservice struct {
+	authorizer *auth.Authorizer
 }
 
 func (c *componentDefinitionWebservice) GetWebService() *restful.WebService {
@@ -38,6 +40,7 @@ func (c *componentDefinitionWebservice) GetWebService() *restful.WebService {
 	ws.Route(ws.GET("/").To(noop).
 		Doc("list all componentdefinition").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "clusterName", auth.VerbRead)).
 		Param(ws.QueryParameter("appName", "if specified, query the componentdefinition supported by the cluster where the application resides.").DataType("string")).
 		Param(ws.QueryParameter("clusterName", "if specified, query the componentdefinition supported by the cluster.").DataType("string")).
 		Writes(apis.ListComponentDefinitionResponse{}))
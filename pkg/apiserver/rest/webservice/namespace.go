@@ -21,9 +21,11 @@ import (
 	restful "github.com/emicklei/go-restful/v3"
 
 	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
 )
 
 type namespaceWebService struct {
+	authorizer *auth.Authorizer
 }
 
 func (c *namespaceWebService) GetWebService() *restful.WebService {
@@ -38,17 +40,20 @@ func (c *namespaceWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.GET("/").To(noop).
 		Doc("list all namespaces").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeNamespace, "", auth.VerbRead)).
 		Writes(apis.ListNamespaceResponse{}))
 
 	ws.Route(ws.POST("/").To(noop).
 		Doc("create namespace").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeNamespace, "", auth.VerbAdmin)).
 		Reads(apis.CreateNamespaceRequest{}).
 		Writes(apis.NamesapceDetailResponse{}))
 
 	ws.Route(ws.GET("/{namespace}").To(noop).
 		Doc("get one namespace").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeNamespace, "namespace", auth.VerbRead)).
 		Param(ws.PathParameter("namespace", "identifier of the namespace").DataType("string")).
 		Writes(apis.NamesapceDetailResponse{}))
 
@@ -56,6 +61,7 @@ func (c *namespaceWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.GET("/{namespace}/applications/:appname").To(noop).
 		Doc("get the specified oam application in the specified namespace").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeNamespace, "namespace", auth.VerbRead)).
 		Param(ws.PathParameter("namespace", "identifier of the namespace").DataType("string")).
 		Param(ws.PathParameter("appname", "identifier of the oam application").DataType("string")).
 		Writes(apis.ApplicationResponse{}))
@@ -63,6 +69,7 @@ func (c *namespaceWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.POST("/{namespace}/applications/:appname").To(noop).
 		Doc("create or update oam application in the specified namespace").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeNamespace, "namespace", auth.VerbWrite)).
 		Param(ws.PathParameter("namespace", "identifier of the namespace").DataType("string")).
 		Param(ws.PathParameter("appname", "identifier of the oam application").DataType("string")).
 		Reads(apis.ApplicationRequest{}))
@@ -70,6 +77,7 @@ func (c *namespaceWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.DELETE("/{namespace}/applications/:appname").To(noop).
 		Doc("create or update oam application in the specified namespace").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeNamespace, "namespace", auth.VerbWrite)).
 		Param(ws.PathParameter("namespace", "identifier of the namespace").DataType("string")).
 		Param(ws.PathParameter("appname", "identifier of the oam application").DataType("string")))
 	return ws
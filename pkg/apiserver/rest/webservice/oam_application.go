@@ -21,9 +21,11 @@ import (
 	restful "github.com/emicklei/go-restful/v3"
 
 	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
 )
 
 type oamApplicationWebService struct {
+	authorizer *auth.Authorizer
 }
 
 func (c *oamApplicationWebService) GetWebService() *restful.WebService {
@@ -38,6 +40,7 @@ func (c *oamApplicationWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.GET("/{namespace}/applications/:appname").To(noop).
 		Doc("get the specified oam application in the specified namespace").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeNamespace, "namespace", auth.VerbRead)).
 		Param(ws.PathParameter("namespace", "identifier of the namespace").DataType("string")).
 		Param(ws.PathParameter("appname", "identifier of the oam application").DataType("string")).
 		Writes(apis.ApplicationResponse{}))
@@ -45,6 +48,7 @@ func (c *oamApplicationWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.POST("/{namespace}/applications/{appname}").To(noop).
 		Doc("create or update oam application in the specified namespace").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeNamespace, "namespace", auth.VerbWrite)).
 		Param(ws.PathParameter("namespace", "identifier of the namespace").DataType("string")).
 		Param(ws.PathParameter("appname", "identifier of the oam application").DataType("string")).
 		Reads(apis.ApplicationRequest{}))
@@ -52,6 +56,7 @@ func (c *oamApplicationWebService) GetWebService() *restful.WebService {
 	ws.Route(ws.DELETE("/{namespace}/applications/:appname").To(noop).
 		Doc("create or update oam application in the specified namespace").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeNamespace, "namespace", auth.VerbWrite)).
 		Param(ws.PathParameter("namespace", "identifier of the namespace").DataType("string")).
 		Param(ws.PathParameter("appname", "identifier of the oam application").DataType("string")))
 	return ws
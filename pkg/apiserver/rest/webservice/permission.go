@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webservice
+
+import (
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	restful "github.com/emicklei/go-restful/v3"
+
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/usecase"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+type permissionWebService struct {
+	permissionUsecase usecase.PermissionUsecase
+	authorizer        *auth.Authorizer
+}
+
+func (c *permissionWebService) GetWebService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/permissions").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for permission manage, only the admin of all clusters can manage the permissions")
+
+	tags := []string{"permission"}
+
+	ws.Route(ws.GET("/").To(c.listPermissions).
+		Doc("list all permissions").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "", auth.VerbAdmin)).
+		Writes(apis.ListPermissionResponse{}).Do(returns200, returns500))
+
+	ws.Route(ws.POST("/").To(c.createPermission).
+		Doc("grant the verb on the scope to the user or the group").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "", auth.VerbAdmin)).
+		Reads(apis.CreatePermissionRequest{}).
+		Writes(apis.PermissionBase{}))
+
+	ws.Route(ws.DELETE("/{name}").To(c.deletePermission).
+		Doc("revoke the permission").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeCluster, "", auth.VerbAdmin)).
+		Param(ws.PathParameter("name", "identifier of the permission").DataType("string")).
+		Writes(apis.PermissionBase{}))
+	return ws
+}
+
+func (c *permissionWebService) listPermissions(req *restful.Request, res *restful.Response) {
+	permissions, err := c.permissionUsecase.ListPermissions(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(permissions); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *permissionWebService) createPermission(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreatePermissionRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	permission, err := c.permissionUsecase.CreatePermission(req.Request.Context(), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(permission); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *permissionWebService) deletePermission(req *restful.Request, res *restful.Response) {
+	permission, err := c.permissionUsecase.DeletePermission(req.Request.Context(), req.PathParameter("name"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(permission); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
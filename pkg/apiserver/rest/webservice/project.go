@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webservice
+
+import (
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	restful "github.com/emicklei/go-restful/v3"
+
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/usecase"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+type projectWebService struct {
+	projectUsecase usecase.ProjectUsecase
+	authorizer     *auth.Authorizer
+}
+
+func (c *projectWebService) GetWebService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/projects").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for project and environment manage")
+
+	tags := []string{"project"}
+
+	ws.Route(ws.GET("/").To(c.listProjects).
+		Doc("list all projects").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeProject, "", auth.VerbRead)).
+		Writes(apis.ListProjectResponse{}).Do(returns200, returns500))
+
+	ws.Route(ws.POST("/").To(c.createProject).
+		Doc("create one project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeProject, "", auth.VerbAdmin)).
+		Reads(apis.CreateProjectRequest{}).
+		Writes(apis.ProjectBase{}))
+
+	ws.Route(ws.GET("/{projectName}").To(c.getProject).
+		Doc("detail one project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeProject, "projectName", auth.VerbRead)).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Writes(apis.ProjectBase{}))
+
+	ws.Route(ws.PUT("/{projectName}").To(c.updateProject).
+		Doc("update the description, members and quota of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeProject, "projectName", auth.VerbAdmin)).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Reads(apis.UpdateProjectRequest{}).
+		Writes(apis.ProjectBase{}))
+
+	ws.Route(ws.DELETE("/{projectName}").To(c.deleteProject).
+		Doc("delete one project, the applications and environments of the project must be deleted first").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeProject, "projectName", auth.VerbAdmin)).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Writes(apis.ProjectBase{}))
+
+	ws.Route(ws.GET("/{projectName}/environments").To(c.listEnvironments).
+		Doc("list the environments of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeProject, "projectName", auth.VerbRead)).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Writes(apis.ListEnvironmentResponse{}))
+
+	// binding the namespace and the clusters to a project requires the platform admin, as creating a project does
+	ws.Route(ws.POST("/{projectName}/environments").To(c.createEnvironment).
+		Doc("create the environment targets the namespace and clusters in the project, requires the admin of all the projects").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeProject, "", auth.VerbAdmin)).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Reads(apis.CreateEnvironmentRequest{}).
+		Writes(apis.EnvironmentBase{}))
+
+	ws.Route(ws.DELETE("/{projectName}/environments/{envName}").To(c.deleteEnvironment).
+		Doc("delete the environment of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.authorizer.Authorize(auth.ScopeProject, "projectName", auth.VerbAdmin)).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("envName", "identifier of the environment").DataType("string")).
+		Writes(apis.EnvironmentBase{}))
+	return ws
+}
+
+func (c *projectWebService) listProjects(req *restful.Request, res *restful.Response) {
+	projects, err := c.projectUsecase.ListProjects(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(projects); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *projectWebService) createProject(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateProjectRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	project, err := c.projectUsecase.CreateProject(req.Request.Context(), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(project); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *projectWebService) getProject(req *restful.Request, res *restful.Response) {
+	project, err := c.projectUsecase.GetProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(project); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *projectWebService) updateProject(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var updateReq apis.UpdateProjectRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	project, err := c.projectUsecase.UpdateProject(req.Request.Context(), req.PathParameter("projectName"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(project); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *projectWebService) deleteProject(req *restful.Request, res *restful.Response) {
+	project, err := c.projectUsecase.DeleteProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(project); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *projectWebService) listEnvironments(req *restful.Request, res *restful.Response) {
+	envs, err := c.projectUsecase.ListEnvironments(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(envs); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *projectWebService) createEnvironment(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateEnvironmentRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	env, err := c.projectUsecase.CreateEnvironment(req.Request.Context(), req.PathParameter("projectName"), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(env); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *projectWebService) deleteEnvironment(req *restful.Request, res *restful.Response) {
+	env, err := c.projectUsecase.DeleteEnvironment(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("envName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(env); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
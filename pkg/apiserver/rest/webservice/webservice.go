@@ -22,6 +22,13 @@ import (
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/go-playground/validator/v10"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/auth"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/usecase"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 )
 
 // versionPrefix API version prefix.
@@ -57,11 +64,24 @@ func returns500(b *restful.RouteBuilder) {
 }
 
 // Init init all webservice, pass in the required parameter object.
-func Init(ctx context.Context) {
-	RegistWebService(&clusterWebService{})
-	RegistWebService(&applicationWebService{})
-	RegistWebService(&namespaceWebService{})
-	RegistWebService(&componentDefinitionWebservice{})
-	RegistWebService(&addonWebService{})
-	RegistWebService(&oamApplicationWebService{})
+func Init(ctx context.Context, ds datastore.DataStore, kubeClient client.WithWatch, dm discoverymapper.DiscoveryMapper,
+	pd *packages.PackageDiscover, authentication *auth.Authentication) {
+	clusterUsecase := usecase.NewClusterUsecase(ds, kubeClient)
+	permissionUsecase := usecase.NewPermissionUsecase(ds)
+	authorizer := auth.NewAuthorizer(authentication, permissionUsecase)
+	RegistWebService(&clusterWebService{clusterUsecase: clusterUsecase, authorizer: authorizer})
+	applicationWatchUsecase := usecase.NewApplicationWatchUsecase(kubeClient)
+	go applicationWatchUsecase.Start(ctx)
+	RegistWebService(&applicationWebService{
+		applicationUsecase:      usecase.NewApplicationUsecase(ds, kubeClient, dm, pd),
+		applicationWatchUsecase: applicationWatchUsecase,
+		authorizer:              authorizer,
+	})
+	RegistWebService(&projectWebService{projectUsecase: usecase.NewProjectUsecase(ds, kubeClient), authorizer: authorizer})
+	RegistWebService(&namespaceWebService{authorizer: authorizer})
+	RegistWebService(&componentDefinitionWebservice{authorizer: authorizer})
+	RegistWebService(&addonWebService{authorizer: authorizer})
+	RegistWebService(&oamApplicationWebService{authorizer: authorizer})
+	RegistWebService(&permissionWebService{permissionUsecase: permissionUsecase, authorizer: authorizer})
+	RegistWebService(&auditWebService{auditUsecase: usecase.NewAuditUsecase(ds), authorizer: authorizer})
 }
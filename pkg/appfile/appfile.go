@@ -40,11 +40,14 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/appfile/helm"
+	"github.com/oam-dev/kubevela/pkg/appfile/k8sobjects"
+	"github.com/oam-dev/kubevela/pkg/appfile/kustomize"
 	"github.com/oam-dev/kubevela/pkg/cue/definition"
 	"github.com/oam-dev/kubevela/pkg/cue/model"
 	"github.com/oam-dev/kubevela/pkg/cue/model/value"
 	"github.com/oam-dev/kubevela/pkg/cue/process"
 	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 )
 
@@ -72,7 +75,11 @@ type Workload struct {
 	FullTemplate       *Template
 	Ctx                process.Context
 	Patch              *value.Value
-	engine             definition.AbstractEngine
+	// RuntimeInfo is exposed to the templates of the workload and traits in the context, e.g. context.cluster
+	RuntimeInfo process.RuntimeInfo
+	engine      definition.AbstractEngine
+	// dm checks the scope of the objects of the k8s-objects schematic
+	dm discoverymapper.DiscoveryMapper
 }
 
 // EvalContext eval workload template and set result to context
@@ -209,6 +216,7 @@ func (af *Appfile) generateSteps() {
 
 func generateUnstructuredFromCUEModule(wl *Workload, appName, revision, ns string, components []common.ApplicationComponent, artifacts []*types.ComponentManifest) (*unstructured.Unstructured, error) {
 	pCtx := process.NewPolicyContext(ns, wl.Name, appName, revision, components)
+	pCtx.SetRuntimeInfo(wl.RuntimeInfo)
 	pCtx.PushData(model.ContextDataArtifacts, prepareArtifactsData(artifacts))
 	if err := wl.EvalContext(pCtx); err != nil {
 		return nil, errors.Wrapf(err, "evaluate base template app=%s in namespace=%s", appName, ns)
@@ -241,11 +249,11 @@ func prepareArtifactsData(comps []*types.ComponentManifest) map[string]interface
 }
 
 // GenerateComponentManifests converts an appFile to a slice of ComponentManifest
-func (af *Appfile) GenerateComponentManifests() ([]*types.ComponentManifest, error) {
+func (af *Appfile) GenerateComponentManifests(ctx context.Context) ([]*types.ComponentManifest, error) {
 	compManifests := make([]*types.ComponentManifest, len(af.Workloads))
 	af.Artifacts = make([]*types.ComponentManifest, len(af.Workloads))
 	for i, wl := range af.Workloads {
-		cm, err := af.GenerateComponentManifest(wl)
+		cm, err := af.GenerateComponentManifest(ctx, wl)
 		if err != nil {
 			return nil, err
 		}
@@ -260,15 +268,18 @@ func (af *Appfile) GenerateComponentManifests() ([]*types.ComponentManifest, err
 }
 
 // GenerateComponentManifest generate only one ComponentManifest
-func (af *Appfile) GenerateComponentManifest(wl *Workload) (*types.ComponentManifest, error) {
+func (af *Appfile) GenerateComponentManifest(ctx context.Context, wl *Workload) (*types.ComponentManifest, error) {
 	if af.Namespace == "" {
 		af.Namespace = corev1.NamespaceDefault
 	}
+	af.setAppMetadata(wl)
 	switch wl.CapabilityCategory {
 	case types.HelmCategory:
-		return generateComponentFromHelmModule(wl, af.Name, af.AppRevisionName, af.Namespace)
+		return generateComponentFromHelmModule(ctx, wl, af.Name, af.AppRevisionName, af.Namespace)
 	case types.KubeCategory:
-		return generateComponentFromKubeModule(wl, af.Name, af.AppRevisionName, af.Namespace)
+		return generateComponentFromKubeModule(ctx, wl, af.Name, af.AppRevisionName, af.Namespace)
+	case types.KustomizeCategory, types.K8sObjectsCategory:
+		return generateComponentFromRenderedModule(ctx, wl, af.Name, af.AppRevisionName, af.Namespace)
 	case types.TerraformCategory:
 		return generateComponentFromTerraformModule(wl, af.Name, af.AppRevisionName, af.Namespace)
 	default:
@@ -276,6 +287,16 @@ func (af *Appfile) GenerateComponentManifest(wl *Workload) (*types.ComponentMani
 	}
 }
 
+// setAppMetadata exposes the labels and annotations of the application to the templates of the workload
+func (af *Appfile) setAppMetadata(wl *Workload) {
+	if wl.RuntimeInfo.AppLabels == nil {
+		wl.RuntimeInfo.AppLabels = af.AppLabels
+	}
+	if wl.RuntimeInfo.AppAnnotations == nil {
+		wl.RuntimeInfo.AppAnnotations = af.AppAnnotations
+	}
+}
+
 // SetOAMContract will set OAM labels and annotations for resources as contract
 func (af *Appfile) SetOAMContract(comp *types.ComponentManifest) error {
 
@@ -451,6 +472,7 @@ func PrepareProcessContext(wl *Workload, applicationName, revision, namespace st
 // NewBasicContext prepares a basic DSL process Context
 func NewBasicContext(wl *Workload, applicationName, revision, namespace string) process.Context {
 	pCtx := process.NewContext(namespace, wl.Name, applicationName, revision)
+	pCtx.SetRuntimeInfo(wl.RuntimeInfo)
 	if wl.Params != nil {
 		pCtx.SetParameters(wl.Params)
 	}
@@ -576,8 +598,9 @@ func evalWorkloadWithContext(pCtx process.Context, wl *Workload, ns, appName, co
 	return compManifest, nil
 }
 
-// GenerateCUETemplate generate CUE Template from Kube module and Helm module
-func GenerateCUETemplate(wl *Workload) (string, error) {
+// GenerateCUETemplate generate CUE Template from Kube module, Kustomize module, K8s objects module and Helm module,
+// the namespace is the one of the application
+func GenerateCUETemplate(ctx context.Context, wl *Workload, ns string) (string, error) {
 	var templateStr string
 	switch wl.CapabilityCategory {
 	case types.KubeCategory:
@@ -596,26 +619,36 @@ func GenerateCUETemplate(wl *Workload) (string, error) {
 			return templateStr, errors.WithMessage(err, "cannot set parameters value")
 		}
 
-		// convert structured kube obj into CUE (go ==marshal==> json ==decoder==> cue)
-		objRaw, err := kubeObj.MarshalJSON()
-		if err != nil {
-			return templateStr, errors.Wrap(err, "cannot marshal kube object")
-		}
-		ins, err := json2cue.Decode(&cue.Runtime{}, "", objRaw)
+		cueRaw, err := convertKubeObjectToCUE(kubeObj)
 		if err != nil {
-			return templateStr, errors.Wrap(err, "cannot decode object into CUE")
-		}
-		cueRaw, err := format.Node(ins.Value().Syntax())
-		if err != nil {
-			return templateStr, errors.Wrap(err, "cannot format CUE")
+			return templateStr, err
 		}
 
 		// NOTE a hack way to enable using CUE capabilities on KUBE schematic workload
 		templateStr = fmt.Sprintf(`
 output: { 
 %s 
-}`, string(cueRaw))
+}`, cueRaw)
+	case types.KustomizeCategory:
+		objs, err := kustomize.Render(ctx, wl.FullTemplate.Kustomize, wl.Params)
+		if err != nil {
+			return templateStr, errors.WithMessage(err, "cannot render kustomize schematic")
+		}
+		return generateCUETemplateFromObjects(objs, wl.FullTemplate.Reference.Definition)
+	case types.K8sObjectsCategory:
+		objs, workloadIdx, err := k8sobjects.Render(wl.FullTemplate.K8sObjects, wl.Params, wl.FullTemplate.Reference.Definition, wl.dm)
+		if err != nil {
+			return templateStr, errors.WithMessage(err, "cannot render k8s-objects schematic")
+		}
+		return convertObjectsToCUETemplate(objs, workloadIdx)
 	case types.HelmCategory:
+		if helm.IsNativeMode(wl.FullTemplate.Helm) {
+			objs, err := helm.RenderChart(ctx, wl.FullTemplate.Helm, wl.Name, ns, wl.Params)
+			if err != nil {
+				return templateStr, errors.WithMessage(err, "cannot render Helm chart")
+			}
+			return generateCUETemplateFromObjects(objs, wl.FullTemplate.Reference.Definition)
+		}
 		gv, err := schema.ParseGroupVersion(wl.FullTemplate.Reference.Definition.APIVersion)
 		if err != nil {
 			return templateStr, err
@@ -633,8 +666,85 @@ output: {
 	return templateStr, nil
 }
 
-func generateComponentFromKubeModule(wl *Workload, appName, revision, ns string) (*types.ComponentManifest, error) {
-	templateStr, err := GenerateCUETemplate(wl)
+// convertKubeObjectToCUE converts structured kube obj into CUE (go ==marshal==> json ==decoder==> cue)
+func convertKubeObjectToCUE(obj *unstructured.Unstructured) (string, error) {
+	objRaw, err := obj.MarshalJSON()
+	if err != nil {
+		return "", errors.Wrap(err, "cannot marshal kube object")
+	}
+	ins, err := json2cue.Decode(&cue.Runtime{}, "", objRaw)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot decode object into CUE")
+	}
+	cueRaw, err := format.Node(ins.Value().Syntax())
+	if err != nil {
+		return "", errors.Wrap(err, "cannot format CUE")
+	}
+	return string(cueRaw), nil
+}
+
+// generateCUETemplateFromObjects generates the CUE template from the rendered resources, e.g. by a kustomization or
+// a Helm chart. The first resource matching the workload type of the definition is the workload, or the first
+// resource if the workload type is not specified, and the others are auxiliary workloads.
+func generateCUETemplateFromObjects(objs []*unstructured.Unstructured, wlGVK common.WorkloadGVK) (string, error) {
+	workloadIdx := -1
+	for i, obj := range objs {
+		if wlGVK.Kind == "" || (obj.GetAPIVersion() == wlGVK.APIVersion && obj.GetKind() == wlGVK.Kind) {
+			workloadIdx = i
+			break
+		}
+	}
+	if workloadIdx < 0 {
+		return "", errors.Errorf("no resource of %s %s is rendered", wlGVK.APIVersion, wlGVK.Kind)
+	}
+	return convertObjectsToCUETemplate(objs, workloadIdx)
+}
+
+// convertObjectsToCUETemplate converts the resources into the CUE template, the resource at workloadIdx is the
+// workload and the others are auxiliary workloads
+func convertObjectsToCUETemplate(objs []*unstructured.Unstructured, workloadIdx int) (string, error) {
+
+	var output string
+	outputs := strings.Builder{}
+	names := map[string]bool{}
+	for i, obj := range objs {
+		cueRaw, err := convertKubeObjectToCUE(obj)
+		if err != nil {
+			return "", err
+		}
+		if i == workloadIdx {
+			output = cueRaw
+			continue
+		}
+		name := strings.ToLower(obj.GetKind() + "-" + obj.GetName())
+		if names[name] {
+			name = fmt.Sprintf("%s-%d", name, i)
+		}
+		names[name] = true
+		fmt.Fprintf(&outputs, "%q: {\n%s}\n", name, cueRaw)
+	}
+
+	// NOTE like the KUBE schematic, this enables using CUE capabilities on the rendered resources
+	return fmt.Sprintf(`
+output: {
+%s}
+outputs: {
+%s}`, output, outputs.String()), nil
+}
+
+// generateComponentFromRenderedModule generates the component from the resources rendered by the kustomize or
+// k8s-objects schematic
+func generateComponentFromRenderedModule(ctx context.Context, wl *Workload, appName, revision, ns string) (*types.ComponentManifest, error) {
+	templateStr, err := GenerateCUETemplate(ctx, wl, ns)
+	if err != nil {
+		return nil, err
+	}
+	wl.FullTemplate.TemplateStr = templateStr
+	return generateComponentFromCUEModule(wl, appName, revision, ns)
+}
+
+func generateComponentFromKubeModule(ctx context.Context, wl *Workload, appName, revision, ns string) (*types.ComponentManifest, error) {
+	templateStr, err := GenerateCUETemplate(ctx, wl, ns)
 	if err != nil {
 		return nil, err
 	}
@@ -786,13 +896,18 @@ func setParameterValuesToKubeObj(obj *unstructured.Unstructured, values paramVal
 	return nil
 }
 
-func generateComponentFromHelmModule(wl *Workload, appName, revision, ns string) (*types.ComponentManifest, error) {
-	templateStr, err := GenerateCUETemplate(wl)
+func generateComponentFromHelmModule(ctx context.Context, wl *Workload, appName, revision, ns string) (*types.ComponentManifest, error) {
+	templateStr, err := GenerateCUETemplate(ctx, wl, ns)
 	if err != nil {
 		return nil, err
 	}
 	wl.FullTemplate.TemplateStr = templateStr
 
+	// the chart is rendered into plain manifests in the native mode, so they are handled like the CUE module
+	if helm.IsNativeMode(wl.FullTemplate.Helm) {
+		return generateComponentFromCUEModule(wl, appName, revision, ns)
+	}
+
 	// re-use the way CUE module generates comp & acComp
 	compManifest := &types.ComponentManifest{
 		Name:             wl.Name,
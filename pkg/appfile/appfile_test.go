@@ -31,17 +31,21 @@ import (
 	terraformapi "github.com/oam-dev/terraform-controller/api/v1beta1"
 	"github.com/pkg/errors"
 	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/yaml"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	oamtypes "github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile/helm"
 	"github.com/oam-dev/kubevela/pkg/cue/definition"
 	"github.com/oam-dev/kubevela/pkg/cue/model"
+	"github.com/oam-dev/kubevela/pkg/oam/mock"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 )
 
@@ -120,7 +124,7 @@ var _ = Describe("Test Helm schematic appfile", func() {
 			},
 		}
 		By("Generate ApplicationConfiguration and Components")
-		components, err := appFile.GenerateComponentManifests()
+		components, err := appFile.GenerateComponentManifests(context.Background())
 		Expect(err).To(BeNil())
 
 		expectCompManifest := &oamtypes.ComponentManifest{
@@ -296,7 +300,7 @@ spec:
 
 	It("Test generate AppConfig resources from Kube schematic", func() {
 		By("Generate ApplicationConfiguration and Components")
-		comps, err := testAppfile().GenerateComponentManifests()
+		comps, err := testAppfile().GenerateComponentManifests(context.Background())
 		Expect(err).To(BeNil())
 
 		expectWorkload := func() *unstructured.Unstructured {
@@ -352,7 +356,7 @@ spec:
 		appfile := testAppfile()
 		// remove parameter settings
 		appfile.Workloads[0].Params = nil
-		_, err := appfile.GenerateComponentManifests()
+		_, err := appfile.GenerateComponentManifests(context.Background())
 
 		expectError := errors.WithMessage(errors.New(`require parameter "image"`), "cannot resolve parameter settings")
 		diff := cmp.Diff(expectError, err, test.EquateErrors())
@@ -470,7 +474,7 @@ spec:
 				},
 			},
 		}
-		_, err := testAppfile.GenerateComponentManifests()
+		_, err := testAppfile.GenerateComponentManifests(context.Background())
 		Expect(err).Should(BeNil())
 		gotPolicies, err := testAppfile.PrepareWorkflowAndPolicy()
 		Expect(err).Should(BeNil())
@@ -623,7 +627,7 @@ variable "password" {
 			}(),
 		}
 
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(context.Background())
 		diff := cmp.Diff(comps[0], expectCompManifest)
 		Expect(diff).ShouldNot(BeEmpty())
 		Expect(err).Should(BeNil())
@@ -1071,6 +1075,12 @@ func TestGenerateTerraformConfigurationWorkload(t *testing.T) {
 }
 
 func TestGenerateCUETemplate(t *testing.T) {
+	helm.LocalChartAllowed = true
+	defer func() { helm.LocalChartAllowed = false }()
+	namespacedDM := mock.NewMockDiscoveryMapper()
+	namespacedDM.MockRESTMapping = func(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+		return &meta.RESTMapping{Scope: meta.RESTScopeNamespace}, nil
+	}
 
 	var testCorrectTemplate = func() runtime.RawExtension {
 		yamlStr := `apiVersion: apps/v1
@@ -1105,6 +1115,12 @@ spec:
 		return runtime.RawExtension{Raw: b}
 	}
 
+	testKustomize := &common.Kustomize{Source: common.KustomizeSource{Files: map[string]string{
+		"kustomization.yaml": "resources:\n- configmap.yaml\n- deployment.yaml",
+		"configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: nginx\ndata:\n  key: value",
+		"deployment.yaml":    "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: nginx\nspec:\n  replicas: 1",
+	}}}
+
 	testcases := map[string]struct {
 		workload   *Workload
 		expectData string
@@ -1254,10 +1270,189 @@ output: {
 		},
 		hasError: true,
 		errInfo:  "unexpected GroupVersion string: app@//v1",
+	}, "Helm workload in the native mode": {
+		workload: &Workload{
+			Name: "web",
+			FullTemplate: &Template{
+				Helm: &common.Helm{
+					Mode:       common.HelmModeNative,
+					LocalChart: "helm/testdata/charts/nginx",
+					Release:    runtime.RawExtension{Raw: []byte(`{"chart":{"spec":{"chart":"nginx"}}}`)},
+				},
+				Reference: common.WorkloadTypeDescriptor{
+					Definition: common.WorkloadGVK{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+					},
+				},
+			},
+			Params: map[string]interface{}{
+				"service": map[string]interface{}{"port": 8080},
+			},
+			CapabilityCategory: oamtypes.HelmCategory,
+		},
+		hasError:   false,
+		expectData: `
+output: {
+apiVersion: "apps/v1"
+kind:       "Deployment"
+metadata: {
+	name: "web"
+	labels: {
+		"app.kubernetes.io/name": "nginx"
+	}
+}
+spec: {
+	replicas: 1
+	selector: {
+		matchLabels: {
+			"app.kubernetes.io/name": "nginx"
+		}
+	}
+	template: {
+		metadata: {
+			labels: {
+				"app.kubernetes.io/name": "nginx"
+			}
+		}
+		spec: {
+			containers: [{
+				name:  "nginx"
+				image: "nginx:1.21.0"
+			}]
+		}
+	}
+}
+}
+outputs: {
+"configmap-web-config": {
+apiVersion: "v1"
+data: {
+	endpoint: "web.default.svc"
+}
+kind: "ConfigMap"
+metadata: {
+	name:      "web-config"
+	namespace: "default"
+}
+}
+"service-web": {
+apiVersion: "v1"
+kind:       "Service"
+metadata: {
+	name: "web"
+}
+spec: {
+	ports: [{
+		port: 8080
+	}]
+	selector: {
+		"app.kubernetes.io/name": "nginx"
+	}
+}
+}
+}`,
+	}, "Kustomize workload": {
+		workload: &Workload{
+			FullTemplate: &Template{
+				Kustomize: testKustomize,
+				Reference: common.WorkloadTypeDescriptor{
+					Definition: common.WorkloadGVK{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+					},
+				},
+			},
+			Params: map[string]interface{}{
+				"replicas": []interface{}{map[string]interface{}{"name": "nginx", "count": 2}},
+			},
+			CapabilityCategory: oamtypes.KustomizeCategory,
+		},
+		hasError:   false,
+		expectData: `
+output: {
+apiVersion: "apps/v1"
+kind:       "Deployment"
+metadata: {
+	name: "nginx"
+}
+spec: {
+	replicas: 2
+}
+}
+outputs: {
+"configmap-nginx": {
+apiVersion: "v1"
+data: {
+	key: "value"
+}
+kind: "ConfigMap"
+metadata: {
+	name: "nginx"
+}
+}
+}`,
+	}, "Kustomize workload without the resource of workload type": {
+		workload: &Workload{
+			FullTemplate: &Template{
+				Kustomize: testKustomize,
+				Reference: common.WorkloadTypeDescriptor{
+					Definition: common.WorkloadGVK{
+						APIVersion: "apps/v1",
+						Kind:       "StatefulSet",
+					},
+				},
+			},
+			CapabilityCategory: oamtypes.KustomizeCategory,
+		},
+		hasError: true,
+		errInfo:  "no resource of apps/v1 StatefulSet is rendered",
+	}, "K8s objects workload": {
+		workload: &Workload{
+			FullTemplate: &Template{
+				K8sObjects: &common.K8sObjects{},
+				Reference:  common.WorkloadTypeDescriptor{Type: oamtypes.AutoDetectWorkloadDefinition},
+			},
+			Params: map[string]interface{}{
+				"objects": []interface{}{
+					map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "nginx"}},
+					map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "nginx"},
+						"spec": map[string]interface{}{"template": map[string]interface{}{}}},
+				},
+				"overlays": []interface{}{map[string]interface{}{
+					"target":     map[string]interface{}{"kind": "Deployment"},
+					"jsonPath": map[string]interface{}{"{.spec.replicas}": 2},
+				}},
+			},
+			CapabilityCategory: oamtypes.K8sObjectsCategory,
+			dm:                 namespacedDM,
+		},
+		hasError: false,
+		expectData: `
+output: {
+apiVersion: "apps/v1"
+kind:       "Deployment"
+metadata: {
+	name: "nginx"
+}
+spec: {
+	replicas: 2
+	template: {}
+}
+}
+outputs: {
+"configmap-nginx": {
+apiVersion: "v1"
+kind:       "ConfigMap"
+metadata: {
+	name: "nginx"
+}
+}
+}`,
 	}}
 
 	for _, tc := range testcases {
-		template, err := GenerateCUETemplate(tc.workload)
+		template, err := GenerateCUETemplate(context.Background(), tc.workload, "default")
 		assert.Equal(t, err != nil, tc.hasError)
 		if tc.hasError {
 			assert.Equal(t, tc.errInfo, err.Error())
@@ -43,6 +43,9 @@ func ConstructHelmReleaseName(appName, compName string) string {
 
 // RenderHelmReleaseAndHelmRepo constructs HelmRelease and HelmRepository in unstructured format
 func RenderHelmReleaseAndHelmRepo(helmSpec *common.Helm, compName, appName, ns string, values map[string]interface{}) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	if helmSpec.LocalChart != "" {
+		return nil, nil, errors.New("local chart is only supported in the native mode")
+	}
 	releaseSpec, repoSpec, err := decodeHelmSpec(helmSpec)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "Helm spec is invalid")
@@ -66,15 +69,9 @@ func RenderHelmReleaseAndHelmRepo(helmSpec *common.Helm, compName, appName, ns s
 	helmRelease := commonutil.GenerateUnstructuredObj(rlsName, ns, helmapi.HelmReleaseGVK)
 
 	// construct HelmRelease chart values
-	chartValues := map[string]interface{}{}
-	if releaseSpec.Values != nil {
-		if err := json.Unmarshal(releaseSpec.Values.Raw, &chartValues); err != nil {
-			return nil, nil, errors.Wrap(err, "cannot get chart values")
-		}
-	}
-	for k, v := range values {
-		// override values with settings from application
-		chartValues[k] = v
+	chartValues, err := mergeChartValues(releaseSpec.Values, values)
+	if err != nil {
+		return nil, nil, err
 	}
 	if len(chartValues) > 0 {
 		// avoid an empty map
@@ -98,12 +95,30 @@ func RenderHelmReleaseAndHelmRepo(helmSpec *common.Helm, compName, appName, ns s
 	return helmRelease, helmRepo, nil
 }
 
+// mergeChartValues overrides the values of the release with settings from application
+func mergeChartValues(releaseValues *apiextensionsv1.JSON, values map[string]interface{}) (map[string]interface{}, error) {
+	chartValues := map[string]interface{}{}
+	if releaseValues != nil {
+		if err := json.Unmarshal(releaseValues.Raw, &chartValues); err != nil {
+			return nil, errors.Wrap(err, "cannot get chart values")
+		}
+	}
+	for k, v := range values {
+		chartValues[k] = v
+	}
+	return chartValues, nil
+}
+
 func decodeHelmSpec(h *common.Helm) (*helmapi.HelmReleaseSpec, *helmapi.HelmRepositorySpec, error) {
 	releaseSpec := &helmapi.HelmReleaseSpec{}
 	if err := json.Unmarshal(h.Release.Raw, releaseSpec); err != nil {
 		return nil, nil, errors.Wrap(err, "Helm release spec is invalid")
 	}
 	repoSpec := &helmapi.HelmRepositorySpec{}
+	// the repository is not required by a local chart
+	if len(h.Repository.Raw) == 0 && h.LocalChart != "" {
+		return releaseSpec, repoSpec, nil
+	}
 	if err := json.Unmarshal(h.Repository.Raw, repoSpec); err != nil {
 		return nil, nil, errors.Wrap(err, "Helm repository spec is invalid")
 	}
@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/utils/lru"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+// chartFilesCacheSize is the max number of the charts of Helm modules in the native mode cached in memory
+const chartFilesCacheSize = 64
+
+var chartFilesCache = lru.New(chartFilesCacheSize)
+
+// LocalChartAllowed specifies whether the chart of a Helm module can be loaded from the local file system, it should
+// only be enabled where the local file system is owned by the user, e.g. the CLI or the tests
+var LocalChartAllowed = false
+
+// IsNativeMode checks whether the chart of the Helm module is rendered into plain manifests by KubeVela
+func IsNativeMode(h *common.Helm) bool {
+	return h != nil && h.Mode == common.HelmModeNative
+}
+
+// RenderChart renders the chart of the Helm module into plain manifests in-process with the Helm SDK, like
+// `helm template`. The values of the release are overridden by the values from the application.
+// The release is named after the component unless the releaseName of the release is set. The release is in the
+// targetNamespace of the release if it's set, otherwise in the given namespace of the application, which is also the
+// .Release.Namespace of the templates. The resources without namespace are set to the targetNamespace if it's set,
+// otherwise they are dispatched to the namespace of the application.
+// The hooks of the chart are not rendered, neither are the CRDs in its crds directory, which should be installed
+// separately.
+func RenderChart(ctx context.Context, helmSpec *common.Helm, compName, namespace string, values map[string]interface{}) ([]*unstructured.Unstructured, error) {
+	releaseSpec, repoSpec, err := decodeHelmSpec(helmSpec)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Helm spec is invalid")
+	}
+	var ch *chart.Chart
+	if helmSpec.LocalChart != "" {
+		if !LocalChartAllowed {
+			return nil, errors.Errorf("local chart %q is not allowed", helmSpec.LocalChart)
+		}
+		if ch, err = loader.Load(helmSpec.LocalChart); err != nil {
+			return nil, errors.Wrapf(err, "cannot load local chart %s", helmSpec.LocalChart)
+		}
+	} else {
+		if repoSpec.URL == "" {
+			return nil, errors.New("Helm repository is not set")
+		}
+		files, err := loadCachedChartFiles(ctx, repoSpec.URL, releaseSpec.Chart.Spec.Chart, releaseSpec.Chart.Spec.Version)
+		if err != nil {
+			return nil, errors.WithMessage(err, "cannot load Chart files")
+		}
+		// the chart is loaded from the files every time as it's modified by the rendering
+		if ch, err = loader.LoadFiles(files); err != nil {
+			return nil, errors.Wrap(err, "cannot load Chart")
+		}
+	}
+	chartValues, err := mergeChartValues(releaseSpec.Values, values)
+	if err != nil {
+		return nil, err
+	}
+
+	install := action.NewInstall(&action.Configuration{Log: func(string, ...interface{}) {}})
+	install.DryRun = true
+	install.ClientOnly = true
+	install.Replace = true
+	install.DisableHooks = true
+	install.ReleaseName = releaseSpec.ReleaseName
+	if install.ReleaseName == "" {
+		install.ReleaseName = compName
+	}
+	install.Namespace = releaseSpec.TargetNamespace
+	if install.Namespace == "" {
+		install.Namespace = namespace
+	}
+	rls, err := install.Run(ch, chartValues)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot render chart %s", ch.Name())
+	}
+	objs, err := decodeManifests(rls.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if releaseSpec.TargetNamespace != "" {
+		for _, obj := range objs {
+			// the namespace is not set for the namespace resources, like the namespace of the application
+			if obj.GetNamespace() == "" && !(obj.GetAPIVersion() == "v1" && obj.GetKind() == "Namespace") {
+				obj.SetNamespace(releaseSpec.TargetNamespace)
+			}
+		}
+	}
+	return objs, nil
+}
+
+// loadCachedChartFiles loads the files of the chart from the repository, the charts are cached as they are rendered
+// every time the application is reconciled. If the version is not specified, the latest version is resolved from the
+// index of the repository, which is cached for ChartURLCacheTTL, and the chart is cached by the URL of its archive,
+// which is specific to the version.
+func loadCachedChartFiles(ctx context.Context, repoURL, chartName, version string) ([]*loader.BufferedFile, error) {
+	key := fmt.Sprintf("%s/%s:%s", repoURL, chartName, version)
+	var url string
+	if version == "" {
+		var err error
+		if url, err = findChartURL(ctx, repoURL, chartName, version); err != nil {
+			return nil, err
+		}
+		key = url
+	}
+	if files, ok := chartFilesCache.Get(key); ok {
+		return files.([]*loader.BufferedFile), nil
+	}
+	var files []*loader.BufferedFile
+	var err error
+	if url != "" {
+		files, err = fetchChartFiles(ctx, url)
+	} else {
+		files, err = loadChartFiles(ctx, repoURL, chartName, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	chartFilesCache.Add(key, files)
+	return files, nil
+}
+
+// decodeManifests decodes the multi-document YAML rendered by Helm, the order of the resources is kept
+func decodeManifests(manifests string) ([]*unstructured.Unstructured, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(manifests)))
+	var objs []*unstructured.Unstructured
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read rendered manifests")
+		}
+		raw, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot decode rendered manifest")
+		}
+		// skip the empty documents, e.g. the templates rendered conditionally
+		if len(strings.TrimSpace(string(raw))) == 0 || string(raw) == "null" {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw); err != nil {
+			return nil, errors.Wrap(err, "cannot decode rendered manifest")
+		}
+		objs = append(objs, obj)
+	}
+}
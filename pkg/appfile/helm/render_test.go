@@ -0,0 +1,235 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/repo"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+func TestRenderChart(t *testing.T) {
+	LocalChartAllowed = true
+	defer func() { LocalChartAllowed = false }()
+
+	h := &common.Helm{
+		Mode:       common.HelmModeNative,
+		LocalChart: "testdata/charts/nginx",
+		Release:    runtime.RawExtension{Raw: []byte(`{"chart":{"spec":{"chart":"nginx"}},"values":{"replicaCount":2}}`)},
+	}
+	objs, err := RenderChart(context.Background(), h, "web", "default", map[string]interface{}{
+		"image": map[string]interface{}{"repository": "nginx", "tag": "1.21.1"},
+	})
+	if err != nil {
+		t.Fatalf("want: nil, got: %v", err)
+	}
+	var got []string
+	for _, obj := range objs {
+		got = append(got, obj.GetKind()+"/"+obj.GetName())
+	}
+	// the test hook is not rendered
+	if diff := cmp.Diff([]string{"ConfigMap/web-config", "Service/web", "Deployment/web"}, got); diff != "" {
+		t.Errorf("\n%s\nRenderChart(...): -want, +got:\n%s\n", "rendered resources", diff)
+	}
+	replicas, _, _ := unstructured.NestedInt64(objs[2].Object, "spec", "replicas")
+	if replicas != 2 {
+		t.Errorf("want: 2 replicas from the values of the release, got: %d", replicas)
+	}
+	containers, _, _ := unstructured.NestedSlice(objs[2].Object, "spec", "template", "spec", "containers")
+	if image := containers[0].(map[string]interface{})["image"]; image != "nginx:1.21.1" {
+		t.Errorf("want: image nginx:1.21.1 from the values of the application, got: %v", image)
+	}
+	// the release is in the namespace of the application
+	if endpoint, _, _ := unstructured.NestedString(objs[0].Object, "data", "endpoint"); endpoint != "web.default.svc" {
+		t.Errorf("want: endpoint web.default.svc in the namespace of the application, got: %s", endpoint)
+	}
+
+	// the service is disabled conditionally, and the release name is specified
+	h.Release = runtime.RawExtension{Raw: []byte(`{"chart":{"spec":{"chart":"nginx"}},"releaseName":"nginx"}`)}
+	objs, err = RenderChart(context.Background(), h, "web", "default", map[string]interface{}{
+		"service": map[string]interface{}{"enabled": false},
+	})
+	if err != nil {
+		t.Fatalf("want: nil, got: %v", err)
+	}
+	if len(objs) != 2 || objs[1].GetName() != "nginx" {
+		t.Errorf("want: only the configmap and the deployment named nginx, got: %v", objs)
+	}
+	if ns := objs[1].GetNamespace(); ns != "" {
+		t.Errorf("want: the namespace of the application, got: %s", ns)
+	}
+
+	// the resources are set to the target namespace
+	h.Release = runtime.RawExtension{Raw: []byte(`{"chart":{"spec":{"chart":"nginx"}},"targetNamespace":"prod"}`)}
+	objs, err = RenderChart(context.Background(), h, "web", "default", nil)
+	if err != nil {
+		t.Fatalf("want: nil, got: %v", err)
+	}
+	for _, obj := range objs {
+		if obj.GetNamespace() != "prod" {
+			t.Errorf("want: %s/%s in the target namespace prod, got: %s", obj.GetKind(), obj.GetName(), obj.GetNamespace())
+		}
+	}
+	if endpoint, _, _ := unstructured.NestedString(objs[0].Object, "data", "endpoint"); endpoint != "web.prod.svc" {
+		t.Errorf("want: endpoint web.prod.svc in the target namespace, got: %s", endpoint)
+	}
+}
+
+func TestRenderChartErrors(t *testing.T) {
+	cases := map[string]struct {
+		helm    *common.Helm
+		wantErr string
+	}{
+		"local chart is not allowed": {
+			helm: &common.Helm{
+				Mode:       common.HelmModeNative,
+				LocalChart: "testdata/charts/nginx",
+				Release:    runtime.RawExtension{Raw: []byte(`{}`)},
+			},
+			wantErr: `local chart "testdata/charts/nginx" is not allowed`,
+		},
+		"repository is not set": {
+			helm: &common.Helm{
+				Mode:       common.HelmModeNative,
+				Release:    runtime.RawExtension{Raw: []byte(`{}`)},
+				Repository: runtime.RawExtension{Raw: []byte(`{}`)},
+			},
+			wantErr: "Helm repository is not set",
+		},
+	}
+	for name, tc := range cases {
+		_, err := RenderChart(context.Background(), tc.helm, "web", "default", nil)
+		if err == nil || err.Error() != tc.wantErr {
+			t.Errorf("\n%s\nRenderChart(...): want error: %s, got: %v\n", name, tc.wantErr, err)
+		}
+	}
+
+	_, _, err := RenderHelmReleaseAndHelmRepo(&common.Helm{LocalChart: "testdata/charts/nginx"}, "web", "app", "default", nil)
+	if err == nil || err.Error() != "local chart is only supported in the native mode" {
+		t.Errorf("RenderHelmReleaseAndHelmRepo(...): want error of local chart, got: %v", err)
+	}
+}
+
+func TestLoadCachedChartFilesOfLatestVersion(t *testing.T) {
+	dir := t.TempDir()
+	ch, err := loader.Load("testdata/charts/nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := chartutil.Save(ch, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetched, indexFetched int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			atomic.AddInt32(&indexFetched, 1)
+			index := repo.NewIndexFile()
+			if err := index.MustAdd(ch.Metadata, filepath.Base(archive), server.URL, ""); err != nil {
+				t.Error(err)
+			}
+			indexFile := filepath.Join(dir, "index.yaml")
+			if err := index.WriteFile(indexFile, 0600); err != nil {
+				t.Error(err)
+			}
+			http.ServeFile(w, r, indexFile)
+		case "/" + filepath.Base(archive):
+			atomic.AddInt32(&fetched, 1)
+			http.ServeFile(w, r, archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// the chart without version is cached by the URL of the latest version
+	for i := 0; i < 2; i++ {
+		files, err := loadCachedChartFiles(context.Background(), server.URL, "nginx", "")
+		if err != nil {
+			t.Fatalf("want: nil, got: %v", err)
+		}
+		if len(files) == 0 {
+			t.Errorf("want: the files of the chart, got none")
+		}
+	}
+	if fetched := atomic.LoadInt32(&fetched); fetched != 1 {
+		t.Errorf("want: the chart is fetched once, got: %d", fetched)
+	}
+	// the URL of the latest version is cached until it expires
+	if indexFetched := atomic.LoadInt32(&indexFetched); indexFetched != 1 {
+		t.Errorf("want: the index is fetched once, got: %d", indexFetched)
+	}
+	key := server.URL + "/nginx:"
+	cached, _ := chartURLCache.Get(key)
+	chartURLCache.Add(key, chartURLEntry{url: cached.(chartURLEntry).url, expireAt: time.Now().Add(-time.Second)})
+	if _, err := loadCachedChartFiles(context.Background(), server.URL, "nginx", ""); err != nil {
+		t.Fatalf("want: nil, got: %v", err)
+	}
+	if indexFetched := atomic.LoadInt32(&indexFetched); indexFetched != 2 {
+		t.Errorf("want: the index is fetched again once the URL expires, got: %d", indexFetched)
+	}
+
+	if _, err := loadCachedChartFiles(context.Background(), server.URL, "redis", ""); err == nil ||
+		err.Error() != fmt.Sprintf(`chart "redis" not found in %s repository`, server.URL) {
+		t.Errorf("want: error of chart not found, got: %v", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	defer func(size int64) { MaxChartSize = size }(MaxChartSize)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("apiVersion: v1\nentries: {}\n"))
+	}))
+	defer server.Close()
+
+	data, err := fetch(context.Background(), server.URL+"/index.yaml")
+	if err != nil || len(data) == 0 {
+		t.Errorf("want: the index, got: %s, %v", data, err)
+	}
+	if _, err := fetch(context.Background(), server.URL+"/nginx-0.1.0.tgz"); err == nil || err.Error() != "unexpected status 404 Not Found" {
+		t.Errorf("want: error of the status, got: %v", err)
+	}
+	MaxChartSize = 8
+	if _, err := fetch(context.Background(), server.URL+"/index.yaml"); err == nil || err.Error() != "the content exceeds the max size of 8 bytes" {
+		t.Errorf("want: error of the max size, got: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fetch(ctx, server.URL+"/index.yaml"); err == nil {
+		t.Errorf("want: error of the canceled context, got: nil")
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/repo"
+	"k8s.io/utils/lru"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// FetchTimeout is the max duration of fetching the index of a repository or a chart archive
+	FetchTimeout = time.Minute
+	// MaxChartSize is the max size in bytes of the index of a repository or a chart archive fetched for a Helm
+	// module, zero means no limit
+	MaxChartSize int64 = 16 * 1024 * 1024
+	// ChartURLCacheTTL is how long the URL of a chart resolved from the index of the repository is cached, so the
+	// index is not fetched every time a chart without version is rendered
+	ChartURLCacheTTL = 5 * time.Minute
+)
+
+// chartURLCacheSize is the max number of the resolved chart URLs cached in memory
+const chartURLCacheSize = 256
+
+var chartURLCache = lru.New(chartURLCacheSize)
+
+type chartURLEntry struct {
+	url      string
+	expireAt time.Time
+}
+
+// findChartURL finds the URL of the chart archive in the index of the repository, the latest version is found if
+// the version is not specified. The URL is cached for ChartURLCacheTTL.
+func findChartURL(ctx context.Context, repoURL, chart, version string) (string, error) {
+	key := fmt.Sprintf("%s/%s:%s", repoURL, chart, version)
+	if cached, ok := chartURLCache.Get(key); ok {
+		if entry := cached.(chartURLEntry); time.Now().Before(entry.expireAt) {
+			return entry.url, nil
+		}
+		chartURLCache.Remove(key)
+	}
+	data, err := fetch(ctx, strings.TrimSuffix(repoURL, "/")+"/index.yaml")
+	if err != nil {
+		return "", errors.WithMessagef(err, "cannot fetch the index of repository %s", repoURL)
+	}
+	index := &repo.IndexFile{}
+	if err := yaml.Unmarshal(data, index); err != nil {
+		return "", errors.Wrapf(err, "cannot decode the index of repository %s", repoURL)
+	}
+	index.SortEntries()
+	cv, err := index.Get(chart, version)
+	if err != nil {
+		if version != "" {
+			return "", errors.Errorf("chart %q version %q not found in %s repository", chart, version, repoURL)
+		}
+		return "", errors.Errorf("chart %q not found in %s repository", chart, repoURL)
+	}
+	if len(cv.URLs) == 0 {
+		return "", errors.Errorf("chart %q version %q has no downloadable URLs", chart, cv.Version)
+	}
+	url, err := repo.ResolveReferenceURL(repoURL, cv.URLs[0])
+	if err != nil {
+		return "", errors.Wrap(err, "cannot find Chart URL")
+	}
+	if ChartURLCacheTTL > 0 {
+		chartURLCache.Add(key, chartURLEntry{url: url, expireAt: time.Now().Add(ChartURLCacheTTL)})
+	}
+	return url, nil
+}
+
+func fetchChartFiles(ctx context.Context, url string) ([]*loader.BufferedFile, error) {
+	data, err := fetch(ctx, url)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "cannot fetch Chart from remote URL:%s", url)
+	}
+	files, err := loader.LoadArchiveFiles(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load Chart files")
+	}
+	return files, nil
+}
+
+// fetch gets the content of the URL within FetchTimeout, the content larger than MaxChartSize is rejected
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	if FetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, FetchTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s", resp.Status)
+	}
+	if MaxChartSize <= 0 {
+		return ioutil.ReadAll(resp.Body)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxChartSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > MaxChartSize {
+		return nil, errors.Errorf("the content exceeds the max size of %d bytes", MaxChartSize)
+	}
+	return data, nil
+}
@@ -21,7 +21,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/format"
@@ -30,21 +29,10 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/chart/loader"
-	"helm.sh/helm/v3/pkg/getter"
-	"helm.sh/helm/v3/pkg/repo"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 )
 
-var (
-	getters = getter.Providers{
-		getter.Provider{
-			Schemes: []string{"http", "https"},
-			New:     getter.NewHTTPGetter,
-		},
-	}
-)
-
 // GetChartValuesJSONSchema fetched the Chart bundle and get JSON schema of Values
 // file.  If the Chart provides a 'values.json.schema' file, use it directly.
 // Otherwise, try to generate a JSON schema based on the Values file.
@@ -53,10 +41,21 @@ func GetChartValuesJSONSchema(ctx context.Context, h *common.Helm) ([]byte, erro
 	if err != nil {
 		return nil, errors.WithMessage(err, "Helm spec is invalid")
 	}
-	chartSpec := releaseSpec.Chart.Spec
-	files, err := loadChartFiles(ctx, repoSpec.URL, chartSpec.Chart, chartSpec.Version)
-	if err != nil {
-		return nil, errors.WithMessage(err, "cannot load Chart files")
+	var files []*loader.BufferedFile
+	if h.LocalChart != "" && LocalChartAllowed {
+		ch, err := loader.Load(h.LocalChart)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot load local chart %s", h.LocalChart)
+		}
+		for _, f := range ch.Raw {
+			files = append(files, &loader.BufferedFile{Name: f.Name, Data: f.Data})
+		}
+	} else {
+		chartSpec := releaseSpec.Chart.Spec
+		files, err = loadChartFiles(ctx, repoSpec.URL, chartSpec.Chart, chartSpec.Version)
+		if err != nil {
+			return nil, errors.WithMessage(err, "cannot load Chart files")
+		}
 	}
 	var values *loader.BufferedFile
 	for _, f := range files {
@@ -137,25 +136,11 @@ func generateSchemaFromValues(values []byte) ([]byte, error) {
 }
 
 func loadChartFiles(ctx context.Context, repoURL, chart, version string) ([]*loader.BufferedFile, error) {
-	url, err := repo.FindChartInRepoURL(repoURL, chart, version, "", "", "", getters)
-	if err != nil {
-		return nil, errors.Wrap(err, "cannot find Chart URL")
-	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	url, err := findChartURL(ctx, repoURL, chart, version)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, errors.Wrapf(err, "cannot fetch Chart from remote URL:%s", url)
-	}
-	//nolint:errcheck
-	defer resp.Body.Close()
-	files, err := loader.LoadArchiveFiles(resp.Body)
-	if err != nil {
-		return nil, errors.Wrap(err, "cannot load Chart files")
-	}
-	return files, nil
+	return fetchChartFiles(ctx, url)
 }
 
 // cue openapi encoder converts default in Chart Values as enum in schema
@@ -21,10 +21,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 )
 
 func TestGenerateSchemaFromValues(t *testing.T) {
@@ -74,6 +78,32 @@ func TestGetChartValuesJSONSchema(t *testing.T) {
 	}
 }
 
+func TestGetChartValuesJSONSchemaOfLocalChart(t *testing.T) {
+	LocalChartAllowed = true
+	defer func() { LocalChartAllowed = false }()
+	testHelm := &common.Helm{
+		Mode:       common.HelmModeNative,
+		LocalChart: "testdata/charts/nginx",
+		Release:    runtime.RawExtension{Raw: []byte(`{"chart":{"spec":{"chart":"nginx"}}}`)},
+	}
+	result, err := GetChartValuesJSONSchema(context.Background(), testHelm)
+	if err != nil {
+		t.Fatal(err, "failed get schema")
+	}
+	schema := &openapi3.Schema{}
+	if err := schema.UnmarshalJSON(result); err != nil {
+		t.Fatal(err, "cannot unmarshal result bytes")
+	}
+	var got []string
+	for name := range schema.Properties {
+		got = append(got, name)
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff([]string{"image", "replicaCount", "service"}, got); diff != "" {
+		t.Fatalf("\nGetChartValuesJSONSchema(...)(...) -want +get \n%s", diff)
+	}
+}
+
 func TestChangeEnumToDefault(t *testing.T) {
 	// testData contains object, string, integer, bool, and array type fields
 	// with enum and required values
@@ -0,0 +1,282 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sobjects
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/third_party/forked/golang/template"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// setJSONPath sets the value of the fields matching the JSONPath, e.g. {.spec.template.spec.containers[0].image} or
+// {.spec.template.spec.containers[?(@.name=="web")].image}. The braces and the leading $ are optional. The missing
+// objects on the path are created, the arrays are not. It supports the fields, the array indexes and slices, the
+// wildcards and the filters of JSONPath, it fails if no field matches.
+func setJSONPath(obj map[string]interface{}, path string, value interface{}) error {
+	expr := strings.TrimSpace(path)
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	parser, err := jsonpath.Parse("overlay", expr)
+	if err != nil {
+		return errors.Wrap(err, "invalid JSONPath")
+	}
+	if len(parser.Root.Nodes) != 1 {
+		return errors.New("invalid JSONPath: it must be a single expression")
+	}
+	list, ok := parser.Root.Nodes[0].(*jsonpath.ListNode)
+	if !ok || len(list.Nodes) == 0 {
+		return errors.New("invalid JSONPath: no field is specified")
+	}
+	_, matched, err := setNodes(obj, list.Nodes, value)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return errors.New("no field matches the JSONPath")
+	}
+	return nil
+}
+
+// setNodes sets the value of the fields matching the nodes under cur, it returns cur which is created if it's a
+// missing object, and the number of the fields set
+func setNodes(cur interface{}, nodes []jsonpath.Node, value interface{}) (interface{}, int, error) {
+	rest := nodes[1:]
+	set := func(v interface{}) (interface{}, int, error) {
+		if len(rest) == 0 {
+			return value, 1, nil
+		}
+		return setNodes(v, rest, value)
+	}
+	switch node := nodes[0].(type) {
+	case *jsonpath.FieldNode:
+		if cur == nil {
+			cur = map[string]interface{}{}
+		}
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return cur, 0, errors.Errorf("cannot get field %q of a non-object", node.Value)
+		}
+		v, matched, err := set(m[node.Value])
+		if err != nil || matched == 0 {
+			return cur, 0, err
+		}
+		m[node.Value] = v
+		return m, matched, nil
+	case *jsonpath.ArrayNode:
+		s, ok := cur.([]interface{})
+		if !ok {
+			return cur, 0, nil
+		}
+		indexes, err := arrayIndexes(node, len(s))
+		if err != nil {
+			return cur, 0, err
+		}
+		return setElements(s, indexes, set)
+	case *jsonpath.FilterNode:
+		s, ok := cur.([]interface{})
+		if !ok {
+			return cur, 0, nil
+		}
+		var indexes []int
+		for i, elem := range s {
+			pass, err := evalFilter(elem, node)
+			if err != nil {
+				return cur, 0, err
+			}
+			if pass {
+				indexes = append(indexes, i)
+			}
+		}
+		return setElements(s, indexes, set)
+	case *jsonpath.WildcardNode:
+		switch c := cur.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(c))
+			for k := range c {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			total := 0
+			for _, k := range keys {
+				v, matched, err := set(c[k])
+				if err != nil {
+					return cur, 0, err
+				}
+				if matched > 0 {
+					c[k] = v
+					total += matched
+				}
+			}
+			return c, total, nil
+		case []interface{}:
+			indexes := make([]int, len(c))
+			for i := range c {
+				indexes[i] = i
+			}
+			return setElements(c, indexes, set)
+		default:
+			return cur, 0, nil
+		}
+	default:
+		return cur, 0, errors.Errorf("%s is not supported in the JSONPath of overlays", node.Type())
+	}
+}
+
+func setElements(s []interface{}, indexes []int, set func(interface{}) (interface{}, int, error)) (interface{}, int, error) {
+	total := 0
+	for _, i := range indexes {
+		v, matched, err := set(s[i])
+		if err != nil {
+			return s, 0, err
+		}
+		if matched > 0 {
+			s[i] = v
+			total += matched
+		}
+	}
+	return s, total, nil
+}
+
+// arrayIndexes returns the indexes selected by the array node like JSONPath does, e.g. [0], [-1], [1:3] or [*]
+func arrayIndexes(node *jsonpath.ArrayNode, length int) ([]int, error) {
+	params := node.Params
+	if !params[0].Known {
+		params[0].Value = 0
+	}
+	if params[0].Value < 0 {
+		params[0].Value += length
+	}
+	if !params[1].Known {
+		params[1].Value = length
+	}
+	if params[1].Value < 0 || (params[1].Value == 0 && params[1].Derived) {
+		params[1].Value += length
+	}
+	if params[1].Value == params[0].Value {
+		return nil, nil
+	}
+	if params[0].Value >= length || params[0].Value < 0 {
+		return nil, errors.Errorf("array index out of bounds: index %d, length %d", params[0].Value, length)
+	}
+	if params[1].Value > length || params[1].Value < 0 {
+		return nil, errors.Errorf("array index out of bounds: index %d, length %d", params[1].Value-1, length)
+	}
+	if params[0].Value > params[1].Value {
+		return nil, errors.Errorf("starting index %d is greater than ending index %d", params[0].Value, params[1].Value)
+	}
+	step := 1
+	if params[2].Known {
+		if params[2].Value <= 0 {
+			return nil, errors.New("step must be > 0")
+		}
+		step = params[2].Value
+	}
+	var indexes []int
+	for i := params[0].Value; i < params[1].Value; i += step {
+		indexes = append(indexes, i)
+	}
+	return indexes, nil
+}
+
+// evalFilter checks whether the element passes the filter, it compares the values like JSONPath does
+func evalFilter(elem interface{}, node *jsonpath.FilterNode) (bool, error) {
+	lefts, err := evalOperand(elem, node.Left)
+	if err != nil {
+		return false, err
+	}
+	if node.Operator == "exists" {
+		return len(lefts) > 0, nil
+	}
+	rights, err := evalOperand(elem, node.Right)
+	if err != nil {
+		return false, err
+	}
+	if len(lefts) == 0 || len(rights) == 0 {
+		return false, nil
+	}
+	if len(lefts) > 1 || len(rights) > 1 {
+		return false, errors.New("can only compare one element at a time")
+	}
+	left, right := lefts[0], rights[0]
+	switch node.Operator {
+	case "<":
+		return template.Less(left, right)
+	case ">":
+		return template.Greater(left, right)
+	case "==":
+		return template.Equal(left, right)
+	case "!=":
+		return template.NotEqual(left, right)
+	case "<=":
+		return template.LessEqual(left, right)
+	case ">=":
+		return template.GreaterEqual(left, right)
+	default:
+		return false, errors.Errorf("unrecognized filter operator %s", node.Operator)
+	}
+}
+
+// evalOperand evaluates the operand of a filter on the element, i.e. a literal or the fields of the element
+func evalOperand(elem interface{}, list *jsonpath.ListNode) ([]interface{}, error) {
+	if list == nil {
+		return nil, nil
+	}
+	cur := []interface{}{elem}
+	for _, n := range list.Nodes {
+		var next []interface{}
+		switch node := n.(type) {
+		case *jsonpath.TextNode:
+			next = []interface{}{node.Text}
+		case *jsonpath.IntNode:
+			next = []interface{}{node.Value}
+		case *jsonpath.FloatNode:
+			next = []interface{}{node.Value}
+		case *jsonpath.BoolNode:
+			next = []interface{}{node.Value}
+		case *jsonpath.FieldNode:
+			for _, c := range cur {
+				if m, ok := c.(map[string]interface{}); ok {
+					if v, ok := m[node.Value]; ok {
+						next = append(next, v)
+					}
+				}
+			}
+		case *jsonpath.ArrayNode:
+			for _, c := range cur {
+				s, ok := c.([]interface{})
+				if !ok {
+					continue
+				}
+				indexes, err := arrayIndexes(node, len(s))
+				if err != nil {
+					return nil, err
+				}
+				for _, i := range indexes {
+					next = append(next, s[i])
+				}
+			}
+		default:
+			return nil, errors.Errorf("%s is not supported in the filters of overlays", node.Type())
+		}
+		cur = next
+	}
+	return cur, nil
+}
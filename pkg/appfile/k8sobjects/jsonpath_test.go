@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sobjects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSetJSONPath(t *testing.T) {
+	newDeployment := func() map[string]interface{} {
+		return map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "web", "image": "nginx", "ports": []interface{}{map[string]interface{}{"containerPort": int64(80)}}},
+							map[string]interface{}{"name": "sidecar", "image": "busybox"},
+						},
+					},
+				},
+			},
+		}
+	}
+	images := func(obj map[string]interface{}) []interface{} {
+		var got []interface{}
+		for _, c := range obj["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{}) {
+			got = append(got, c.(map[string]interface{})["image"])
+		}
+		return got
+	}
+
+	cases := map[string]struct {
+		path    string
+		value   interface{}
+		check   func(t *testing.T, obj map[string]interface{})
+		wantErr string
+	}{
+		"create the missing objects": {
+			path:  "{.metadata.labels.app}",
+			value: "web",
+			check: func(t *testing.T, obj map[string]interface{}) {
+				assert.Equal(t, map[string]interface{}{"labels": map[string]interface{}{"app": "web"}}, obj["metadata"])
+			},
+		},
+		"without braces": {
+			path:  "$.spec.replicas",
+			value: int64(3),
+			check: func(t *testing.T, obj map[string]interface{}) {
+				assert.Equal(t, int64(3), obj["spec"].(map[string]interface{})["replicas"])
+			},
+		},
+		"index": {
+			path:  "{.spec.template.spec.containers[-1].image}",
+			value: "busybox:1.34",
+			check: func(t *testing.T, obj map[string]interface{}) {
+				assert.Equal(t, []interface{}{"nginx", "busybox:1.34"}, images(obj))
+			},
+		},
+		"wildcard": {
+			path:  "{.spec.template.spec.containers[*].imagePullPolicy}",
+			value: "Always",
+			check: func(t *testing.T, obj map[string]interface{}) {
+				for _, c := range obj["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{}) {
+					assert.Equal(t, "Always", c.(map[string]interface{})["imagePullPolicy"])
+				}
+			},
+		},
+		"filter by name": {
+			path:  `{.spec.template.spec.containers[?(@.name=="web")].image}`,
+			value: "nginx:1.21",
+			check: func(t *testing.T, obj map[string]interface{}) {
+				assert.Equal(t, []interface{}{"nginx:1.21", "busybox"}, images(obj))
+			},
+		},
+		"filter by nested field": {
+			path:  `{.spec.template.spec.containers[?(@.ports[0].containerPort==80)].image}`,
+			value: "nginx:1.21",
+			check: func(t *testing.T, obj map[string]interface{}) {
+				assert.Equal(t, []interface{}{"nginx:1.21", "busybox"}, images(obj))
+			},
+		},
+		"filter by existence": {
+			path:  `{.spec.template.spec.containers[?(@.ports)].ports[0].containerPort}`,
+			value: int64(8080),
+			check: func(t *testing.T, obj map[string]interface{}) {
+				containers := obj["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+				ports := containers[0].(map[string]interface{})["ports"].([]interface{})
+				assert.Equal(t, int64(8080), ports[0].(map[string]interface{})["containerPort"])
+			},
+		},
+		"replace the matched element": {
+			path:  `{.spec.template.spec.containers[?(@.name=="sidecar")]}`,
+			value: map[string]interface{}{"name": "sidecar", "image": "envoy"},
+			check: func(t *testing.T, obj map[string]interface{}) {
+				assert.Equal(t, []interface{}{"nginx", "envoy"}, images(obj))
+			},
+		},
+		"no field matches": {
+			path:    `{.spec.template.spec.containers[?(@.name=="api")].image}`,
+			value:   "api",
+			wantErr: "no field matches the JSONPath",
+		},
+		"index out of bounds": {
+			path:    "{.spec.template.spec.containers[2].image}",
+			value:   "api",
+			wantErr: "array index out of bounds: index 2, length 2",
+		},
+		"field of non-object": {
+			path:    "{.spec.template.spec.containers.image}",
+			value:   "api",
+			wantErr: `cannot get field "image" of a non-object`,
+		},
+		"recursive descent": {
+			path:    "{..image}",
+			value:   "api",
+			wantErr: "NodeRecursive is not supported in the JSONPath of overlays",
+		},
+		"invalid JSONPath": {
+			path:    "{.spec[}",
+			value:   "api",
+			wantErr: "invalid JSONPath",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			obj := newDeployment()
+			err := setJSONPath(obj, tc.path, tc.value)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, obj)
+			// the object is still a valid unstructured object
+			assert.NotPanics(t, func() { runtime.DeepCopyJSON(obj) })
+		})
+	}
+}
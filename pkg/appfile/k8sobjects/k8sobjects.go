@@ -0,0 +1,276 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sobjects
+
+import (
+	"encoding/json"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+)
+
+var k8sScheme = runtime.NewScheme()
+
+func init() {
+	_ = clientgoscheme.AddToScheme(k8sScheme)
+}
+
+// Parameters are the properties of a component using the k8s-objects schematic
+type Parameters struct {
+	// Objects are the raw Kubernetes objects appended to the objects of the definition
+	Objects []json.RawMessage `json:"objects,omitempty"`
+	// Overlays are applied to the objects in order
+	Overlays []Overlay `json:"overlays,omitempty"`
+	// Workload selects the object which is the workload of the component, i.e. the object patched by the traits
+	Workload *Selector `json:"workload,omitempty"`
+}
+
+// Overlay patches the objects matching the target, the JSONPath values are set after the strategic merge patch
+// is applied
+type Overlay struct {
+	// Target selects the objects to patch, all the objects are patched if it's not specified
+	Target *Selector `json:"target,omitempty"`
+	// JSONPath sets the values of the fields keyed by their JSONPath, e.g. {.spec.template.spec.containers[0].image}
+	// or {.spec.template.spec.containers[?(@.name=="web")].image}
+	JSONPath map[string]json.RawMessage `json:"jsonPath,omitempty"`
+	// StrategicMerge is a strategic merge patch, it's applied as a JSON merge patch to the custom resources
+	StrategicMerge json.RawMessage `json:"strategicMerge,omitempty"`
+}
+
+// Selector selects objects by apiVersion, kind and name, the empty fields match any object
+type Selector struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+}
+
+// Matches checks whether the object is selected
+func (s *Selector) Matches(obj *unstructured.Unstructured) bool {
+	return (s.APIVersion == "" || s.APIVersion == obj.GetAPIVersion()) &&
+		(s.Kind == "" || s.Kind == obj.GetKind()) &&
+		(s.Name == "" || s.Name == obj.GetName())
+}
+
+// Render decodes the objects of the schematic and the component, applies the overlays and returns the objects
+// with the index of the workload. The workload is the first object matching the workload selector of the component,
+// or the first object of the workload type of the definition. If neither of them is specified, it's the first
+// object with a pod template, or the first object if there is none.
+// The namespace of the namespaced objects is cleared so that they are created in the namespace of the application,
+// the cluster-scoped objects are rejected unless their kinds are allowed by the schematic.
+func Render(spec *common.K8sObjects, params map[string]interface{}, wlGVK common.WorkloadGVK, dm discoverymapper.DiscoveryMapper) ([]*unstructured.Unstructured, int, error) {
+	if spec == nil {
+		return nil, -1, errors.New("k8s-objects schematic is not set")
+	}
+	var p Parameters
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return nil, -1, errors.Wrap(err, "cannot marshal k8s-objects parameters")
+		}
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, -1, errors.Wrap(err, "invalid k8s-objects parameters")
+		}
+	}
+
+	raws := make([][]byte, 0, len(spec.Objects)+len(p.Objects))
+	for _, o := range spec.Objects {
+		raws = append(raws, o.Raw)
+	}
+	for _, o := range p.Objects {
+		raws = append(raws, o)
+	}
+	if len(raws) == 0 {
+		return nil, -1, errors.New("no object is specified")
+	}
+	objs := make([]*unstructured.Unstructured, 0, len(raws))
+	for i, raw := range raws {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw); err != nil {
+			return nil, -1, errors.Wrapf(err, "cannot decode object %d", i)
+		}
+		objs = append(objs, obj)
+	}
+
+	for i, overlay := range p.Overlays {
+		if err := applyOverlay(objs, overlay); err != nil {
+			return nil, -1, errors.WithMessagef(err, "cannot apply overlay %d", i)
+		}
+	}
+
+	if err := scopeObjects(objs, spec.AllowedClusterScopedKinds, dm); err != nil {
+		return nil, -1, err
+	}
+
+	workloadIdx, err := findWorkload(objs, p.Workload, wlGVK)
+	if err != nil {
+		return nil, -1, err
+	}
+	return objs, workloadIdx, nil
+}
+
+// scopeObjects clears the namespace of the namespaced objects and checks the kinds of the cluster-scoped objects
+// are allowed. It's checked after the overlays are applied as they could change the kinds.
+func scopeObjects(objs []*unstructured.Unstructured, allowedClusterScopedKinds []string, dm discoverymapper.DiscoveryMapper) error {
+	if dm == nil {
+		return errors.New("cannot check the scope of the objects without discovery mapper")
+	}
+	allowed := make(map[schema.GroupKind]bool, len(allowedClusterScopedKinds))
+	for _, k := range allowedClusterScopedKinds {
+		allowed[schema.ParseGroupKind(k)] = true
+	}
+	for _, obj := range objs {
+		gk := obj.GroupVersionKind().GroupKind()
+		namespaced, err := discoverymapper.IsNamespacedScope(dm, gk)
+		if err != nil {
+			return errors.WithMessagef(err, "%s %s", obj.GetKind(), obj.GetName())
+		}
+		if namespaced {
+			obj.SetNamespace("")
+			continue
+		}
+		if !allowed[gk] {
+			return errors.Errorf("cluster-scoped %s %s is not allowed by the definition", gk.String(), obj.GetName())
+		}
+	}
+	return nil
+}
+
+func findWorkload(objs []*unstructured.Unstructured, selector *Selector, wlGVK common.WorkloadGVK) (int, error) {
+	switch {
+	case selector != nil:
+		for i, obj := range objs {
+			if selector.Matches(obj) {
+				return i, nil
+			}
+		}
+		return -1, errors.Errorf("no object matches the workload selector %+v", *selector)
+	case wlGVK.Kind != "":
+		for i, obj := range objs {
+			if obj.GetAPIVersion() == wlGVK.APIVersion && obj.GetKind() == wlGVK.Kind {
+				return i, nil
+			}
+		}
+		return -1, errors.Errorf("no object of %s %s is specified", wlGVK.APIVersion, wlGVK.Kind)
+	}
+	for i, obj := range objs {
+		if hasPodTemplate(obj) {
+			return i, nil
+		}
+	}
+	return 0, nil
+}
+
+// hasPodTemplate checks whether the object runs pods, e.g. a Deployment, a CronJob or a Pod itself
+func hasPodTemplate(obj *unstructured.Unstructured) bool {
+	if obj.GetAPIVersion() == "v1" && obj.GetKind() == "Pod" {
+		return true
+	}
+	for _, fields := range [][]string{{"spec", "template"}, {"spec", "jobTemplate"}} {
+		if _, found, _ := unstructured.NestedMap(obj.Object, fields...); found {
+			return true
+		}
+	}
+	return false
+}
+
+func applyOverlay(objs []*unstructured.Unstructured, overlay Overlay) error {
+	matched := false
+	for _, obj := range objs {
+		if overlay.Target != nil && !overlay.Target.Matches(obj) {
+			continue
+		}
+		matched = true
+		if len(overlay.StrategicMerge) > 0 {
+			if err := strategicMerge(obj, overlay.StrategicMerge); err != nil {
+				return errors.WithMessagef(err, "cannot patch %s %s", obj.GetKind(), obj.GetName())
+			}
+		}
+		if err := setJSONPathValues(obj, overlay.JSONPath); err != nil {
+			return errors.WithMessagef(err, "cannot patch %s %s", obj.GetKind(), obj.GetName())
+		}
+	}
+	if !matched {
+		return errors.Errorf("no object matches the target %+v", *overlay.Target)
+	}
+	return nil
+}
+
+// strategicMerge applies the patch to the object, the custom resources are patched as JSON merge patch as the
+// strategic merge patch doesn't support them
+func strategicMerge(obj *unstructured.Unstructured, patch []byte) error {
+	versionedObject, err := k8sScheme.New(obj.GroupVersionKind())
+	switch {
+	case runtime.IsNotRegisteredError(err):
+		original, err := obj.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		patched, err := jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return errors.Wrap(err, "cannot apply JSON merge patch")
+		}
+		return obj.UnmarshalJSON(patched)
+	case err != nil:
+		return err
+	}
+	lookupPatchMeta, err := strategicpatch.NewPatchMetaFromStruct(versionedObject)
+	if err != nil {
+		return err
+	}
+	// the numbers are decoded into int64 like the object
+	patchMap := map[string]interface{}{}
+	if err := utiljson.Unmarshal(patch, &patchMap); err != nil {
+		return errors.Wrap(err, "invalid strategic merge patch")
+	}
+	patched, err := strategicpatch.StrategicMergeMapPatchUsingLookupPatchMeta(obj.Object, patchMap, lookupPatchMeta)
+	if err != nil {
+		return errors.Wrap(err, "cannot apply strategic merge patch")
+	}
+	obj.Object = patched
+	return nil
+}
+
+// setJSONPathValues sets the values of the fields, the paths are sorted so that the values are set in a
+// deterministic order
+func setJSONPathValues(obj *unstructured.Unstructured, values map[string]json.RawMessage) error {
+	paths := make([]string, 0, len(values))
+	for p := range values {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		// the numbers are decoded into int64 like the object
+		var value []interface{}
+		if err := utiljson.Unmarshal([]byte("["+string(values[p])+"]"), &value); err != nil {
+			return errors.Wrapf(err, "invalid value of %q", p)
+		}
+		if err := setJSONPath(obj.Object, p, value[0]); err != nil {
+			return errors.Wrapf(err, "cannot set the value of %q", p)
+		}
+	}
+	return nil
+}
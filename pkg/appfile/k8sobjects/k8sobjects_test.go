@@ -0,0 +1,229 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sobjects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/pkg/oam/mock"
+)
+
+func TestRender(t *testing.T) {
+	configMap := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "config"},
+		"data":       map[string]interface{}{"key": "value"},
+	}
+	deployment := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "kube-system"},
+		"spec": map[string]interface{}{
+			"replicas": 1,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "nginx:1.20"},
+						map[string]interface{}{"name": "sidecar", "image": "busybox"},
+					},
+				},
+			},
+		},
+	}
+	crontab := map[string]interface{}{
+		"apiVersion": "stable.example.com/v1",
+		"kind":       "CronTab",
+		"metadata":   map[string]interface{}{"name": "crontab"},
+		"spec":       map[string]interface{}{"cronSpec": "* * * * */5", "image": "crontab:v1"},
+	}
+	objects := []interface{}{configMap, deployment, crontab}
+	clusterRole := map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRole",
+		"metadata":   map[string]interface{}{"name": "reader"},
+	}
+
+	cases := map[string]struct {
+		spec     *common.K8sObjects
+		params   map[string]interface{}
+		wlGVK    common.WorkloadGVK
+		check    func(t *testing.T, objs []*unstructured.Unstructured)
+		workload int
+		wantErr  string
+	}{
+		"detect the workload with pod template": {
+			spec:     &common.K8sObjects{},
+			params:   map[string]interface{}{"objects": objects},
+			workload: 1,
+			check: func(t *testing.T, objs []*unstructured.Unstructured) {
+				require.Len(t, objs, 3)
+				replicas, _, _ := unstructured.NestedInt64(objs[1].Object, "spec", "replicas")
+				assert.Equal(t, int64(1), replicas)
+				assert.Equal(t, "", objs[1].GetNamespace())
+			},
+		},
+		"allowed cluster-scoped object": {
+			spec:   &common.K8sObjects{AllowedClusterScopedKinds: []string{"ClusterRole.rbac.authorization.k8s.io"}},
+			params: map[string]interface{}{"objects": []interface{}{configMap, clusterRole}},
+			check: func(t *testing.T, objs []*unstructured.Unstructured) {
+				require.Len(t, objs, 2)
+				assert.Equal(t, "ClusterRole", objs[1].GetKind())
+			},
+		},
+		"cluster-scoped object not allowed": {
+			spec:    &common.K8sObjects{AllowedClusterScopedKinds: []string{"Namespace"}},
+			params:  map[string]interface{}{"objects": []interface{}{configMap, clusterRole}},
+			wantErr: "cluster-scoped ClusterRole.rbac.authorization.k8s.io reader is not allowed by the definition",
+		},
+		"objects of the definition": {
+			spec: &common.K8sObjects{Objects: []runtime.RawExtension{
+				{Raw: []byte(`{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"web"}}`)},
+			}},
+			params:   map[string]interface{}{"objects": objects},
+			wlGVK:    common.WorkloadGVK{APIVersion: "stable.example.com/v1", Kind: "CronTab"},
+			workload: 3,
+			check: func(t *testing.T, objs []*unstructured.Unstructured) {
+				require.Len(t, objs, 4)
+				assert.Equal(t, "ServiceAccount", objs[0].GetKind())
+			},
+		},
+		"workload selector": {
+			spec: &common.K8sObjects{},
+			params: map[string]interface{}{
+				"objects":  objects,
+				"workload": map[string]interface{}{"kind": "ConfigMap"},
+			},
+			workload: 0,
+			check:    func(t *testing.T, objs []*unstructured.Unstructured) {},
+		},
+		"overlays": {
+			spec: &common.K8sObjects{},
+			params: map[string]interface{}{
+				"objects": objects,
+				"overlays": []interface{}{
+					map[string]interface{}{
+						"target": map[string]interface{}{"kind": "Deployment", "name": "web"},
+						"jsonPath": map[string]interface{}{
+							"{.spec.replicas}": 3,
+							`{.spec.template.spec.containers[?(@.name=="web")].image}`: "nginx:1.21",
+						},
+						"strategicMerge": map[string]interface{}{
+							"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+								"containers": []interface{}{map[string]interface{}{"name": "sidecar", "image": "busybox:1.34"}},
+							}}},
+						},
+					},
+					map[string]interface{}{
+						"target":         map[string]interface{}{"kind": "CronTab"},
+						"strategicMerge": map[string]interface{}{"spec": map[string]interface{}{"image": "crontab:v2"}},
+					},
+					map[string]interface{}{
+						"jsonPath": map[string]interface{}{"$.metadata.labels.app": "web"},
+					},
+				},
+			},
+			workload: 1,
+			check: func(t *testing.T, objs []*unstructured.Unstructured) {
+				require.Len(t, objs, 3)
+				replicas, _, _ := unstructured.NestedInt64(objs[1].Object, "spec", "replicas")
+				assert.Equal(t, int64(3), replicas)
+				containers, _, _ := unstructured.NestedSlice(objs[1].Object, "spec", "template", "spec", "containers")
+				require.Len(t, containers, 2)
+				assert.Equal(t, "nginx:1.21", containers[0].(map[string]interface{})["image"])
+				assert.Equal(t, "busybox:1.34", containers[1].(map[string]interface{})["image"])
+				image, _, _ := unstructured.NestedString(objs[2].Object, "spec", "image")
+				assert.Equal(t, "crontab:v2", image)
+				cronSpec, _, _ := unstructured.NestedString(objs[2].Object, "spec", "cronSpec")
+				assert.Equal(t, "* * * * */5", cronSpec)
+				for _, obj := range objs {
+					assert.Equal(t, map[string]string{"app": "web"}, obj.GetLabels())
+				}
+			},
+		},
+		"JSONPath matches no field": {
+			spec: &common.K8sObjects{},
+			params: map[string]interface{}{
+				"objects": objects,
+				"overlays": []interface{}{map[string]interface{}{
+					"target":   map[string]interface{}{"kind": "Deployment"},
+					"jsonPath": map[string]interface{}{`{.spec.template.spec.containers[?(@.name=="api")].image}`: "api:v1"},
+				}},
+			},
+			wantErr: `cannot apply overlay 0: cannot patch Deployment web: cannot set the value of "{.spec.template.spec.containers[?(@.name==\"api\")].image}": no field matches the JSONPath`,
+		},
+		"no object": {
+			spec:    &common.K8sObjects{},
+			wantErr: "no object is specified",
+		},
+		"invalid object": {
+			spec:    &common.K8sObjects{},
+			params:  map[string]interface{}{"objects": []interface{}{map[string]interface{}{"data": "value"}}},
+			wantErr: "cannot decode object 0: Object 'Kind' is missing in '{\"data\":\"value\"}'",
+		},
+		"overlay matches no object": {
+			spec: &common.K8sObjects{},
+			params: map[string]interface{}{
+				"objects":  objects,
+				"overlays": []interface{}{map[string]interface{}{"target": map[string]interface{}{"kind": "Service"}}},
+			},
+			wantErr: "cannot apply overlay 0: no object matches the target {APIVersion: Kind:Service Name:}",
+		},
+		"workload selector matches no object": {
+			spec: &common.K8sObjects{},
+			params: map[string]interface{}{
+				"objects":  objects,
+				"workload": map[string]interface{}{"kind": "Deployment", "name": "api"},
+			},
+			wantErr: "no object matches the workload selector {APIVersion: Kind:Deployment Name:api}",
+		},
+		"no object of the workload type": {
+			spec:    &common.K8sObjects{},
+			params:  map[string]interface{}{"objects": objects},
+			wlGVK:   common.WorkloadGVK{APIVersion: "apps/v1", Kind: "StatefulSet"},
+			wantErr: "no object of apps/v1 StatefulSet is specified",
+		},
+	}
+	dm := mock.NewMockDiscoveryMapper()
+	dm.MockRESTMapping = func(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+		if gk.Kind == "ClusterRole" || gk.Kind == "Namespace" {
+			return &meta.RESTMapping{Scope: meta.RESTScopeRoot}, nil
+		}
+		return &meta.RESTMapping{Scope: meta.RESTScopeNamespace}, nil
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			objs, workload, err := Render(tc.spec, tc.params, tc.wlGVK, dm)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.workload, workload)
+			tc.check(t, objs)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+const (
+	sourceDir  = "/source"
+	overlayDir = "/overlay"
+)
+
+var (
+	// FetchTimeout is the max duration of fetching the source of a kustomization
+	FetchTimeout = time.Minute
+	// MaxSourceSize is the max size in bytes of the Git objects or OCI layers fetched for a kustomization, and of the
+	// files extracted from them, zero means no limit
+	MaxSourceSize int64 = 32 * 1024 * 1024
+	// SourceCacheSize is the max number of the fetched Git or OCI sources kept in memory, zero disables the cache
+	SourceCacheSize = 16
+	// LocalPathAllowed specifies whether a kustomization can be loaded from the local file system, it should only
+	// be enabled where the local file system is owned by the user, e.g. the CLI or the tests
+	LocalPathAllowed = false
+)
+
+// Parameters are the properties of a component using the kustomize schematic, they are rendered into an overlay
+// of the kustomization
+type Parameters struct {
+	// Images overrides the name, tag or digest of images
+	Images []kusttypes.Image `json:"images,omitempty"`
+	// Replicas overrides the replicas of resources by name
+	Replicas []kusttypes.Replica `json:"replicas,omitempty"`
+	// Patches are strategic merge or JSON6902 patches applied to the resources matching the target
+	Patches []Patch `json:"patches,omitempty"`
+}
+
+// Patch is an inline patch of the overlay
+type Patch struct {
+	Patch  string              `json:"patch"`
+	Target *kusttypes.Selector `json:"target,omitempty"`
+}
+
+// Render fetches the kustomization of the schematic, applies the parameters in an overlay and renders the resources
+// in-process, the kustomization can only refer to files within its source
+func Render(ctx context.Context, spec *common.Kustomize, params map[string]interface{}) ([]*unstructured.Unstructured, error) {
+	if spec == nil {
+		return nil, errors.New("kustomize schematic is not set")
+	}
+	base, err := cleanRelativePath(spec.Path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid path of kustomization")
+	}
+	overlay, err := newOverlay(base, params)
+	if err != nil {
+		return nil, err
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	fetchCtx, cancel := context.WithTimeout(ctx, FetchTimeout)
+	defer cancel()
+	if err := fetchSource(fetchCtx, spec.Source, fSys, sourceDir); err != nil {
+		return nil, errors.WithMessage(err, "cannot fetch the source of kustomization")
+	}
+	if err := checkReferences(fSys, path.Join(sourceDir, base)); err != nil {
+		return nil, err
+	}
+	if err := fSys.MkdirAll(overlayDir); err != nil {
+		return nil, err
+	}
+	if err := fSys.WriteFile(path.Join(overlayDir, konfig.DefaultKustomizationFileName()), overlay); err != nil {
+		return nil, err
+	}
+
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, overlayDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot render kustomization")
+	}
+	objs := make([]*unstructured.Unstructured, 0, resMap.Size())
+	for _, res := range resMap.Resources() {
+		// the numbers in the map of the resource are int, so it's converted via JSON
+		raw, err := res.MarshalJSON()
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot marshal rendered resource %s", res.CurId())
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw); err != nil {
+			return nil, errors.Wrapf(err, "cannot convert rendered resource %s", res.CurId())
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// newOverlay generates the kustomization of the overlay on top of the base from the parameters
+func newOverlay(base string, params map[string]interface{}) ([]byte, error) {
+	var p Parameters
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot marshal kustomize parameters")
+		}
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, errors.Wrap(err, "invalid kustomize parameters")
+		}
+	}
+	k := kusttypes.Kustomization{
+		TypeMeta: kusttypes.TypeMeta{
+			APIVersion: kusttypes.KustomizationVersion,
+			Kind:       kusttypes.KustomizationKind,
+		},
+		Resources: []string{path.Join("..", sourceDir, base)},
+		Images:    p.Images,
+		Replicas:  p.Replicas,
+	}
+	for i, patch := range p.Patches {
+		if strings.TrimSpace(patch.Patch) == "" {
+			return nil, errors.Errorf("patch %d of kustomize parameters is empty", i)
+		}
+		k.Patches = append(k.Patches, kusttypes.Patch{Patch: patch.Patch, Target: patch.Target})
+	}
+	return yaml.Marshal(k)
+}
+
+// checkReferences walks the kustomizations from the root and ensures they only refer to files in the fetched
+// source, otherwise the kustomize loader would clone remote bases with the git binary or download remote files
+func checkReferences(fSys filesys.FileSystem, root string) error {
+	visited := map[string]bool{}
+	var check func(dir string) error
+	check = func(dir string) error {
+		if visited[dir] {
+			return nil
+		}
+		visited[dir] = true
+		k, file, err := readKustomization(fSys, dir)
+		if err != nil {
+			return err
+		}
+		if len(k.HelmChartInflationGenerator) > 0 {
+			return errors.Errorf("%s: helmChartInflationGenerator is not supported", file)
+		}
+		var refs []string
+		for _, refList := range [][]string{k.Resources, k.Bases, k.Components, k.Crds, k.Configurations,
+			k.Generators, k.Transformers, k.Validators} {
+			refs = append(refs, refList...)
+		}
+		for _, p := range k.Patches {
+			refs = append(refs, p.Path)
+		}
+		for _, p := range k.PatchesJson6902 {
+			refs = append(refs, p.Path)
+		}
+		for _, p := range k.PatchesStrategicMerge {
+			// an inline patch is not a reference
+			if !strings.Contains(string(p), "\n") {
+				refs = append(refs, string(p))
+			}
+		}
+		for _, args := range k.ConfigMapGenerator {
+			refs = append(refs, generatorFiles(args.GeneratorArgs)...)
+		}
+		for _, args := range k.SecretGenerator {
+			refs = append(refs, generatorFiles(args.GeneratorArgs)...)
+		}
+		for _, ref := range refs {
+			if ref == "" {
+				continue
+			}
+			if u, err := url.Parse(ref); err == nil && u.Scheme != "" {
+				return errors.Errorf("%s: remote reference %q is not supported", file, ref)
+			}
+			target := path.Join(dir, ref)
+			if !fSys.Exists(target) {
+				return errors.Errorf("%s: %q is not found in the source of kustomization", file, ref)
+			}
+			if fSys.IsDir(target) {
+				if err := check(target); err != nil {
+					return err
+				}
+			}
+		}
+		// the generator could also be configured in a file
+		for _, ref := range k.Generators {
+			target := path.Join(dir, ref)
+			if fSys.IsDir(target) {
+				continue
+			}
+			raw, err := fSys.ReadFile(target)
+			if err != nil {
+				return err
+			}
+			if strings.Contains(string(raw), "HelmChartInflationGenerator") {
+				return errors.Errorf("%s: HelmChartInflationGenerator is not supported", target)
+			}
+		}
+		return nil
+	}
+	return check(root)
+}
+
+func readKustomization(fSys filesys.FileSystem, dir string) (*kusttypes.Kustomization, string, error) {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		file := path.Join(dir, name)
+		if !fSys.Exists(file) {
+			continue
+		}
+		raw, err := fSys.ReadFile(file)
+		if err != nil {
+			return nil, file, err
+		}
+		k := &kusttypes.Kustomization{}
+		if err := yaml.Unmarshal(raw, k); err != nil {
+			return nil, file, errors.Wrapf(err, "invalid kustomization %s", file)
+		}
+		return k, file, nil
+	}
+	return nil, "", errors.Errorf("kustomization is not found in %s", strings.TrimPrefix(dir, sourceDir))
+}
+
+// generatorFiles returns the files read by a ConfigMap or Secret generator, a file source is either a path or
+// key=path
+func generatorFiles(args kusttypes.GeneratorArgs) []string {
+	files := append([]string{}, args.EnvSources...)
+	if args.EnvSource != "" {
+		files = append(files, args.EnvSource)
+	}
+	for _, src := range args.FileSources {
+		if i := strings.Index(src, "="); i >= 0 {
+			src = src[i+1:]
+		}
+		files = append(files, src)
+	}
+	return files
+}
+
+// cleanRelativePath cleans the path relative to the root of the source and ensures it does not escape the root
+func cleanRelativePath(p string) (string, error) {
+	if cleaned := path.Clean(p); cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errors.Errorf("%q escapes the root of the source", p)
+	}
+	return strings.TrimPrefix(path.Clean("/"+p), "/"), nil
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+func TestRender(t *testing.T) {
+	LocalPathAllowed = true
+	defer func() { LocalPathAllowed = false }()
+
+	inline := map[string]string{
+		"app/kustomization.yaml": `
+resources:
+- ../base
+namePrefix: prod-
+`,
+		"base/kustomization.yaml": `
+resources:
+- configmap.yaml
+`,
+		"base/configmap.yaml": `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+`,
+	}
+
+	cases := map[string]struct {
+		spec    *common.Kustomize
+		params  map[string]interface{}
+		check   func(t *testing.T, objs []*unstructured.Unstructured)
+		wantErr string
+	}{
+		"local path": {
+			spec: &common.Kustomize{Source: common.KustomizeSource{LocalPath: "testdata"}, Path: "base"},
+			check: func(t *testing.T, objs []*unstructured.Unstructured) {
+				require.Len(t, objs, 2)
+				assert.Equal(t, "Deployment", objs[0].GetKind())
+				assert.Equal(t, "Service", objs[1].GetKind())
+				assert.Equal(t, map[string]string{"app": "podinfo"}, objs[1].GetLabels())
+			},
+		},
+		"parameters": {
+			spec: &common.Kustomize{Source: common.KustomizeSource{LocalPath: "testdata/base"}},
+			params: map[string]interface{}{
+				"images":   []interface{}{map[string]interface{}{"name": "stefanprodan/podinfo", "newTag": "6.0.0"}},
+				"replicas": []interface{}{map[string]interface{}{"name": "podinfo", "count": 3}},
+				"patches": []interface{}{map[string]interface{}{
+					"patch":  `[{"op": "add", "path": "/metadata/annotations", "value": {"patched": "true"}}]`,
+					"target": map[string]interface{}{"kind": "Service"},
+				}},
+			},
+			check: func(t *testing.T, objs []*unstructured.Unstructured) {
+				require.Len(t, objs, 2)
+				replicas, _, _ := unstructured.NestedInt64(objs[0].Object, "spec", "replicas")
+				assert.Equal(t, int64(3), replicas)
+				containers, _, _ := unstructured.NestedSlice(objs[0].Object, "spec", "template", "spec", "containers")
+				assert.Equal(t, "stefanprodan/podinfo:6.0.0", containers[0].(map[string]interface{})["image"])
+				assert.Equal(t, map[string]string{"patched": "true"}, objs[1].GetAnnotations())
+			},
+		},
+		"inline files": {
+			spec: &common.Kustomize{Source: common.KustomizeSource{Files: inline}, Path: "app"},
+			check: func(t *testing.T, objs []*unstructured.Unstructured) {
+				require.Len(t, objs, 1)
+				assert.Equal(t, "prod-config", objs[0].GetName())
+			},
+		},
+		"remote resource": {
+			spec: &common.Kustomize{Source: common.KustomizeSource{Files: map[string]string{
+				"kustomization.yaml": "resources:\n- https://github.com/kubernetes-sigs/kustomize//examples/helloWorld",
+			}}},
+			wantErr: `/source/kustomization.yaml: remote reference "https://github.com/kubernetes-sigs/kustomize//examples/helloWorld" is not supported`,
+		},
+		"remote base": {
+			spec: &common.Kustomize{Source: common.KustomizeSource{Files: map[string]string{
+				"kustomization.yaml": "bases:\n- github.com/kubernetes-sigs/kustomize/examples/helloWorld?ref=v3.3.1",
+			}}},
+			wantErr: `/source/kustomization.yaml: "github.com/kubernetes-sigs/kustomize/examples/helloWorld?ref=v3.3.1" is not found in the source of kustomization`,
+		},
+		"helm chart": {
+			spec: &common.Kustomize{Source: common.KustomizeSource{Files: map[string]string{
+				"kustomization.yaml": "helmChartInflationGenerator:\n- chartName: podinfo",
+			}}},
+			wantErr: "/source/kustomization.yaml: helmChartInflationGenerator is not supported",
+		},
+		"path escapes the source": {
+			spec:    &common.Kustomize{Source: common.KustomizeSource{Files: inline}, Path: "../app"},
+			wantErr: `invalid path of kustomization: "../app" escapes the root of the source`,
+		},
+		"no kustomization": {
+			spec:    &common.Kustomize{Source: common.KustomizeSource{Files: inline}, Path: "others"},
+			wantErr: "kustomization is not found in /others",
+		},
+		"no source": {
+			spec:    &common.Kustomize{},
+			wantErr: "cannot fetch the source of kustomization: no source is specified",
+		},
+		"git ref not pinned": {
+			spec:    &common.Kustomize{Source: common.KustomizeSource{Git: &common.KustomizeGitSource{URL: "https://github.com/oam-dev/samples", Ref: "master"}}},
+			wantErr: `cannot fetch the source of kustomization: git ref "master" of https://github.com/oam-dev/samples is not pinned to a commit hash`,
+		},
+		"OCI reference not pinned": {
+			spec:    &common.Kustomize{Source: common.KustomizeSource{OCI: &common.KustomizeOCISource{Ref: "ghcr.io/oam-dev/config:v1"}}},
+			wantErr: `cannot fetch the source of kustomization: OCI reference "ghcr.io/oam-dev/config:v1" is not pinned to a digest`,
+		},
+		"git url of the file protocol": {
+			spec:    &common.Kustomize{Source: common.KustomizeSource{Git: &common.KustomizeGitSource{URL: "file:///tmp/config"}}},
+			wantErr: `cannot fetch the source of kustomization: git url "file:///tmp/config" of the file protocol is not supported`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			objs, err := Render(context.Background(), tc.spec, tc.params)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, objs)
+		})
+	}
+}
+
+func TestRenderLocalPathNotAllowed(t *testing.T) {
+	_, err := Render(context.Background(), &common.Kustomize{Source: common.KustomizeSource{LocalPath: "testdata"}}, nil)
+	require.Error(t, err)
+	assert.Equal(t, `cannot fetch the source of kustomization: local path "testdata" is not allowed as the source of kustomization`, err.Error())
+}
+
+func TestSourceSizeLimit(t *testing.T) {
+	defer func(size int64) { MaxSourceSize = size }(MaxSourceSize)
+	MaxSourceSize = 64
+
+	_, err := Render(context.Background(), &common.Kustomize{Source: common.KustomizeSource{Files: map[string]string{
+		"kustomization.yaml": strings.Repeat("#", 65),
+	}}}, nil)
+	require.Error(t, err)
+	assert.Equal(t, "cannot fetch the source of kustomization: the source exceeds the max size of 64 bytes", err.Error())
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{"a.yaml", "b.yaml"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: 40, Typeflag: tar.TypeReg}))
+		_, err = tw.Write([]byte(strings.Repeat("#", 40)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	files := newSourceFiles()
+	err = extractTarball(&buf, false, files)
+	require.Error(t, err)
+	assert.Equal(t, "the source exceeds the max size of 64 bytes", err.Error())
+}
+
+func TestFetchCached(t *testing.T) {
+	fetched := 0
+	fetch := func(files *sourceFiles) error {
+		fetched++
+		return files.add("kustomization.yaml", []byte("resources: []"))
+	}
+	for i := 0; i < 2; i++ {
+		files, err := fetchCached("git:https://example.com/config@cached", fetch)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("resources: []"), files.files["/kustomization.yaml"])
+	}
+	assert.Equal(t, 1, fetched)
+}
@@ -0,0 +1,344 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/reference"
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/deislabs/oras/pkg/content"
+	"github.com/deislabs/oras/pkg/oras"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"k8s.io/utils/lru"
+	"sigs.k8s.io/kustomize/api/filesys"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+var (
+	sourceCacheOnce sync.Once
+	sourceCache     *lru.Cache
+)
+
+// sourceFiles are the files of a source keyed by their paths, their total size is limited by MaxSourceSize
+type sourceFiles struct {
+	files map[string][]byte
+	size  int64
+}
+
+func newSourceFiles() *sourceFiles {
+	return &sourceFiles{files: map[string][]byte{}}
+}
+
+// add adds the file, the name is cleaned so that the file cannot escape the root of the source
+func (s *sourceFiles) add(name string, data []byte) error {
+	s.size += int64(len(data))
+	if MaxSourceSize > 0 && s.size > MaxSourceSize {
+		return errSourceTooLarge()
+	}
+	s.files[path.Clean("/"+name)] = data
+	return nil
+}
+
+// readAll reads the data of a file without exceeding the max size of the source
+func (s *sourceFiles) readAll(r io.Reader) ([]byte, error) {
+	if MaxSourceSize <= 0 {
+		return ioutil.ReadAll(r)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, MaxSourceSize-s.size+1))
+	if err != nil {
+		return nil, err
+	}
+	if s.size+int64(len(data)) > MaxSourceSize {
+		return nil, errSourceTooLarge()
+	}
+	return data, nil
+}
+
+// writeTo writes the files into dir of the file system
+func (s *sourceFiles) writeTo(fSys filesys.FileSystem, dir string) error {
+	for name, data := range s.files {
+		file := path.Join(dir, name)
+		if err := fSys.MkdirAll(path.Dir(file)); err != nil {
+			return err
+		}
+		if err := fSys.WriteFile(file, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func errSourceTooLarge() error {
+	return errors.Errorf("the source exceeds the max size of %d bytes", MaxSourceSize)
+}
+
+// fetchSource fetches the files of the source into dir of the file system
+func fetchSource(ctx context.Context, src common.KustomizeSource, fSys filesys.FileSystem, dir string) error {
+	if err := fSys.MkdirAll(dir); err != nil {
+		return err
+	}
+	files := newSourceFiles()
+	var err error
+	switch {
+	case src.Git != nil:
+		files, err = fetchCached("git:"+src.Git.URL+"@"+src.Git.Ref, func(files *sourceFiles) error {
+			return fetchGit(ctx, src.Git, files)
+		})
+	case src.OCI != nil:
+		files, err = fetchCached("oci:"+src.OCI.Ref, func(files *sourceFiles) error {
+			return fetchOCI(ctx, src.OCI, files)
+		})
+	case src.Files != nil:
+		for name, data := range src.Files {
+			if err = files.add(name, []byte(data)); err != nil {
+				break
+			}
+		}
+	case src.LocalPath != "":
+		if !LocalPathAllowed {
+			return errors.Errorf("local path %q is not allowed as the source of kustomization", src.LocalPath)
+		}
+		err = copyLocalPath(src.LocalPath, files)
+	default:
+		return errors.New("no source is specified")
+	}
+	if err != nil {
+		return err
+	}
+	return files.writeTo(fSys, dir)
+}
+
+// fetchCached returns the files of the remote source from the cache, or fetches them. The remote sources are pinned
+// to a commit or a digest, so the key identifies the files and the cached files never expire.
+func fetchCached(key string, fetch func(files *sourceFiles) error) (*sourceFiles, error) {
+	sourceCacheOnce.Do(func() {
+		if SourceCacheSize > 0 {
+			sourceCache = lru.New(SourceCacheSize)
+		}
+	})
+	if sourceCache != nil {
+		if cached, ok := sourceCache.Get(key); ok {
+			return cached.(*sourceFiles), nil
+		}
+	}
+	files := newSourceFiles()
+	if err := fetch(files); err != nil {
+		return nil, err
+	}
+	if sourceCache != nil {
+		sourceCache.Add(key, files)
+	}
+	return files, nil
+}
+
+// limitedStorage stores the objects of the cloned repository in memory, it fails once the size of the objects
+// exceeds MaxSourceSize
+type limitedStorage struct {
+	*memory.Storage
+	size int64
+}
+
+// SetEncodedObject implements storer.EncodedObjectStorer
+func (s *limitedStorage) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	s.size += obj.Size()
+	if MaxSourceSize > 0 && s.size > MaxSourceSize {
+		return plumbing.ZeroHash, errSourceTooLarge()
+	}
+	return s.Storage.SetEncodedObject(obj)
+}
+
+func fetchGit(ctx context.Context, src *common.KustomizeGitSource, files *sourceFiles) error {
+	endpoint, err := transport.NewEndpoint(src.URL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid git url %q", src.URL)
+	}
+	// the file transport runs the git binary
+	if endpoint.Protocol == "file" {
+		return errors.Errorf("git url %q of the file protocol is not supported", src.URL)
+	}
+	// a branch or a tag could change the rendered resources without a new revision of the application
+	if !plumbing.IsHash(src.Ref) {
+		return errors.Errorf("git ref %q of %s is not pinned to a commit hash", src.Ref, src.URL)
+	}
+
+	worktree := memfs.New()
+	repo, err := git.CloneContext(ctx, &limitedStorage{Storage: memory.NewStorage()}, worktree, &git.CloneOptions{URL: src.URL, NoCheckout: true})
+	if err == nil {
+		var wt *git.Worktree
+		if wt, err = repo.Worktree(); err == nil {
+			err = wt.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(src.Ref)})
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot clone git repository %s", src.URL)
+	}
+	return copyBillyDir(worktree, "/", files)
+}
+
+func copyBillyDir(from billy.Filesystem, fromDir string, files *sourceFiles) error {
+	infos, err := from.ReadDir(fromDir)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		name := path.Join(fromDir, info.Name())
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				continue
+			}
+			if err := copyBillyDir(from, name, files); err != nil {
+				return err
+			}
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		f, err := from.Open(name)
+		if err != nil {
+			return err
+		}
+		data, err := files.readAll(f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		if err := files.add(name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchOCI pulls the layers of the OCI artifact, a layer with the file name annotation is saved as the file, and the
+// others are extracted as (gzipped) tarballs
+func fetchOCI(ctx context.Context, src *common.KustomizeOCISource, files *sourceFiles) error {
+	spec, err := reference.Parse(src.Ref)
+	if err != nil {
+		return errors.Wrapf(err, "invalid OCI reference %q", src.Ref)
+	}
+	// a tag could change the rendered resources without a new revision of the application
+	if spec.Digest() == "" {
+		return errors.Errorf("OCI reference %q is not pinned to a digest", src.Ref)
+	}
+	var pulled int64
+	limitPull := images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if MaxSourceSize > 0 && atomic.AddInt64(&pulled, desc.Size) > MaxSourceSize {
+			return nil, errSourceTooLarge()
+		}
+		return nil, nil
+	})
+	store := content.NewMemoryStore()
+	resolver := docker.NewResolver(docker.ResolverOptions{})
+	_, layers, err := oras.Pull(ctx, resolver, src.Ref, store,
+		oras.WithPullEmptyNameAllowed(),
+		oras.WithPullBaseHandler(limitPull),
+		oras.WithAllowedMediaType(ocispec.MediaTypeImageLayer, ocispec.MediaTypeImageLayerGzip))
+	if err != nil {
+		return errors.Wrapf(err, "cannot pull OCI artifact %s", src.Ref)
+	}
+	for _, layer := range layers {
+		_, data, ok := store.Get(layer)
+		if !ok {
+			return errors.Errorf("layer %s of OCI artifact %s is not pulled", layer.Digest, src.Ref)
+		}
+		name := layer.Annotations[ocispec.AnnotationTitle]
+		switch {
+		case layer.MediaType == ocispec.MediaTypeImageLayerGzip:
+			err = extractTarball(bytes.NewReader(data), true, files)
+		case name != "":
+			err = files.add(name, data)
+		default:
+			err = extractTarball(bytes.NewReader(data), false, files)
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "cannot extract layer %s of OCI artifact %s", layer.Digest, src.Ref)
+		}
+	}
+	return nil
+}
+
+func extractTarball(r io.Reader, gzipped bool, files *sourceFiles) error {
+	if gzipped {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close() // nolint:errcheck
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := files.readAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := files.add(hdr.Name, data); err != nil {
+			return err
+		}
+	}
+}
+
+func copyLocalPath(localPath string, files *sourceFiles) error {
+	return filepath.Walk(localPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(localPath, p)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(filepath.Clean(p))
+		if err != nil {
+			return err
+		}
+		return files.add(filepath.ToSlash(rel), data)
+	})
+}
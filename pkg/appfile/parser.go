@@ -27,6 +27,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/cue/definition"
@@ -44,6 +45,19 @@ func (fn TemplateLoaderFn) LoadTemplate(ctx context.Context, dm discoverymapper.
 	return fn(ctx, dm, c, capName, capType)
 }
 
+// builtinPolicyTypes are the policies handled by the application controller directly, they have no PolicyDefinition
+// and render nothing.
+var builtinPolicyTypes = map[string]bool{
+	v1alpha1.DriftDetectionPolicyType: true,
+	v1alpha1.AppHealthPolicyType:      true,
+	v1alpha1.GarbageCollectPolicyType: true,
+}
+
+// IsBuiltinPolicy checks whether the policy type is handled by the application controller directly
+func IsBuiltinPolicy(typ string) bool {
+	return builtinPolicyTypes[typ]
+}
+
 // Parser is an application parser
 type Parser struct {
 	client     client.Client
@@ -125,6 +139,12 @@ func (p *Parser) GenerateAppFile(ctx context.Context, app *v1beta1.Application)
 		}
 	}
 
+	for _, w := range append(appfile.Workloads, appfile.Policies...) {
+		if w != nil {
+			appfile.setAppMetadata(w)
+		}
+	}
+
 	appfile.WorkflowMode = common.WorkflowModeDAG
 	if wfSpec := app.Spec.Workflow; wfSpec != nil {
 		appfile.WorkflowMode = common.WorkflowModeStep
@@ -210,6 +230,12 @@ func (p *Parser) GenerateAppFileFromRevision(appRev *v1beta1.ApplicationRevision
 		return nil, fmt.Errorf("failed to parsePolicies: %w", err)
 	}
 
+	for _, w := range append(appfile.Workloads, appfile.Policies...) {
+		if w != nil {
+			appfile.setAppMetadata(w)
+		}
+	}
+
 	for k, v := range appRev.Spec.ComponentDefinitions {
 		appfile.RelatedComponentDefinitions[k] = v.DeepCopy()
 	}
@@ -230,6 +256,9 @@ func (p *Parser) GenerateAppFileFromRevision(appRev *v1beta1.ApplicationRevision
 func (p *Parser) parsePolicies(ctx context.Context, policies []v1beta1.AppPolicy) ([]*Workload, error) {
 	ws := []*Workload{}
 	for _, policy := range policies {
+		if IsBuiltinPolicy(policy.Type) {
+			continue
+		}
 		w, err := p.makeWorkload(ctx, policy.Name, policy.Type, types.TypePolicy, policy.Properties)
 		if err != nil {
 			return nil, err
@@ -242,6 +271,9 @@ func (p *Parser) parsePolicies(ctx context.Context, policies []v1beta1.AppPolicy
 func (p *Parser) parsePoliciesFromRevision(policies []v1beta1.AppPolicy, appRev *v1beta1.ApplicationRevision) ([]*Workload, error) {
 	ws := []*Workload{}
 	for _, policy := range policies {
+		if IsBuiltinPolicy(policy.Type) {
+			continue
+		}
 		w, err := p.makeWorkloadFromRevision(policy.Name, policy.Type, types.TypePolicy, policy.Properties, appRev)
 		if err != nil {
 			return nil, err
@@ -286,6 +318,7 @@ func (p *Parser) convertTemplate2Workload(name, typ string, props runtime.RawExt
 		FullTemplate:       templ,
 		Params:             settings,
 		engine:             definition.NewWorkloadAbstractEngine(name, p.pd),
+		dm:                 p.dm,
 	}, nil
 }
 
@@ -310,6 +343,9 @@ func (p *Parser) parseWorkload(ctx context.Context, comp common.ApplicationCompo
 
 		workload.Traits = append(workload.Traits, trait)
 	}
+	if workload.Traits, err = orderTraits(comp.Name, workload.Traits); err != nil {
+		return nil, err
+	}
 	for scopeType, instanceName := range comp.Scopes {
 		sd, gvk, err := GetScopeDefAndGVK(ctx, p.client, p.dm, scopeType)
 		if err != nil {
@@ -346,6 +382,9 @@ func (p *Parser) ParseWorkloadFromRevision(comp common.ApplicationComponent, app
 
 		workload.Traits = append(workload.Traits, trait)
 	}
+	if workload.Traits, err = orderTraits(comp.Name, workload.Traits); err != nil {
+		return nil, err
+	}
 	for scopeType, instanceName := range comp.Scopes {
 		sd, gvk, err := GetScopeDefAndGVKFromRevision(scopeType, appRev)
 		if err != nil {
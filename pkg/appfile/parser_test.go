@@ -410,7 +410,7 @@ var _ = Describe("Test appFile parser", func() {
 			Data:       map[string]string{"c1": "v1", "c2": "v2"},
 		}
 		Expect(k8sClient.Create(context.Background(), cm.DeepCopy())).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
-		comps, err := TestApp.GenerateComponentManifests()
+		comps, err := TestApp.GenerateComponentManifests(context.Background())
 		Expect(err).To(BeNil())
 
 		expectWorkload := &unstructured.Unstructured{
@@ -56,6 +56,8 @@ type Template struct {
 	Helm               *common.Helm
 	Kube               *common.Kube
 	Terraform          *common.Terraform
+	Kustomize          *common.Kustomize
+	K8sObjects         *common.K8sObjects
 
 	ComponentDefinition *v1beta1.ComponentDefinition
 	WorkloadDefinition  *v1beta1.WorkloadDefinition
@@ -398,6 +400,16 @@ func loadSchematicToTemplate(tmpl *Template, status *common.Status, schematic *c
 			tmpl.Terraform = schematic.Terraform
 			return nil
 		}
+		if schematic.Kustomize != nil {
+			tmpl.CapabilityCategory = types.KustomizeCategory
+			tmpl.Kustomize = schematic.Kustomize
+			return nil
+		}
+		if schematic.K8sObjects != nil {
+			tmpl.CapabilityCategory = types.K8sObjectsCategory
+			tmpl.K8sObjects = schematic.K8sObjects
+			return nil
+		}
 	}
 
 	if tmpl.TemplateStr == "" && ext != nil {
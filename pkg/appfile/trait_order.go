@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appfile
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// traitStages lists the stages in the order they are rendered
+var traitStages = []v1beta1.TraitStageType{v1beta1.PreStage, v1beta1.DefaultStage, v1beta1.PostStage}
+
+// orderTraits validates the conflicts and dependencies declared by the TraitDefinitions of the traits applied to
+// the component, and returns the traits in the order they should be rendered.
+// Traits are grouped by their stage, and within a stage a trait is rendered after the traits it depends on,
+// otherwise the order the user lists them is kept.
+func orderTraits(compName string, traits []*Trait) ([]*Trait, error) {
+	if err := validateTraitConflicts(compName, traits); err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*Trait, len(traits))
+	for _, t := range traits {
+		byName[t.Name] = t
+	}
+	for _, t := range traits {
+		for _, dep := range traitDefinitionOf(t).Spec.DependsOn {
+			depTrait, ok := byName[dep]
+			if !ok {
+				return nil, errors.Errorf("trait %q depends on trait %q which is not applied to component %q", t.Name, dep, compName)
+			}
+			if stageIndex(depTrait) > stageIndex(t) {
+				return nil, errors.Errorf("trait %q in stage %s cannot depend on trait %q in the later stage %s of component %q",
+					t.Name, traitStages[stageIndex(t)], dep, traitStages[stageIndex(depTrait)], compName)
+			}
+		}
+	}
+
+	ordered := make([]*Trait, 0, len(traits))
+	rendered := map[string]bool{}
+	for i := range traitStages {
+		var pending []*Trait
+		for _, t := range traits {
+			if stageIndex(t) == i {
+				pending = append(pending, t)
+			}
+		}
+		for len(pending) > 0 {
+			next := -1
+			for j, t := range pending {
+				if dependenciesRendered(t, rendered) {
+					next = j
+					break
+				}
+			}
+			if next < 0 {
+				names := make([]string, 0, len(pending))
+				for _, t := range pending {
+					names = append(names, t.Name)
+				}
+				return nil, errors.Errorf("circular dependency among traits %s of component %q", strings.Join(names, ", "), compName)
+			}
+			ordered = append(ordered, pending[next])
+			rendered[pending[next].Name] = true
+			pending = append(pending[:next], pending[next+1:]...)
+		}
+	}
+	return ordered, nil
+}
+
+// validateTraitConflicts validates whether the traits applied to the component conflict with each other
+// according to the conflictsWith rules of their TraitDefinitions
+func validateTraitConflicts(compName string, traits []*Trait) error {
+	conflicting := make([]util.ConflictingTrait, 0, len(traits))
+	for _, t := range traits {
+		td := traitDefinitionOf(t)
+		conflicting = append(conflicting, util.ConflictingTrait{
+			Name:          t.Name,
+			CRDName:       td.Spec.Reference.Name,
+			Labels:        td.Labels,
+			ConflictsWith: td.Spec.ConflictsWith,
+		})
+	}
+	return util.ValidateTraitConflicts(compName, conflicting)
+}
+
+func dependenciesRendered(t *Trait, rendered map[string]bool) bool {
+	for _, dep := range traitDefinitionOf(t).Spec.DependsOn {
+		if !rendered[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// stageIndex returns the index of the trait's stage in traitStages, traits without stage are in the default stage
+func stageIndex(t *Trait) int {
+	switch traitDefinitionOf(t).Spec.Stage {
+	case v1beta1.PreStage:
+		return 0
+	case v1beta1.PostStage:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func traitDefinitionOf(t *Trait) *v1beta1.TraitDefinition {
+	if t.FullTemplate == nil || t.FullTemplate.TraitDefinition == nil {
+		return &v1beta1.TraitDefinition{}
+	}
+	return t.FullTemplate.TraitDefinition
+}
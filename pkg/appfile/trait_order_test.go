@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestOrderTraits(t *testing.T) {
+	newTrait := func(name string, spec v1beta1.TraitDefinitionSpec, lbs map[string]string) *Trait {
+		return &Trait{Name: name, FullTemplate: &Template{TraitDefinition: &v1beta1.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: lbs},
+			Spec:       spec,
+		}}}
+	}
+	names := func(traits []*Trait) []string {
+		var res []string
+		for _, t := range traits {
+			res = append(res, t.Name)
+		}
+		return res
+	}
+
+	cases := map[string]struct {
+		traits  []*Trait
+		want    []string
+		wantErr string
+	}{
+		"keep the user order": {
+			traits: []*Trait{newTrait("b", v1beta1.TraitDefinitionSpec{}, nil), {Name: "a"}},
+			want:   []string{"b", "a"},
+		},
+		"order by stage and dependency": {
+			traits: []*Trait{
+				newTrait("sidecar", v1beta1.TraitDefinitionSpec{Stage: v1beta1.PostStage}, nil),
+				newTrait("ingress", v1beta1.TraitDefinitionSpec{DependsOn: []string{"expose"}}, nil),
+				newTrait("expose", v1beta1.TraitDefinitionSpec{DependsOn: []string{"labels"}}, nil),
+				newTrait("labels", v1beta1.TraitDefinitionSpec{Stage: v1beta1.PreStage}, nil),
+				newTrait("scaler", v1beta1.TraitDefinitionSpec{Stage: v1beta1.DefaultStage}, nil),
+			},
+			want: []string{"labels", "expose", "ingress", "scaler", "sidecar"},
+		},
+		"missing dependency": {
+			traits:  []*Trait{newTrait("ingress", v1beta1.TraitDefinitionSpec{DependsOn: []string{"expose"}}, nil)},
+			wantErr: `trait "ingress" depends on trait "expose" which is not applied to component "comp"`,
+		},
+		"depend on a later stage": {
+			traits: []*Trait{
+				newTrait("labels", v1beta1.TraitDefinitionSpec{Stage: v1beta1.PreStage, DependsOn: []string{"expose"}}, nil),
+				newTrait("expose", v1beta1.TraitDefinitionSpec{}, nil),
+			},
+			wantErr: `trait "labels" in stage PreProcess cannot depend on trait "expose" in the later stage Default of component "comp"`,
+		},
+		"circular dependency": {
+			traits: []*Trait{
+				newTrait("a", v1beta1.TraitDefinitionSpec{DependsOn: []string{"b"}}, nil),
+				newTrait("b", v1beta1.TraitDefinitionSpec{DependsOn: []string{"a"}}, nil),
+			},
+			wantErr: `circular dependency among traits a, b of component "comp"`,
+		},
+		"conflict by definition name": {
+			traits: []*Trait{
+				newTrait("scaler", v1beta1.TraitDefinitionSpec{ConflictsWith: []string{"hpa"}}, nil),
+				newTrait("hpa", v1beta1.TraitDefinitionSpec{}, nil),
+			},
+			wantErr: `conflict(rule: "hpa") between traits ("scaler" and "hpa") of component "comp" is detected`,
+		},
+		"conflict by label selector": {
+			traits: []*Trait{
+				newTrait("scaler", v1beta1.TraitDefinitionSpec{ConflictsWith: []string{"labelSelector:type=scaler"}}, nil),
+				newTrait("hpa", v1beta1.TraitDefinitionSpec{}, map[string]string{"type": "scaler"}),
+			},
+			wantErr: `conflict(rule: "labelSelector:type=scaler") between traits ("scaler" and "hpa") of component "comp" is detected`,
+		},
+		"conflict by API group": {
+			traits: []*Trait{
+				newTrait("route", v1beta1.TraitDefinitionSpec{ConflictsWith: []string{"*.networking.k8s.io"}}, nil),
+				newTrait("ingress", v1beta1.TraitDefinitionSpec{Reference: common.DefinitionReference{Name: "ingresses.networking.k8s.io"}}, nil),
+			},
+			wantErr: `conflict(rule: "*.networking.k8s.io") between traits ("route" and "ingress") of component "comp" is detected`,
+		},
+		"conflict with all": {
+			traits: []*Trait{
+				newTrait("exclusive", v1beta1.TraitDefinitionSpec{ConflictsWith: []string{"*"}}, nil),
+				newTrait("other", v1beta1.TraitDefinitionSpec{}, nil),
+			},
+			wantErr: `trait "exclusive" of component "comp" conflicts with all other traits`,
+		},
+		"conflict with all alone": {
+			traits: []*Trait{newTrait("exclusive", v1beta1.TraitDefinitionSpec{ConflictsWith: []string{"*"}}, nil)},
+			want:   []string{"exclusive"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := orderTraits("comp", tc.traits)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, names(got))
+		})
+	}
+}
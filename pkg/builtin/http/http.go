@@ -19,8 +19,10 @@ package http
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
 
 	"cuelang.org/go/cue"
 
@@ -31,13 +33,37 @@ func init() {
 	registry.RegisterRunner("http", newHTTPCmd)
 }
 
+// maxRedirects is the max number of redirects followed by a request, the same as the default policy of http.Client
+const maxRedirects = 10
+
 // HTTPCmd provides methods for http task
 type HTTPCmd struct {
 	*http.Client
 }
 
+type urlCheckKey struct{}
+
+// WithURLCheck returns a context which makes the http task check the URL of every redirect of the request with the
+// function, the request fails with the error of the check
+func WithURLCheck(ctx context.Context, check func(u *url.URL) error) context.Context {
+	return context.WithValue(ctx, urlCheckKey{}, check)
+}
+
+// checkRedirect follows the redirects like the default policy of http.Client, and checks the URL of every hop by
+// the check in the context of the request
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	if check, ok := req.Context().Value(urlCheckKey{}).(func(u *url.URL) error); ok && check != nil {
+		return check(req.URL)
+	}
+	return nil
+}
+
 func newHTTPCmd(v cue.Value) (registry.Runner, error) {
 	client := &http.Client{
+		CheckRedirect: checkRedirect,
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: true,
@@ -77,7 +103,11 @@ func (c *HTTPCmd) Run(meta *registry.Meta) (res interface{}, err error) {
 		return nil, meta.Err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), method, u, r)
+	ctx := meta.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
 	if err != nil {
 		return nil, err
 	}
@@ -17,10 +17,15 @@ limitations under the License.
 package core_oam_dev
 
 import (
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
 	"github.com/oam-dev/kubevela/pkg/cue/packages"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
 )
 
 // ApplyOnceOnlyMode enumerates ApplyOnceOnly modes.
@@ -80,4 +85,66 @@ type Args struct {
 
 	// OAMSpecVer is the oam spec version controller want to setup
 	OAMSpecVer string
+
+	// ServerSideApplyControllers are the names of the controllers which apply resources by server-side apply,
+	// such as application and envbinding. All the controllers use server-side apply if it contains "*".
+	ServerSideApplyControllers []string
+
+	// ApplyFieldManager is the field manager of the server-side apply
+	ApplyFieldManager string
+
+	// ApplyForceConflicts indicates whether the server-side apply takes over the fields owned by other managers
+	ApplyForceConflicts bool
+
+	// AppNamespaceQPS and AppNamespaceBurst configure the token bucket of each namespace which rate limits the
+	// applications enqueued to the application controller, the limit is disabled if the QPS is not positive
+	AppNamespaceQPS   float64
+	AppNamespaceBurst int
+
+	// AppLowPriorityQPS and AppLowPriorityBurst configure the token bucket shared by all the low priority applications,
+	// the limit is disabled if the QPS is not positive
+	AppLowPriorityQPS   float64
+	AppLowPriorityBurst int
+
+	// DefinitionCompatibilityCheck indicates whether the definition webhooks deny the updates of ComponentDefinitions
+	// and TraitDefinitions whose parameter changes would break the existing applications
+	DefinitionCompatibilityCheck bool
+}
+
+// serverSideApplyControllerNames are the names of the controllers which can apply resources by server-side apply
+var serverSideApplyControllerNames = map[string]bool{
+	"application":              true,
+	"approllout":               true,
+	"envbinding":               true,
+	"applicationconfiguration": true,
+	"rollout":                  true,
+	"*":                        true,
+}
+
+// ParseServerSideApplyControllers parses the comma separated list of the controllers which apply resources by
+// server-side apply, the spaces around the names are trimmed and the unknown names are rejected
+func ParseServerSideApplyControllers(s string) ([]string, error) {
+	var controllers []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !serverSideApplyControllerNames[name] {
+			return nil, errors.Errorf("unknown server-side apply controller %q", name)
+		}
+		controllers = append(controllers, name)
+	}
+	return controllers, nil
+}
+
+// NewApplicator returns the server-side Applicator if the controller is enabled in ServerSideApplyControllers,
+// otherwise returns the client-side Applicator which tracks the last-applied-state in the annotation.
+func (a Args) NewApplicator(c client.Client, controller string) apply.Applicator {
+	for _, name := range a.ServerSideApplyControllers {
+		if name == controller || name == "*" {
+			return apply.NewServerSideApplicator(c, a.ApplyFieldManager, a.ApplyForceConflicts)
+		}
+	}
+	return apply.NewAPIApplicator(c)
 }
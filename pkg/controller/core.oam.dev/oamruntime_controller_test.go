@@ -0,0 +1,41 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core_oam_dev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServerSideApplyControllers(t *testing.T) {
+	controllers, err := ParseServerSideApplyControllers("")
+	require.NoError(t, err)
+	assert.Empty(t, controllers)
+
+	controllers, err = ParseServerSideApplyControllers("application, envbinding ,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"application", "envbinding"}, controllers)
+
+	controllers, err = ParseServerSideApplyControllers("*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*"}, controllers)
+
+	_, err = ParseServerSideApplyControllers("application,env-binding")
+	assert.EqualError(t, err, `unknown server-side apply controller "env-binding"`)
+}
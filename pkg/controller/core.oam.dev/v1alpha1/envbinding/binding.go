@@ -120,7 +120,7 @@ func (e *EnvBindApp) render(ctx context.Context, appParser *appfile.Parser) erro
 	if err != nil {
 		return err
 	}
-	comps, err := appFile.GenerateComponentManifests()
+	comps, err := appFile.GenerateComponentManifests(ctx)
 	if err != nil {
 		return err
 	}
@@ -18,6 +18,7 @@ package envbinding
 
 import (
 	"context"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
@@ -56,6 +57,7 @@ type Reconciler struct {
 	pd                   *packages.PackageDiscover
 	Scheme               *runtime.Scheme
 	record               event.Recorder
+	applicator           apply.Applicator
 	concurrentReconciles int
 }
 
@@ -70,12 +72,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	endReconcile, err := r.handleFinalizers(ctx, envBinding)
+	endReconcile, requeueAfter, err := r.handleFinalizers(ctx, envBinding)
 	if err != nil {
 		return r.endWithNegativeCondition(ctx, envBinding, condition.ReconcileError(err))
 	}
 	if endReconcile {
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	if err := validatePlacement(envBinding); err != nil {
@@ -193,7 +195,10 @@ func (r *Reconciler) dispatchManifests(ctx context.Context, resourceTracker *v1b
 		BlockOwnerDeletion: pointer.BoolPtr(true),
 	}}
 
-	applicator := apply.NewAPIApplicator(r.Client)
+	applicator := r.applicator
+	if applicator == nil {
+		applicator = apply.NewAPIApplicator(r.Client)
+	}
 	for _, app := range envBindApps {
 		for _, obj := range app.ScheduledManifests {
 			obj.SetOwnerReferences(ownerReference)
@@ -250,12 +255,12 @@ func (r *Reconciler) updateResourceTrackerStatus(ctx context.Context, rtName str
 	return nil
 }
 
-func (r *Reconciler) handleFinalizers(ctx context.Context, envBinding *v1alpha1.EnvBinding) (bool, error) {
+func (r *Reconciler) handleFinalizers(ctx context.Context, envBinding *v1alpha1.EnvBinding) (bool, time.Duration, error) {
 	if envBinding.ObjectMeta.DeletionTimestamp.IsZero() {
 		if !meta.FinalizerExists(envBinding, resourceTrackerFinalizer) {
 			meta.AddFinalizer(envBinding, resourceTrackerFinalizer)
 			klog.InfoS("Register new finalizer for envBinding", "envBinding", klog.KObj(envBinding), "finalizer", resourceTrackerFinalizer)
-			return true, errors.Wrap(r.Client.Update(ctx, envBinding), "cannot update envBinding finalizer")
+			return true, 0, errors.Wrap(r.Client.Update(ctx, envBinding), "cannot update envBinding finalizer")
 		}
 	} else {
 		if meta.FinalizerExists(envBinding, resourceTrackerFinalizer) {
@@ -263,22 +268,27 @@ func (r *Reconciler) handleFinalizers(ctx context.Context, envBinding *v1alpha1.
 			rt.SetName(constructResourceTrackerName(envBinding.Name, envBinding.Namespace))
 			if err := r.Client.Get(ctx, client.ObjectKey{Name: rt.Name}, rt); err != nil && !kerrors.IsNotFound(err) {
 				klog.ErrorS(err, "Failed to get resource tracker of envBinding", "envBinding", klog.KObj(envBinding))
-				return true, errors.WithMessage(err, "cannot remove finalizer")
+				return true, 0, errors.WithMessage(err, "cannot remove finalizer")
 			}
 
 			if err := r.Client.Delete(ctx, rt); err != nil && !kerrors.IsNotFound(err) {
 				klog.ErrorS(err, "Failed to delete resource tracker of envBinding", "envBinding", klog.KObj(envBinding))
-				return true, errors.WithMessage(err, "cannot remove finalizer")
+				return true, 0, errors.WithMessage(err, "cannot remove finalizer")
 			}
 
-			if err := GarbageCollectionForAllResourceTrackersInSubCluster(ctx, r.Client, envBinding); err != nil {
-				return true, err
+			wait, err := GarbageCollectionForAllResourceTrackersInSubCluster(ctx, r.Client, envBinding)
+			if err != nil {
+				return true, 0, err
+			}
+			if wait > 0 {
+				klog.InfoS("Wait for the retained resources of envBinding to expire", "envBinding", klog.KObj(envBinding), "wait", wait)
+				return true, wait, nil
 			}
 			meta.RemoveFinalizer(envBinding, resourceTrackerFinalizer)
-			return true, errors.Wrap(r.Client.Update(ctx, envBinding), "cannot update envBinding finalizer")
+			return true, 0, errors.Wrap(r.Client.Update(ctx, envBinding), "cannot update envBinding finalizer")
 		}
 	}
-	return false, nil
+	return false, 0, nil
 }
 
 func (r *Reconciler) endWithNegativeCondition(ctx context.Context, envBinding *v1alpha1.EnvBinding, cond condition.Condition) (ctrl.Result, error) {
@@ -315,6 +325,7 @@ func Setup(mgr ctrl.Manager, args oamctrl.Args) error {
 		dm:                   args.DiscoveryMapper,
 		pd:                   args.PackageDiscover,
 		Scheme:               mgr.GetScheme(),
+		applicator:           args.NewApplicator(mgr.GetClient(), "envbinding"),
 		concurrentReconciles: args.ConcurrentReconciles,
 	}
 	return r.SetupWithManager(mgr)
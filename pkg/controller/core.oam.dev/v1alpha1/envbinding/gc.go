@@ -18,6 +18,7 @@ package envbinding
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/dispatch"
 	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
@@ -71,13 +73,20 @@ func GarbageCollectionForOutdatedResourcesInSubClusters(ctx context.Context, c c
 	return nil
 }
 
-// GarbageCollectionForAllResourceTrackersInSubCluster run garbage collection in sub clusters and remove all ResourceTrackers for the EnvBinding
-func GarbageCollectionForAllResourceTrackersInSubCluster(ctx context.Context, c client.Client, envBinding *v1alpha1.EnvBinding) error {
+// GarbageCollectionForAllResourceTrackersInSubCluster run garbage collection in sub clusters and remove all ResourceTrackers for the EnvBinding.
+// The ResourceTrackers are kept until the resources retained by the garbage-collect policy expire, the returned
+// duration is the time to wait for them.
+func GarbageCollectionForAllResourceTrackersInSubCluster(ctx context.Context, c client.Client, envBinding *v1alpha1.EnvBinding) (time.Duration, error) {
 	baseApp, err := util.RawExtension2Application(envBinding.Spec.AppTemplate.RawExtension)
 	if err != nil {
 		klog.ErrorS(err, "failed to parse AppTemplate of EnvBinding")
-		return errors.WithMessage(err, "cannot remove finalizer")
+		return 0, errors.WithMessage(err, "cannot remove finalizer")
 	}
+	gcRules, err := dispatch.ParseGarbageCollectRules(baseApp.Spec.Policies)
+	if err != nil {
+		return 0, errors.WithMessage(err, "cannot remove finalizer")
+	}
+	var wait time.Duration
 	// delete subCluster resourceTracker
 	for _, decision := range envBinding.Status.ClusterDecisions {
 		subCtx := multicluster.ContextWithClusterName(ctx, decision.Cluster)
@@ -89,14 +98,25 @@ func GarbageCollectionForAllResourceTrackersInSubCluster(ctx context.Context, c
 		rtList := &v1beta1.ResourceTrackerList{}
 		if err := c.List(subCtx, rtList, listOpts...); err != nil {
 			klog.ErrorS(err, "failed to list resource tracker of app", "name", baseApp.Name, "env", decision.Env)
-			return errors.WithMessage(err, "cannot remove finalizer")
+			return 0, errors.WithMessage(err, "cannot remove finalizer")
+		}
+		clusterWait, err := dispatch.ReleaseResourcesOnDelete(subCtx, c, gcRules, rtList.Items)
+		if err != nil {
+			klog.ErrorS(err, "failed to release resources of app by garbage-collect policy", "name", baseApp.Name, "env", decision.Env)
+			return 0, errors.WithMessage(err, "cannot remove finalizer")
+		}
+		if clusterWait > 0 {
+			if wait == 0 || clusterWait < wait {
+				wait = clusterWait
+			}
+			continue
 		}
 		for _, rt := range rtList.Items {
 			if err := c.Delete(subCtx, rt.DeepCopy()); err != nil && !kerrors.IsNotFound(err) {
 				klog.ErrorS(err, "failed to delete resource tracker", "name", rt.Name)
-				return errors.WithMessage(err, "cannot remove finalizer")
+				return 0, errors.WithMessage(err, "cannot remove finalizer")
 			}
 		}
 	}
-	return nil
+	return wait, nil
 }
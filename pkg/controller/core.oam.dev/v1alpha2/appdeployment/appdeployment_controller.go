@@ -308,7 +308,7 @@ func (r *Reconciler) getWorkloadsFromRevision(ctx context.Context, revName, ns s
 	if err != nil {
 		return nil, err
 	}
-	manifs, err := af.GenerateComponentManifests()
+	manifs, err := af.GenerateComponentManifests(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -34,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
@@ -43,6 +44,7 @@ import (
 	common2 "github.com/oam-dev/kubevela/pkg/controller/common"
 	core "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha1/envbinding"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/dispatch"
 	"github.com/oam-dev/kubevela/pkg/cue/packages"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
@@ -78,6 +80,7 @@ type Reconciler struct {
 	applicator           apply.Applicator
 	appRevisionLimit     int
 	concurrentReconciles int
+	enqueuer             *appEnqueueHandler
 }
 
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applications,verbs=get;list;watch;create;update;patch;delete
@@ -90,6 +93,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	defer cancel()
 
 	klog.InfoS("Reconcile application", "application", klog.KRef(req.Namespace, req.Name))
+	if r.enqueuer != nil {
+		r.enqueuer.done(req.NamespacedName)
+	}
 
 	app := new(v1beta1.Application)
 	if err := r.Get(ctx, client.ObjectKey{
@@ -110,43 +116,73 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		app:    app,
 		parser: appParser,
 	}
-	endReconcile, err := r.handleFinalizers(ctx, app)
+	if isAppPaused(app) {
+		return r.reconcilePaused(ctx, handler)
+	}
+	if needPreDelete(app) {
+		finished, err := r.reconcilePreDelete(ctx, handler)
+		if err != nil {
+			klog.ErrorS(err, "Failed to run the pre-delete workflow", "application", klog.KObj(app))
+			return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("PreDelete", err), common.ApplicationDeleting)
+		}
+		if !finished {
+			return ctrl.Result{RequeueAfter: baseWorkflowBackoffWaitTime}, nil
+		}
+	}
+	endReconcile, requeueAfter, err := r.handleFinalizers(ctx, app)
 	if err != nil {
 		return r.endWithNegativeCondition(ctx, app, condition.ReconcileError(err), common.ApplicationStarting)
 	}
 	if endReconcile {
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
-	appFile, err := appParser.GenerateAppFile(ctx, app)
+	rollbackRev, err := r.getRollbackRevision(ctx, app)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get the revision to roll back", "application", klog.KObj(app))
+		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedRollback, err))
+		return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("Rollback", err), common.ApplicationRendering)
+	}
+
+	var appFile *appfile.Appfile
+	if rollbackRev != nil {
+		handler.useRollbackRevision(rollbackRev)
+		appFile, err = appParser.GenerateAppFileFromRevision(rollbackRev)
+	} else {
+		appFile, err = appParser.GenerateAppFile(ctx, app)
+	}
 	if err != nil {
 		klog.ErrorS(err, "Failed to parse application", "application", klog.KObj(app))
 		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedParse, err))
 		return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("Parsed", err), common.ApplicationRendering)
 	}
+	resumePausedCondition(app)
 	app.Status.SetConditions(condition.ReadyCondition("Parsed"))
 	r.Recorder.Event(app, event.Normal(velatypes.ReasonParsed, velatypes.MessageParsed))
 
-	if err := handler.PrepareCurrentAppRevision(ctx, appFile); err != nil {
-		klog.ErrorS(err, "Failed to prepare app revision", "application", klog.KObj(app))
-		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedRevision, err))
-		return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("Revision", err), common.ApplicationRendering)
-	}
-	if err := handler.FinalizeAndApplyAppRevision(ctx); err != nil {
-		klog.ErrorS(err, "Failed to apply app revision", "application", klog.KObj(app))
-		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedRevision, err))
-		return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("Revision", err), common.ApplicationRendering)
-	}
-	klog.Info("Successfully prepare current app revision", "revisionName", handler.currentAppRev.Name,
-		"revisionHash", handler.currentRevHash, "isNewRevision", handler.isNewRevision)
-	app.Status.SetConditions(condition.ReadyCondition("Revision"))
-	r.Recorder.Event(app, event.Normal(velatypes.ReasonRevisoned, velatypes.MessageRevisioned))
-
-	if err := handler.UpdateAppLatestRevisionStatus(ctx); err != nil {
-		klog.ErrorS(err, "Failed to update application status", "application", klog.KObj(app))
-		return r.endWithNegativeCondition(ctx, app, condition.ReconcileError(err), common.ApplicationRendering)
+	// the revision rolled back to is used as it is, no new revision is created until the spec is changed
+	if rollbackRev == nil {
+		if err := handler.PrepareCurrentAppRevision(ctx, appFile); err != nil {
+			klog.ErrorS(err, "Failed to prepare app revision", "application", klog.KObj(app))
+			r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedRevision, err))
+			return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("Revision", err), common.ApplicationRendering)
+		}
+		if err := handler.FinalizeAndApplyAppRevision(ctx); err != nil {
+			klog.ErrorS(err, "Failed to apply app revision", "application", klog.KObj(app))
+			r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedRevision, err))
+			return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("Revision", err), common.ApplicationRendering)
+		}
+		klog.Info("Successfully prepare current app revision", "revisionName", handler.currentAppRev.Name,
+			"revisionHash", handler.currentRevHash, "isNewRevision", handler.isNewRevision)
+		app.Status.SetConditions(condition.ReadyCondition("Revision"))
+		r.Recorder.Event(app, event.Normal(velatypes.ReasonRevisoned, velatypes.MessageRevisioned))
+
+		if err := handler.UpdateAppLatestRevisionStatus(ctx); err != nil {
+			klog.ErrorS(err, "Failed to update application status", "application", klog.KObj(app))
+			return r.endWithNegativeCondition(ctx, app, condition.ReconcileError(err), common.ApplicationRendering)
+		}
+		klog.Info("Successfully apply application revision", "application", klog.KObj(app))
 	}
-	klog.Info("Successfully apply application revision", "application", klog.KObj(app))
 
 	policies, err := appFile.PrepareWorkflowAndPolicy()
 	if err != nil {
@@ -216,7 +252,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		klog.Info("Application manifests has applied by workflow successfully", "application", klog.KObj(app))
 	} else {
 		var comps []*velatypes.ComponentManifest
-		comps, err = appFile.GenerateComponentManifests()
+		comps, err = appFile.GenerateComponentManifests(ctx)
 		if err != nil {
 			klog.ErrorS(err, "Failed to render components", "application", klog.KObj(app))
 			r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedRender, err))
@@ -255,11 +291,19 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		klog.InfoS("Finished rollout ", "application", klog.KObj(app))
 	}
 	var phase = common.ApplicationRunning
+	var healthRequeue time.Duration
 	if !hasHealthCheckPolicy(appFile.Policies) {
 		app.Status.Services = handler.services
-		if !isHealthy(handler.services) {
+		health, requeue, err := handler.updateAppHealth(handler.services)
+		if err != nil {
+			klog.ErrorS(err, "Failed to aggregate health status", "application", klog.KObj(app))
+			r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedHealthCheck, err))
+			return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("HealthCheck", err), common.ApplicationRunning)
+		}
+		if !health.Healthy && !health.InGracePeriod {
 			phase = common.ApplicationUnhealthy
 		}
+		healthRequeue = requeue
 	}
 
 	if err := garbageCollection(ctx, handler); err != nil {
@@ -268,6 +312,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return r.endWithNegativeCondition(ctx, app, condition.ReconcileError(err), phase)
 	}
 	klog.Info("Successfully garbage collect", "application", klog.KObj(app))
+
+	var driftRequeue time.Duration
+	if !appWillRollout(app) {
+		if driftRequeue, err = handler.DetectDrift(ctx, appFile); err != nil {
+			klog.ErrorS(err, "Failed to detect drift", "application", klog.KObj(app))
+			r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedDriftDetection, err))
+			return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("DriftDetection", err), phase)
+		}
+	}
 	app.Status.SetConditions(condition.Condition{
 		Type:               condition.TypeReady,
 		Status:             corev1.ConditionTrue,
@@ -275,18 +328,20 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		Reason:             condition.ReasonReconcileSuccess,
 	})
 	r.Recorder.Event(app, event.Normal(velatypes.ReasonDeployed, velatypes.MessageDeployed))
-	return ctrl.Result{}, r.patchStatus(ctx, app, phase)
+	return ctrl.Result{RequeueAfter: minRequeueAfter(healthRequeue, driftRequeue, handler.gcRequeueAfter())}, r.patchStatus(ctx, app, phase)
 }
 
 // NOTE Because resource tracker is cluster-scoped resources, we cannot garbage collect them
 // by setting application(namespace-scoped) as their owners.
 // We must delete all resource trackers related to an application through finalizer logic.
-func (r *Reconciler) handleFinalizers(ctx context.Context, app *v1beta1.Application) (bool, error) {
+// The resource trackers are kept until the resources retained by the garbage-collect policy expire, the returned
+// duration is the time to wait for them.
+func (r *Reconciler) handleFinalizers(ctx context.Context, app *v1beta1.Application) (bool, time.Duration, error) {
 	if app.ObjectMeta.DeletionTimestamp.IsZero() {
 		if !meta.FinalizerExists(app, resourceTrackerFinalizer) {
 			meta.AddFinalizer(app, resourceTrackerFinalizer)
 			klog.InfoS("Register new finalizer for application", "application", klog.KObj(app), "finalizer", resourceTrackerFinalizer)
-			return true, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
+			return true, 0, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
 		}
 	} else {
 		if meta.FinalizerExists(app, legacyResourceTrackerFinalizer) {
@@ -296,10 +351,10 @@ func (r *Reconciler) handleFinalizers(ctx context.Context, app *v1beta1.Applicat
 			rt.SetName(fmt.Sprintf("%s-%s", app.Namespace, app.Name))
 			if err := r.Client.Delete(ctx, rt); err != nil && !kerrors.IsNotFound(err) {
 				klog.ErrorS(err, "Failed to delete legacy resource tracker", "name", rt.Name)
-				return true, errors.WithMessage(err, "cannot remove finalizer")
+				return true, 0, errors.WithMessage(err, "cannot remove finalizer")
 			}
 			meta.RemoveFinalizer(app, legacyResourceTrackerFinalizer)
-			return true, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
+			return true, 0, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
 		}
 		if meta.FinalizerExists(app, resourceTrackerFinalizer) || meta.FinalizerExists(app, legacyOnlyRevisionFinalizer) {
 			listOpts := []client.ListOption{
@@ -310,22 +365,38 @@ func (r *Reconciler) handleFinalizers(ctx context.Context, app *v1beta1.Applicat
 			rtList := &v1beta1.ResourceTrackerList{}
 			if err := r.Client.List(ctx, rtList, listOpts...); err != nil {
 				klog.ErrorS(err, "Failed to list resource tracker of app", "name", app.Name)
-				return true, errors.WithMessage(err, "cannot remove finalizer")
+				return true, 0, errors.WithMessage(err, "cannot remove finalizer")
+			}
+			gcRules, err := dispatch.ParseGarbageCollectRules(app.Spec.Policies)
+			if err != nil {
+				// a malformed policy must not block the deletion, the resources are released by the default strategy
+				klog.ErrorS(err, "Failed to parse garbage-collect policy of app, fall back to the default strategy", "name", app.Name)
+				r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedGC, errors.WithMessage(err, "fall back to the default garbage-collect strategy")))
+				gcRules = nil
+			}
+			wait, err := dispatch.ReleaseResourcesOnDelete(ctx, r.Client, gcRules, rtList.Items)
+			if err != nil {
+				klog.ErrorS(err, "Failed to release resources of app by garbage-collect policy", "name", app.Name)
+				return true, 0, errors.WithMessage(err, "cannot remove finalizer")
+			}
+			if wait > 0 {
+				klog.InfoS("Wait for the retained resources of app to expire", "name", app.Name, "wait", wait)
+				return true, wait, nil
 			}
 			for _, rt := range rtList.Items {
 				if err := r.Client.Delete(ctx, rt.DeepCopy()); err != nil && !kerrors.IsNotFound(err) {
 					klog.ErrorS(err, "Failed to delete resource tracker", "name", rt.Name)
-					return true, errors.WithMessage(err, "cannot remove finalizer")
+					return true, 0, errors.WithMessage(err, "cannot remove finalizer")
 				}
 			}
 			meta.RemoveFinalizer(app, resourceTrackerFinalizer)
 			// legacyOnlyRevisionFinalizer will be deprecated in the future
 			// this is for backward compatibility
 			meta.RemoveFinalizer(app, legacyOnlyRevisionFinalizer)
-			return true, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
+			return true, 0, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
 		}
 	}
-	return false, nil
+	return false, 0, nil
 }
 
 func (r *Reconciler) endWithNegativeCondition(ctx context.Context, app *v1beta1.Application, condition condition.Condition, phase common.ApplicationPhase) (ctrl.Result, error) {
@@ -359,29 +430,36 @@ func hasHealthCheckPolicy(policies []*appfile.Workload) bool {
 	return false
 }
 
-func isHealthy(services []common.ApplicationComponentStatus) bool {
-	for _, service := range services {
-		if !service.Healthy {
-			return false
-		}
-		for _, tr := range service.Traits {
-			if !tr.Healthy {
-				return false
-			}
+// minRequeueAfter returns the minimum positive duration, zero if none of them is positive
+func minRequeueAfter(durations ...time.Duration) time.Duration {
+	var min time.Duration
+	for _, d := range durations {
+		if d > 0 && (min == 0 || d < min) {
+			min = d
 		}
 	}
-	return true
+	return min
 }
 
 // SetupWithManager install to manager
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.enqueuer == nil {
+		r.enqueuer = newAppEnqueueHandler(RateLimitOptions{})
+	}
+	r.enqueuer.setQueueDepth(r.concurrentReconciles)
+	if err := mgr.Add(r.enqueuer); err != nil {
+		return err
+	}
 	// If Application Own these two child objects, AC status change will notify application controller and recursively update AC again, and trigger application event again...
-	return ctrl.NewControllerManagedBy(mgr).
-		WithOptions(controller.Options{
-			MaxConcurrentReconciles: r.concurrentReconciles,
-		}).
-		For(&v1beta1.Application{}).
-		Complete(r)
+	c, err := controller.New("application", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: r.concurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+	// applications are enqueued by priority and rate limited per namespace instead of the default handler
+	return c.Watch(&source.Kind{Type: &v1beta1.Application{}}, r.enqueuer)
 }
 
 // Setup adds a controller that reconciles AppRollout.
@@ -392,9 +470,15 @@ func Setup(mgr ctrl.Manager, args core.Args) error {
 		Recorder:             event.NewAPIRecorder(mgr.GetEventRecorderFor("Application")),
 		dm:                   args.DiscoveryMapper,
 		pd:                   args.PackageDiscover,
-		applicator:           apply.NewAPIApplicator(mgr.GetClient()),
+		applicator:           args.NewApplicator(mgr.GetClient(), "application"),
 		appRevisionLimit:     args.AppRevisionLimit,
 		concurrentReconciles: args.ConcurrentReconciles,
+		enqueuer: newAppEnqueueHandler(RateLimitOptions{
+			NamespaceQPS:     args.AppNamespaceQPS,
+			NamespaceBurst:   args.AppNamespaceBurst,
+			LowPriorityQPS:   args.AppLowPriorityQPS,
+			LowPriorityBurst: args.AppLowPriorityBurst,
+		}),
 	}
 	return reconciler.SetupWithManager(mgr)
 }
@@ -364,7 +364,7 @@ var _ = Describe("Test Application Controller", func() {
 
 		af, err := appParser.GenerateAppFileFromRevision(appRev)
 		Expect(err).Should(BeNil())
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(ctx)
 		Expect(err).Should(BeNil())
 		Expect(len(comps) > 0).Should(BeTrue())
 		comp := comps[0]
@@ -423,7 +423,7 @@ var _ = Describe("Test Application Controller", func() {
 
 		af, err := appParser.GenerateAppFileFromRevision(appRev)
 		Expect(err).Should(BeNil())
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(ctx)
 		Expect(err).Should(BeNil())
 		Expect(len(comps) > 0).Should(BeTrue())
 		comp := comps[0]
@@ -493,7 +493,7 @@ var _ = Describe("Test Application Controller", func() {
 		By("Check AppRevision Created with the expected workload spec")
 		af, err := appParser.GenerateAppFileFromRevision(appRevision)
 		Expect(err).Should(BeNil())
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(ctx)
 		Expect(err).Should(BeNil())
 		Expect(len(comps) > 0).Should(BeTrue())
 		comp := comps[0]
@@ -589,7 +589,7 @@ var _ = Describe("Test Application Controller", func() {
 		By("Check AppRevision Created with the expected workload spec")
 		af, err := appParser.GenerateAppFileFromRevision(appRevision)
 		Expect(err).Should(BeNil())
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(ctx)
 		Expect(err).Should(BeNil())
 		Expect(len(comps) > 0).Should(BeTrue())
 		comp := comps[0]
@@ -660,7 +660,7 @@ var _ = Describe("Test Application Controller", func() {
 
 		af, err := appParser.GenerateAppFileFromRevision(appRevision)
 		Expect(err).Should(BeNil())
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(ctx)
 		Expect(err).Should(BeNil())
 		Expect(len(comps) > 0).Should(BeTrue())
 		comp1 := comps[0]
@@ -732,7 +732,7 @@ var _ = Describe("Test Application Controller", func() {
 
 		af, err = appParser.GenerateAppFileFromRevision(appRevision)
 		Expect(err).Should(BeNil())
-		comps, err = af.GenerateComponentManifests()
+		comps, err = af.GenerateComponentManifests(ctx)
 		Expect(err).Should(BeNil())
 		Expect(len(comps) > 0).Should(BeTrue())
 		comp1 = comps[0]
@@ -798,7 +798,7 @@ var _ = Describe("Test Application Controller", func() {
 
 		af, err := appParser.GenerateAppFileFromRevision(appRevision)
 		Expect(err).Should(BeNil())
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(ctx)
 		Expect(err).Should(BeNil())
 		Expect(len(comps) > 0).Should(BeTrue())
 		comp := comps[0]
@@ -958,7 +958,7 @@ var _ = Describe("Test Application Controller", func() {
 
 		af, err := appParser.GenerateAppFileFromRevision(appRevision)
 		Expect(err).Should(BeNil())
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(ctx)
 		Expect(err).Should(BeNil())
 		Expect(len(comps) > 0).Should(BeTrue())
 		comp := comps[0]
@@ -1290,7 +1290,7 @@ var _ = Describe("Test Application Controller", func() {
 
 		af, err := appParser.GenerateAppFileFromRevision(appRevision)
 		Expect(err).Should(BeNil())
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(ctx)
 		Expect(err).Should(BeNil())
 		Expect(len(comps) > 0).Should(BeTrue())
 		comp := comps[0]
@@ -1397,7 +1397,7 @@ var _ = Describe("Test Application Controller", func() {
 
 		af, err := appParser.GenerateAppFileFromRevision(appRevision)
 		Expect(err).Should(BeNil())
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(ctx)
 		Expect(err).Should(BeNil())
 		Expect(len(comps) > 0).Should(BeTrue())
 		comp := comps[0]
@@ -20,8 +20,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"testing"
+	"time"
 
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/testutil"
+	utilscommon "github.com/oam-dev/kubevela/pkg/utils/common"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -473,3 +482,41 @@ spec:
       }
 `
 )
+
+func TestHandleFinalizersWithMalformedGCPolicy(t *testing.T) {
+	ctx := context.Background()
+	now := metav1.Now()
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "app",
+			Namespace:         "default",
+			Finalizers:        []string{resourceTrackerFinalizer},
+			DeletionTimestamp: &now,
+		},
+		Spec: v1beta1.ApplicationSpec{
+			Policies: []v1beta1.AppPolicy{{
+				Name:       "gc",
+				Type:       "garbage-collect",
+				Properties: runtime.RawExtension{Raw: []byte(`{"rules":[{"strategy":"unknown"}]}`)},
+			}},
+		},
+	}
+	rt := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{
+		Name:   "default-app-v1",
+		Labels: map[string]string{oam.LabelAppName: "app", oam.LabelAppNamespace: "default"},
+	}}
+	cli := fake.NewClientBuilder().WithScheme(utilscommon.Scheme).WithObjects(app, rt).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{Client: cli, Recorder: event.NewAPIRecorder(recorder)}
+
+	// the malformed policy does not block the deletion
+	endReconcile, wait, err := r.handleFinalizers(ctx, app)
+	assert.NoError(t, err)
+	assert.True(t, endReconcile)
+	assert.Equal(t, time.Duration(0), wait)
+	assert.Empty(t, app.Finalizers)
+	assert.Contains(t, <-recorder.Events, "fall back to the default garbage-collect strategy")
+	rtList := &v1beta1.ResourceTrackerList{}
+	assert.NoError(t, cli.List(ctx, rtList))
+	assert.Empty(t, rtList.Items)
+}
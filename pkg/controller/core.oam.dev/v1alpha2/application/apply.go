@@ -18,6 +18,7 @@ package application
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -156,10 +157,18 @@ func (h *AppHandler) initDispatcher() {
 	if h.dispatcher == nil {
 		// only do GC when ALL resources are dispatched successfully
 		// so skip GC while dispatching addon resources
-		h.dispatcher = dispatch.NewAppManifestsDispatcher(h.r.Client, h.currentAppRev).StartAndSkipGC(h.latestTracker)
+		h.dispatcher = dispatch.NewAppManifestsDispatcher(h.r.Client, h.currentAppRev).WithApplicator(h.r.applicator).StartAndSkipGC(h.latestTracker)
 	}
 }
 
+// gcRequeueAfter returns the duration until the first retained resource expires by its delay, 0 if there is none
+func (h *AppHandler) gcRequeueAfter() time.Duration {
+	if h.dispatcher == nil {
+		return 0
+	}
+	return h.dispatcher.GCRequeueAfter()
+}
+
 // ProduceArtifacts will produce Application artifacts that will be saved in configMap.
 func (h *AppHandler) ProduceArtifacts(ctx context.Context, comps []*types.ComponentManifest, policies []*unstructured.Unstructured) error {
 	return h.createResourcesConfigMap(ctx, h.currentAppRev, comps, policies)
@@ -215,9 +224,12 @@ func (h *AppHandler) collectHealthStatus(wl *appfile.Workload, appRev *v1beta1.A
 	return &status, isHealth, nil
 }
 
-func (h *AppHandler) aggregateHealthStatus(appFile *appfile.Appfile) ([]common.ApplicationComponentStatus, bool, error) {
+func (h *AppHandler) aggregateHealthStatus(ctx context.Context, appFile *appfile.Appfile) ([]common.ApplicationComponentStatus, bool, error) {
 	var appStatus []common.ApplicationComponentStatus
-	var healthy = true
+	policy, err := getAppHealthPolicy(h.app)
+	if err != nil {
+		return nil, false, err
+	}
 	for _, wl := range appFile.Workloads {
 		var status = common.ApplicationComponentStatus{
 			Name:               wl.Name,
@@ -230,22 +242,20 @@ func (h *AppHandler) aggregateHealthStatus(appFile *appfile.Appfile) ([]common.A
 		switch wl.CapabilityCategory {
 		case types.TerraformCategory:
 			pCtx = appfile.NewBasicContext(wl, appFile.Name, appFile.AppRevisionName, appFile.Namespace)
-			ctx := context.Background()
 			var configuration terraformapi.Configuration
 			if err := h.r.Client.Get(ctx, client.ObjectKey{Name: wl.Name, Namespace: h.app.Namespace}, &configuration); err != nil {
 				return nil, false, errors.WithMessagef(err, "app=%s, comp=%s, check health error", appFile.Name, wl.Name)
 			}
 			if configuration.Status.Apply.State != terraformtypes.Available {
-				healthy = false
 				status.Healthy = false
 			} else {
 				status.Healthy = true
 			}
 			status.Message = configuration.Status.Apply.Message
 		default:
-			pCtx = process.NewContext(h.app.Namespace, wl.Name, appFile.Name, appFile.AppRevisionName)
+			pCtx = process.NewProcessContextWithCtx(ctx, h.app.Namespace, wl.Name, appFile.Name, appFile.AppRevisionName)
 			if !h.isNewRevision && wl.CapabilityCategory != types.CUECategory {
-				templateStr, err := appfile.GenerateCUETemplate(wl)
+				templateStr, err := appfile.GenerateCUETemplate(ctx, wl, h.app.Namespace)
 				if err != nil {
 					return nil, false, err
 				}
@@ -262,7 +272,6 @@ func (h *AppHandler) aggregateHealthStatus(appFile *appfile.Appfile) ([]common.A
 			if !workloadHealth {
 				// TODO(wonderflow): we should add a custom way to let the template say why it's unhealthy, only a bool flag is not enough
 				status.Healthy = false
-				healthy = false
 			}
 
 			status.Message, err = wl.EvalStatus(pCtx, h.r.Client, h.app.Namespace)
@@ -288,7 +297,6 @@ func (h *AppHandler) aggregateHealthStatus(appFile *appfile.Appfile) ([]common.A
 			if !traitHealth {
 				// TODO(wonderflow): we should add a custom way to let the template say why it's unhealthy, only a bool flag is not enough
 				traitStatus.Healthy = false
-				healthy = false
 			}
 			traitStatus.Message, err = tr.EvalStatus(pCtx, h.r.Client, h.app.Namespace)
 			if err != nil {
@@ -301,7 +309,9 @@ func (h *AppHandler) aggregateHealthStatus(appFile *appfile.Appfile) ([]common.A
 		status.Scopes = generateScopeReference(wl.Scopes)
 		appStatus = append(appStatus, status)
 	}
-	return appStatus, healthy, nil
+	// the optional components do not affect the health of the application
+	unhealthy, _ := policy.componentsHealthy(appStatus)
+	return appStatus, len(unhealthy) == 0, nil
 }
 
 func (h *AppHandler) handleCheckManageWorkloadTrait(traitDefs map[string]v1beta1.TraitDefinition, comps []*types.ComponentManifest) {
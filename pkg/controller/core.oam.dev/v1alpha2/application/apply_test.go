@@ -186,7 +186,7 @@ var _ = Describe("Test statusAggregate", func() {
 		)
 
 		By("aggregate status")
-		statuses, healthy, err := h.aggregateHealthStatus(appFile)
+		statuses, healthy, err := h.aggregateHealthStatus(context.Background(), appFile)
 		Expect(statuses).Should(BeNil())
 		Expect(healthy).Should(Equal(false))
 		Expect(err).Should(HaveOccurred())
@@ -199,7 +199,7 @@ var _ = Describe("Test statusAggregate", func() {
 		k8sClient.Create(ctx, &configuration)
 
 		By("aggregate status again")
-		statuses, healthy, err = h.aggregateHealthStatus(appFile)
+		statuses, healthy, err = h.aggregateHealthStatus(context.Background(), appFile)
 		Expect(len(statuses)).Should(Equal(1))
 		Expect(healthy).Should(Equal(false))
 		Expect(err).Should(BeNil())
@@ -211,7 +211,7 @@ var _ = Describe("Test statusAggregate", func() {
 		k8sClient.Status().Update(ctx, &gotConfiguration)
 
 		By("aggregate status one more time")
-		statuses, healthy, err = h.aggregateHealthStatus(appFile)
+		statuses, healthy, err = h.aggregateHealthStatus(context.Background(), appFile)
 		Expect(len(statuses)).Should(Equal(1))
 		Expect(healthy).Should(Equal(true))
 		Expect(err).Should(BeNil())
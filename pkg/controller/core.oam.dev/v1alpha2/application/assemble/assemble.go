@@ -17,6 +17,8 @@ limitations under the License.
 package assemble
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -247,7 +249,7 @@ func (am *AppManifests) complete() error {
 		if err != nil {
 			return errors.WithMessage(err, "fail to generate appfile from revision for app manifests complete")
 		}
-		am.componentManifests, err = af.GenerateComponentManifests()
+		am.componentManifests, err = af.GenerateComponentManifests(context.Background())
 		if err != nil {
 			return errors.WithMessage(err, "fail to complete manifests as generate from app revision failed")
 		}
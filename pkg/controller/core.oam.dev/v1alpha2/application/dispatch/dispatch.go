@@ -19,6 +19,7 @@ package dispatch
 import (
 	"context"
 	"reflect"
+	"time"
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/oam"
@@ -58,12 +60,15 @@ type AppManifestsDispatcher struct {
 	appRev     *v1beta1.ApplicationRevision
 	previousRT *v1beta1.ResourceTracker
 	skipGC     bool
+	gcRules    []v1alpha1.GarbageCollectPolicyRule
 
 	appRevName    string
 	namespace     string
 	currentRTName string
 	currentRT     *v1beta1.ResourceTracker
 	legacyRTs     []*v1beta1.ResourceTracker
+	// gcRequeueAfter is the duration until the first retained resource expires by its delay
+	gcRequeueAfter time.Duration
 }
 
 // EndAndGC return an AppManifestsDispatcher that do GC after dispatching resources.
@@ -89,6 +94,15 @@ func (a *AppManifestsDispatcher) StartAndSkipGC(previousRT *v1beta1.ResourceTrac
 	return a
 }
 
+// WithApplicator return an AppManifestsDispatcher that applies manifests by the given Applicator,
+// the default client-side Applicator is kept if it's nil.
+func (a *AppManifestsDispatcher) WithApplicator(applicator apply.Applicator) *AppManifestsDispatcher {
+	if applicator != nil {
+		a.applicator = applicator
+	}
+	return a
+}
+
 // Dispatch apply manifests into k8s and return a resource tracker recording applied manifests' references.
 // If GC is enabled, it will do GC after applying.
 // If 'UpgradeAndSkipGC' is enabled, it will:
@@ -109,13 +123,33 @@ func (a *AppManifestsDispatcher) Dispatch(ctx context.Context, manifests []*unst
 		return nil, err
 	}
 	if !a.skipGC && a.previousRT != nil && a.previousRT.Name != a.currentRTName {
-		if err := a.gcHandler.GarbageCollect(ctx, a.previousRT, a.currentRT, a.legacyRTs); err != nil {
+		if err := a.gcHandler.GarbageCollect(ctx, a.previousRT, a.currentRT, a.legacyRTs, a.gcRules); err != nil {
 			return nil, errors.WithMessagef(err, "cannot do GC based on resource trackers %q and %q", a.previousRT.Name, a.currentRTName)
 		}
+		a.setGCRequeueAfter(nextRetainedResourceExpiry(a.currentRT))
+	} else if !a.skipGC {
+		requeueAfter, err := a.gcHandler.CollectExpiredResources(ctx, a.currentRT)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "cannot do GC for the retained resources of resource tracker %q", a.currentRTName)
+		}
+		a.setGCRequeueAfter(requeueAfter)
 	}
 	return a.currentRT.DeepCopy(), nil
 }
 
+// GCRequeueAfter returns the duration until the first retained resource of the dispatched resource trackers expires
+// by its delay, 0 if there is none. The application should be reconciled again then to delete the resource.
+func (a *AppManifestsDispatcher) GCRequeueAfter() time.Duration {
+	return a.gcRequeueAfter
+}
+
+// setGCRequeueAfter keeps the shortest duration as the dispatcher may dispatch to several clusters
+func (a *AppManifestsDispatcher) setGCRequeueAfter(d time.Duration) {
+	if d > 0 && (a.gcRequeueAfter == 0 || d < a.gcRequeueAfter) {
+		a.gcRequeueAfter = d
+	}
+}
+
 // ReferenceScopes add workload reference to scopes' workloadRefPath
 func (a *AppManifestsDispatcher) ReferenceScopes(ctx context.Context, wlRef *v1.ObjectReference, scopes []*v1.ObjectReference) error {
 	// TODO handle scopes
@@ -138,6 +172,11 @@ func (a *AppManifestsDispatcher) validateAndComplete(ctx context.Context) error
 	a.appRevName = a.appRev.Name
 	a.namespace = a.appRev.Namespace
 	a.currentRTName = ConstructResourceTrackerName(a.appRevName, a.namespace)
+	gcRules, err := ParseGarbageCollectRules(a.appRev.Spec.Application.Spec.Policies)
+	if err != nil {
+		return err
+	}
+	a.gcRules = gcRules
 
 	// if upgrade is enabled (no matter GC or skip GC), it requires a valid existing resource tracker
 	if a.previousRT != nil && a.previousRT.Name != a.currentRTName {
@@ -207,6 +246,11 @@ func (a *AppManifestsDispatcher) retrieveLegacyResourceTrackers(ctx context.Cont
 }
 
 func (a *AppManifestsDispatcher) applyAndRecordManifests(ctx context.Context, manifests []*unstructured.Unstructured) error {
+	// apply the resources others may depend on first, like CRDs, Namespaces and ServiceAccounts
+	manifests, err := sortManifests(manifests)
+	if err != nil {
+		return err
+	}
 	ctrlUIDs := []types.UID{a.currentRT.UID}
 	if a.previousRT != nil && a.previousRT.Name != a.currentRTName {
 		klog.InfoS("Going to apply or upgrade resources", "from", a.previousRT.Name, "to", a.currentRTName)
@@ -231,11 +275,7 @@ func (a *AppManifestsDispatcher) applyAndRecordManifests(ctx context.Context, ma
 		Controller:         pointer.BoolPtr(true),
 		BlockOwnerDeletion: pointer.BoolPtr(true),
 	}
-	for _, rsc := range manifests {
-		if rsc == nil {
-			continue
-		}
-
+	for i, rsc := range manifests {
 		immutable, err := a.ImmutableResourcesUpdate(ctx, rsc, ownerRef, applyOpts)
 		if immutable {
 			if err != nil {
@@ -257,6 +297,11 @@ func (a *AppManifestsDispatcher) applyAndRecordManifests(ctx context.Context, ma
 		}
 		klog.InfoS("Successfully apply a resource", "object",
 			klog.KObj(rsc), "apiVersion", rsc.GetAPIVersion(), "kind", rsc.GetKind())
+		if needWaitEstablished(rsc, manifests[i+1:]) {
+			if err := a.waitEstablished(ctx, rsc); err != nil {
+				return err
+			}
+		}
 	}
 	return a.updateResourceTrackerStatus(ctx, manifests)
 }
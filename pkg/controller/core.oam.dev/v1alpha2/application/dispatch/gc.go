@@ -19,27 +19,32 @@ package dispatch
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/oam"
 )
 
 // GarbageCollector do GC according two resource trackers
 type GarbageCollector interface {
-	GarbageCollect(ctx context.Context, oldRT, newRT *v1beta1.ResourceTracker, legacyRTs []*v1beta1.ResourceTracker) error
+	GarbageCollect(ctx context.Context, oldRT, newRT *v1beta1.ResourceTracker, legacyRTs []*v1beta1.ResourceTracker, rules []v1alpha1.GarbageCollectPolicyRule) error
+	CollectExpiredResources(ctx context.Context, rt *v1beta1.ResourceTracker) (time.Duration, error)
 }
 
 // NewGCHandler create a GCHandler
 func NewGCHandler(c client.Client, ns string) *GCHandler {
-	return &GCHandler{c, ns, nil, nil}
+	return &GCHandler{c: c, namespace: ns}
 }
 
 // GCHandler implement GarbageCollector interface
@@ -49,26 +54,23 @@ type GCHandler struct {
 
 	oldRT *v1beta1.ResourceTracker
 	newRT *v1beta1.ResourceTracker
+	rules []v1alpha1.GarbageCollectPolicyRule
 }
 
-// GarbageCollect delete the old resources that are no longer in the new resource tracker
-func (h *GCHandler) GarbageCollect(ctx context.Context, oldRT, newRT *v1beta1.ResourceTracker, legacyRTs []*v1beta1.ResourceTracker) error {
+// GarbageCollect delete the old resources that are no longer in the new resource tracker.
+// The resources matching the rules of the garbage-collect policy are orphaned or retained by the new resource tracker
+// instead, the retained resources of the old resource tracker are deleted if they expire.
+func (h *GCHandler) GarbageCollect(ctx context.Context, oldRT, newRT *v1beta1.ResourceTracker, legacyRTs []*v1beta1.ResourceTracker, rules []v1alpha1.GarbageCollectPolicyRule) error {
 	h.oldRT = oldRT
 	h.newRT = newRT
+	h.rules = rules
 	if err := h.validate(); err != nil {
 		return err
 	}
 	klog.InfoS("Garbage collect for application", "old", h.oldRT.Name, "new", h.newRT.Name)
+	retainedCount := len(h.newRT.Status.RetainedResources)
 	for _, oldRsc := range h.oldRT.Status.TrackedResources {
-		reused := false
-		for _, newRsc := range h.newRT.Status.TrackedResources {
-			if oldRsc.APIVersion == newRsc.APIVersion && oldRsc.Kind == newRsc.Kind &&
-				oldRsc.Namespace == newRsc.Namespace && oldRsc.Name == newRsc.Name {
-				reused = true
-				break
-			}
-		}
-		if !reused {
+		if !h.isTrackedByNewRT(oldRsc.APIVersion, oldRsc.Kind, oldRsc.Namespace, oldRsc.Name) {
 			toBeDeleted := &unstructured.Unstructured{}
 			toBeDeleted.SetAPIVersion(oldRsc.APIVersion)
 			toBeDeleted.SetKind(oldRsc.Kind)
@@ -84,6 +86,13 @@ func (h *GCHandler) GarbageCollect(ctx context.Context, oldRT, newRT *v1beta1.Re
 				// the resource have skipGC annotation, will not delete the resource
 				continue
 			}
+			isHandled := false
+			if isHandled, err = h.handleResourceByPolicy(ctx, toBeDeleted); err != nil {
+				return errors.Wrap(err, "cannot handle resource by garbage-collect policy")
+			}
+			if isHandled {
+				continue
+			}
 
 			if err := h.c.Delete(ctx, toBeDeleted); err != nil && !kerrors.IsNotFound(err) {
 				klog.ErrorS(err, "Failed to delete a resource", "name", oldRsc.Name, "apiVersion", oldRsc.APIVersion, "kind", oldRsc.Kind)
@@ -92,6 +101,15 @@ func (h *GCHandler) GarbageCollect(ctx context.Context, oldRT, newRT *v1beta1.Re
 			klog.InfoS("Successfully GC a resource", "name", oldRsc.Name, "apiVersion", oldRsc.APIVersion, "kind", oldRsc.Kind)
 		}
 	}
+	if err := h.inheritRetainedResources(ctx); err != nil {
+		return err
+	}
+	if len(h.newRT.Status.RetainedResources) != retainedCount || len(h.oldRT.Status.RetainedResources) > 0 {
+		if err := updateRetainedResources(ctx, h.c, h.newRT); err != nil {
+			return err
+		}
+	}
+
 	// delete the old resource tracker
 	if err := h.c.Delete(ctx, h.oldRT); err != nil && !kerrors.IsNotFound(err) {
 		klog.ErrorS(err, "Failed to delete resource tracker", "name", h.oldRT.Name)
@@ -137,18 +155,159 @@ func (h *GCHandler) handleResourceSkipGC(ctx context.Context, u *unstructured.Un
 	if _, exist := res.GetAnnotations()[oam.AnnotationSkipGC]; !exist {
 		return false, nil
 	}
-	var owners []metav1.OwnerReference
-	for _, ownerReference := range res.GetOwnerReferences() {
-		if ownerReference.UID == oldRt.GetUID() {
+	if err := orphanResource(ctx, h.c, res, oldRt); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// handleResourceByPolicy orphans or retains the resource if it matches any rule of the garbage-collect policy and
+// returns true, the resource is retained by the new resource tracker until it expires
+func (h *GCHandler) handleResourceByPolicy(ctx context.Context, u *unstructured.Unstructured) (bool, error) {
+	if len(h.rules) == 0 {
+		return false, nil
+	}
+	res := u.DeepCopy()
+	if err := h.c.Get(ctx, types.NamespacedName{Namespace: res.GetNamespace(), Name: res.GetName()}, res); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return false, err
+		}
+		return true, nil
+	}
+	rule := matchGarbageCollectRule(h.rules, res)
+	if rule == nil {
+		return false, nil
+	}
+	switch rule.Strategy {
+	case v1alpha1.GarbageCollectStrategyNeverDelete, v1alpha1.GarbageCollectStrategyOrphan:
+		return true, orphanResource(ctx, h.c, res, h.oldRT)
+	default:
+		if err := h.transferToNewRT(ctx, res); err != nil {
+			return false, err
+		}
+		retained := newRetainedResource(res, rule, revisionOfResourceTracker(h.oldRT, h.namespace))
+		if !h.isRetainedByNewRT(retained) {
+			h.newRT.Status.RetainedResources = append(h.newRT.Status.RetainedResources, retained)
+		}
+		klog.InfoS("Retain a resource by garbage-collect policy", "kind", res.GetKind(), "namespace", res.GetNamespace(),
+			"name", res.GetName(), "strategy", rule.Strategy)
+		return true, nil
+	}
+}
+
+// inheritRetainedResources moves the retained resources of the old resource tracker to the new one, the expired ones
+// are deleted and the ones added back to the application are no longer retained
+func (h *GCHandler) inheritRetainedResources(ctx context.Context) error {
+	revision := revisionOfResourceTracker(h.newRT, h.namespace)
+	for _, retained := range h.oldRT.Status.RetainedResources {
+		if h.isTrackedByNewRT(retained.APIVersion, retained.Kind, retained.Namespace, retained.Name) ||
+			h.isRetainedByNewRT(retained) {
+			continue
+		}
+		res := unstructuredFromRetained(retained)
+		if isRetainedResourceExpired(retained, revision) {
+			if err := deleteRetainedResource(ctx, h.c, res); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := h.c.Get(ctx, types.NamespacedName{Namespace: res.GetNamespace(), Name: res.GetName()}, res); err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "cannot get retained resource %s %q", retained.Kind, retained.Name)
+		}
+		if err := h.transferToNewRT(ctx, res); err != nil {
+			return err
+		}
+		h.newRT.Status.RetainedResources = append(h.newRT.Status.RetainedResources, retained)
+	}
+	return nil
+}
+
+// CollectExpiredResources deletes the expired retained resources of the resource tracker, and returns the duration
+// until the next retained resource expires by its delay, 0 if there is none
+func (h *GCHandler) CollectExpiredResources(ctx context.Context, rt *v1beta1.ResourceTracker) (time.Duration, error) {
+	if len(rt.Status.RetainedResources) == 0 {
+		return 0, nil
+	}
+	revision := revisionOfResourceTracker(rt, h.namespace)
+	var retainedResources []v1beta1.RetainedResource
+	for _, retained := range rt.Status.RetainedResources {
+		if !isRetainedResourceExpired(retained, revision) {
+			retainedResources = append(retainedResources, retained)
 			continue
 		}
-		owners = append(owners, ownerReference)
+		if err := deleteRetainedResource(ctx, h.c, unstructuredFromRetained(retained)); err != nil {
+			return 0, err
+		}
+	}
+	if len(retainedResources) == len(rt.Status.RetainedResources) {
+		return nextRetainedResourceExpiry(rt), nil
+	}
+	rt.Status.RetainedResources = retainedResources
+	if err := updateRetainedResources(ctx, h.c, rt); err != nil {
+		return 0, err
 	}
-	res.SetOwnerReferences(owners)
+	return nextRetainedResourceExpiry(rt), nil
+}
+
+func deleteRetainedResource(ctx context.Context, c client.Client, res *unstructured.Unstructured) error {
+	if err := c.Delete(ctx, res); err != nil && !kerrors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to delete a retained resource", "name", res.GetName(), "apiVersion", res.GetAPIVersion(), "kind", res.GetKind())
+		return errors.Wrapf(err, "cannot delete retained resource %s %q", res.GetKind(), res.GetName())
+	}
+	klog.InfoS("Successfully GC an expired retained resource", "name", res.GetName(), "apiVersion", res.GetAPIVersion(), "kind", res.GetKind())
+	return nil
+}
+
+// transferToNewRT sets the new resource tracker as the controller of the resource, so that it's not deleted with the
+// old resource tracker
+func (h *GCHandler) transferToNewRT(ctx context.Context, res *unstructured.Unstructured) error {
+	setOrOverrideOAMControllerOwner(res, metav1.OwnerReference{
+		APIVersion:         v1beta1.SchemeGroupVersion.String(),
+		Kind:               v1beta1.ResourceTrackerKind,
+		Name:               h.newRT.Name,
+		UID:                h.newRT.UID,
+		Controller:         pointer.BoolPtr(true),
+		BlockOwnerDeletion: pointer.BoolPtr(true),
+	})
 	if err := h.c.Update(ctx, res); err != nil {
-		klog.ErrorS(err, "handleResourceSkipGC failed cannot orphan a res kind ", res.GetKind(), "namespace", res.GetNamespace(), "name", res.GetName())
-		return false, err
+		return errors.Wrapf(err, "cannot transfer resource %s %q to resource tracker %q", res.GetKind(), res.GetName(), h.newRT.Name)
 	}
-	klog.InfoS("succeed to handle a skipGC res kind ", res.GetKind(), "namespace", res.GetNamespace(), "name", res.GetName())
-	return true, nil
+	return nil
+}
+
+func updateRetainedResources(ctx context.Context, c client.Client, rt *v1beta1.ResourceTracker) error {
+	retainedResources := rt.Status.RetainedResources
+	copyRT := rt.DeepCopy()
+	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if err = c.Get(ctx, client.ObjectKey{Name: rt.Name}, copyRT); err != nil {
+			return
+		}
+		copyRT.Status.RetainedResources = retainedResources
+		return c.Status().Update(ctx, copyRT)
+	}); err != nil {
+		klog.ErrorS(err, "Failed to update retained resources of resource tracker", "resourceTracker", rt.Name)
+		return errors.Wrap(err, "cannot update resource tracker status")
+	}
+	return nil
+}
+
+func (h *GCHandler) isTrackedByNewRT(apiVersion, kind, namespace, name string) bool {
+	for _, newRsc := range h.newRT.Status.TrackedResources {
+		if apiVersion == newRsc.APIVersion && kind == newRsc.Kind && namespace == newRsc.Namespace && name == newRsc.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *GCHandler) isRetainedByNewRT(retained v1beta1.RetainedResource) bool {
+	for _, r := range h.newRT.Status.RetainedResources {
+		if r.APIVersion == retained.APIVersion && r.Kind == retained.Kind && r.Namespace == retained.Namespace && r.Name == retained.Name {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,264 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// ParseGarbageCollectRules returns the rules of the garbage-collect policy in the given policies, nil if there is none
+func ParseGarbageCollectRules(policies []v1beta1.AppPolicy) ([]v1alpha1.GarbageCollectPolicyRule, error) {
+	for _, policy := range policies {
+		if policy.Type != v1alpha1.GarbageCollectPolicyType {
+			continue
+		}
+		spec := &v1alpha1.GarbageCollectPolicySpec{}
+		if policy.Properties.Raw != nil {
+			if err := json.Unmarshal(policy.Properties.Raw, spec); err != nil {
+				return nil, errors.Wrapf(err, "invalid properties of policy %s", policy.Name)
+			}
+		}
+		for i, rule := range spec.Rules {
+			switch rule.Strategy {
+			case v1alpha1.GarbageCollectStrategyNeverDelete, v1alpha1.GarbageCollectStrategyOrphan:
+			case v1alpha1.GarbageCollectStrategyDeleteAfterDelay:
+				delay, err := time.ParseDuration(rule.Delay)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid delay of rule %d in policy %s", i, policy.Name)
+				}
+				if delay <= 0 {
+					return nil, errors.Errorf("delay of rule %d in policy %s must be positive", i, policy.Name)
+				}
+			case v1alpha1.GarbageCollectStrategyKeepLastNRevisions:
+				if rule.Revisions <= 0 {
+					return nil, errors.Errorf("revisions of rule %d in policy %s must be positive", i, policy.Name)
+				}
+			default:
+				return nil, errors.Errorf("unknown strategy %q of rule %d in policy %s", rule.Strategy, i, policy.Name)
+			}
+		}
+		return spec.Rules, nil
+	}
+	return nil, nil
+}
+
+// matchGarbageCollectRule returns the first rule matching the resource, nil if there is none
+func matchGarbageCollectRule(rules []v1alpha1.GarbageCollectPolicyRule, res *unstructured.Unstructured) *v1alpha1.GarbageCollectPolicyRule {
+	for i, rule := range rules {
+		sel := rule.Selector
+		labels := res.GetLabels()
+		if matchAny(sel.ComponentNames, labels[oam.LabelAppComponent]) && matchAny(sel.TraitTypes, labels[oam.TraitTypeLabel]) &&
+			matchAny(sel.ResourceKinds, res.GetKind()) && matchAny(sel.ResourceNames, res.GetName()) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// matchAny checks whether the value is one of the candidates, an empty candidate list matches anything
+func matchAny(candidates []string, value string) bool {
+	if len(candidates) == 0 {
+		return true
+	}
+	for _, c := range candidates {
+		if c == value {
+			return true
+		}
+	}
+	return false
+}
+
+// newRetainedResource records the resource retained by the rule, the given revision is the last one using the resource
+func newRetainedResource(res *unstructured.Unstructured, rule *v1alpha1.GarbageCollectPolicyRule, revision int) v1beta1.RetainedResource {
+	retained := v1beta1.RetainedResource{
+		APIVersion: res.GetAPIVersion(),
+		Kind:       res.GetKind(),
+		Namespace:  res.GetNamespace(),
+		Name:       res.GetName(),
+	}
+	switch rule.Strategy {
+	case v1alpha1.GarbageCollectStrategyDeleteAfterDelay:
+		// the delay has been validated while parsing the rules
+		delay, _ := time.ParseDuration(rule.Delay)
+		deleteAfter := metav1.NewTime(time.Now().Add(delay))
+		retained.DeleteAfter = &deleteAfter
+	case v1alpha1.GarbageCollectStrategyKeepLastNRevisions:
+		retained.DeleteAtRevision = revision + rule.Revisions
+	default:
+	}
+	return retained
+}
+
+// isRetainedResourceExpired checks whether the retained resource should be deleted in the given revision
+func isRetainedResourceExpired(retained v1beta1.RetainedResource, revision int) bool {
+	if retained.DeleteAfter != nil && !time.Now().Before(retained.DeleteAfter.Time) {
+		return true
+	}
+	return retained.DeleteAtRevision > 0 && revision >= retained.DeleteAtRevision
+}
+
+// revisionOfResourceTracker returns the revision number of the application revision the resource tracker belongs to
+func revisionOfResourceTracker(rt *v1beta1.ResourceTracker, ns string) int {
+	revision, err := utils.ExtractRevision(ExtractAppRevisionName(rt.Name, ns))
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+func unstructuredFromRetained(retained v1beta1.RetainedResource) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(retained.APIVersion)
+	u.SetKind(retained.Kind)
+	u.SetNamespace(retained.Namespace)
+	u.SetName(retained.Name)
+	return u
+}
+
+// orphanResource removes the owner reference to the resource tracker from the resource, so that the resource is not
+// deleted with the resource tracker
+func orphanResource(ctx context.Context, c client.Client, res *unstructured.Unstructured, rt *v1beta1.ResourceTracker) error {
+	var owners []metav1.OwnerReference
+	for _, ownerReference := range res.GetOwnerReferences() {
+		if ownerReference.UID == rt.GetUID() {
+			continue
+		}
+		owners = append(owners, ownerReference)
+	}
+	res.SetOwnerReferences(owners)
+	if err := c.Update(ctx, res); err != nil {
+		klog.ErrorS(err, "Failed to orphan a resource", "kind", res.GetKind(), "namespace", res.GetNamespace(), "name", res.GetName())
+		return errors.Wrapf(err, "cannot orphan resource %s %q", res.GetKind(), res.GetName())
+	}
+	klog.InfoS("Successfully orphan a resource", "kind", res.GetKind(), "namespace", res.GetNamespace(), "name", res.GetName())
+	return nil
+}
+
+// nextRetainedResourceExpiry returns the duration until the first retained resource of the resource tracker expires
+// by its delay, 0 if none of them expires by delay
+func nextRetainedResourceExpiry(rt *v1beta1.ResourceTracker) time.Duration {
+	var next time.Duration
+	for _, retained := range rt.Status.RetainedResources {
+		if retained.DeleteAfter == nil {
+			continue
+		}
+		d := time.Until(retained.DeleteAfter.Time)
+		if d <= 0 {
+			// the expired resource is deleted by the next collection as soon as possible
+			d = time.Second
+		}
+		if next == 0 || d < next {
+			next = d
+		}
+	}
+	return next
+}
+
+func isRetainedBy(rt *v1beta1.ResourceTracker, res *unstructured.Unstructured) bool {
+	for _, retained := range rt.Status.RetainedResources {
+		if retained.APIVersion == res.GetAPIVersion() && retained.Kind == res.GetKind() &&
+			retained.Namespace == res.GetNamespace() && retained.Name == res.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// ReleaseResourcesOnDelete handles the resources of the resource trackers by the garbage-collect rules before the
+// resource trackers are deleted with the application. The never-delete resources are orphaned, the delete-after-delay
+// resources are retained until their delay expires and then deleted. The others, including the orphan and
+// keep-last-n-revisions ones, are deleted with the resource trackers as no later revision uses them.
+// It returns the duration to wait before the resource trackers can be deleted, 0 if they can be deleted now.
+func ReleaseResourcesOnDelete(ctx context.Context, c client.Client, rules []v1alpha1.GarbageCollectPolicyRule, rts []v1beta1.ResourceTracker) (time.Duration, error) {
+	if len(rules) == 0 {
+		return 0, nil
+	}
+	var wait time.Duration
+	for i := range rts {
+		rt := &rts[i]
+		changed := false
+		resources := make([]*unstructured.Unstructured, 0, len(rt.Status.TrackedResources)+len(rt.Status.RetainedResources))
+		for _, ref := range rt.Status.TrackedResources {
+			resources = append(resources, unstructuredFromRetained(v1beta1.RetainedResource{
+				APIVersion: ref.APIVersion, Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name}))
+		}
+		for _, retained := range rt.Status.RetainedResources {
+			resources = append(resources, unstructuredFromRetained(retained))
+		}
+		for _, res := range resources {
+			if err := c.Get(ctx, types.NamespacedName{Namespace: res.GetNamespace(), Name: res.GetName()}, res); err != nil {
+				if kerrors.IsNotFound(err) {
+					continue
+				}
+				return 0, errors.Wrapf(err, "cannot get resource %s %q", res.GetKind(), res.GetName())
+			}
+			rule := matchGarbageCollectRule(rules, res)
+			if rule == nil {
+				continue
+			}
+			switch rule.Strategy {
+			case v1alpha1.GarbageCollectStrategyNeverDelete:
+				if err := orphanResource(ctx, c, res, rt); err != nil {
+					return 0, err
+				}
+			case v1alpha1.GarbageCollectStrategyDeleteAfterDelay:
+				if !isRetainedBy(rt, res) {
+					rt.Status.RetainedResources = append(rt.Status.RetainedResources, newRetainedResource(res, rule, 0))
+					changed = true
+				}
+			default:
+			}
+		}
+
+		var retainedResources []v1beta1.RetainedResource
+		for _, retained := range rt.Status.RetainedResources {
+			if retained.DeleteAfter == nil || time.Now().Before(retained.DeleteAfter.Time) {
+				retainedResources = append(retainedResources, retained)
+				continue
+			}
+			if err := deleteRetainedResource(ctx, c, unstructuredFromRetained(retained)); err != nil {
+				return 0, err
+			}
+			changed = true
+		}
+		rt.Status.RetainedResources = retainedResources
+		if changed {
+			if err := updateRetainedResources(ctx, c, rt); err != nil {
+				return 0, err
+			}
+		}
+		if d := nextRetainedResourceExpiry(rt); d > 0 && (wait == 0 || d < wait) {
+			wait = d
+		}
+	}
+	return wait, nil
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestParseGarbageCollectRules(t *testing.T) {
+	r := require.New(t)
+	policy := func(props string) []v1beta1.AppPolicy {
+		return []v1beta1.AppPolicy{{Name: "gc", Type: v1alpha1.GarbageCollectPolicyType, Properties: runtime.RawExtension{Raw: []byte(props)}}}
+	}
+
+	rules, err := ParseGarbageCollectRules(nil)
+	r.NoError(err)
+	r.Nil(rules)
+
+	rules, err = ParseGarbageCollectRules(policy(`{"rules":[{"selector":{"resourceKinds":["PersistentVolumeClaim"]},"strategy":"never-delete"},
+{"selector":{"componentNames":["web"]},"strategy":"delete-after-delay","delay":"1h"},
+{"selector":{"traitTypes":["config"]},"strategy":"keep-last-n-revisions","revisions":2}]}`))
+	r.NoError(err)
+	r.Len(rules, 3)
+
+	for _, props := range []string{
+		`{"rules":[{"strategy":"unknown"}]}`,
+		`{"rules":[{"strategy":"delete-after-delay"}]}`,
+		`{"rules":[{"strategy":"delete-after-delay","delay":"-1m"}]}`,
+		`{"rules":[{"strategy":"keep-last-n-revisions"}]}`,
+		`{"rules":"invalid"}`,
+	} {
+		_, err = ParseGarbageCollectRules(policy(props))
+		r.Error(err, props)
+	}
+}
+
+func TestGarbageCollectWithPolicy(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	ns := "default"
+	rules := []v1alpha1.GarbageCollectPolicyRule{
+		{Selector: v1alpha1.GarbageCollectSelector{ResourceKinds: []string{"PersistentVolumeClaim"}}, Strategy: v1alpha1.GarbageCollectStrategyNeverDelete},
+		{Selector: v1alpha1.GarbageCollectSelector{ResourceNames: []string{"orphan"}}, Strategy: v1alpha1.GarbageCollectStrategyOrphan},
+		{Selector: v1alpha1.GarbageCollectSelector{ComponentNames: []string{"web"}, ResourceKinds: []string{"Secret"}}, Strategy: v1alpha1.GarbageCollectStrategyDeleteAfterDelay, Delay: "1h"},
+		{Selector: v1alpha1.GarbageCollectSelector{TraitTypes: []string{"config"}}, Strategy: v1alpha1.GarbageCollectStrategyKeepLastNRevisions, Revisions: 2},
+	}
+	newRT := func(rev string, uid types.UID, tracked ...client.Object) *v1beta1.ResourceTracker {
+		rt := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: ConstructResourceTrackerName("app-"+rev, ns), UID: uid}}
+		for _, obj := range tracked {
+			gvk := obj.GetObjectKind().GroupVersionKind()
+			rt.Status.TrackedResources = append(rt.Status.TrackedResources, corev1.ObjectReference{
+				APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind, Namespace: obj.GetNamespace(), Name: obj.GetName()})
+		}
+		return rt
+	}
+	owned := func(obj client.Object, kind string, labels map[string]string, rt *v1beta1.ResourceTracker) client.Object {
+		obj.GetObjectKind().SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(kind))
+		obj.SetNamespace(ns)
+		obj.SetLabels(labels)
+		obj.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(rt, v1beta1.ResourceTrackerKindVersionKind)})
+		return obj
+	}
+
+	rt1 := newRT("v1", "uid-v1")
+	pvc := owned(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data"}}, "PersistentVolumeClaim", nil, rt1)
+	orphan := owned(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "orphan"}}, "ConfigMap", nil, rt1)
+	secret := owned(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token"}}, "Secret", map[string]string{oam.LabelAppComponent: "web"}, rt1)
+	config := owned(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config"}}, "ConfigMap", map[string]string{oam.TraitTypeLabel: "config"}, rt1)
+	svc := owned(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}}, "Service", map[string]string{oam.LabelAppComponent: "web"}, rt1)
+	rt1.Status = newRT("v1", "", pvc, orphan, secret, config, svc).Status
+	rt2 := newRT("v2", "uid-v2")
+	rt3 := newRT("v3", "uid-v3")
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(rt1, rt2, rt3, pvc, orphan, secret, config, svc).Build()
+	h := NewGCHandler(cli, ns)
+	get := func(kind, name string) (*unstructured.Unstructured, error) {
+		u := newManifest("v1", kind, name)
+		return u, cli.Get(ctx, client.ObjectKey{Namespace: ns, Name: name}, u)
+	}
+	getRT := func(name string) (*v1beta1.ResourceTracker, error) {
+		rt := &v1beta1.ResourceTracker{}
+		return rt, cli.Get(ctx, client.ObjectKey{Name: name}, rt)
+	}
+
+	r.NoError(h.GarbageCollect(ctx, rt1, rt2, nil, rules))
+	_, err := get("Service", "web")
+	r.True(kerrors.IsNotFound(err))
+	_, err = getRT(rt1.Name)
+	r.True(kerrors.IsNotFound(err))
+	for kind, name := range map[string]string{"PersistentVolumeClaim": "data", "ConfigMap": "orphan"} {
+		u, err := get(kind, name)
+		r.NoError(err)
+		r.Empty(u.GetOwnerReferences())
+	}
+	for kind, name := range map[string]string{"Secret": "token", "ConfigMap": "config"} {
+		u, err := get(kind, name)
+		r.NoError(err)
+		r.Equal(rt2.UID, metav1.GetControllerOf(u).UID)
+	}
+	gotRT, err := getRT(rt2.Name)
+	r.NoError(err)
+	r.Len(gotRT.Status.RetainedResources, 2)
+	r.Equal("token", gotRT.Status.RetainedResources[0].Name)
+	r.NotNil(gotRT.Status.RetainedResources[0].DeleteAfter)
+	r.Equal("config", gotRT.Status.RetainedResources[1].Name)
+	r.Equal(3, gotRT.Status.RetainedResources[1].DeleteAtRevision)
+
+	// the config is kept by the last 2 revisions, v1 and v2, so it's deleted in v3
+	r.NoError(h.GarbageCollect(ctx, gotRT, rt3, nil, rules))
+	_, err = get("ConfigMap", "config")
+	r.True(kerrors.IsNotFound(err))
+	u, err := get("Secret", "token")
+	r.NoError(err)
+	r.Equal(rt3.UID, metav1.GetControllerOf(u).UID)
+	gotRT, err = getRT(rt3.Name)
+	r.NoError(err)
+	r.Len(gotRT.Status.RetainedResources, 1)
+
+	// nothing expires yet, the next collection is when the secret expires
+	requeueAfter, err := h.CollectExpiredResources(ctx, gotRT)
+	r.NoError(err)
+	r.True(requeueAfter > 59*time.Minute && requeueAfter <= time.Hour, requeueAfter)
+	_, err = get("Secret", "token")
+	r.NoError(err)
+
+	past := metav1.NewTime(time.Now().Add(-time.Minute))
+	gotRT.Status.RetainedResources[0].DeleteAfter = &past
+	requeueAfter, err = h.CollectExpiredResources(ctx, gotRT)
+	r.NoError(err)
+	r.Zero(requeueAfter)
+	_, err = get("Secret", "token")
+	r.True(kerrors.IsNotFound(err))
+	gotRT, err = getRT(rt3.Name)
+	r.NoError(err)
+	r.Empty(gotRT.Status.RetainedResources)
+}
+
+func TestReleaseResourcesOnDelete(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	rt := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "app-v1-default", UID: "uid-v1"}}
+	rt.Status.TrackedResources = []corev1.ObjectReference{
+		{APIVersion: "v1", Kind: "PersistentVolumeClaim", Namespace: "default", Name: "data"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "config"},
+		{APIVersion: "v1", Kind: "Secret", Namespace: "default", Name: "token"},
+		{APIVersion: "v1", Kind: "Secret", Namespace: "default", Name: "missing"},
+	}
+	past := metav1.NewTime(time.Now().Add(-time.Minute))
+	rt.Status.RetainedResources = []v1beta1.RetainedResource{
+		{APIVersion: "v1", Kind: "Secret", Namespace: "default", Name: "expired", DeleteAfter: &past},
+	}
+	ownerRefs := []metav1.OwnerReference{*metav1.NewControllerRef(rt, v1beta1.ResourceTrackerKindVersionKind)}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data", OwnerReferences: ownerRefs}}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config", OwnerReferences: ownerRefs}}
+	token := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "token", OwnerReferences: ownerRefs}}
+	expired := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "expired", OwnerReferences: ownerRefs}}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(rt, pvc, cm, token, expired).Build()
+	rules := []v1alpha1.GarbageCollectPolicyRule{
+		{Selector: v1alpha1.GarbageCollectSelector{ResourceNames: []string{"config"}}, Strategy: v1alpha1.GarbageCollectStrategyOrphan},
+		{Selector: v1alpha1.GarbageCollectSelector{ResourceKinds: []string{"PersistentVolumeClaim", "ConfigMap"}}, Strategy: v1alpha1.GarbageCollectStrategyNeverDelete},
+		{Selector: v1alpha1.GarbageCollectSelector{ResourceKinds: []string{"Secret"}}, Strategy: v1alpha1.GarbageCollectStrategyDeleteAfterDelay, Delay: "1h"},
+	}
+
+	wait, err := ReleaseResourcesOnDelete(ctx, cli, rules, []v1beta1.ResourceTracker{*rt})
+	r.NoError(err)
+	r.True(wait > 59*time.Minute && wait <= time.Hour, wait)
+	gotPVC := &corev1.PersistentVolumeClaim{}
+	r.NoError(cli.Get(ctx, client.ObjectKeyFromObject(pvc), gotPVC))
+	r.Empty(gotPVC.OwnerReferences)
+	// the first matched rule of the config map is orphan, which doesn't keep it after the application is deleted
+	gotCM := &corev1.ConfigMap{}
+	r.NoError(cli.Get(ctx, client.ObjectKeyFromObject(cm), gotCM))
+	r.Len(gotCM.OwnerReferences, 1)
+	// the expired secret is deleted, the token is retained until the delay expires
+	r.True(kerrors.IsNotFound(cli.Get(ctx, client.ObjectKeyFromObject(expired), &corev1.Secret{})))
+	r.NoError(cli.Get(ctx, client.ObjectKeyFromObject(token), &corev1.Secret{}))
+	gotRT := &v1beta1.ResourceTracker{}
+	r.NoError(cli.Get(ctx, client.ObjectKeyFromObject(rt), gotRT))
+	r.Len(gotRT.Status.RetainedResources, 1)
+	r.Equal("token", gotRT.Status.RetainedResources[0].Name)
+
+	// the retained token is not retained twice
+	wait, err = ReleaseResourcesOnDelete(ctx, cli, rules, []v1beta1.ResourceTracker{*gotRT})
+	r.NoError(err)
+	r.True(wait > 0)
+	r.NoError(cli.Get(ctx, client.ObjectKeyFromObject(rt), gotRT))
+	r.Len(gotRT.Status.RetainedResources, 1)
+
+	gotRT.Status.RetainedResources[0].DeleteAfter = &past
+	wait, err = ReleaseResourcesOnDelete(ctx, cli, rules, []v1beta1.ResourceTracker{*gotRT})
+	r.NoError(err)
+	r.Zero(wait)
+	r.True(kerrors.IsNotFound(cli.Get(ctx, client.ObjectKeyFromObject(token), &corev1.Secret{})))
+
+	wait, err = ReleaseResourcesOnDelete(ctx, cli, nil, []v1beta1.ResourceTracker{*gotRT})
+	r.NoError(err)
+	r.Zero(wait)
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dispatch
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const apiextensionsGroup = "apiextensions.k8s.io"
+
+var (
+	// establishedTimeout is the max duration to wait for a CRD or a Namespace to be ready for the resources depend on it
+	establishedTimeout = 30 * time.Second
+	// establishedPollInterval is the interval to check whether a CRD or a Namespace is ready
+	establishedPollInterval = 500 * time.Millisecond
+)
+
+// kindOrder is the order to apply the resources of well-known kinds, the resources which others may depend on are
+// applied first. The resources of other kinds, like custom resources, are applied after them.
+var kindOrder = []string{
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"Ingress",
+	"APIService",
+}
+
+var kindPriority = func() map[string]int {
+	priority := make(map[string]int, len(kindOrder))
+	for i, kind := range kindOrder {
+		priority[kind] = i
+	}
+	return priority
+}()
+
+// getKindPriority returns the priority of the kind, the lower one is applied first
+func getKindPriority(kind string) int {
+	if p, ok := kindPriority[kind]; ok {
+		return p
+	}
+	return len(kindOrder)
+}
+
+// getApplyOrder returns the explicit order of the resource set by the annotation, 0 if it's not set
+func getApplyOrder(rsc *unstructured.Unstructured) (int, error) {
+	v, ok := rsc.GetAnnotations()[oam.AnnotationApplyOrder]
+	if !ok {
+		return 0, nil
+	}
+	order, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid annotation %s of resource, name: %q kind: %q", oam.AnnotationApplyOrder, rsc.GetName(), rsc.GetKind())
+	}
+	return order, nil
+}
+
+// sortManifests sorts the manifests by the explicit apply order first and then the priority of their kinds, the
+// manifests with the same order and kind priority keep their original order
+func sortManifests(manifests []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	sorted := make([]*unstructured.Unstructured, 0, len(manifests))
+	orders := map[*unstructured.Unstructured]int{}
+	for _, rsc := range manifests {
+		if rsc == nil {
+			continue
+		}
+		order, err := getApplyOrder(rsc)
+		if err != nil {
+			return nil, err
+		}
+		orders[rsc] = order
+		sorted = append(sorted, rsc)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if orders[sorted[i]] != orders[sorted[j]] {
+			return orders[sorted[i]] < orders[sorted[j]]
+		}
+		return getKindPriority(sorted[i].GetKind()) < getKindPriority(sorted[j].GetKind())
+	})
+	return sorted, nil
+}
+
+// needWaitEstablished checks whether any of the resources applied after the resource depends on it, i.e. they are
+// the custom resources defined by the CRD or in the Namespace, so that they must wait until it is ready
+func needWaitEstablished(rsc *unstructured.Unstructured, following []*unstructured.Unstructured) bool {
+	gvk := rsc.GroupVersionKind()
+	switch {
+	case gvk.Group == apiextensionsGroup && gvk.Kind == "CustomResourceDefinition":
+		group, _, _ := unstructured.NestedString(rsc.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(rsc.Object, "spec", "names", "kind")
+		for _, r := range following {
+			if r.GroupVersionKind().Group == group && r.GetKind() == kind {
+				return true
+			}
+		}
+	case gvk.Group == "" && gvk.Kind == "Namespace":
+		for _, r := range following {
+			if r.GetNamespace() == rsc.GetName() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isEstablished checks whether the CRD is established or the Namespace is active
+func isEstablished(obj *unstructured.Unstructured) bool {
+	if obj.GetKind() == "Namespace" {
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		return phase == "Active"
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if ok && cond["type"] == "Established" && cond["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// waitEstablished waits until the CRD is established or the Namespace is active, so that the resources depend on it
+// can be applied. It gives up once the context is done, e.g. the reconcile times out.
+func (a *AppManifestsDispatcher) waitEstablished(ctx context.Context, rsc *unstructured.Unstructured) error {
+	ctx, cancel := context.WithTimeout(ctx, establishedTimeout)
+	defer cancel()
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(rsc.GroupVersionKind())
+	err := wait.PollImmediateUntil(establishedPollInterval, func() (bool, error) {
+		if err := a.c.Get(ctx, client.ObjectKey{Name: rsc.GetName()}, obj); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return isEstablished(obj), nil
+	}, ctx.Done())
+	if err != nil {
+		return errors.Wrapf(err, "%s %q is not ready", rsc.GetKind(), rsc.GetName())
+	}
+	klog.InfoS("Resource is ready for the dependents", "kind", rsc.GetKind(), "name", rsc.GetName())
+	return nil
+}
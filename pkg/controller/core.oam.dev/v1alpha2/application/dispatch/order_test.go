@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func newManifest(apiVersion, kind, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetName(name)
+	return u
+}
+
+func TestSortManifests(t *testing.T) {
+	cr := newManifest("example.com/v1", "Foo", "foo")
+	deploy := newManifest("apps/v1", "Deployment", "web")
+	sa := newManifest("v1", "ServiceAccount", "web")
+	crd := newManifest("apiextensions.k8s.io/v1", "CustomResourceDefinition", "foos.example.com")
+	ns := newManifest("v1", "Namespace", "team")
+	svc := newManifest("v1", "Service", "web")
+	first := newManifest("v1", "ConfigMap", "first")
+	first.SetAnnotations(map[string]string{oam.AnnotationApplyOrder: "-1"})
+	last := newManifest("v1", "Secret", "last")
+	last.SetAnnotations(map[string]string{oam.AnnotationApplyOrder: "10"})
+
+	sorted, err := sortManifests([]*unstructured.Unstructured{cr, last, deploy, nil, sa, crd, svc, first, ns})
+	assert.NoError(t, err)
+	assert.Equal(t, []*unstructured.Unstructured{first, ns, sa, crd, svc, deploy, cr, last}, sorted)
+
+	bad := newManifest("v1", "ConfigMap", "bad")
+	bad.SetAnnotations(map[string]string{oam.AnnotationApplyOrder: "first"})
+	_, err = sortManifests([]*unstructured.Unstructured{bad})
+	assert.Error(t, err)
+}
+
+func TestWaitEstablished(t *testing.T) {
+	oldTimeout, oldInterval := establishedTimeout, establishedPollInterval
+	establishedTimeout, establishedPollInterval = 100*time.Millisecond, 10*time.Millisecond
+	defer func() {
+		establishedTimeout, establishedPollInterval = oldTimeout, oldInterval
+	}()
+
+	established := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{{
+				Type:   apiextensionsv1.Established,
+				Status: apiextensionsv1.ConditionTrue,
+			}},
+		},
+	}
+	pending := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "bars.example.com"}}
+	a := &AppManifestsDispatcher{c: fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(established, pending).Build()}
+	ctx := context.Background()
+
+	crd := newManifest("apiextensions.k8s.io/v1", "CustomResourceDefinition", "foos.example.com")
+	assert.NoError(t, a.waitEstablished(ctx, crd))
+	assert.Error(t, a.waitEstablished(ctx, newManifest("apiextensions.k8s.io/v1", "CustomResourceDefinition", "bars.example.com")))
+	assert.Error(t, a.waitEstablished(ctx, newManifest("apiextensions.k8s.io/v1", "CustomResourceDefinition", "missing.example.com")))
+
+	// the wait stops once the context is done
+	establishedTimeout = time.Minute
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	start := time.Now()
+	assert.Error(t, a.waitEstablished(canceled, newManifest("apiextensions.k8s.io/v1", "CustomResourceDefinition", "bars.example.com")))
+	assert.Less(t, time.Since(start), time.Second)
+
+	ns := newManifest("v1", "Namespace", "team")
+	assert.False(t, isEstablished(ns))
+	assert.NoError(t, unstructured.SetNestedField(ns.Object, "Active", "status", "phase"))
+	assert.True(t, isEstablished(ns))
+}
+
+func TestNeedWaitEstablished(t *testing.T) {
+	crd := newManifest("apiextensions.k8s.io/v1", "CustomResourceDefinition", "foos.example.com")
+	assert.NoError(t, unstructured.SetNestedField(crd.Object, "example.com", "spec", "group"))
+	assert.NoError(t, unstructured.SetNestedField(crd.Object, "Foo", "spec", "names", "kind"))
+	ns := newManifest("v1", "Namespace", "team")
+	deploy := newManifest("apps/v1", "Deployment", "web")
+	deploy.SetNamespace("default")
+	sa := newManifest("v1", "ServiceAccount", "web")
+	sa.SetNamespace("team")
+
+	assert.True(t, needWaitEstablished(crd, []*unstructured.Unstructured{deploy, newManifest("example.com/v1", "Foo", "foo")}))
+	assert.False(t, needWaitEstablished(crd, []*unstructured.Unstructured{deploy, newManifest("example.com/v1", "Bar", "bar")}))
+	assert.False(t, needWaitEstablished(crd, nil))
+	assert.True(t, needWaitEstablished(ns, []*unstructured.Unstructured{deploy, sa}))
+	assert.False(t, needWaitEstablished(ns, []*unstructured.Unstructured{deploy}))
+	assert.False(t, needWaitEstablished(deploy, []*unstructured.Unstructured{sa}))
+}
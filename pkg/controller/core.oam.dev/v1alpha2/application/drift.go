@@ -0,0 +1,386 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	velatypes "github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/assemble"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+)
+
+// defaultDriftDetectionInterval is the interval between two drift detections if it's not set in the policy
+const defaultDriftDetectionInterval = 5 * time.Minute
+
+// getDriftDetectionPolicy returns the spec of the drift-detection policy of the application, nil if there is none
+func getDriftDetectionPolicy(app *v1beta1.Application) (*v1alpha1.DriftDetectionPolicySpec, error) {
+	for _, policy := range app.Spec.Policies {
+		if policy.Type != v1alpha1.DriftDetectionPolicyType {
+			continue
+		}
+		spec := &v1alpha1.DriftDetectionPolicySpec{}
+		if policy.Properties.Raw != nil {
+			if err := json.Unmarshal(policy.Properties.Raw, spec); err != nil {
+				return nil, errors.Wrapf(err, "invalid properties of policy %s", policy.Name)
+			}
+		}
+		switch spec.Mode {
+		case "":
+			spec.Mode = v1alpha1.DriftDetectionModeReport
+		case v1alpha1.DriftDetectionModeReport, v1alpha1.DriftDetectionModeAutoCorrect:
+		default:
+			return nil, errors.Errorf("unknown mode %q of policy %s", spec.Mode, policy.Name)
+		}
+		return spec, nil
+	}
+	return nil, nil
+}
+
+func driftDetectionInterval(spec *v1alpha1.DriftDetectionPolicySpec) (time.Duration, error) {
+	if spec.Interval == "" {
+		return defaultDriftDetectionInterval, nil
+	}
+	interval, err := time.ParseDuration(spec.Interval)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid interval %q of drift-detection policy", spec.Interval)
+	}
+	if interval <= 0 {
+		return 0, errors.Errorf("interval %q of drift-detection policy must be positive", spec.Interval)
+	}
+	return interval, nil
+}
+
+// DetectDrift compares the resources applied by the application with the rendered manifests of the current
+// application revision, the drifted resources are recorded in the status of the application. The drifted resources
+// are re-applied if the policy is in auto-correct mode. It returns the duration to wait before the next detection,
+// zero means there is no drift-detection policy.
+func (h *AppHandler) DetectDrift(ctx context.Context, af *appfile.Appfile) (time.Duration, error) {
+	app := h.app
+	spec, err := getDriftDetectionPolicy(app)
+	if err != nil || spec == nil {
+		app.Status.Drift = nil
+		return 0, err
+	}
+	interval, err := driftDetectionInterval(spec)
+	if err != nil {
+		return 0, err
+	}
+	if app.Status.Drift != nil {
+		if wait := interval - time.Since(app.Status.Drift.LastCheckTime.Time); wait > 0 {
+			return wait, nil
+		}
+	}
+
+	desired, err := h.renderDesiredManifests(ctx, af)
+	if err != nil {
+		return 0, errors.WithMessage(err, "cannot render manifests of the current revision")
+	}
+	refs, err := h.listTrackedResources(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	status := &common.DriftStatus{LastCheckTime: metav1.Now()}
+	for _, ref := range refs {
+		manifest, ok := desired[manifestKey(ref.APIVersion, ref.Kind, ref.Namespace, ref.Name)]
+		if !ok {
+			// the resource is not rendered from the components, e.g. it's applied by a custom workflow step
+			continue
+		}
+		drifted, err := h.detectResourceDrift(ctx, ref, manifest, spec.IgnoreFields)
+		if err != nil {
+			return 0, err
+		}
+		if drifted == nil {
+			continue
+		}
+		if spec.Mode == v1alpha1.DriftDetectionModeAutoCorrect {
+			if err := h.Dispatch(multicluster.ContextWithClusterName(ctx, ref.Cluster), ref.Cluster, ref.Creator, manifest.DeepCopy()); err != nil {
+				return 0, errors.WithMessagef(err, "cannot correct the drifted resource %s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+			}
+			drifted.Corrected = true
+		}
+		status.Resources = append(status.Resources, *drifted)
+	}
+	app.Status.Drift = status
+	h.recordDriftEvents(status)
+	return interval, nil
+}
+
+// renderDesiredManifests renders the workloads and traits of the components in the same way as applying components,
+// the manifests are indexed by manifestKey.
+func (h *AppHandler) renderDesiredManifests(ctx context.Context, af *appfile.Appfile) (map[string]*unstructured.Unstructured, error) {
+	comps, err := af.GenerateComponentManifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	desired := map[string]*unstructured.Unstructured{}
+	add := func(objs ...*unstructured.Unstructured) {
+		for _, obj := range objs {
+			if obj == nil {
+				continue
+			}
+			ns := obj.GetNamespace()
+			if ns == "" {
+				ns = h.app.Namespace
+			}
+			desired[manifestKey(obj.GetAPIVersion(), obj.GetKind(), ns, obj.GetName())] = obj
+		}
+	}
+	for _, comp := range comps {
+		add(comp.PackagedWorkloadResources...)
+		workload, traits, err := assemble.PrepareBeforeApply(comp, h.currentAppRev, []assemble.WorkloadOption{assemble.DiscoveryHelmBasedWorkload(ctx, h.r.Client)})
+		if err != nil {
+			return nil, err
+		}
+		add(workload)
+		add(traits...)
+	}
+	return desired, nil
+}
+
+// listTrackedResources merges the applied resources of the workflow and the resources tracked by the resource tracker
+func (h *AppHandler) listTrackedResources(ctx context.Context) ([]common.ClusterObjectReference, error) {
+	refs := append([]common.ClusterObjectReference{}, h.app.Status.AppliedResources...)
+	if h.app.Status.ResourceTracker == nil {
+		return refs, nil
+	}
+	rt := &v1beta1.ResourceTracker{}
+	if err := h.r.Get(ctx, client.ObjectKey{Name: h.app.Status.ResourceTracker.Name}, rt); err != nil {
+		if kerrors.IsNotFound(err) {
+			return refs, nil
+		}
+		return nil, errors.Wrap(err, "cannot get resource tracker")
+	}
+	for _, tracked := range rt.Status.TrackedResources {
+		ref := common.ClusterObjectReference{Creator: common.WorkflowResourceCreator, ObjectReference: tracked}
+		found := false
+		for _, current := range refs {
+			if isSameObjReference(current, ref) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// detectResourceDrift returns the drifted resource, nil if the live object matches the desired manifest
+func (h *AppHandler) detectResourceDrift(ctx context.Context, ref common.ClusterObjectReference, desired *unstructured.Unstructured, rules []v1alpha1.DriftIgnoreRule) (*common.DriftedResource, error) {
+	live := &unstructured.Unstructured{}
+	live.SetAPIVersion(ref.APIVersion)
+	live.SetKind(ref.Kind)
+	key := client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	if err := h.r.Get(multicluster.ContextWithClusterName(ctx, ref.Cluster), key, live); err != nil {
+		if kerrors.IsNotFound(err) {
+			return &common.DriftedResource{ClusterObjectReference: ref, Missing: true}, nil
+		}
+		return nil, errors.Wrapf(err, "cannot get the resource %s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+	}
+	fields := diffDesiredFields(desired.Object, live.Object, ignoredPaths(rules, ref))
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return &common.DriftedResource{ClusterObjectReference: ref, Fields: fields}, nil
+}
+
+func (h *AppHandler) recordDriftEvents(status *common.DriftStatus) {
+	var drifted, corrected []string
+	for _, res := range status.Resources {
+		name := fmt.Sprintf("%s %s/%s", res.Kind, res.Namespace, res.Name)
+		if res.Cluster != "" {
+			name = fmt.Sprintf("%s in cluster %s", name, res.Cluster)
+		}
+		if res.Corrected {
+			corrected = append(corrected, name)
+		} else {
+			drifted = append(drifted, name)
+		}
+	}
+	if len(drifted) > 0 {
+		klog.InfoS("Detected drifted resources", "application", klog.KObj(h.app), "resources", drifted)
+		h.r.Recorder.Event(h.app, event.Warning(velatypes.ReasonDriftDetected,
+			errors.Errorf(velatypes.MessageDriftDetected, strings.Join(drifted, ", "))))
+	}
+	if len(corrected) > 0 {
+		klog.InfoS("Corrected drifted resources", "application", klog.KObj(h.app), "resources", corrected)
+		h.r.Recorder.Event(h.app, event.Normal(velatypes.ReasonDriftCorrected,
+			fmt.Sprintf(velatypes.MessageDriftCorrected, strings.Join(corrected, ", "))))
+	}
+}
+
+func manifestKey(apiVersion, kind, namespace, name string) string {
+	return strings.Join([]string{apiVersion, kind, namespace, name}, "/")
+}
+
+// ignoredPaths returns the paths of the rules matching the resource
+func ignoredPaths(rules []v1alpha1.DriftIgnoreRule, ref common.ClusterObjectReference) []string {
+	var paths []string
+	for _, rule := range rules {
+		if (rule.APIVersion == "" || rule.APIVersion == ref.APIVersion) &&
+			(rule.Kind == "" || rule.Kind == ref.Kind) &&
+			(rule.Name == "" || rule.Name == ref.Name) {
+			paths = append(paths, rule.Paths...)
+		}
+	}
+	return paths
+}
+
+// diffDesiredFields returns the paths of the fields in the desired object which are not matched by the live object.
+// The fields only in the live object are not regarded as drift, as they are usually defaulted by the apiserver or
+// set by other controllers. The status and the metadata except the labels and annotations are not compared.
+func diffDesiredFields(desired, live map[string]interface{}, ignored []string) []string {
+	var fields []string
+	isIgnored := func(path string) bool {
+		for _, p := range ignored {
+			if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[") {
+				return true
+			}
+		}
+		return false
+	}
+	var compare func(path string, desired, live interface{})
+	compare = func(path string, desired, live interface{}) {
+		if isIgnored(path) {
+			return
+		}
+		switch d := desired.(type) {
+		case nil:
+			return
+		case map[string]interface{}:
+			l, ok := live.(map[string]interface{})
+			if !ok {
+				fields = append(fields, path)
+				return
+			}
+			for _, k := range sortedKeys(d) {
+				compare(path+"."+k, d[k], l[k])
+			}
+		case []interface{}:
+			l, ok := live.([]interface{})
+			if !ok || len(l) != len(d) {
+				fields = append(fields, path)
+				return
+			}
+			for i := range d {
+				compare(fmt.Sprintf("%s[%d]", path, i), d[i], l[i])
+			}
+		default:
+			if !scalarEqual(d, live) {
+				fields = append(fields, path)
+			}
+		}
+	}
+
+	for _, k := range sortedKeys(desired) {
+		switch k {
+		case "apiVersion", "kind", "status":
+		case "metadata":
+			dm, _ := desired[k].(map[string]interface{})
+			lm, _ := live[k].(map[string]interface{})
+			for _, sub := range []string{"labels", "annotations"} {
+				d, _ := dm[sub].(map[string]interface{})
+				l, _ := lm[sub].(map[string]interface{})
+				for _, key := range sortedKeys(d) {
+					// the labels and annotations of kubevela, including the last-applied-configuration, are changed by every revision
+					if strings.Contains(key, "oam.dev/") {
+						continue
+					}
+					compare("metadata."+sub+"."+key, d[key], l[key])
+				}
+			}
+		default:
+			compare(k, desired[k], live[k])
+		}
+	}
+	return fields
+}
+
+// scalarEqual compares the scalar values, the numbers and the quantities are compared by value
+func scalarEqual(desired, live interface{}) bool {
+	if reflect.DeepEqual(desired, live) {
+		return true
+	}
+	if d, ok := toFloat(desired); ok {
+		l, ok := toFloat(live)
+		return ok && d == l
+	}
+	ds, ok := desired.(string)
+	if !ok {
+		return false
+	}
+	dq, err := resource.ParseQuantity(ds)
+	if err != nil {
+		return false
+	}
+	var lq resource.Quantity
+	switch l := live.(type) {
+	case string:
+		if lq, err = resource.ParseQuantity(l); err != nil {
+			return false
+		}
+	case int64:
+		lq = *resource.NewQuantity(l, resource.DecimalSI)
+	default:
+		return false
+	}
+	return dq.Cmp(lq) == 0
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
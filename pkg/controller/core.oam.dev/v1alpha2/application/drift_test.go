@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestDiffDesiredFields(t *testing.T) {
+	desired := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name": "web",
+			"labels": map[string]interface{}{
+				"app":                   "web",
+				"app.oam.dev/component": "web",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "web",
+							"image": "nginx:1.20",
+							"resources": map[string]interface{}{
+								"limits": map[string]interface{}{"cpu": "1000m"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	live := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"resourceVersion": "10",
+			"labels": map[string]interface{}{
+				"app":                   "web",
+				"app.oam.dev/component": "changed",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas":             float64(2),
+			"revisionHistoryLimit": int64(10),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "web",
+							"image": "nginx:1.20",
+							"resources": map[string]interface{}{
+								"limits": map[string]interface{}{"cpu": "1"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{"replicas": int64(1)},
+	}
+	assert.Empty(t, diffDesiredFields(desired, live, nil))
+
+	live["metadata"].(map[string]interface{})["labels"].(map[string]interface{})["app"] = "other"
+	live["spec"].(map[string]interface{})["replicas"] = int64(5)
+	container := live["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0]
+	container.(map[string]interface{})["image"] = "nginx:latest"
+	assert.Equal(t, []string{
+		"metadata.labels.app",
+		"spec.replicas",
+		"spec.template.spec.containers[0].image",
+	}, diffDesiredFields(desired, live, nil))
+
+	assert.Equal(t, []string{"metadata.labels.app"},
+		diffDesiredFields(desired, live, []string{"spec.replicas", "spec.template.spec.containers"}))
+
+	live["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"] = []interface{}{}
+	assert.Equal(t, []string{"metadata.labels.app", "spec.replicas", "spec.template.spec.containers"},
+		diffDesiredFields(desired, live, nil))
+}
+
+func TestIgnoredPaths(t *testing.T) {
+	rules := []v1alpha1.DriftIgnoreRule{
+		{Paths: []string{"metadata.annotations.note"}},
+		{Kind: "Deployment", Paths: []string{"spec.replicas"}},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "worker", Paths: []string{"spec.template"}},
+		{Kind: "Service", Paths: []string{"spec.ports"}},
+	}
+	ref := common.ClusterObjectReference{ObjectReference: corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}}
+	assert.Equal(t, []string{"metadata.annotations.note", "spec.replicas"}, ignoredPaths(rules, ref))
+	ref.Name = "worker"
+	assert.Equal(t, []string{"metadata.annotations.note", "spec.replicas", "spec.template"}, ignoredPaths(rules, ref))
+}
+
+func TestGetDriftDetectionPolicy(t *testing.T) {
+	app := &v1beta1.Application{}
+	spec, err := getDriftDetectionPolicy(app)
+	assert.NoError(t, err)
+	assert.Nil(t, spec)
+
+	app.Spec.Policies = []v1beta1.AppPolicy{{
+		Name: "drift",
+		Type: v1alpha1.DriftDetectionPolicyType,
+	}}
+	spec, err = getDriftDetectionPolicy(app)
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.DriftDetectionModeReport, spec.Mode)
+	interval, err := driftDetectionInterval(spec)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultDriftDetectionInterval, interval)
+
+	app.Spec.Policies[0].Properties = runtime.RawExtension{Raw: []byte(`{"mode":"auto-correct","interval":"30s","ignoreFields":[{"kind":"Deployment","paths":["spec.replicas"]}]}`)}
+	spec, err = getDriftDetectionPolicy(app)
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.DriftDetectionModeAutoCorrect, spec.Mode)
+	assert.Equal(t, []v1alpha1.DriftIgnoreRule{{Kind: "Deployment", Paths: []string{"spec.replicas"}}}, spec.IgnoreFields)
+	interval, err = driftDetectionInterval(spec)
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, interval)
+
+	app.Spec.Policies[0].Properties = runtime.RawExtension{Raw: []byte(`{"mode":"fix"}`)}
+	_, err = getDriftDetectionPolicy(app)
+	assert.Error(t, err)
+
+	_, err = driftDetectionInterval(&v1alpha1.DriftDetectionPolicySpec{Interval: "-1m"})
+	assert.Error(t, err)
+}
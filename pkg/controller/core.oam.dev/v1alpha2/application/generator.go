@@ -31,6 +31,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/assemble"
 	"github.com/oam-dev/kubevela/pkg/cue/model/value"
 	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/cue/process"
 	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
@@ -43,7 +44,6 @@ import (
 )
 
 // GenerateApplicationSteps generate application steps.
-// nolint:gocyclo
 func (h *AppHandler) GenerateApplicationSteps(ctx context.Context,
 	app *v1beta1.Application,
 	appParser *appfile.Parser,
@@ -52,15 +52,45 @@ func (h *AppHandler) GenerateApplicationSteps(ctx context.Context,
 	cli client.Client,
 	dm discoverymapper.DiscoveryMapper,
 	pd *packages.PackageDiscover) ([]wfTypes.TaskRunner, error) {
+	return h.generateWorkflowSteps(ctx, app, appParser, af, appRev, cli, dm, pd, af.WorkflowSteps, app.Status.Workflow)
+}
+
+// GeneratePreDeleteSteps generate the pre-delete steps of the application.
+func (h *AppHandler) GeneratePreDeleteSteps(ctx context.Context,
+	app *v1beta1.Application,
+	appParser *appfile.Parser,
+	af *appfile.Appfile,
+	appRev *v1beta1.ApplicationRevision,
+	cli client.Client,
+	dm discoverymapper.DiscoveryMapper,
+	pd *packages.PackageDiscover) ([]wfTypes.TaskRunner, error) {
+	var wfStatus *common.WorkflowStatus
+	if app.Status.PreDelete != nil {
+		wfStatus = app.Status.PreDelete.Workflow
+	}
+	return h.generateWorkflowSteps(ctx, app, appParser, af, appRev, cli, dm, pd, app.Spec.PreDelete.Steps, wfStatus)
+}
+
+// nolint:gocyclo
+func (h *AppHandler) generateWorkflowSteps(ctx context.Context,
+	app *v1beta1.Application,
+	appParser *appfile.Parser,
+	af *appfile.Appfile,
+	appRev *v1beta1.ApplicationRevision,
+	cli client.Client,
+	dm discoverymapper.DiscoveryMapper,
+	pd *packages.PackageDiscover,
+	steps []v1beta1.WorkflowStep,
+	wfStatus *common.WorkflowStatus) ([]wfTypes.TaskRunner, error) {
 	handlerProviders := providers.NewProviders()
 	kube.Install(handlerProviders, cli, h.Dispatch)
 	oamProvider.Install(handlerProviders, app, h.applyComponentFunc(
 		appParser, appRev, af, cli))
 	taskDiscover := tasks.NewTaskDiscover(handlerProviders, pd, cli, dm)
 	var tasks []wfTypes.TaskRunner
-	for _, step := range af.WorkflowSteps {
+	for _, step := range steps {
 		options := &wfTypes.GeneratorOptions{
-			ID: generateStepID(step.Name, app.Status.Workflow),
+			ID: generateStepID(step.Name, wfStatus),
 		}
 		generatorName := step.Type
 		if generatorName == "apply-component" {
@@ -124,7 +154,7 @@ func convertStepProperties(step *v1beta1.WorkflowStep, app *v1beta1.Application)
 }
 
 func (h *AppHandler) applyComponentFunc(appParser *appfile.Parser, appRev *v1beta1.ApplicationRevision, af *appfile.Appfile, cli client.Client) oamProvider.ComponentApply {
-	return func(comp common.ApplicationComponent, patcher *value.Value, clusterName string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error) {
+	return func(comp common.ApplicationComponent, patcher *value.Value, clusterName string, envName string, stepName string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error) {
 		ctx := multicluster.ContextWithClusterName(context.Background(), clusterName)
 
 		wl, err := appParser.ParseWorkloadFromRevision(comp, appRev)
@@ -132,7 +162,8 @@ func (h *AppHandler) applyComponentFunc(appParser *appfile.Parser, appRev *v1bet
 			return nil, nil, false, errors.WithMessage(err, "ParseWorkload")
 		}
 		wl.Patch = patcher
-		manifest, err := af.GenerateComponentManifest(wl)
+		wl.RuntimeInfo = process.RuntimeInfo{Cluster: clusterName, EnvName: envName, StepName: stepName}
+		manifest, err := af.GenerateComponentManifest(ctx, wl)
 		if err != nil {
 			return nil, nil, false, errors.WithMessage(err, "GenerateComponentManifest")
 		}
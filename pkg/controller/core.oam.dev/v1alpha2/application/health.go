@@ -0,0 +1,202 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// defaultHealthCheckInterval is the minimum duration between two counted health checks if it's not set in the policy
+const defaultHealthCheckInterval = 10 * time.Second
+
+// appHealthPolicy is the parsed app-health policy
+type appHealthPolicy struct {
+	components         map[string]v1alpha1.ComponentHealthRule
+	startupGracePeriod time.Duration
+	healthyThreshold   int
+	checkInterval      time.Duration
+}
+
+// getAppHealthPolicy returns the app-health policy of the application. The default policy, with which all the
+// components are critical and the application is healthy once all of them are healthy, is returned if there is none.
+func getAppHealthPolicy(app *v1beta1.Application) (*appHealthPolicy, error) {
+	policy := &appHealthPolicy{
+		components:       map[string]v1alpha1.ComponentHealthRule{},
+		healthyThreshold: 1,
+		checkInterval:    defaultHealthCheckInterval,
+	}
+	for _, p := range app.Spec.Policies {
+		if p.Type != v1alpha1.AppHealthPolicyType {
+			continue
+		}
+		spec := &v1alpha1.AppHealthPolicySpec{}
+		if p.Properties.Raw != nil {
+			if err := json.Unmarshal(p.Properties.Raw, spec); err != nil {
+				return nil, errors.Wrapf(err, "invalid properties of policy %s", p.Name)
+			}
+		}
+		for _, rule := range spec.Components {
+			switch rule.Criticality {
+			case "":
+				rule.Criticality = v1alpha1.ComponentCriticalityCritical
+			case v1alpha1.ComponentCriticalityCritical, v1alpha1.ComponentCriticalityOptional:
+			default:
+				return nil, errors.Errorf("unknown criticality %q of component %s in policy %s", rule.Criticality, rule.Name, p.Name)
+			}
+			if rule.Weight < 0 {
+				return nil, errors.Errorf("weight of component %s in policy %s must not be negative", rule.Name, p.Name)
+			}
+			policy.components[rule.Name] = rule
+		}
+		var err error
+		if spec.StartupGracePeriod != "" {
+			if policy.startupGracePeriod, err = time.ParseDuration(spec.StartupGracePeriod); err != nil {
+				return nil, errors.Wrapf(err, "invalid startupGracePeriod of policy %s", p.Name)
+			}
+			if policy.startupGracePeriod <= 0 {
+				return nil, errors.Errorf("startupGracePeriod %q of policy %s must be positive", spec.StartupGracePeriod, p.Name)
+			}
+		}
+		if spec.CheckInterval != "" {
+			if policy.checkInterval, err = time.ParseDuration(spec.CheckInterval); err != nil {
+				return nil, errors.Wrapf(err, "invalid checkInterval of policy %s", p.Name)
+			}
+			if policy.checkInterval <= 0 {
+				return nil, errors.Errorf("checkInterval %q of policy %s must be positive", spec.CheckInterval, p.Name)
+			}
+		}
+		if spec.HealthyThreshold > 0 {
+			policy.healthyThreshold = spec.HealthyThreshold
+		}
+		break
+	}
+	return policy, nil
+}
+
+// isCritical checks whether the health of the component affects the health of the application
+func (p *appHealthPolicy) isCritical(component string) bool {
+	rule, ok := p.components[component]
+	return !ok || rule.Criticality != v1alpha1.ComponentCriticalityOptional
+}
+
+func (p *appHealthPolicy) weight(component string) int {
+	rule, ok := p.components[component]
+	if !ok || rule.Weight == 0 {
+		return 1
+	}
+	return rule.Weight
+}
+
+// componentsHealthy returns the critical components which are unhealthy and the health score of the components
+func (p *appHealthPolicy) componentsHealthy(services []common.ApplicationComponentStatus) ([]string, int) {
+	var unhealthy []string
+	var total, healthy int
+	for _, svc := range services {
+		ok := svc.Healthy
+		for _, tr := range svc.Traits {
+			ok = ok && tr.Healthy
+		}
+		weight := p.weight(svc.Name)
+		total += weight
+		if ok {
+			healthy += weight
+			continue
+		}
+		if p.isCritical(svc.Name) && !stringInSlice(svc.Name, unhealthy) {
+			unhealthy = append(unhealthy, svc.Name)
+		}
+	}
+	score := 100
+	if total > 0 {
+		score = healthy * 100 / total
+	}
+	return unhealthy, score
+}
+
+// evalAppHealth aggregates the health of the components with the policy. The previous health status is used to count
+// the consecutive healthy checks, the checks within the check interval are not counted. It returns the duration to
+// wait before the next check, zero means there is no need to check again.
+func (p *appHealthPolicy) evalAppHealth(services []common.ApplicationComponentStatus, previous *common.AppHealthStatus,
+	revisionCreated, now time.Time) (*common.AppHealthStatus, time.Duration) {
+	unhealthy, score := p.componentsHealthy(services)
+	status := &common.AppHealthStatus{
+		Score:               score,
+		UnhealthyComponents: unhealthy,
+		LastCheckTime:       metav1.NewTime(now),
+	}
+	var requeue time.Duration
+	if len(unhealthy) > 0 {
+		if graceEnd := revisionCreated.Add(p.startupGracePeriod); now.Before(graceEnd) {
+			status.InGracePeriod = true
+			requeue = graceEnd.Sub(now)
+		}
+		return status, requeue
+	}
+
+	if previous == nil {
+		previous = &common.AppHealthStatus{}
+	}
+	status.ConsecutiveHealthyChecks = previous.ConsecutiveHealthyChecks
+	if wait := p.checkInterval - now.Sub(previous.LastCheckTime.Time); previous.ConsecutiveHealthyChecks > 0 && wait > 0 {
+		// the check is too close to the last counted check
+		status.LastCheckTime = previous.LastCheckTime
+		requeue = wait
+	} else {
+		status.ConsecutiveHealthyChecks++
+	}
+	// once the application is reported healthy, it keeps healthy until any critical component is unhealthy
+	status.Healthy = previous.Healthy || status.ConsecutiveHealthyChecks >= p.healthyThreshold
+	if status.Healthy {
+		return status, 0
+	}
+	if requeue == 0 {
+		requeue = p.checkInterval
+	}
+	return status, requeue
+}
+
+// updateAppHealth evaluates the health of the application by the app-health policy and records it in the status
+func (h *AppHandler) updateAppHealth(services []common.ApplicationComponentStatus) (*common.AppHealthStatus, time.Duration, error) {
+	policy, err := getAppHealthPolicy(h.app)
+	if err != nil {
+		return nil, 0, err
+	}
+	var revisionCreated time.Time
+	if h.currentAppRev != nil {
+		revisionCreated = h.currentAppRev.CreationTimestamp.Time
+	}
+	status, requeue := policy.evalAppHealth(services, h.app.Status.Health, revisionCreated, time.Now())
+	h.app.Status.Health = status
+	return status, requeue, nil
+}
+
+func stringInSlice(s string, list []string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
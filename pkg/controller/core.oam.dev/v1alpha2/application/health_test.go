@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func newAppWithHealthPolicy(properties string) *v1beta1.Application {
+	app := &v1beta1.Application{}
+	app.Spec.Policies = []v1beta1.AppPolicy{{
+		Name:       "health",
+		Type:       v1alpha1.AppHealthPolicyType,
+		Properties: runtime.RawExtension{Raw: []byte(properties)},
+	}}
+	return app
+}
+
+func TestGetAppHealthPolicy(t *testing.T) {
+	policy, err := getAppHealthPolicy(&v1beta1.Application{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, policy.healthyThreshold)
+	assert.Equal(t, defaultHealthCheckInterval, policy.checkInterval)
+	assert.Equal(t, time.Duration(0), policy.startupGracePeriod)
+	assert.True(t, policy.isCritical("any"))
+
+	policy, err = getAppHealthPolicy(newAppWithHealthPolicy(`{"components":[{"name":"cache","criticality":"optional"},{"name":"web","weight":3}],"startupGracePeriod":"2m","healthyThreshold":3,"checkInterval":"30s"}`))
+	assert.NoError(t, err)
+	assert.False(t, policy.isCritical("cache"))
+	assert.True(t, policy.isCritical("web"))
+	assert.Equal(t, 3, policy.weight("web"))
+	assert.Equal(t, 1, policy.weight("cache"))
+	assert.Equal(t, 2*time.Minute, policy.startupGracePeriod)
+	assert.Equal(t, 3, policy.healthyThreshold)
+	assert.Equal(t, 30*time.Second, policy.checkInterval)
+
+	_, err = getAppHealthPolicy(newAppWithHealthPolicy(`{"components":[{"name":"web","criticality":"important"}]}`))
+	assert.Error(t, err)
+	_, err = getAppHealthPolicy(newAppWithHealthPolicy(`{"startupGracePeriod":"soon"}`))
+	assert.Error(t, err)
+	_, err = getAppHealthPolicy(newAppWithHealthPolicy(`{"startupGracePeriod":"-1m"}`))
+	assert.Error(t, err)
+	_, err = getAppHealthPolicy(newAppWithHealthPolicy(`{"checkInterval":"0s","healthyThreshold":3}`))
+	assert.Error(t, err)
+}
+
+func TestComponentsHealthy(t *testing.T) {
+	policy, err := getAppHealthPolicy(newAppWithHealthPolicy(`{"components":[{"name":"cache","criticality":"optional"},{"name":"web","weight":3}]}`))
+	assert.NoError(t, err)
+
+	unhealthy, score := policy.componentsHealthy(nil)
+	assert.Empty(t, unhealthy)
+	assert.Equal(t, 100, score)
+
+	services := []common.ApplicationComponentStatus{
+		{Name: "web", Healthy: true},
+		{Name: "cache", Healthy: false},
+	}
+	unhealthy, score = policy.componentsHealthy(services)
+	assert.Empty(t, unhealthy)
+	assert.Equal(t, 75, score)
+
+	services[0].Traits = []common.ApplicationTraitStatus{{Type: "ingress", Healthy: false}}
+	unhealthy, score = policy.componentsHealthy(services)
+	assert.Equal(t, []string{"web"}, unhealthy)
+	assert.Equal(t, 0, score)
+}
+
+func TestEvalAppHealth(t *testing.T) {
+	policy, err := getAppHealthPolicy(newAppWithHealthPolicy(`{"startupGracePeriod":"1m","healthyThreshold":2,"checkInterval":"10s"}`))
+	assert.NoError(t, err)
+	created := time.Now()
+	unhealthy := []common.ApplicationComponentStatus{{Name: "web", Healthy: false}}
+	healthy := []common.ApplicationComponentStatus{{Name: "web", Healthy: true}}
+
+	// unhealthy in the grace period
+	status, requeue := policy.evalAppHealth(unhealthy, nil, created, created.Add(20*time.Second))
+	assert.False(t, status.Healthy)
+	assert.True(t, status.InGracePeriod)
+	assert.Equal(t, []string{"web"}, status.UnhealthyComponents)
+	assert.Equal(t, 40*time.Second, requeue)
+
+	// unhealthy after the grace period
+	status, requeue = policy.evalAppHealth(unhealthy, status, created, created.Add(2*time.Minute))
+	assert.False(t, status.Healthy)
+	assert.False(t, status.InGracePeriod)
+	assert.Equal(t, time.Duration(0), requeue)
+
+	// the first healthy check
+	now := created.Add(3 * time.Minute)
+	status, requeue = policy.evalAppHealth(healthy, status, created, now)
+	assert.False(t, status.Healthy)
+	assert.Equal(t, 1, status.ConsecutiveHealthyChecks)
+	assert.Equal(t, 10*time.Second, requeue)
+
+	// the check within the interval is not counted
+	status, requeue = policy.evalAppHealth(healthy, status, created, now.Add(4*time.Second))
+	assert.False(t, status.Healthy)
+	assert.Equal(t, 1, status.ConsecutiveHealthyChecks)
+	assert.Equal(t, 6*time.Second, requeue)
+
+	// the second healthy check
+	status, requeue = policy.evalAppHealth(healthy, status, created, now.Add(10*time.Second))
+	assert.True(t, status.Healthy)
+	assert.Equal(t, 2, status.ConsecutiveHealthyChecks)
+	assert.Equal(t, 100, status.Score)
+	assert.Equal(t, time.Duration(0), requeue)
+
+	// the healthy application keeps healthy
+	status, _ = policy.evalAppHealth(healthy, status, created, now.Add(11*time.Second))
+	assert.True(t, status.Healthy)
+
+	// the counter is reset once any critical component is unhealthy
+	status, _ = policy.evalAppHealth(unhealthy, status, created, now.Add(20*time.Second))
+	assert.False(t, status.Healthy)
+	assert.Equal(t, 0, status.ConsecutiveHealthyChecks)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	velatypes "github.com/oam-dev/kubevela/apis/types"
+)
+
+const (
+	// conditionTypePaused is the type of the condition records whether the application is paused
+	conditionTypePaused condition.ConditionType = "Paused"
+
+	reasonPaused  condition.ConditionReason = "Paused"
+	reasonResumed condition.ConditionReason = "Resumed"
+)
+
+// isAppPaused checks whether the application is paused by the spec
+func isAppPaused(app *v1beta1.Application) bool {
+	return app.Spec.Pause != nil
+}
+
+// pausedCondition returns the condition records who paused the application and why
+func pausedCondition(app *v1beta1.Application) condition.Condition {
+	return condition.Condition{
+		Type:               conditionTypePaused,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonPaused,
+		Message:            fmt.Sprintf(velatypes.MessagePaused, app.Spec.Pause.By, app.Spec.Pause.Reason),
+	}
+}
+
+// resumePausedCondition marks the paused condition false if the application was paused
+func resumePausedCondition(app *v1beta1.Application) {
+	if app.Status.GetCondition(conditionTypePaused).Status != corev1.ConditionTrue {
+		return
+	}
+	app.Status.SetConditions(condition.Condition{
+		Type:               conditionTypePaused,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonResumed,
+	})
+}
+
+// reconcilePaused only updates the health and rollback status of the paused application, the revision, resources,
+// workflow, pre-delete workflow and garbage collection are all left untouched. The health is checked against the latest
+// revision, as the spec may have been changed since the application is paused.
+func (r *Reconciler) reconcilePaused(ctx context.Context, handler *AppHandler) (ctrl.Result, error) {
+	app := handler.app
+	klog.InfoS("Application is paused, skip dispatching resources", "application", klog.KObj(app),
+		"pausedBy", app.Spec.Pause.By)
+	if app.Status.GetCondition(conditionTypePaused).Status != corev1.ConditionTrue {
+		r.Recorder.Event(app, event.Normal(velatypes.ReasonPaused, pausedCondition(app).Message))
+	}
+	app.Status.SetConditions(pausedCondition(app))
+
+	phase := app.Status.Phase
+	// record the rollback made while the application is paused, so it's kept after the application is unpaused
+	if _, err := r.getRollbackRevision(ctx, app); err != nil {
+		klog.ErrorS(err, "Failed to get the revision to roll back of paused application", "application", klog.KObj(app))
+		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedRollback, err))
+		return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("Rollback", err), phase)
+	}
+	if app.Status.LatestRevision == nil {
+		return ctrl.Result{}, r.patchStatus(ctx, app, phase)
+	}
+	appRev := &v1beta1.ApplicationRevision{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: app.Namespace, Name: app.Status.LatestRevision.Name}, appRev); err != nil {
+		klog.ErrorS(err, "Failed to get the latest revision of paused application", "application", klog.KObj(app))
+		return r.endWithNegativeCondition(ctx, app, condition.ReconcileError(err), phase)
+	}
+	appFile, err := handler.parser.GenerateAppFileFromRevision(appRev)
+	if err != nil {
+		klog.ErrorS(err, "Failed to parse the latest revision of paused application", "application", klog.KObj(app))
+		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedParse, err))
+		return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("Parsed", err), phase)
+	}
+	if hasHealthCheckPolicy(appFile.Policies) {
+		return ctrl.Result{}, r.patchStatus(ctx, app, phase)
+	}
+	appFile.AppRevisionName = appRev.Name
+	services, _, err := handler.aggregateHealthStatus(ctx, appFile)
+	if err != nil {
+		klog.ErrorS(err, "Failed to check health of paused application", "application", klog.KObj(app))
+		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedHealthCheck, err))
+		return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("HealthCheck", err), phase)
+	}
+	app.Status.Services = services
+	health, requeue, err := handler.updateAppHealth(services)
+	if err != nil {
+		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedHealthCheck, err))
+		return r.endWithNegativeCondition(ctx, app, condition.ErrorCondition("HealthCheck", err), phase)
+	}
+	phase = common.ApplicationRunning
+	if !health.Healthy && !health.InGracePeriod {
+		phase = common.ApplicationUnhealthy
+	}
+	return ctrl.Result{RequeueAfter: requeue}, r.patchStatus(ctx, app, phase)
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestPausedCondition(t *testing.T) {
+	app := &v1beta1.Application{}
+	assert.False(t, isAppPaused(app))
+
+	// nothing changes if the application has never been paused
+	resumePausedCondition(app)
+	assert.Empty(t, app.Status.Conditions)
+
+	app.Spec.Pause = &v1beta1.ApplicationPause{By: "alice", Reason: "incident"}
+	assert.True(t, isAppPaused(app))
+	app.Status.SetConditions(pausedCondition(app))
+	cond := app.Status.GetCondition(conditionTypePaused)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Equal(t, "Paused by alice, reason: incident", cond.Message)
+
+	resumePausedCondition(app)
+	cond = app.Status.GetCondition(conditionTypePaused)
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, reasonResumed, cond.Reason)
+}
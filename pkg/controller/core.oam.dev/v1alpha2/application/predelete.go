@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	velatypes "github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/workflow"
+)
+
+// defaultPreDeleteTimeout is the max duration to run the pre-delete workflow if it's not set in the spec
+const defaultPreDeleteTimeout = 10 * time.Minute
+
+// needPreDelete checks whether the pre-delete workflow should run before the resources of the application are
+// released, it runs only once when the application is being deleted
+func needPreDelete(app *v1beta1.Application) bool {
+	if app.DeletionTimestamp.IsZero() || app.Spec.PreDelete == nil || len(app.Spec.PreDelete.Steps) == 0 {
+		return false
+	}
+	if !meta.FinalizerExists(app, resourceTrackerFinalizer) && !meta.FinalizerExists(app, legacyOnlyRevisionFinalizer) {
+		return false
+	}
+	return app.Status.PreDelete == nil || !app.Status.PreDelete.Finished
+}
+
+func preDeleteTimeout(spec *v1beta1.PreDeleteWorkflow) (time.Duration, error) {
+	if spec.Timeout == "" {
+		return defaultPreDeleteTimeout, nil
+	}
+	timeout, err := time.ParseDuration(spec.Timeout)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid timeout %q of pre-delete workflow", spec.Timeout)
+	}
+	if timeout <= 0 {
+		return 0, errors.Errorf("timeout %q of pre-delete workflow must be positive", spec.Timeout)
+	}
+	return timeout, nil
+}
+
+// RunPreDelete runs the pre-delete workflow with the revision currently deployed. It returns true once the workflow
+// succeeds, is terminated or times out, then the resources of the application can be released.
+func (h *AppHandler) RunPreDelete(ctx context.Context) (bool, error) {
+	app := h.app
+	timeout, err := preDeleteTimeout(app.Spec.PreDelete)
+	if err != nil {
+		// an invalid timeout must not block the deletion forever
+		h.r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedPreDelete,
+			errors.WithMessagef(err, "fall back to the default timeout %s", defaultPreDeleteTimeout)))
+		timeout = defaultPreDeleteTimeout
+	}
+	if app.Status.PreDelete == nil {
+		app.Status.PreDelete = &common.PreDeleteStatus{StartTime: metav1.Now()}
+	}
+	status := app.Status.PreDelete
+	if time.Since(status.StartTime.Time) >= timeout {
+		status.Finished = true
+		status.Message = fmt.Sprintf("timed out after %s", timeout)
+		return true, nil
+	}
+
+	revName := ""
+	switch {
+	case app.Status.Rollback != nil:
+		revName = app.Status.Rollback.Revision
+	case app.Status.LatestRevision != nil:
+		revName = app.Status.LatestRevision.Name
+	default:
+		status.Finished = true
+		status.Message = "skipped as the application has never been deployed"
+		return true, nil
+	}
+	appRev := &v1beta1.ApplicationRevision{}
+	if err := h.r.Get(ctx, client.ObjectKey{Namespace: app.Namespace, Name: revName}, appRev); err != nil {
+		return false, errors.Wrapf(err, "cannot get application revision %q", revName)
+	}
+	h.currentAppRev = appRev
+	af, err := h.parser.GenerateAppFileFromRevision(appRev)
+	if err != nil {
+		return false, errors.WithMessage(err, "cannot parse application revision")
+	}
+	steps, err := h.GeneratePreDeleteSteps(ctx, app, h.parser, af, appRev, h.r.Client, h.r.dm, h.r.pd)
+	if err != nil {
+		return false, errors.WithMessage(err, "cannot generate pre-delete steps")
+	}
+	state, err := workflow.NewPreDeleteWorkflow(app, h.r.Client).ExecuteSteps(ctx, appRev, steps)
+	if err != nil {
+		return false, errors.WithMessage(err, "cannot execute pre-delete steps")
+	}
+	switch state {
+	case common.WorkflowStateFinished:
+		status.Finished = true
+		status.Message = "succeeded"
+	case common.WorkflowStateTerminated:
+		status.Finished = true
+		status.Message = "terminated"
+	default:
+	}
+	return status.Finished, nil
+}
+
+// reconcilePreDelete runs the pre-delete workflow and records its status, it returns true if the resources of the
+// application can be released in this reconciliation
+func (r *Reconciler) reconcilePreDelete(ctx context.Context, handler *AppHandler) (bool, error) {
+	app := handler.app
+	finished, err := handler.RunPreDelete(ctx)
+	if err != nil {
+		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedPreDelete, err))
+		return false, err
+	}
+	if finished {
+		r.Recorder.Event(app, event.Normal(velatypes.ReasonPreDeleted, fmt.Sprintf(velatypes.MessagePreDeleted, app.Status.PreDelete.Message)))
+	}
+	// the status is recorded before the finalizer is removed, so that the workflow never runs again
+	if err := r.patchStatus(ctx, app, common.ApplicationDeleting); err != nil {
+		return false, errors.WithMessage(err, "cannot update application status")
+	}
+	return finished, nil
+}
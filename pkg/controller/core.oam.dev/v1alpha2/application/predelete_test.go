@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	commontypes "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestNeedPreDelete(t *testing.T) {
+	now := metav1.Now()
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", Finalizers: []string{resourceTrackerFinalizer}},
+		Spec: v1beta1.ApplicationSpec{
+			PreDelete: &v1beta1.PreDeleteWorkflow{Steps: []v1beta1.WorkflowStep{{Name: "drain", Type: "suspend"}}},
+		},
+	}
+	assert.False(t, needPreDelete(app))
+
+	app.DeletionTimestamp = &now
+	assert.True(t, needPreDelete(app))
+
+	app.Status.PreDelete = &commontypes.PreDeleteStatus{Finished: true}
+	assert.False(t, needPreDelete(app))
+
+	app.Status.PreDelete = nil
+	app.Finalizers = nil
+	assert.False(t, needPreDelete(app))
+}
+
+func TestRunPreDelete(t *testing.T) {
+	ctx := context.Background()
+	appRev := &v1beta1.ApplicationRevision{ObjectMeta: metav1.ObjectMeta{Name: "app-v1", Namespace: "default"}}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(appRev).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   cli,
+		pd:       &packages.PackageDiscover{},
+		Recorder: event.NewAPIRecorder(recorder),
+	}
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: v1beta1.ApplicationSpec{
+			PreDelete: &v1beta1.PreDeleteWorkflow{Steps: []v1beta1.WorkflowStep{{Name: "drain", Type: "suspend"}}},
+		},
+	}
+	newHandler := func() *AppHandler {
+		return &AppHandler{r: r, app: app, parser: appfile.NewApplicationParser(cli, nil, r.pd)}
+	}
+
+	// skip the workflow if the application has never been deployed
+	finished, err := newHandler().RunPreDelete(ctx)
+	assert.NoError(t, err)
+	assert.True(t, finished)
+
+	app.Status.PreDelete = nil
+	app.Status.LatestRevision = &commontypes.Revision{Name: "app-v1", Revision: 1}
+	finished, err = newHandler().RunPreDelete(ctx)
+	assert.NoError(t, err)
+	assert.False(t, finished)
+	assert.True(t, app.Status.PreDelete.Workflow.Suspend)
+	assert.Equal(t, "drain", app.Status.PreDelete.Workflow.Steps[0].Name)
+	assert.Nil(t, app.Status.Workflow)
+
+	// the resources are released once it times out
+	app.Spec.PreDelete.Timeout = "1m"
+	app.Status.PreDelete.StartTime = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	finished, err = newHandler().RunPreDelete(ctx)
+	assert.NoError(t, err)
+	assert.True(t, finished)
+	assert.Contains(t, app.Status.PreDelete.Message, "timed out")
+
+	// the default timeout is used if the timeout is invalid
+	app.Spec.PreDelete.Timeout = "invalid"
+	app.Status.PreDelete.Finished = false
+	finished, err = newHandler().RunPreDelete(ctx)
+	assert.NoError(t, err)
+	assert.False(t, finished)
+	assert.Contains(t, <-recorder.Events, "fall back to the default timeout 10m0s")
+
+	app.Status.PreDelete.StartTime = metav1.NewTime(time.Now().Add(-defaultPreDeleteTimeout))
+	finished, err = newHandler().RunPreDelete(ctx)
+	assert.NoError(t, err)
+	assert.True(t, finished)
+	assert.Equal(t, "timed out after 10m0s", app.Status.PreDelete.Message)
+}
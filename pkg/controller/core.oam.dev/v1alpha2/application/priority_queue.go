@@ -0,0 +1,358 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const (
+	// AppPriorityHigh applications are enqueued without rate limiting
+	AppPriorityHigh = "high"
+	// AppPriorityNormal applications are rate limited by the token bucket of their namespace
+	AppPriorityNormal = "normal"
+	// AppPriorityLow applications are rate limited by the token bucket of their namespace and a global token bucket
+	// shared by all the low priority applications
+	AppPriorityLow = "low"
+)
+
+// appPriorities are the priorities from the highest to the lowest
+var appPriorities = []string{AppPriorityHigh, AppPriorityNormal, AppPriorityLow}
+
+func priorityRank(priority string) int {
+	for i, p := range appPriorities {
+		if p == priority {
+			return i
+		}
+	}
+	return len(appPriorities)
+}
+
+var (
+	appQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kubevela_application_queue_depth",
+		Help: "Number of applications waiting in the queue of the application controller, by priority.",
+	}, []string{"priority"})
+	appQueueLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kubevela_application_queue_latency_seconds",
+		Help:    "How long an application waits in the queue before it's reconciled, by priority.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"priority"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(appQueueDepth, appQueueLatency)
+}
+
+// RateLimitOptions configures the token buckets of the application controller queue, a non-positive QPS disables
+// the corresponding limit
+type RateLimitOptions struct {
+	NamespaceQPS     float64
+	NamespaceBurst   int
+	LowPriorityQPS   float64
+	LowPriorityBurst int
+}
+
+// getAppPriority returns the priority of the application from its label or annotation, normal if it's unset or invalid
+func getAppPriority(obj client.Object) string {
+	priority, ok := obj.GetLabels()[oam.LabelAppPriority]
+	if !ok {
+		priority = obj.GetAnnotations()[oam.AnnotationAppPriority]
+	}
+	switch priority {
+	case AppPriorityHigh, AppPriorityLow:
+		return priority
+	default:
+		return AppPriorityNormal
+	}
+}
+
+func newLimiter(qps float64, burst int) *rate.Limiter {
+	if qps <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(qps), burst)
+}
+
+type pendingState int
+
+const (
+	// pendingDelayed requests wait for the token buckets
+	pendingDelayed pendingState = iota
+	// pendingReady requests wait in the queue of their priority
+	pendingReady
+	// pendingQueued requests are added to the controller queue
+	pendingQueued
+)
+
+type pendingApp struct {
+	priority   string
+	state      pendingState
+	enqueuedAt time.Time
+}
+
+// appEnqueueHandler enqueues the events of applications by priority. The requests are delayed by the token buckets
+// first, so that a flood of events, e.g. from an informer re-sync, is spread over time. Then they wait in a queue of
+// their priority, and are moved to the controller queue from the highest priority to the lowest, only as fast as the
+// controller reconciles them. The controller queue is kept shallow, so that high priority applications are not
+// blocked by the others.
+// A request is pending until the application starts to be reconciled, the events during then are merged into it, and
+// a higher priority of the later events takes effect.
+type appEnqueueHandler struct {
+	opts RateLimitOptions
+	// queueDepth is the max number of requests in the controller queue added by the handler
+	queueDepth int
+	delayed    workqueue.DelayingInterface
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// namespaceLimiters are kept only while there are pending applications in the namespaces, as the token buckets
+	// are full again once the pending applications are drained
+	namespaceLimiters  map[string]*rate.Limiter
+	namespacePending   map[string]int
+	lowPriorityLimiter *rate.Limiter
+	pending            map[types.NamespacedName]*pendingApp
+	ready              map[string][]types.NamespacedName
+	queued             int
+	queue              workqueue.RateLimitingInterface
+	stopped            bool
+}
+
+func newAppEnqueueHandler(opts RateLimitOptions) *appEnqueueHandler {
+	h := &appEnqueueHandler{
+		opts:               opts,
+		queueDepth:         1,
+		delayed:            workqueue.NewNamedDelayingQueue("application-rate-limit"),
+		namespaceLimiters:  map[string]*rate.Limiter{},
+		namespacePending:   map[string]int{},
+		lowPriorityLimiter: newLimiter(opts.LowPriorityQPS, opts.LowPriorityBurst),
+		pending:            map[types.NamespacedName]*pendingApp{},
+		ready:              map[string][]types.NamespacedName{},
+	}
+	h.cond = sync.NewCond(&h.mu)
+	return h
+}
+
+// setQueueDepth sets the max number of requests in the controller queue, it should be the number of the workers so
+// that none of them is idle
+func (h *appEnqueueHandler) setQueueDepth(depth int) {
+	if depth < 1 {
+		depth = 1
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queueDepth = depth
+}
+
+// Create implements EventHandler
+func (h *appEnqueueHandler) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(evt.Object, q)
+}
+
+// Update implements EventHandler
+func (h *appEnqueueHandler) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(evt.ObjectNew, q)
+}
+
+// Delete implements EventHandler
+func (h *appEnqueueHandler) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(evt.Object, q)
+}
+
+// Generic implements EventHandler
+func (h *appEnqueueHandler) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(evt.Object, q)
+}
+
+func (h *appEnqueueHandler) enqueue(obj client.Object, q workqueue.RateLimitingInterface) {
+	if obj == nil {
+		return
+	}
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	h.mu.Lock()
+	if h.queue == nil {
+		h.queue = q
+	}
+	h.mu.Unlock()
+	if delay, ok := h.reserve(key, getAppPriority(obj)); ok {
+		h.delayed.AddAfter(key, delay)
+	}
+}
+
+// reserve records the application as pending and returns the delay to enqueue it, false if it needn't be delayed
+// again. A pending application raised to a higher priority is moved to the queue of the new priority, or delayed
+// again by the token buckets of the new priority if it's still delayed, the earlier delay takes effect.
+func (h *appEnqueueHandler) reserve(key types.NamespacedName, priority string) (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	item, ok := h.pending[key]
+	if ok {
+		if item.state == pendingQueued || priorityRank(priority) >= priorityRank(item.priority) {
+			return 0, false
+		}
+		appQueueDepth.WithLabelValues(item.priority).Dec()
+		appQueueDepth.WithLabelValues(priority).Inc()
+		if item.state == pendingReady {
+			h.removeReady(key, item.priority)
+			item.priority = priority
+			h.addReady(key, priority)
+			return 0, false
+		}
+		item.priority = priority
+	} else {
+		h.pending[key] = &pendingApp{priority: priority, state: pendingDelayed, enqueuedAt: time.Now()}
+		h.namespacePending[key.Namespace]++
+		appQueueDepth.WithLabelValues(priority).Inc()
+	}
+
+	if priority == AppPriorityHigh {
+		return 0, true
+	}
+	limiter, ok := h.namespaceLimiters[key.Namespace]
+	if !ok {
+		limiter = newLimiter(h.opts.NamespaceQPS, h.opts.NamespaceBurst)
+		h.namespaceLimiters[key.Namespace] = limiter
+	}
+	delay := limiter.Reserve().Delay()
+	if priority == AppPriorityLow {
+		if lowDelay := h.lowPriorityLimiter.Reserve().Delay(); lowDelay > delay {
+			delay = lowDelay
+		}
+	}
+	return delay, true
+}
+
+func (h *appEnqueueHandler) addReady(key types.NamespacedName, priority string) {
+	h.ready[priority] = append(h.ready[priority], key)
+	h.cond.Broadcast()
+}
+
+func (h *appEnqueueHandler) removeReady(key types.NamespacedName, priority string) {
+	keys := h.ready[priority]
+	for i, k := range keys {
+		if k == key {
+			h.ready[priority] = append(keys[:i:i], keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// Start moves the requests out of the token buckets and feeds the controller queue by priority until the context is
+// done, it implements manager.Runnable
+func (h *appEnqueueHandler) Start(ctx context.Context) error {
+	go h.releaseDelayed()
+	go func() {
+		<-ctx.Done()
+		h.delayed.ShutDown()
+		h.mu.Lock()
+		h.stopped = true
+		h.cond.Broadcast()
+		h.mu.Unlock()
+	}()
+	h.feed()
+	return nil
+}
+
+// releaseDelayed moves the requests allowed by the token buckets to the queues of their priorities
+func (h *appEnqueueHandler) releaseDelayed() {
+	for {
+		item, shutdown := h.delayed.Get()
+		if shutdown {
+			return
+		}
+		h.delayed.Done(item)
+		key := item.(types.NamespacedName)
+		h.mu.Lock()
+		if p, ok := h.pending[key]; ok && p.state == pendingDelayed {
+			p.state = pendingReady
+			h.addReady(key, p.priority)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// feed adds the ready requests to the controller queue from the highest priority to the lowest, while the controller
+// queue has room for them
+func (h *appEnqueueHandler) feed() {
+	for {
+		h.mu.Lock()
+		key, ok := h.nextReady()
+		for !h.stopped && (!ok || h.queue == nil || h.queued >= h.queueDepth) {
+			h.cond.Wait()
+			key, ok = h.nextReady()
+		}
+		if h.stopped {
+			h.mu.Unlock()
+			return
+		}
+		p := h.pending[key]
+		h.removeReady(key, p.priority)
+		p.state = pendingQueued
+		h.queued++
+		q := h.queue
+		h.mu.Unlock()
+		q.Add(reconcile.Request{NamespacedName: key})
+	}
+}
+
+// nextReady returns the first ready request of the highest priority
+func (h *appEnqueueHandler) nextReady() (types.NamespacedName, bool) {
+	for _, priority := range appPriorities {
+		if keys := h.ready[priority]; len(keys) > 0 {
+			return keys[0], true
+		}
+	}
+	return types.NamespacedName{}, false
+}
+
+// done removes the application from the pending ones when it starts to be reconciled and records its queue latency,
+// so that the events during the reconciliation are enqueued again. The limiter of the namespace is evicted once no
+// application is pending in it.
+func (h *appEnqueueHandler) done(key types.NamespacedName) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	item, ok := h.pending[key]
+	if !ok || item.state != pendingQueued {
+		// requeued by the reconciler itself, the pending request is still to be reconciled
+		return
+	}
+	delete(h.pending, key)
+	if h.namespacePending[key.Namespace]--; h.namespacePending[key.Namespace] <= 0 {
+		delete(h.namespacePending, key.Namespace)
+		delete(h.namespaceLimiters, key.Namespace)
+	}
+	h.queued--
+	h.cond.Broadcast()
+	appQueueDepth.WithLabelValues(item.priority).Dec()
+	appQueueLatency.WithLabelValues(item.priority).Observe(time.Since(item.enqueuedAt).Seconds())
+}
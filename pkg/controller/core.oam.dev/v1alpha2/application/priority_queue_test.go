@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestGetAppPriority(t *testing.T) {
+	app := &v1beta1.Application{}
+	assert.Equal(t, AppPriorityNormal, getAppPriority(app))
+	app.SetAnnotations(map[string]string{oam.AnnotationAppPriority: AppPriorityLow})
+	assert.Equal(t, AppPriorityLow, getAppPriority(app))
+	app.SetLabels(map[string]string{oam.LabelAppPriority: AppPriorityHigh})
+	assert.Equal(t, AppPriorityHigh, getAppPriority(app))
+	app.SetLabels(map[string]string{oam.LabelAppPriority: "urgent"})
+	assert.Equal(t, AppPriorityNormal, getAppPriority(app))
+}
+
+func TestAppEnqueueHandler(t *testing.T) {
+	h := newAppEnqueueHandler(RateLimitOptions{NamespaceQPS: 1, NamespaceBurst: 1, LowPriorityQPS: 0.1, LowPriorityBurst: 1})
+	newApp := func(ns, name, priority string) *v1beta1.Application {
+		return &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns, Name: name, Labels: map[string]string{oam.LabelAppPriority: priority}}}
+	}
+	reserve := func(app *v1beta1.Application) (time.Duration, bool) {
+		return h.reserve(types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, getAppPriority(app))
+	}
+
+	delay, ok := reserve(newApp("team-a", "a1", AppPriorityNormal))
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+	// already pending
+	_, ok = reserve(newApp("team-a", "a1", AppPriorityNormal))
+	assert.False(t, ok)
+	// the bucket of team-a is exhausted
+	delay, ok = reserve(newApp("team-a", "a2", AppPriorityNormal))
+	assert.True(t, ok)
+	assert.Greater(t, int64(delay), int64(0))
+	// high priority applications are not limited
+	delay, _ = reserve(newApp("team-a", "a3", AppPriorityHigh))
+	assert.Zero(t, delay)
+	// other namespaces are not affected
+	delay, _ = reserve(newApp("team-b", "b1", AppPriorityNormal))
+	assert.Zero(t, delay)
+	// low priority applications share the global bucket
+	delay, _ = reserve(newApp("team-c", "c1", AppPriorityLow))
+	assert.Zero(t, delay)
+	delay, _ = reserve(newApp("team-d", "d1", AppPriorityLow))
+	assert.Greater(t, int64(delay), int64(time.Second))
+
+	// raised to a higher priority, it's delayed again by the token buckets of the new priority
+	delay, ok = reserve(newApp("team-a", "a2", AppPriorityHigh))
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+	_, ok = reserve(newApp("team-a", "a2", AppPriorityNormal))
+	assert.False(t, ok)
+
+	h.pending[types.NamespacedName{Namespace: "team-a", Name: "a1"}].state = pendingQueued
+	h.queued = 1
+	depth := testutil.ToFloat64(appQueueDepth.WithLabelValues(AppPriorityNormal))
+	h.done(types.NamespacedName{Namespace: "team-a", Name: "a1"})
+	assert.Equal(t, depth-1, testutil.ToFloat64(appQueueDepth.WithLabelValues(AppPriorityNormal)))
+	// requeued by the reconciler
+	h.done(types.NamespacedName{Namespace: "team-a", Name: "a1"})
+	assert.Equal(t, depth-1, testutil.ToFloat64(appQueueDepth.WithLabelValues(AppPriorityNormal)))
+
+	// the limiter of a namespace is evicted once no application is pending in it
+	assert.Contains(t, h.namespaceLimiters, "team-a")
+	h.pending[types.NamespacedName{Namespace: "team-b", Name: "b1"}].state = pendingQueued
+	h.queued = 1
+	h.done(types.NamespacedName{Namespace: "team-b", Name: "b1"})
+	assert.NotContains(t, h.namespaceLimiters, "team-b")
+	assert.NotContains(t, h.namespacePending, "team-b")
+	delay, _ = reserve(newApp("team-b", "b1", AppPriorityNormal))
+	assert.Zero(t, delay)
+}
+
+func TestAppEnqueueHandlerFeedByPriority(t *testing.T) {
+	h := newAppEnqueueHandler(RateLimitOptions{})
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = h.Start(ctx) }()
+
+	newApp := func(name, priority string) *v1beta1.Application {
+		return &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: name, Annotations: map[string]string{oam.AnnotationAppPriority: priority}}}
+	}
+	next := func() types.NamespacedName {
+		item, _ := q.Get()
+		q.Done(item)
+		key := item.(reconcile.Request).NamespacedName
+		h.done(key)
+		return key
+	}
+	readyCount := func() int {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		count := 0
+		for _, keys := range h.ready {
+			count += len(keys)
+		}
+		return count
+	}
+
+	// the events of a pending application are merged
+	h.Update(event.UpdateEvent{ObjectNew: newApp("first", AppPriorityNormal)}, q)
+	h.Create(event.CreateEvent{Object: newApp("first", AppPriorityNormal)}, q)
+	assert.Eventually(t, func() bool { return q.Len() == 1 }, time.Second, 10*time.Millisecond)
+	// the controller queue is full until the first one is reconciled
+	h.Create(event.CreateEvent{Object: newApp("low", AppPriorityLow)}, q)
+	h.Create(event.CreateEvent{Object: newApp("normal", AppPriorityNormal)}, q)
+	h.Create(event.CreateEvent{Object: newApp("high", AppPriorityHigh)}, q)
+	h.Create(event.CreateEvent{Object: newApp("raised", AppPriorityLow)}, q)
+	assert.Eventually(t, func() bool { return readyCount() == 4 }, time.Second, 10*time.Millisecond)
+	h.Update(event.UpdateEvent{ObjectNew: newApp("raised", AppPriorityHigh)}, q)
+	assert.Equal(t, 1, q.Len())
+
+	var got []string
+	for i := 0; i < 5; i++ {
+		got = append(got, next().Name)
+	}
+	assert.Equal(t, []string{"first", "high", "raised", "normal", "low"}, got)
+
+	// the events during the reconciliation are enqueued again
+	h.Create(event.CreateEvent{Object: newApp("first", AppPriorityNormal)}, q)
+	assert.Eventually(t, func() bool { return q.Len() == 1 }, time.Second, 10*time.Millisecond)
+}
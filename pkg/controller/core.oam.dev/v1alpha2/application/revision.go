@@ -157,9 +157,10 @@ func (h *AppHandler) gatherRevisionSpec(af *appfile.Appfile) (*v1beta1.Applicati
 	copiedApp := h.app.DeepCopy()
 	// We better to remove all object status in the appRevision
 	copiedApp.Status = common.AppStatus{}
-	// AppRevision shouldn't contain RolloutPlan and Workflow
+	// AppRevision shouldn't contain RolloutPlan, Workflow and Pause
 	copiedApp.Spec.RolloutPlan = nil
 	copiedApp.Spec.Workflow = nil
+	copiedApp.Spec.Pause = nil
 	appRev := &v1beta1.ApplicationRevision{
 		Spec: v1beta1.ApplicationRevisionSpec{
 			Application:             *copiedApp,
@@ -777,6 +778,9 @@ func gatherUsingAppRevision(ctx context.Context, h *AppHandler) (map[string]bool
 	if h.app.Status.LatestRevision != nil && len(h.app.Status.LatestRevision.Name) != 0 {
 		usingRevision[h.app.Status.LatestRevision.Name] = true
 	}
+	if h.app.Status.Rollback != nil && len(h.app.Status.Rollback.Revision) != 0 {
+		usingRevision[h.app.Status.Rollback.Revision] = true
+	}
 	rtList := &v1beta1.ResourceTrackerList{}
 	if err := h.r.List(ctx, rtList, listOpts...); err != nil {
 		return nil, err
@@ -918,7 +922,7 @@ func cleanUpRollOutComponentRevision(ctx context.Context, h *AppHandler) error {
 		if err != nil {
 			return err
 		}
-		comps, err := af.GenerateComponentManifests()
+		comps, err := af.GenerateComponentManifests(ctx)
 		if err != nil {
 			return err
 		}
@@ -933,7 +937,7 @@ func cleanUpRollOutComponentRevision(ctx context.Context, h *AppHandler) error {
 	if err != nil {
 		return err
 	}
-	comps, err := af.GenerateComponentManifests()
+	comps, err := af.GenerateComponentManifests(ctx)
 	if err != nil {
 		return err
 	}
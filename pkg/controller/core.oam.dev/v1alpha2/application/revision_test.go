@@ -248,7 +248,7 @@ var _ = Describe("test generate revision ", func() {
 		app.SetAnnotations(map[string]string{annoKey1: "true"})
 		generatedAppfile, err := appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		comps, err = generatedAppfile.GenerateComponentManifests()
+		comps, err = generatedAppfile.GenerateComponentManifests(ctx)
 		Expect(err).Should(Succeed())
 		Expect(handler.PrepareCurrentAppRevision(ctx, generatedAppfile)).Should(Succeed())
 		Expect(handler.HandleComponentsRevision(ctx, comps)).Should(Succeed())
@@ -304,7 +304,7 @@ var _ = Describe("test generate revision ", func() {
 		annoKey2 := "testKey2"
 		app.SetAnnotations(map[string]string{annoKey2: "true"})
 		lastRevision := curApp.Status.LatestRevision.Name
-		comps, err = generatedAppfile.GenerateComponentManifests()
+		comps, err = generatedAppfile.GenerateComponentManifests(ctx)
 		Expect(err).Should(Succeed())
 		Expect(handler.PrepareCurrentAppRevision(ctx, generatedAppfile)).Should(Succeed())
 		Expect(handler.HandleComponentsRevision(ctx, comps)).Should(Succeed())
@@ -355,7 +355,7 @@ var _ = Describe("test generate revision ", func() {
 		Expect(k8sClient.Update(ctx, &app)).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
 		generatedAppfile, err = appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		comps, err = generatedAppfile.GenerateComponentManifests()
+		comps, err = generatedAppfile.GenerateComponentManifests(ctx)
 		Expect(err).Should(Succeed())
 		handler.app = &app
 		Expect(handler.PrepareCurrentAppRevision(ctx, generatedAppfile)).Should(Succeed())
@@ -412,7 +412,7 @@ var _ = Describe("test generate revision ", func() {
 		Expect(k8sClient.Update(ctx, &app)).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
 		generatedAppfile, err = appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		comps, err = generatedAppfile.GenerateComponentManifests()
+		comps, err = generatedAppfile.GenerateComponentManifests(ctx)
 		Expect(err).Should(Succeed())
 		handler.app = &app
 		Expect(handler.PrepareCurrentAppRevision(ctx, generatedAppfile)).Should(Succeed())
@@ -476,7 +476,7 @@ var _ = Describe("test generate revision ", func() {
 		app.SetAnnotations(map[string]string{oam.AnnotationAppRollout: strconv.FormatBool(true)})
 		generatedAppfile, err := appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		comps, err = generatedAppfile.GenerateComponentManifests()
+		comps, err = generatedAppfile.GenerateComponentManifests(ctx)
 		Expect(err).Should(Succeed())
 		Expect(handler.PrepareCurrentAppRevision(ctx, generatedAppfile)).Should(Succeed())
 		Expect(handler.FinalizeAndApplyAppRevision(ctx)).Should(Succeed())
@@ -553,7 +553,7 @@ var _ = Describe("test generate revision ", func() {
 		Expect(k8sClient.Update(ctx, &app)).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
 		generatedAppfile, err = appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		comps, err = generatedAppfile.GenerateComponentManifests()
+		comps, err = generatedAppfile.GenerateComponentManifests(ctx)
 		Expect(err).Should(Succeed())
 		handler.app = &app
 		Expect(handler.PrepareCurrentAppRevision(ctx, generatedAppfile)).Should(Succeed())
@@ -600,7 +600,7 @@ var _ = Describe("test generate revision ", func() {
 		app.SetAnnotations(map[string]string{annoKey1: "true"})
 		generatedAppfile, err := appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		comps, err = generatedAppfile.GenerateComponentManifests()
+		comps, err = generatedAppfile.GenerateComponentManifests(ctx)
 		Expect(err).Should(Succeed())
 		Expect(handler.PrepareCurrentAppRevision(ctx, generatedAppfile)).Should(Succeed())
 		Expect(handler.FinalizeAndApplyAppRevision(ctx)).Should(Succeed())
@@ -667,7 +667,7 @@ var _ = Describe("test generate revision ", func() {
 		ctx = util.SetNamespaceInCtx(ctx, app.Namespace)
 		generatedAppfile, err := appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		comps, err = generatedAppfile.GenerateComponentManifests()
+		comps, err = generatedAppfile.GenerateComponentManifests(ctx)
 		Expect(err).Should(Succeed())
 		Expect(handler.PrepareCurrentAppRevision(ctx, generatedAppfile)).Should(Succeed())
 		Expect(handler.HandleComponentsRevision(ctx, comps)).Should(Succeed())
@@ -707,7 +707,7 @@ var _ = Describe("test generate revision ", func() {
 		Expect(k8sClient.Update(ctx, &app)).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
 		generatedAppfile, err = appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		comps, err = generatedAppfile.GenerateComponentManifests()
+		comps, err = generatedAppfile.GenerateComponentManifests(ctx)
 		Expect(err).Should(Succeed())
 		Expect(handler.PrepareCurrentAppRevision(ctx, generatedAppfile)).Should(Succeed())
 		Expect(handler.HandleComponentsRevision(ctx, comps)).Should(Succeed())
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	velatypes "github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// isRollbackActive checks whether the rollback annotations of the application are still in effect,
+// the rollback ends once the spec of the application is changed. Pausing and unpausing the application change its
+// generation too, so the rollback recorded in the status is kept while the spec without the pause is unchanged.
+func isRollbackActive(app *v1beta1.Application) (bool, error) {
+	annotations := app.GetAnnotations()
+	revName := annotations[oam.AnnotationAppRollbackRevision]
+	if revName == "" {
+		return false, nil
+	}
+	if annotations[oam.AnnotationAppRollbackGeneration] == strconv.FormatInt(app.Generation, 10) {
+		return true, nil
+	}
+	rollback := app.Status.Rollback
+	if rollback == nil || rollback.Revision != revName || rollback.SpecHash == "" {
+		return false, nil
+	}
+	specHash, err := rollbackSpecHash(app)
+	if err != nil {
+		return false, err
+	}
+	return rollback.SpecHash == specHash, nil
+}
+
+// rollbackSpecHash computes the hash of the spec of the application without the pause
+func rollbackSpecHash(app *v1beta1.Application) (string, error) {
+	spec := app.Spec.DeepCopy()
+	spec.Pause = nil
+	return utils.ComputeSpecHash(spec)
+}
+
+// getRollbackRevision returns the revision the application is rolled back to, nil if the application is not rolled
+// back. The rollback status of the application is updated accordingly.
+func (r *Reconciler) getRollbackRevision(ctx context.Context, app *v1beta1.Application) (*v1beta1.ApplicationRevision, error) {
+	active, err := isRollbackActive(app)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot check the rollback of the application")
+	}
+	if !active {
+		app.Status.Rollback = nil
+		return nil, nil
+	}
+	revName := app.GetAnnotations()[oam.AnnotationAppRollbackRevision]
+	appRev := &v1beta1.ApplicationRevision{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: app.Namespace, Name: revName}, appRev); err != nil {
+		return nil, errors.Wrapf(err, "cannot get the application revision %s to roll back", revName)
+	}
+	if appRev.Labels[oam.LabelAppName] != app.Name {
+		return nil, errors.Errorf("the application revision %s does not belong to application %s", revName, app.Name)
+	}
+	specHash, err := rollbackSpecHash(app)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot compute the hash of the application spec")
+	}
+	if app.Status.Rollback == nil || app.Status.Rollback.Revision != revName || app.Status.Rollback.SpecHash != specHash {
+		klog.InfoS("Roll back application", "application", klog.KObj(app), "revision", revName)
+		r.Recorder.Event(app, event.Normal(velatypes.ReasonRolledBack, fmt.Sprintf(velatypes.MessageRolledBack, revName)))
+		app.Status.Rollback = &common.AppRollbackStatus{
+			Revision:     revName,
+			Generation:   app.Generation,
+			RollbackTime: metav1.Now(),
+			SpecHash:     specHash,
+		}
+	}
+	return appRev, nil
+}
+
+// useRollbackRevision makes the handler dispatch the manifests and run the workflow of the revision rolled back to,
+// instead of creating a new revision from the spec. The spec of the application is replaced by the spec stored in
+// the revision in memory only, as only the status of the application is patched.
+func (h *AppHandler) useRollbackRevision(appRev *v1beta1.ApplicationRevision) {
+	h.app.Spec = *appRev.Spec.Application.Spec.DeepCopy()
+	h.currentAppRev = appRev
+	h.currentRevHash = appRev.GetLabels()[oam.LabelAppRevisionHash]
+	h.isNewRevision = false
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	commontypes "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestGetRollbackRevision(t *testing.T) {
+	ctx := context.Background()
+	appRev := &v1beta1.ApplicationRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-v1",
+			Namespace: "default",
+			Labels:    map[string]string{oam.LabelAppName: "app"},
+		},
+	}
+	r := &Reconciler{
+		Client:   fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(appRev).Build(),
+		Recorder: event.NewAPIRecorder(record.NewFakeRecorder(10)),
+	}
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "app",
+			Namespace:  "default",
+			Generation: 2,
+		},
+	}
+
+	rev, err := r.getRollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Nil(t, rev)
+	assert.Nil(t, app.Status.Rollback)
+
+	app.SetAnnotations(map[string]string{
+		oam.AnnotationAppRollbackRevision:   "app-v1",
+		oam.AnnotationAppRollbackGeneration: "2",
+	})
+	rev, err = r.getRollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Equal(t, "app-v1", rev.Name)
+	assert.Equal(t, "app-v1", app.Status.Rollback.Revision)
+	assert.Equal(t, int64(2), app.Status.Rollback.Generation)
+	rollbackTime := app.Status.Rollback.RollbackTime
+
+	// the status is kept in the following reconciles
+	_, err = r.getRollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Equal(t, rollbackTime, app.Status.Rollback.RollbackTime)
+
+	// the rollback ends once the spec is changed
+	app.Spec.Components = []commontypes.ApplicationComponent{{Name: "web", Type: "webservice"}}
+	app.Generation = 3
+	rev, err = r.getRollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Nil(t, rev)
+	assert.Nil(t, app.Status.Rollback)
+
+	app.SetAnnotations(map[string]string{
+		oam.AnnotationAppRollbackRevision:   "app-v0",
+		oam.AnnotationAppRollbackGeneration: "3",
+	})
+	_, err = r.getRollbackRevision(ctx, app)
+	assert.Error(t, err)
+}
+
+func TestRollbackKeptByPause(t *testing.T) {
+	ctx := context.Background()
+	appRev := &v1beta1.ApplicationRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-v1",
+			Namespace: "default",
+			Labels:    map[string]string{oam.LabelAppName: "app"},
+		},
+	}
+	r := &Reconciler{
+		Client:   fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(appRev).Build(),
+		Recorder: event.NewAPIRecorder(record.NewFakeRecorder(10)),
+	}
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "app",
+			Namespace:  "default",
+			Generation: 2,
+			Annotations: map[string]string{
+				oam.AnnotationAppRollbackRevision:   "app-v1",
+				oam.AnnotationAppRollbackGeneration: "2",
+			},
+		},
+		Spec: v1beta1.ApplicationSpec{Components: []commontypes.ApplicationComponent{{Name: "web", Type: "webservice"}}},
+	}
+	rev, err := r.getRollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Equal(t, "app-v1", rev.Name)
+	rollbackTime := app.Status.Rollback.RollbackTime
+
+	// pause and unpause bump the generation, the rollback is kept
+	app.Spec.Pause = &v1beta1.ApplicationPause{By: "alice"}
+	app.Generation = 3
+	rev, err = r.getRollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Equal(t, "app-v1", rev.Name)
+	app.Spec.Pause = nil
+	app.Generation = 4
+	rev, err = r.getRollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Equal(t, "app-v1", rev.Name)
+	assert.Equal(t, rollbackTime, app.Status.Rollback.RollbackTime)
+	assert.Equal(t, int64(2), app.Status.Rollback.Generation)
+
+	// the rollback ends once the spec is changed
+	app.Spec.Components[0].Type = "worker"
+	app.Generation = 5
+	rev, err = r.getRollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Nil(t, rev)
+	assert.Nil(t, app.Status.Rollback)
+
+	// rolled back while paused, the rollback is kept after it's unpaused
+	app.Spec.Pause = &v1beta1.ApplicationPause{By: "alice"}
+	app.Generation = 6
+	app.SetAnnotations(map[string]string{
+		oam.AnnotationAppRollbackRevision:   "app-v1",
+		oam.AnnotationAppRollbackGeneration: "6",
+	})
+	_, err = r.getRollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	app.Spec.Pause = nil
+	app.Generation = 7
+	rev, err = r.getRollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Equal(t, "app-v1", rev.Name)
+}
+
+func TestRevisionHashWithoutPause(t *testing.T) {
+	app := &v1beta1.Application{
+		Spec: v1beta1.ApplicationSpec{Components: []commontypes.ApplicationComponent{{Name: "web", Type: "webservice"}}},
+	}
+	h := &AppHandler{app: app}
+	_, hash, err := h.gatherRevisionSpec(&appfile.Appfile{})
+	assert.NoError(t, err)
+	app.Spec.Pause = &v1beta1.ApplicationPause{By: "alice"}
+	pausedRev, pausedHash, err := h.gatherRevisionSpec(&appfile.Appfile{})
+	assert.NoError(t, err)
+	assert.Equal(t, hash, pausedHash)
+	assert.Nil(t, pausedRev.Spec.Application.Spec.Pause)
+}
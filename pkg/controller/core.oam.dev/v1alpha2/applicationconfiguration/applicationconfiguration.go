@@ -100,6 +100,7 @@ func Setup(mgr ctrl.Manager, args core.Args) error {
 		Complete(NewReconciler(mgr, args.DiscoveryMapper,
 			WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
 			WithApplyOnceOnlyMode(args.ApplyMode),
+			WithResourceApplicator(args.NewApplicator(mgr.GetClient(), "applicationconfiguration")),
 			WithDependCheckWait(args.DependCheckWait)))
 }
 
@@ -135,6 +136,16 @@ func WithApplicator(a WorkloadApplicator) ReconcilerOption {
 	}
 }
 
+// WithResourceApplicator specifies the Applicator used by the default WorkloadApplicator
+// to apply workloads and traits.
+func WithResourceApplicator(a apply.Applicator) ReconcilerOption {
+	return func(rc *OAMApplicationReconciler) {
+		if w, ok := rc.workloads.(*workloads); ok {
+			w.applicator = a
+		}
+	}
+}
+
 // WithGarbageCollector specifies how the Reconciler should garbage collect
 // workloads and traits when an ApplicationConfiguration is edited to remove
 // them.
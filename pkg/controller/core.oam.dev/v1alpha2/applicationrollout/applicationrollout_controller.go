@@ -44,6 +44,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/cue/packages"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
 )
 
 const (
@@ -59,6 +60,7 @@ type Reconciler struct {
 	dm                   discoverymapper.DiscoveryMapper
 	record               event.Recorder
 	Scheme               *runtime.Scheme
+	applicator           apply.Applicator
 	concurrentReconciles int
 }
 
@@ -312,6 +314,7 @@ func Setup(mgr ctrl.Manager, args oamctrl.Args) error {
 		Client:               mgr.GetClient(),
 		dm:                   args.DiscoveryMapper,
 		Scheme:               mgr.GetScheme(),
+		applicator:           args.NewApplicator(mgr.GetClient(), "approllout"),
 		concurrentReconciles: args.ConcurrentReconciles,
 	}
 	return reconciler.SetupWithManager(mgr)
@@ -198,7 +198,7 @@ func (h *rolloutHandler) templateTargetManifest(ctx context.Context) error {
 	}
 
 	// use source resourceTracker to handle same resource owner transfer
-	dispatcher := dispatch.NewAppManifestsDispatcher(h.Client, h.targetAppRevision).StartAndSkipGC(rt)
+	dispatcher := dispatch.NewAppManifestsDispatcher(h.Client, h.targetAppRevision).WithApplicator(h.applicator).StartAndSkipGC(rt)
 	_, err := dispatcher.Dispatch(ctx, h.targetManifests)
 	if err != nil {
 		klog.Errorf("dispatch targetRevision error %s:%v", h.appRollout.Spec.TargetAppRevisionName, err)
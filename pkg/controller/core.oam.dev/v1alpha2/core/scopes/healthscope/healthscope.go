@@ -455,7 +455,7 @@ func CUEBasedHealthCheck(ctx context.Context, c client.Client, wlRef WorkloadRef
 		default:
 			pCtx = process.NewProcessContextWithCtx(ctx, ns, wl.Name, appfile.Name, appfile.AppRevisionName)
 			if wl.CapabilityCategory != oamtypes.CUECategory {
-				templateStr, err := af.GenerateCUETemplate(wl)
+				templateStr, err := af.GenerateCUETemplate(ctx, wl, ns)
 				if err != nil {
 					wlHealth.HealthStatus = StatusUnhealthy
 					wlHealth.Diagnosis = errors.Wrap(err, errHealthCheck).Error()
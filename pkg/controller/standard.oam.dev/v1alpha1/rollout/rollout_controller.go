@@ -188,7 +188,7 @@ func Setup(mgr ctrl.Manager, args oamctrl.Args) error {
 		Scheme:               mgr.GetScheme(),
 		concurrentReconciles: args.ConcurrentReconciles,
 	}
-	r.applicator = apply.NewAPIApplicator(r.Client)
+	r.applicator = args.NewApplicator(r.Client, "rollout")
 	return r.SetupWithManager(mgr)
 }
 
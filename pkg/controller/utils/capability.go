@@ -86,9 +86,11 @@ type CapabilityComponentDefinition struct {
 	WorkloadType    util.WorkloadType `json:"workloadType"`
 	WorkloadDefName string            `json:"workloadDefName"`
 
-	Helm      *commontypes.Helm      `json:"helm"`
-	Kube      *commontypes.Kube      `json:"kube"`
-	Terraform *commontypes.Terraform `json:"terraform"`
+	Helm       *commontypes.Helm       `json:"helm"`
+	Kube       *commontypes.Kube       `json:"kube"`
+	Terraform  *commontypes.Terraform  `json:"terraform"`
+	Kustomize  *commontypes.Kustomize  `json:"kustomize"`
+	K8sObjects *commontypes.K8sObjects `json:"k8sObjects"`
 	CapabilityBaseDefinition
 }
 
@@ -113,6 +115,14 @@ func NewCapabilityComponentDef(componentDefinition *v1beta1.ComponentDefinition)
 			def.WorkloadType = util.TerraformDef
 			def.Terraform = componentDefinition.Spec.Schematic.Terraform
 		}
+		if componentDefinition.Spec.Schematic.Kustomize != nil {
+			def.WorkloadType = util.KustomizeDef
+			def.Kustomize = componentDefinition.Spec.Schematic.Kustomize
+		}
+		if componentDefinition.Spec.Schematic.K8sObjects != nil {
+			def.WorkloadType = util.K8sObjectsDef
+			def.K8sObjects = componentDefinition.Spec.Schematic.K8sObjects
+		}
 	}
 	def.ComponentDefinition = *componentDefinition.DeepCopy()
 	return def
@@ -215,9 +225,70 @@ func GetKubeSchematicOpenAPISchema(params []commontypes.KubeParameter) ([]byte,
 	return generateJSONSchemaWithRequiredProperty(properties, required)
 }
 
-// StoreOpenAPISchema stores OpenAPI v3 schema in ConfigMap from WorkloadDefinition
-func (def *CapabilityComponentDefinition) StoreOpenAPISchema(ctx context.Context, k8sClient client.Client,
-	pd *packages.PackageDiscover, namespace, name, revName string) (string, error) {
+// GetKustomizeSchematicOpenAPISchema gets OpenAPI v3 schema of the parameters of kustomize schematic, which are the
+// images, replicas and patches applied to the kustomization
+func GetKustomizeSchematicOpenAPISchema() ([]byte, error) {
+	stringProperties := func(names ...string) *openapi3.Schema {
+		s := openapi3.NewObjectSchema()
+		for _, name := range names {
+			s.WithProperty(name, openapi3.NewStringSchema())
+		}
+		return s
+	}
+	images := openapi3.NewArraySchema().WithItems(stringProperties("name", "newName", "newTag", "digest"))
+	images.Description = "The images to override the name, tag or digest of."
+
+	replica := stringProperties("name").WithProperty("count", openapi3.NewIntegerSchema())
+	replica.Required = []string{"name", "count"}
+	replicas := openapi3.NewArraySchema().WithItems(replica)
+	replicas.Description = "The resources to override the replicas of by name."
+
+	patch := stringProperties("patch").
+		WithProperty("target", stringProperties("group", "version", "kind", "name", "namespace", "labelSelector", "annotationSelector"))
+	patch.Required = []string{"patch"}
+	patches := openapi3.NewArraySchema().WithItems(patch)
+	patches.Description = "The strategic merge or JSON6902 patches to apply to the resources matching the target."
+
+	return generateJSONSchemaWithRequiredProperty(map[string]*openapi3.Schema{
+		"images":   images,
+		"replicas": replicas,
+		"patches":  patches,
+	}, nil)
+}
+
+// GetK8sObjectsSchematicOpenAPISchema gets OpenAPI v3 schema of the parameters of k8s-objects schematic, which are
+// the objects, the overlays applied to them and the selector of the workload
+func GetK8sObjectsSchematicOpenAPISchema() ([]byte, error) {
+	selector := func() *openapi3.Schema {
+		return openapi3.NewObjectSchema().
+			WithProperty("apiVersion", openapi3.NewStringSchema()).
+			WithProperty("kind", openapi3.NewStringSchema()).
+			WithProperty("name", openapi3.NewStringSchema())
+	}
+	object := openapi3.NewObjectSchema()
+	object.ExtensionProps.Extensions = map[string]interface{}{"x-kubernetes-preserve-unknown-fields": true}
+	objects := openapi3.NewArraySchema().WithItems(object)
+	objects.Description = "The raw Kubernetes objects."
+
+	overlay := openapi3.NewObjectSchema().
+		WithProperty("target", selector()).
+		WithProperty("jsonPath", openapi3.NewObjectSchema().WithAnyAdditionalProperties()).
+		WithProperty("strategicMerge", openapi3.NewObjectSchema().WithAnyAdditionalProperties())
+	overlays := openapi3.NewArraySchema().WithItems(overlay)
+	overlays.Description = "The JSONPath values or strategic merge patches to apply to the objects matching the target."
+
+	workload := selector()
+	workload.Description = "The selector of the object which is the workload of the component."
+
+	return generateJSONSchemaWithRequiredProperty(map[string]*openapi3.Schema{
+		"objects":  objects,
+		"overlays": overlays,
+		"workload": workload,
+	}, nil)
+}
+
+// GenerateOpenAPISchema generates OpenAPI v3 schema of the parameter according to the schematic of ComponentDefinition
+func (def *CapabilityComponentDefinition) GenerateOpenAPISchema(ctx context.Context, pd *packages.PackageDiscover, name string) ([]byte, error) {
 	var jsonSchema []byte
 	var err error
 	switch def.WorkloadType {
@@ -227,14 +298,28 @@ func (def *CapabilityComponentDefinition) StoreOpenAPISchema(ctx context.Context
 		jsonSchema, err = GetKubeSchematicOpenAPISchema(def.Kube.Parameters)
 	case util.TerraformDef:
 		if def.Terraform == nil {
-			return "", fmt.Errorf("no Configuration is set in Terraform specification: %s", def.Name)
+			return nil, fmt.Errorf("no Configuration is set in Terraform specification: %s", def.Name)
 		}
 		jsonSchema, err = GetOpenAPISchemaFromTerraformComponentDefinition(def.Terraform.Configuration)
+	case util.KustomizeDef:
+		jsonSchema, err = GetKustomizeSchematicOpenAPISchema()
+	case util.K8sObjectsDef:
+		jsonSchema, err = GetK8sObjectsSchematicOpenAPISchema()
 	default:
 		jsonSchema, err = def.GetOpenAPISchema(pd, name)
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to generate OpenAPI v3 JSON schema for capability %s: %w", def.Name, err)
+		return nil, fmt.Errorf("failed to generate OpenAPI v3 JSON schema for capability %s: %w", def.Name, err)
+	}
+	return jsonSchema, nil
+}
+
+// StoreOpenAPISchema stores OpenAPI v3 schema in ConfigMap from WorkloadDefinition
+func (def *CapabilityComponentDefinition) StoreOpenAPISchema(ctx context.Context, k8sClient client.Client,
+	pd *packages.PackageDiscover, namespace, name, revName string) (string, error) {
+	jsonSchema, err := def.GenerateOpenAPISchema(ctx, pd, name)
+	if err != nil {
+		return "", err
 	}
 	componentDefinition := def.ComponentDefinition
 	ownerReference := []metav1.OwnerReference{{
@@ -303,8 +388,8 @@ func (def *CapabilityTraitDefinition) GetOpenAPISchema(pd *packages.PackageDisco
 	return getOpenAPISchema(capability, pd)
 }
 
-// StoreOpenAPISchema stores OpenAPI v3 schema from TraitDefinition in ConfigMap
-func (def *CapabilityTraitDefinition) StoreOpenAPISchema(ctx context.Context, k8sClient client.Client, pd *packages.PackageDiscover, namespace, name string, revName string) (string, error) {
+// GenerateOpenAPISchema generates OpenAPI v3 schema of the parameter according to the schematic of TraitDefinition
+func (def *CapabilityTraitDefinition) GenerateOpenAPISchema(pd *packages.PackageDiscover, name string) ([]byte, error) {
 	var jsonSchema []byte
 	var err error
 	switch def.DefCategoryType {
@@ -314,7 +399,16 @@ func (def *CapabilityTraitDefinition) StoreOpenAPISchema(ctx context.Context, k8
 		jsonSchema, err = def.GetOpenAPISchema(pd, name)
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to generate OpenAPI v3 JSON schema for capability %s: %w", def.Name, err)
+		return nil, fmt.Errorf("failed to generate OpenAPI v3 JSON schema for capability %s: %w", def.Name, err)
+	}
+	return jsonSchema, nil
+}
+
+// StoreOpenAPISchema stores OpenAPI v3 schema from TraitDefinition in ConfigMap
+func (def *CapabilityTraitDefinition) StoreOpenAPISchema(ctx context.Context, k8sClient client.Client, pd *packages.PackageDiscover, namespace, name string, revName string) (string, error) {
+	jsonSchema, err := def.GenerateOpenAPISchema(pd, name)
+	if err != nil {
+		return "", err
 	}
 
 	traitDefinition := def.TraitDefinition
@@ -18,6 +18,7 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -240,6 +241,42 @@ func TestNewCapabilityComponentDef(t *testing.T) {
 	assert.Equal(t, def.Terraform, terraform)
 }
 
+func TestGetKustomizeSchematicOpenAPISchema(t *testing.T) {
+	kustomize := &common.Kustomize{Source: common.KustomizeSource{Files: map[string]string{"kustomization.yaml": ""}}}
+	def := NewCapabilityComponentDef(&v1beta1.ComponentDefinition{
+		Spec: v1beta1.ComponentDefinitionSpec{Schematic: &common.Schematic{Kustomize: kustomize}},
+	})
+	assert.Equal(t, def.WorkloadType, util.KustomizeDef)
+	assert.Equal(t, def.Kustomize, kustomize)
+
+	schema, err := def.GenerateOpenAPISchema(context.Background(), nil, "")
+	assert.NilError(t, err)
+	var got openapi3.Schema
+	assert.NilError(t, got.UnmarshalJSON(schema))
+	assert.Equal(t, len(got.Properties), 3)
+	assert.DeepEqual(t, got.Properties["replicas"].Value.Items.Value.Required, []string{"name", "count"})
+	assert.DeepEqual(t, got.Properties["patches"].Value.Items.Value.Required, []string{"patch"})
+	assert.Equal(t, got.Properties["patches"].Value.Items.Value.Properties["target"].Value.Properties["kind"].Value.Type, "string")
+}
+
+func TestGetK8sObjectsSchematicOpenAPISchema(t *testing.T) {
+	k8sObjects := &common.K8sObjects{}
+	def := NewCapabilityComponentDef(&v1beta1.ComponentDefinition{
+		Spec: v1beta1.ComponentDefinitionSpec{Schematic: &common.Schematic{K8sObjects: k8sObjects}},
+	})
+	assert.Equal(t, def.WorkloadType, util.K8sObjectsDef)
+	assert.Equal(t, def.K8sObjects, k8sObjects)
+
+	schema, err := def.GenerateOpenAPISchema(context.Background(), nil, "")
+	assert.NilError(t, err)
+	var got openapi3.Schema
+	assert.NilError(t, got.UnmarshalJSON(schema))
+	assert.Equal(t, len(got.Properties), 3)
+	assert.Equal(t, got.Properties["objects"].Value.Items.Value.Type, "object")
+	assert.Equal(t, got.Properties["overlays"].Value.Items.Value.Properties["target"].Value.Properties["kind"].Value.Type, "string")
+	assert.Equal(t, got.Properties["workload"].Value.Properties["name"].Value.Type, "string")
+}
+
 func TestGetOpenAPISchemaFromTerraformComponentDefinition(t *testing.T) {
 	type want struct {
 		subStr string
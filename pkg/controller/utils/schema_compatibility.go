@@ -0,0 +1,452 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// SchemaChangeType is the type of a parameter change between two definition revisions
+type SchemaChangeType string
+
+const (
+	// SchemaChangeFieldAdded means an optional field is added
+	SchemaChangeFieldAdded SchemaChangeType = "field-added"
+	// SchemaChangeRequiredFieldAdded means a required field without default value is added
+	SchemaChangeRequiredFieldAdded SchemaChangeType = "required-field-added"
+	// SchemaChangeFieldRequired means an existing field becomes required without default value
+	SchemaChangeFieldRequired SchemaChangeType = "field-required"
+	// SchemaChangeFieldOptional means an existing field is not required anymore
+	SchemaChangeFieldOptional SchemaChangeType = "field-optional"
+	// SchemaChangeFieldRemoved means a field is removed
+	SchemaChangeFieldRemoved SchemaChangeType = "field-removed"
+	// SchemaChangeTypeNarrowed means a field accepts less values, e.g. number to integer or enum values removed
+	SchemaChangeTypeNarrowed SchemaChangeType = "type-narrowed"
+	// SchemaChangeTypeWidened means a field accepts more values, e.g. integer to number or enum values added
+	SchemaChangeTypeWidened SchemaChangeType = "type-widened"
+	// SchemaChangeDefaultChanged means the default value of a field is changed
+	SchemaChangeDefaultChanged SchemaChangeType = "default-changed"
+)
+
+// breakingSchemaChanges are the changes which may break the existing applications
+var breakingSchemaChanges = map[SchemaChangeType]bool{
+	SchemaChangeRequiredFieldAdded: true,
+	SchemaChangeFieldRequired:      true,
+	SchemaChangeFieldRemoved:       true,
+	SchemaChangeTypeNarrowed:       true,
+	SchemaChangeDefaultChanged:     true,
+}
+
+const (
+	schemaPathItems                = "[]"
+	schemaPathAdditionalProperties = "*"
+)
+
+// SchemaChange is a change of the parameter schema
+type SchemaChange struct {
+	Path     string
+	Type     SchemaChangeType
+	Breaking bool
+	Message  string
+
+	segments []string
+	// schema is the new schema of the field
+	schema *openapi3.Schema
+}
+
+// String returns the change in the format of [TYPE] PATH: MESSAGE
+func (c SchemaChange) String() string {
+	return fmt.Sprintf("[%s] %s: %s", c.Type, c.Path, c.Message)
+}
+
+// DiffOpenAPISchema compares the OpenAPI v3 schemas of the parameter of two definition revisions
+func DiffOpenAPISchema(oldSchema, newSchema []byte) ([]SchemaChange, error) {
+	oldS, newS := openapi3.NewSchema(), openapi3.NewSchema()
+	if err := json.Unmarshal(oldSchema, oldS); err != nil {
+		return nil, errors.Wrap(err, "invalid old schema")
+	}
+	if err := json.Unmarshal(newSchema, newS); err != nil {
+		return nil, errors.Wrap(err, "invalid new schema")
+	}
+	var changes []SchemaChange
+	diffSchema(nil, oldS, newS, &changes)
+	return changes, nil
+}
+
+// HasBreakingSchemaChange checks whether there is any breaking change
+func HasBreakingSchemaChange(changes []SchemaChange) bool {
+	for _, c := range changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+func formatSchemaPath(segments []string) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		if seg != schemaPathItems && b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(seg)
+	}
+	return b.String()
+}
+
+func addSchemaChange(changes *[]SchemaChange, segments []string, t SchemaChangeType, schema *openapi3.Schema, format string, args ...interface{}) {
+	segments = append([]string{}, segments...)
+	*changes = append(*changes, SchemaChange{
+		Path:     formatSchemaPath(segments),
+		Type:     t,
+		Breaking: breakingSchemaChanges[t],
+		Message:  fmt.Sprintf(format, args...),
+		segments: segments,
+		schema:   schema,
+	})
+}
+
+func schemaOf(ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref == nil || ref.Value == nil {
+		return openapi3.NewSchema()
+	}
+	return ref.Value
+}
+
+// isEffectivelyRequired checks whether the field must be set by users, the generated schema marks all the non-optional
+// fields as required even if they have default values
+func isEffectivelyRequired(parent *openapi3.Schema, name string) bool {
+	required := false
+	for _, r := range parent.Required {
+		if r == name {
+			required = true
+			break
+		}
+	}
+	if !required {
+		return false
+	}
+	s := schemaOf(parent.Properties[name])
+	if s.Default != nil {
+		return false
+	}
+	switch s.Type {
+	case "array":
+		return false
+	case "object":
+		for field := range s.Properties {
+			if isEffectivelyRequired(s, field) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// nolint:gocyclo
+func diffSchema(segments []string, oldS, newS *openapi3.Schema, changes *[]SchemaChange) {
+	if oldS.Type != newS.Type {
+		switch {
+		case oldS.Type == "integer" && newS.Type == "number", newS.Type == "":
+			addSchemaChange(changes, segments, SchemaChangeTypeWidened, newS, "type changed from %q to %q", oldS.Type, newS.Type)
+		default:
+			addSchemaChange(changes, segments, SchemaChangeTypeNarrowed, newS, "type changed from %q to %q", oldS.Type, newS.Type)
+		}
+		return
+	}
+
+	removed, added := diffEnum(oldS.Enum, newS.Enum)
+	switch {
+	case len(newS.Enum) > 0 && len(oldS.Enum) == 0:
+		addSchemaChange(changes, segments, SchemaChangeTypeNarrowed, newS, "values are restricted to %s", formatSchemaValues(newS.Enum))
+	case len(removed) > 0:
+		addSchemaChange(changes, segments, SchemaChangeTypeNarrowed, newS, "values %s are not allowed", formatSchemaValues(removed))
+	case len(oldS.Enum) > 0 && (len(newS.Enum) == 0 || len(added) > 0):
+		addSchemaChange(changes, segments, SchemaChangeTypeWidened, newS, "more values are allowed")
+	default:
+	}
+	if narrowedBound(oldS.Min, newS.Min, func(o, n float64) bool { return n > o }) {
+		addSchemaChange(changes, segments, SchemaChangeTypeNarrowed, newS, "minimum changed to %v", *newS.Min)
+	}
+	if narrowedBound(oldS.Max, newS.Max, func(o, n float64) bool { return n < o }) {
+		addSchemaChange(changes, segments, SchemaChangeTypeNarrowed, newS, "maximum changed to %v", *newS.Max)
+	}
+	if oldS.Default != nil && newS.Default != nil && !reflect.DeepEqual(oldS.Default, newS.Default) {
+		addSchemaChange(changes, segments, SchemaChangeDefaultChanged, newS, "default changed from %s to %s",
+			formatSchemaValues([]interface{}{oldS.Default}), formatSchemaValues([]interface{}{newS.Default}))
+	}
+
+	switch newS.Type {
+	case "array":
+		diffSchema(append(segments, schemaPathItems), schemaOf(oldS.Items), schemaOf(newS.Items), changes)
+	case "object":
+		if oldS.AdditionalProperties != nil && newS.AdditionalProperties != nil {
+			diffSchema(append(segments, schemaPathAdditionalProperties), schemaOf(oldS.AdditionalProperties), schemaOf(newS.AdditionalProperties), changes)
+		}
+		names := map[string]bool{}
+		for name := range oldS.Properties {
+			names[name] = true
+		}
+		for name := range newS.Properties {
+			names[name] = true
+		}
+		sorted := make([]string, 0, len(names))
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+		sort.Strings(sorted)
+		for _, name := range sorted {
+			fieldSegments := append(append([]string{}, segments...), name)
+			oldField, inOld := oldS.Properties[name]
+			newField, inNew := newS.Properties[name]
+			oldRequired, newRequired := isEffectivelyRequired(oldS, name), isEffectivelyRequired(newS, name)
+			switch {
+			case !inNew:
+				addSchemaChange(changes, fieldSegments, SchemaChangeFieldRemoved, nil, "field is removed")
+			case !inOld && newRequired:
+				addSchemaChange(changes, fieldSegments, SchemaChangeRequiredFieldAdded, schemaOf(newField), "required field is added")
+			case !inOld:
+				addSchemaChange(changes, fieldSegments, SchemaChangeFieldAdded, schemaOf(newField), "optional field is added")
+			default:
+				if !oldRequired && newRequired {
+					addSchemaChange(changes, fieldSegments, SchemaChangeFieldRequired, schemaOf(newField), "field becomes required")
+				} else if oldRequired && !newRequired {
+					addSchemaChange(changes, fieldSegments, SchemaChangeFieldOptional, schemaOf(newField), "field becomes optional")
+				}
+				diffSchema(fieldSegments, schemaOf(oldField), schemaOf(newField), changes)
+			}
+		}
+	default:
+	}
+}
+
+func diffEnum(oldEnum, newEnum []interface{}) (removed, added []interface{}) {
+	contains := func(values []interface{}, v interface{}) bool {
+		for _, value := range values {
+			if reflect.DeepEqual(value, v) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(newEnum) > 0 {
+		for _, v := range oldEnum {
+			if !contains(newEnum, v) {
+				removed = append(removed, v)
+			}
+		}
+	}
+	for _, v := range newEnum {
+		if !contains(oldEnum, v) {
+			added = append(added, v)
+		}
+	}
+	return removed, added
+}
+
+func narrowedBound(oldBound, newBound *float64, narrower func(o, n float64) bool) bool {
+	if newBound == nil {
+		return false
+	}
+	return oldBound == nil || narrower(*oldBound, *newBound)
+}
+
+func formatSchemaValues(values []interface{}) string {
+	bs, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+	if len(values) == 1 {
+		return strings.TrimSuffix(strings.TrimPrefix(string(bs), "["), "]")
+	}
+	return string(bs)
+}
+
+// ApplicationImpact is an application using the definition which would break by the schema changes
+type ApplicationImpact struct {
+	Namespace string
+	Name      string
+	Component string
+	Reasons   []string
+}
+
+// String returns the impact in the format of NAMESPACE/NAME (component COMPONENT): REASONS
+func (i ApplicationImpact) String() string {
+	return fmt.Sprintf("%s/%s (component %s): %s", i.Namespace, i.Name, i.Component, strings.Join(i.Reasons, "; "))
+}
+
+// FindBrokenApplications returns the applications using the ComponentDefinition or TraitDefinition which would break by
+// the breaking schema changes. The definitions in the system definition namespace are used by the applications in all
+// namespaces, and the applications pinning a definition revision, e.g. type: webservice@v1, are not affected.
+func FindBrokenApplications(ctx context.Context, c client.Reader, def client.Object, changes []SchemaChange) ([]ApplicationImpact, error) {
+	if !HasBreakingSchemaChange(changes) {
+		return nil, nil
+	}
+	apps := &v1beta1.ApplicationList{}
+	var opts []client.ListOption
+	if def.GetNamespace() != oam.SystemDefinitonNamespace {
+		opts = append(opts, client.InNamespace(def.GetNamespace()))
+	}
+	if err := c.List(ctx, apps, opts...); err != nil {
+		return nil, errors.Wrap(err, "cannot list applications")
+	}
+	_, isTrait := def.(*v1beta1.TraitDefinition)
+	var impacts []ApplicationImpact
+	for _, app := range apps.Items {
+		for _, comp := range app.Spec.Components {
+			var usages []map[string]interface{}
+			if !isTrait && comp.Type == def.GetName() {
+				usages = append(usages, rawToMap(comp.Properties.Raw))
+			}
+			for _, trait := range comp.Traits {
+				if isTrait && trait.Type == def.GetName() {
+					usages = append(usages, rawToMap(trait.Properties.Raw))
+				}
+			}
+			var reasons []string
+			for _, props := range usages {
+				reasons = append(reasons, brokenReasons(props, changes)...)
+			}
+			if len(reasons) > 0 {
+				impacts = append(impacts, ApplicationImpact{Namespace: app.Namespace, Name: app.Name, Component: comp.Name, Reasons: reasons})
+			}
+		}
+	}
+	return impacts, nil
+}
+
+func rawToMap(raw []byte) map[string]interface{} {
+	props := map[string]interface{}{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &props)
+	}
+	return props
+}
+
+// brokenReasons returns why the properties break by the breaking changes
+func brokenReasons(props map[string]interface{}, changes []SchemaChange) []string {
+	var reasons []string
+	for _, c := range changes {
+		if !c.Breaking {
+			continue
+		}
+		if len(c.segments) == 0 {
+			// the change of the parameter itself, e.g. its type is changed
+			if reason := brokenRootReason(props, c); reason != "" {
+				reasons = append(reasons, reason)
+			}
+			continue
+		}
+		parent, name := c.segments[:len(c.segments)-1], c.segments[len(c.segments)-1]
+		switch c.Type {
+		case SchemaChangeFieldRemoved:
+			if len(valuesAtSchemaPath(props, c.segments)) > 0 {
+				reasons = append(reasons, fmt.Sprintf("%s is removed", c.Path))
+			}
+		case SchemaChangeRequiredFieldAdded, SchemaChangeFieldRequired:
+			if missingAtSchemaPath(props, parent, name) {
+				reasons = append(reasons, fmt.Sprintf("%s is required", c.Path))
+			}
+		case SchemaChangeDefaultChanged:
+			if missingAtSchemaPath(props, parent, name) {
+				reasons = append(reasons, fmt.Sprintf("%s relies on the changed default value", c.Path))
+			}
+		case SchemaChangeTypeNarrowed:
+			for _, v := range valuesAtSchemaPath(props, c.segments) {
+				if err := c.schema.VisitJSON(v); err != nil {
+					reasons = append(reasons, fmt.Sprintf("%s is invalid: %s", c.Path, c.Message))
+					break
+				}
+			}
+		default:
+		}
+	}
+	return reasons
+}
+
+// brokenRootReason returns why the properties break by the breaking change of the whole parameter, empty if they don't
+func brokenRootReason(props map[string]interface{}, c SchemaChange) string {
+	switch c.Type {
+	case SchemaChangeDefaultChanged:
+		if len(props) == 0 {
+			return "parameter relies on the changed default value"
+		}
+	case SchemaChangeTypeNarrowed:
+		if c.schema != nil {
+			if err := c.schema.VisitJSON(props); err != nil {
+				return fmt.Sprintf("parameter is invalid: %s", c.Message)
+			}
+		}
+	default:
+	}
+	return ""
+}
+
+// valuesAtSchemaPath returns the values at the path, the items of arrays and the values of maps are expanded
+func valuesAtSchemaPath(v interface{}, segments []string) []interface{} {
+	if len(segments) == 0 {
+		return []interface{}{v}
+	}
+	var values []interface{}
+	switch seg := segments[0]; seg {
+	case schemaPathItems:
+		items, _ := v.([]interface{})
+		for _, item := range items {
+			values = append(values, valuesAtSchemaPath(item, segments[1:])...)
+		}
+	case schemaPathAdditionalProperties:
+		m, _ := v.(map[string]interface{})
+		for _, item := range m {
+			values = append(values, valuesAtSchemaPath(item, segments[1:])...)
+		}
+	default:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if item, ok := m[seg]; ok {
+			values = append(values, valuesAtSchemaPath(item, segments[1:])...)
+		}
+	}
+	return values
+}
+
+// missingAtSchemaPath checks whether the field is missing in any of the objects at the parent path
+func missingAtSchemaPath(props map[string]interface{}, parent []string, name string) bool {
+	for _, v := range valuesAtSchemaPath(props, parent) {
+		if m, ok := v.(map[string]interface{}); ok {
+			if _, ok := m[name]; !ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	utilscommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+const oldParameterSchema = `{
+  "properties": {
+    "replicas": {"default": 1, "type": "integer"},
+    "image": {"type": "string"},
+    "mode": {"default": "a", "enum": ["a", "b"], "type": "string"},
+    "ratio": {"type": "number"},
+    "labels": {"additionalProperties": {"type": "string"}, "type": "object"},
+    "ports": {"items": {"properties": {"port": {"type": "integer"}}, "required": ["port"], "type": "object"}, "type": "array"},
+    "debug": {"type": "boolean"}
+  },
+  "required": ["replicas", "image", "mode", "labels", "ports"],
+  "type": "object"
+}`
+
+const newParameterSchema = `{
+  "properties": {
+    "replicas": {"default": 2, "type": "integer"},
+    "image": {"type": "string"},
+    "mode": {"default": "a", "enum": ["a"], "type": "string"},
+    "ratio": {"type": "integer"},
+    "labels": {"additionalProperties": {"type": "string"}, "type": "object"},
+    "ports": {"items": {"properties": {"port": {"maximum": 1024, "type": "integer"}, "protocol": {"type": "string"}}, "required": ["port", "protocol"], "type": "object"}, "type": "array"},
+    "cpu": {"type": "string"},
+    "memory": {"type": "string"}
+  },
+  "required": ["replicas", "image", "mode", "labels", "ports", "memory"],
+  "type": "object"
+}`
+
+func TestDiffOpenAPISchema(t *testing.T) {
+	changes, err := DiffOpenAPISchema([]byte(oldParameterSchema), []byte(newParameterSchema))
+	require.NoError(t, err)
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.String())
+		assert.Equal(t, breakingSchemaChanges[c.Type], c.Breaking)
+	}
+	assert.Equal(t, []string{
+		`[field-added] cpu: optional field is added`,
+		`[field-removed] debug: field is removed`,
+		`[required-field-added] memory: required field is added`,
+		`[type-narrowed] mode: values "b" are not allowed`,
+		`[type-narrowed] ports[].port: maximum changed to 1024`,
+		`[required-field-added] ports[].protocol: required field is added`,
+		`[type-narrowed] ratio: type changed from "number" to "integer"`,
+		`[default-changed] replicas: default changed from 1 to 2`,
+	}, got)
+	assert.True(t, HasBreakingSchemaChange(changes))
+
+	changes, err = DiffOpenAPISchema([]byte(newParameterSchema), []byte(newParameterSchema))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	_, err = DiffOpenAPISchema([]byte("{"), []byte(newParameterSchema))
+	assert.Error(t, err)
+}
+
+func TestFindBrokenApplications(t *testing.T) {
+	newApp := func(ns, name, compType string, props string) *v1beta1.Application {
+		return &v1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec: v1beta1.ApplicationSpec{Components: []common.ApplicationComponent{{
+				Name:       "comp",
+				Type:       compType,
+				Properties: runtime.RawExtension{Raw: []byte(props)},
+			}}},
+		}
+	}
+	cli := fake.NewClientBuilder().WithScheme(utilscommon.Scheme).WithObjects(
+		// relies on the changed default replicas, sets the removed debug and the narrowed ratio
+		newApp("default", "broken", "worker", `{"image":"nginx","memory":"1Gi","debug":true,"ratio":0.5}`),
+		// sets all the required fields with valid values
+		newApp("default", "fine", "worker", `{"image":"nginx","memory":"1Gi","replicas":3,"mode":"a","ratio":1,"ports":[{"port":80,"protocol":"TCP"}]}`),
+		// the port is out of range and protocol is missing
+		newApp("team", "ports", "worker", `{"image":"nginx","memory":"1Gi","replicas":3,"ports":[{"port":8080}]}`),
+		// pinned to the definition revision
+		newApp("team", "pinned", "worker@v1", `{"image":"nginx"}`),
+		newApp("team", "other", "webservice", `{"image":"nginx"}`),
+	).Build()
+
+	changes, err := DiffOpenAPISchema([]byte(oldParameterSchema), []byte(newParameterSchema))
+	require.NoError(t, err)
+
+	def := &v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Namespace: oam.SystemDefinitonNamespace, Name: "worker"}}
+	impacts, err := FindBrokenApplications(context.Background(), cli, def, changes)
+	require.NoError(t, err)
+	require.Len(t, impacts, 2)
+	assert.Equal(t, ApplicationImpact{Namespace: "default", Name: "broken", Component: "comp", Reasons: []string{
+		"debug is removed",
+		"ratio is invalid: type changed from \"number\" to \"integer\"",
+		"replicas relies on the changed default value",
+	}}, impacts[0])
+	assert.Equal(t, ApplicationImpact{Namespace: "team", Name: "ports", Component: "comp", Reasons: []string{
+		"ports[].port is invalid: maximum changed to 1024",
+		"ports[].protocol is required",
+	}}, impacts[1])
+
+	// definitions out of the system namespace only affect the applications in the same namespace
+	def.Namespace = "default"
+	impacts, err = FindBrokenApplications(context.Background(), cli, def, changes)
+	require.NoError(t, err)
+	require.Len(t, impacts, 1)
+	assert.Equal(t, "broken", impacts[0].Name)
+
+	trait := &v1beta1.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Namespace: oam.SystemDefinitonNamespace, Name: "worker"}}
+	impacts, err = FindBrokenApplications(context.Background(), cli, trait, changes)
+	require.NoError(t, err)
+	assert.Empty(t, impacts)
+}
+
+func TestBrokenReasonsOfRootChange(t *testing.T) {
+	changes, err := DiffOpenAPISchema([]byte(`{"type": "object", "properties": {"image": {"type": "string"}}}`),
+		[]byte(`{"type": "array", "items": {"type": "string"}}`))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "", changes[0].Path)
+	assert.True(t, changes[0].Breaking)
+	assert.Equal(t, []string{`parameter is invalid: type changed from "object" to "array"`},
+		brokenReasons(map[string]interface{}{"image": "nginx"}, changes))
+
+	changes, err = DiffOpenAPISchema([]byte(`{"type": "object", "default": {"image": "nginx"}}`),
+		[]byte(`{"type": "object", "default": {"image": "busybox"}}`))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, []string{"parameter relies on the changed default value"}, brokenReasons(map[string]interface{}{}, changes))
+	assert.Empty(t, brokenReasons(map[string]interface{}{"image": "nginx"}, changes))
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/cue/process"
+)
+
+var (
+	// RenderCacheSize is the max number of the rendered results of definitions kept in memory, zero disables the cache.
+	// The results are keyed by the hash of the definition template, the parameters and the context, so a definition is
+	// only evaluated again if any of them changes.
+	RenderCacheSize = 1024
+	// RenderCacheMaxBytes is the max total size in bytes of the rendered results kept in memory, the least recently
+	// used results are evicted once it's exceeded, zero means no limit
+	RenderCacheMaxBytes = 128 * 1024 * 1024
+)
+
+var (
+	renderCacheOnce sync.Once
+	renderCache     *boundedCache
+
+	renderCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kubevela_definition_render_cache_requests_total",
+		Help: "Number of lookups of the rendered results of definitions in the render cache, by definition kind and result.",
+	}, []string{"kind", "result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(renderCacheRequests)
+}
+
+// renderCacheKey identifies the rendered result of a definition, the definition hash covers the template and the
+// built-in packages it's evaluated with
+type renderCacheKey struct {
+	definition string
+	parameter  string
+	context    string
+}
+
+// renderedWorkload is the result of evaluating the workload template
+type renderedWorkload struct {
+	base        model.Instance
+	auxiliaries []process.Auxiliary
+	// size is the size in bytes of the rendered resources
+	size int
+}
+
+func getRenderCache() *boundedCache {
+	renderCacheOnce.Do(func() {
+		if RenderCacheSize > 0 {
+			renderCache = newBoundedCache(RenderCacheSize, RenderCacheMaxBytes)
+		}
+	})
+	return renderCache
+}
+
+func newRenderCacheKey(kind, name, template string, pd *packages.PackageDiscover, params interface{}, contextFile string) (renderCacheKey, error) {
+	bt, err := json.Marshal(params)
+	if err != nil {
+		return renderCacheKey{}, err
+	}
+	return renderCacheKey{
+		definition: hashString(fmt.Sprintf("%s/%s/%d\n%s", kind, name, pd.Generation(), template)),
+		parameter:  hashString(string(bt)),
+		context:    hashString(contextFile),
+	}, nil
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// lookupRenderCache returns the cached rendered result, the result must not be modified
+func lookupRenderCache(kind string, key renderCacheKey) (interface{}, bool) {
+	cache := getRenderCache()
+	if cache == nil {
+		return nil, false
+	}
+	v, ok := cache.Get(key)
+	if ok {
+		renderCacheRequests.WithLabelValues(kind, "hit").Inc()
+	} else {
+		renderCacheRequests.WithLabelValues(kind, "miss").Inc()
+	}
+	return v, ok
+}
+
+// addRenderCache caches the rendered result with its size in bytes
+func addRenderCache(key renderCacheKey, rendered interface{}, size int) {
+	if cache := getRenderCache(); cache != nil {
+		cache.Add(key, rendered, size)
+	}
+}
+
+// boundedCache is a LRU cache bounded by both the number of the entries and their total size in bytes
+type boundedCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int
+	bytes      int
+	ll         *list.List
+	items      map[renderCacheKey]*list.Element
+}
+
+type boundedCacheEntry struct {
+	key   renderCacheKey
+	value interface{}
+	size  int
+}
+
+// newBoundedCache creates a cache keeping at most maxEntries entries of maxBytes in total, zero means no limit
+func newBoundedCache(maxEntries, maxBytes int) *boundedCache {
+	return &boundedCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ll:         list.New(),
+		items:      map[renderCacheKey]*list.Element{},
+	}
+}
+
+// Get returns the cached value and marks it as the most recently used
+func (c *boundedCache) Get(key renderCacheKey) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*boundedCacheEntry).value, true
+}
+
+// Add caches the value and evicts the least recently used entries exceeding the bounds, the value larger than
+// maxBytes is not cached
+func (c *boundedCache) Add(key renderCacheKey, value interface{}, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+	c.items[key] = c.ll.PushFront(&boundedCacheEntry{key: key, value: value, size: size})
+	c.bytes += size
+	for (c.maxEntries > 0 && c.ll.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.ll.Back())
+	}
+}
+
+// Len returns the number of the cached entries
+func (c *boundedCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *boundedCache) remove(e *list.Element) {
+	entry := c.ll.Remove(e).(*boundedCacheEntry)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+}
+
+// copyAuxiliaries copies the auxiliaries so that unifying them does not affect the cached ones
+func copyAuxiliaries(auxiliaries []process.Auxiliary) []process.Auxiliary {
+	copied := make([]process.Auxiliary, 0, len(auxiliaries))
+	for _, auxiliary := range auxiliaries {
+		copied = append(copied, process.Auxiliary{Ins: model.Copy(auxiliary.Ins), Type: auxiliary.Type, Name: auxiliary.Name})
+	}
+	return copied
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/cue/process"
+)
+
+func TestRenderCache(t *testing.T) {
+	getRenderCache()
+	defer func(cache *boundedCache) { renderCache = cache }(renderCache)
+	renderCache = newBoundedCache(16, 0)
+
+	workloadTemplate := `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	metadata: name: context.name
+	spec: replicas: parameter.replicas
+}
+parameter: replicas: *1 | int
+`
+	traitTemplate := `
+patch: metadata: labels: app: context.name
+outputs: service: {
+	apiVersion: "v1"
+	kind:       "Service"
+	metadata: name: context.name
+}
+`
+	hits := func(kind string) float64 {
+		return testutil.ToFloat64(renderCacheRequests.WithLabelValues(kind, "hit"))
+	}
+	render := func(name string, replicas int) process.Context {
+		ctx := process.NewContext("default", name, "myapp", "myapp-v1")
+		wd := NewWorkloadAbstractEngine("deployment", &packages.PackageDiscover{})
+		require.NoError(t, wd.Complete(ctx, workloadTemplate, map[string]interface{}{"replicas": replicas}))
+		td := NewTraitAbstractEngine("service", &packages.PackageDiscover{})
+		require.NoError(t, td.Complete(ctx, traitTemplate, nil))
+		return ctx
+	}
+
+	workloadHits, traitHits := hits("workload"), hits("trait")
+	first := render("web", 2)
+	assert.Equal(t, workloadHits, hits("workload"))
+	assert.Equal(t, traitHits, hits("trait"))
+
+	// the same template, parameters and context hit the cache, and the cached results are not modified by the patch
+	second := render("web", 2)
+	assert.Equal(t, workloadHits+1, hits("workload"))
+	assert.Equal(t, traitHits+1, hits("trait"))
+	firstBase, firstAuxiliaries := first.Output()
+	secondBase, secondAuxiliaries := second.Output()
+	assert.Equal(t, firstBase.String(), secondBase.String())
+	require.Len(t, secondAuxiliaries, 1)
+	assert.Equal(t, firstAuxiliaries[0].Ins.String(), secondAuxiliaries[0].Ins.String())
+	obj, err := secondBase.Unstructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "web"}, obj.GetLabels())
+
+	// changing the parameters or the context misses the cache
+	render("web", 3)
+	render("api", 2)
+	assert.Equal(t, workloadHits+1, hits("workload"))
+}
+
+func TestBoundedCache(t *testing.T) {
+	key := func(name string) renderCacheKey { return renderCacheKey{definition: name} }
+	cache := newBoundedCache(3, 100)
+	cache.Add(key("a"), "a", 40)
+	cache.Add(key("b"), "b", 40)
+	_, ok := cache.Get(key("a"))
+	assert.True(t, ok)
+
+	// the least recently used entry is evicted once the total size exceeds the max bytes
+	cache.Add(key("c"), "c", 40)
+	_, ok = cache.Get(key("b"))
+	assert.False(t, ok)
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, 80, cache.bytes)
+
+	// the entry larger than the max bytes is not cached
+	cache.Add(key("d"), "d", 101)
+	_, ok = cache.Get(key("d"))
+	assert.False(t, ok)
+
+	// the least recently used entry is evicted once the number of the entries exceeds the max entries
+	cache.Add(key("e"), "e", 1)
+	cache.Add(key("f"), "f", 1)
+	assert.Equal(t, 3, cache.Len())
+	_, ok = cache.Get(key("a"))
+	assert.False(t, ok)
+
+	// replacing an entry updates its size
+	cache.Add(key("e"), "e", 10)
+	assert.Equal(t, 51, cache.bytes)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model"
+)
+
+var (
+	// RenderTimeout is the max duration of rendering the template of a definition, zero means no limit
+	RenderTimeout = 30 * time.Second
+	// MaxRenderOutputSize is the max size in bytes of the resources rendered by the template of a definition,
+	// zero means no limit
+	MaxRenderOutputSize = 4 * 1024 * 1024
+	// MaxConcurrentRenders is the max number of the templates of definitions rendered at the same time, including
+	// the renderings which timed out but are still evaluated in the background, zero means no limit
+	MaxConcurrentRenders = 64
+)
+
+// renderSlots tracks the guarded renderings in flight. A rendering which timed out keeps its slot until the CUE
+// evaluation returns, and the definition cannot be rendered again meanwhile.
+var renderSlots = struct {
+	sync.Mutex
+	running   int
+	abandoned map[string]int
+}{abandoned: map[string]int{}}
+
+// guardedRender is a rendering holding a slot
+type guardedRender struct {
+	definition string
+	// finished and abandoned are guarded by renderSlots
+	finished  bool
+	abandoned bool
+}
+
+// RenderError is returned if rendering the template of a definition violates the render guards,
+// e.g. it runs out of time or renders oversized resources
+type RenderError struct {
+	// Definition is the kind and name of the definition being rendered, e.g. "trait ingress"
+	Definition string
+	Reason     string
+}
+
+// Error implements error
+func (e *RenderError) Error() string {
+	return fmt.Sprintf("rendering %s is aborted: %s", e.Definition, e.Reason)
+}
+
+// IsRenderError checks whether the error is caused by violating the render guards
+func IsRenderError(err error) bool {
+	var renderErr *RenderError
+	return errors.As(err, &renderErr)
+}
+
+// runWithRenderGuard runs the rendering function with RenderTimeout, the function gets a context which is canceled
+// once the timeout is reached. As the CUE evaluation cannot be interrupted, the function keeps running after the
+// timeout until it checks the context or returns, so it must not modify any state shared with the caller and its
+// results are abandoned. The renderings are limited by MaxConcurrentRenders, and a definition is not rendered again
+// while its abandoned rendering is still running; both fail fast instead of waiting.
+func runWithRenderGuard(ctx context.Context, definition string, render func(ctx context.Context) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r, err := acquireRenderSlot(definition)
+	if err != nil {
+		return err
+	}
+	if RenderTimeout <= 0 {
+		defer r.release()
+		return recoverRender(ctx, definition, render)
+	}
+	ctx, cancel := context.WithTimeout(ctx, RenderTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		err := recoverRender(ctx, definition, render)
+		r.release()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return abortedError(ctx, definition, err)
+	case <-ctx.Done():
+		if !r.abandon() {
+			return abortedError(ctx, definition, <-done)
+		}
+		return abortedError(ctx, definition, ctx.Err())
+	}
+}
+
+// abortedError turns the error returned as the guard context is done into a RenderError, the rendering may return
+// the error of the context itself if it checks the context before the caller sees the timeout
+func abortedError(ctx context.Context, definition string, err error) error {
+	if err == nil || ctx.Err() == nil || !errors.Is(err, ctx.Err()) {
+		return err
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &RenderError{Definition: definition, Reason: fmt.Sprintf("timed out after %s", RenderTimeout)}
+	}
+	return &RenderError{Definition: definition, Reason: ctx.Err().Error()}
+}
+
+func acquireRenderSlot(definition string) (*guardedRender, error) {
+	renderSlots.Lock()
+	defer renderSlots.Unlock()
+	if renderSlots.abandoned[definition] > 0 {
+		return nil, &RenderError{Definition: definition, Reason: "the previous rendering which timed out is still running"}
+	}
+	if MaxConcurrentRenders > 0 && renderSlots.running >= MaxConcurrentRenders {
+		return nil, &RenderError{Definition: definition, Reason: fmt.Sprintf("the max %d concurrent renderings are running", MaxConcurrentRenders)}
+	}
+	renderSlots.running++
+	return &guardedRender{definition: definition}, nil
+}
+
+// release frees the slot once the rendering returns
+func (r *guardedRender) release() {
+	renderSlots.Lock()
+	defer renderSlots.Unlock()
+	renderSlots.running--
+	r.finished = true
+	if r.abandoned {
+		if renderSlots.abandoned[r.definition]--; renderSlots.abandoned[r.definition] <= 0 {
+			delete(renderSlots.abandoned, r.definition)
+		}
+	}
+}
+
+// abandon marks the rendering abandoned by the caller, it returns false if the rendering has already returned
+func (r *guardedRender) abandon() bool {
+	renderSlots.Lock()
+	defer renderSlots.Unlock()
+	if r.finished {
+		return false
+	}
+	r.abandoned = true
+	renderSlots.abandoned[r.definition]++
+	return true
+}
+
+// recoverRender turns the panic in the CUE evaluation into an error instead of crashing the controller
+func recoverRender(ctx context.Context, definition string, render func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &RenderError{Definition: definition, Reason: fmt.Sprintf("panic: %v", r)}
+		}
+	}()
+	return render(ctx)
+}
+
+// outputSizeCounter accumulates the size of the resources rendered by a definition and checks it against
+// MaxRenderOutputSize
+type outputSizeCounter struct {
+	name string
+	size int
+}
+
+func (c *outputSizeCounter) add(ins model.Instance) error {
+	c.size += len(ins.String())
+	if MaxRenderOutputSize > 0 && c.size > MaxRenderOutputSize {
+		return &RenderError{Definition: c.name, Reason: fmt.Sprintf("the rendered resources exceed the max size of %d bytes", MaxRenderOutputSize)}
+	}
+	return nil
+}
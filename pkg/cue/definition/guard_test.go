@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/cue/process"
+	"github.com/oam-dev/kubevela/pkg/cue/task"
+)
+
+func TestRunWithRenderGuard(t *testing.T) {
+	defer func(timeout time.Duration) { RenderTimeout = timeout }(RenderTimeout)
+	RenderTimeout = 50 * time.Millisecond
+
+	err := runWithRenderGuard(context.Background(), "trait slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.Error(t, err)
+	assert.True(t, IsRenderError(err))
+	assert.Equal(t, "rendering trait slow is aborted: timed out after 50ms", err.Error())
+
+	err = runWithRenderGuard(context.Background(), "trait panic", func(ctx context.Context) error {
+		panic("boom")
+	})
+	assert.True(t, IsRenderError(err))
+	assert.Equal(t, "rendering trait panic is aborted: panic: boom", err.Error())
+
+	err = runWithRenderGuard(context.Background(), "trait failed", func(ctx context.Context) error {
+		return errors.New("invalid template")
+	})
+	assert.False(t, IsRenderError(err))
+	assert.Equal(t, "invalid template", err.Error())
+}
+
+func TestRenderGuardConcurrency(t *testing.T) {
+	defer func(timeout time.Duration) { RenderTimeout = timeout }(RenderTimeout)
+	defer func(max int) { MaxConcurrentRenders = max }(MaxConcurrentRenders)
+	RenderTimeout = 50 * time.Millisecond
+	MaxConcurrentRenders = 1
+	// wait for the renderings abandoned by the other tests
+	require.Eventually(t, func() bool {
+		renderSlots.Lock()
+		defer renderSlots.Unlock()
+		return renderSlots.running == 0
+	}, time.Second, 10*time.Millisecond)
+
+	release := make(chan struct{})
+	returned := make(chan struct{})
+	err := runWithRenderGuard(context.Background(), "trait blocked", func(ctx context.Context) error {
+		defer close(returned)
+		<-release
+		return nil
+	})
+	assert.Equal(t, "rendering trait blocked is aborted: timed out after 50ms", err.Error())
+
+	// the abandoned rendering is still running
+	err = runWithRenderGuard(context.Background(), "trait blocked", func(ctx context.Context) error { return nil })
+	assert.Equal(t, "rendering trait blocked is aborted: the previous rendering which timed out is still running", err.Error())
+	err = runWithRenderGuard(context.Background(), "trait other", func(ctx context.Context) error { return nil })
+	assert.Equal(t, "rendering trait other is aborted: the max 1 concurrent renderings are running", err.Error())
+
+	close(release)
+	<-returned
+	require.Eventually(t, func() bool {
+		return runWithRenderGuard(context.Background(), "trait blocked", func(ctx context.Context) error { return nil }) == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, runWithRenderGuard(context.Background(), "trait other", func(ctx context.Context) error { return nil }))
+}
+
+func TestRenderGuardViolations(t *testing.T) {
+	defer func(size int) { MaxRenderOutputSize = size }(MaxRenderOutputSize)
+	defer func(disabled bool) { task.ProcessingDisabled = disabled }(task.ProcessingDisabled)
+
+	workloadTemplate := `
+output: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	data: value: parameter.value
+}
+parameter: value: string
+`
+	ctx := process.NewContext("default", "test", "myapp", "myapp-v1")
+	wd := NewWorkloadAbstractEngine("configmap", &packages.PackageDiscover{})
+	require.NoError(t, wd.Complete(ctx, workloadTemplate, map[string]interface{}{"value": "small"}))
+
+	MaxRenderOutputSize = 128
+	ctx = process.NewContext("default", "test", "myapp", "myapp-v1")
+	err := wd.Complete(ctx, workloadTemplate, map[string]interface{}{"value": string(make([]byte, 256))})
+	require.Error(t, err)
+	assert.True(t, IsRenderError(err))
+	assert.Contains(t, err.Error(), "rendering workload configmap is aborted: the rendered resources exceed the max size of 128 bytes")
+
+	MaxRenderOutputSize = 0
+	task.ProcessingDisabled = true
+	ctx = process.NewContext("default", "test", "myapp", "myapp-v1")
+	require.NoError(t, wd.Complete(ctx, workloadTemplate, map[string]interface{}{"value": "small"}))
+	td := NewTraitAbstractEngine("token", &packages.PackageDiscover{})
+	err = td.Complete(ctx, `
+processing: {
+	output: token?: string
+	http: {
+		method: "GET"
+		url:    "http://127.0.0.1:8090/api/v1/token"
+	}
+}
+patch: data: token: processing.output.token
+`, nil)
+	require.Error(t, err)
+	assert.True(t, IsRenderError(err))
+	assert.Equal(t, "rendering trait token is aborted: processing is not allowed: processing is disabled", err.Error())
+}
+
+func TestTraitRenderWithAuxiliariesSnapshot(t *testing.T) {
+	ctx := process.NewContext("default", "test", "myapp", "myapp-v1")
+	wd := NewWorkloadAbstractEngine("worker", &packages.PackageDiscover{})
+	require.NoError(t, wd.Complete(ctx, `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+}
+outputs: gameconfig: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+}
+`, nil))
+	_, auxiliaries := ctx.Output()
+	snapshot := copyAuxiliaries(auxiliaries)
+	contextFile := ctx.ExtendedContextFile()
+
+	td := NewTraitAbstractEngine("annotate", &packages.PackageDiscover{}).(*traitDef)
+	type result struct {
+		rendered *renderedTrait
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		rendered, err := td.render(context.Background(), snapshot, contextFile, `
+patch: context: outputs: gameconfig: metadata: annotations: "patch-by": "trait"
+`, nil)
+		done <- result{rendered: rendered, err: err}
+	}()
+	// the caller keeps using the context while the rendering runs, e.g. once the rendering times out
+	for i := 0; i < 10; i++ {
+		require.NoError(t, ctx.AppendAuxiliaries(process.Auxiliary{Ins: snapshot[0].Ins, Type: "trait", Name: fmt.Sprintf("aux-%d", i)}))
+	}
+	res := <-done
+	require.NoError(t, res.err)
+	assert.Contains(t, res.rendered.auxiliaryPatches, "gameconfig")
+}
@@ -82,68 +82,115 @@ func NewWorkloadAbstractEngine(name string, pd *packages.PackageDiscover) Abstra
 
 // Complete do workload definition's rendering
 func (wd *workloadDef) Complete(ctx process.Context, abstractTemplate string, params interface{}) error {
+	contextFile := ctx.ExtendedContextFile()
+	key, keyErr := newRenderCacheKey("workload", wd.name, abstractTemplate, wd.pd, params, contextFile)
+	var rendered *renderedWorkload
+	if keyErr == nil {
+		if cached, ok := lookupRenderCache("workload", key); ok {
+			rendered = cached.(*renderedWorkload).copy()
+		}
+	}
+	if rendered == nil {
+		if err := runWithRenderGuard(ctx.GetCtx(), "workload "+wd.name, func(guardCtx context.Context) error {
+			var err error
+			rendered, err = wd.render(guardCtx, contextFile, abstractTemplate, params)
+			return err
+		}); err != nil {
+			return err
+		}
+		if keyErr == nil {
+			addRenderCache(key, rendered.copy(), rendered.size)
+		}
+	}
+	if err := ctx.SetBase(rendered.base); err != nil {
+		return err
+	}
+	for _, auxiliary := range rendered.auxiliaries {
+		if err := ctx.AppendAuxiliaries(auxiliary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *renderedWorkload) copy() *renderedWorkload {
+	return &renderedWorkload{base: model.Copy(r.base), auxiliaries: copyAuxiliaries(r.auxiliaries), size: r.size}
+}
+
+// render evaluates the workload template and returns the rendered base and auxiliaries without modifying the context,
+// it stops between the evaluation steps once the guard context is canceled
+func (wd *workloadDef) render(guardCtx context.Context, contextFile string, abstractTemplate string, params interface{}) (*renderedWorkload, error) {
 	bi := build.NewContext().NewInstance("", nil)
 	if err := bi.AddFile("-", abstractTemplate); err != nil {
-		return errors.WithMessagef(err, "invalid cue template of workload %s", wd.name)
+		return nil, errors.WithMessagef(err, "invalid cue template of workload %s", wd.name)
 	}
 	var paramFile = model.ParameterFieldName + ": {}"
 	if params != nil {
 		bt, err := json.Marshal(params)
 		if err != nil {
-			return errors.WithMessagef(err, "marshal parameter of workload %s", wd.name)
+			return nil, errors.WithMessagef(err, "marshal parameter of workload %s", wd.name)
 		}
 		if string(bt) != "null" {
 			paramFile = fmt.Sprintf("%s: %s", model.ParameterFieldName, string(bt))
 		}
 	}
 	if err := bi.AddFile(model.ParameterFieldName, paramFile); err != nil {
-		return errors.WithMessagef(err, "invalid parameter of workload %s", wd.name)
+		return nil, errors.WithMessagef(err, "invalid parameter of workload %s", wd.name)
 	}
 
-	if err := bi.AddFile("-", ctx.ExtendedContextFile()); err != nil {
-		return err
+	if err := bi.AddFile("-", contextFile); err != nil {
+		return nil, err
 	}
 
 	inst, err := wd.pd.ImportPackagesAndBuildInstance(bi)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := inst.Value().Validate(); err != nil {
-		return errors.WithMessagef(err, "invalid cue template of workload %s after merge parameter and context", wd.name)
+		return nil, errors.WithMessagef(err, "invalid cue template of workload %s after merge parameter and context", wd.name)
 	}
+	if err := guardCtx.Err(); err != nil {
+		return nil, err
+	}
+	size := &outputSizeCounter{name: "workload " + wd.name}
 	output := inst.Lookup(OutputFieldName)
 	base, err := model.NewBase(output)
 	if err != nil {
-		return errors.WithMessagef(err, "invalid output of workload %s", wd.name)
+		return nil, errors.WithMessagef(err, "invalid output of workload %s", wd.name)
 	}
-	if err := ctx.SetBase(base); err != nil {
-		return err
+	if err := size.add(base); err != nil {
+		return nil, err
 	}
 
 	// we will support outputs for workload composition, and it will become trait in AppConfig.
 	outputs := inst.Lookup(OutputsFieldName)
 	if !outputs.Exists() {
-		return nil
+		return &renderedWorkload{base: base, size: size.size}, nil
 	}
 	st, err := outputs.Struct()
 	if err != nil {
-		return errors.WithMessagef(err, "invalid outputs of workload %s", wd.name)
+		return nil, errors.WithMessagef(err, "invalid outputs of workload %s", wd.name)
 	}
+	var auxiliaries []process.Auxiliary
 	for i := 0; i < st.Len(); i++ {
 		fieldInfo := st.Field(i)
 		if fieldInfo.IsDefinition || fieldInfo.IsHidden || fieldInfo.IsOptional {
 			continue
 		}
+		if err := guardCtx.Err(); err != nil {
+			return nil, err
+		}
 		other, err := model.NewOther(fieldInfo.Value)
 		if err != nil {
-			return errors.WithMessagef(err, "invalid outputs(%s) of workload %s", fieldInfo.Name, wd.name)
+			return nil, errors.WithMessagef(err, "invalid outputs(%s) of workload %s", fieldInfo.Name, wd.name)
 		}
-		if err := ctx.AppendAuxiliaries(process.Auxiliary{Ins: other, Type: AuxiliaryWorkload, Name: fieldInfo.Name}); err != nil {
-			return err
+		if err := size.add(other); err != nil {
+			return nil, err
 		}
+		auxiliaries = append(auxiliaries, process.Auxiliary{Ins: other, Type: AuxiliaryWorkload, Name: fieldInfo.Name})
 	}
-	return nil
+	return &renderedWorkload{base: base, auxiliaries: auxiliaries, size: size.size}, nil
 }
 
 func (wd *workloadDef) getTemplateContext(ctx process.Context, cli client.Reader, ns string) (map[string]interface{}, error) {
@@ -281,88 +328,182 @@ func NewTraitAbstractEngine(name string, pd *packages.PackageDiscover) AbstractE
 
 // Complete do trait definition's rendering
 func (td *traitDef) Complete(ctx process.Context, abstractTemplate string, params interface{}) error {
+	contextFile := ctx.ExtendedContextFile()
+	key, keyErr := newRenderCacheKey("trait", td.name, abstractTemplate, td.pd, params, contextFile)
+	var rendered *renderedTrait
+	if keyErr == nil {
+		if cached, ok := lookupRenderCache("trait", key); ok {
+			rendered = cached.(*renderedTrait).copy()
+		}
+	}
+	if rendered == nil {
+		// the rendering may keep running after it times out, so it gets a snapshot of the auxiliaries instead of the
+		// context which is still used by the caller
+		_, auxiliaries := ctx.Output()
+		auxiliaries = copyAuxiliaries(auxiliaries)
+		if err := runWithRenderGuard(ctx.GetCtx(), "trait "+td.name, func(guardCtx context.Context) error {
+			var err error
+			rendered, err = td.render(guardCtx, auxiliaries, contextFile, abstractTemplate, params)
+			return err
+		}); err != nil {
+			return err
+		}
+		// the result of processing depends on the http response, so it is not cached
+		if keyErr == nil && !rendered.processed {
+			addRenderCache(key, rendered.copy(), rendered.size)
+		}
+	}
+	for _, auxiliary := range rendered.auxiliaries {
+		if err := ctx.AppendAuxiliaries(auxiliary); err != nil {
+			return err
+		}
+	}
+
+	if rendered.patch != nil {
+		base, auxiliaries := ctx.Output()
+		if err := base.Unify(rendered.patch); err != nil {
+			return errors.WithMessagef(err, "invalid patch trait %s into workload", td.name)
+		}
+
+		for _, auxiliary := range auxiliaries {
+			t, ok := rendered.auxiliaryPatches[auxiliary.Name]
+			if !ok {
+				continue
+			}
+			if err := auxiliary.Ins.Unify(t); err != nil {
+				return errors.WithMessagef(err, "trait=%s, to=%s, invalid patch trait into auxiliary workload", td.name, auxiliary.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// renderedTrait is the result of evaluating the trait template
+type renderedTrait struct {
+	auxiliaries      []process.Auxiliary
+	patch            model.Instance
+	auxiliaryPatches map[string]model.Instance
+	// processed indicates the template runs the http processing
+	processed bool
+	// size is the size in bytes of the rendered resources and patches
+	size int
+}
+
+func (r *renderedTrait) copy() *renderedTrait {
+	copied := &renderedTrait{
+		auxiliaries:      copyAuxiliaries(r.auxiliaries),
+		auxiliaryPatches: make(map[string]model.Instance, len(r.auxiliaryPatches)),
+		processed:        r.processed,
+		size:             r.size,
+	}
+	if r.patch != nil {
+		copied.patch = model.Copy(r.patch)
+	}
+	for name, patch := range r.auxiliaryPatches {
+		copied.auxiliaryPatches[name] = model.Copy(patch)
+	}
+	return copied
+}
+
+// render evaluates the trait template and returns the rendered auxiliaries and patches, the given auxiliaries are the
+// ones already in the context to be patched. It stops between the evaluation steps once the guard context is canceled
+func (td *traitDef) render(guardCtx context.Context, auxiliaries []process.Auxiliary, contextFile string, abstractTemplate string, params interface{}) (*renderedTrait, error) {
 	bi := build.NewContext().NewInstance("", nil)
 	if err := bi.AddFile("-", abstractTemplate); err != nil {
-		return errors.WithMessagef(err, "invalid template of trait %s", td.name)
+		return nil, errors.WithMessagef(err, "invalid template of trait %s", td.name)
 	}
 	var paramFile = model.ParameterFieldName + ": {}"
 	if params != nil {
 		bt, err := json.Marshal(params)
 		if err != nil {
-			return errors.WithMessagef(err, "marshal parameter of trait %s", td.name)
+			return nil, errors.WithMessagef(err, "marshal parameter of trait %s", td.name)
 		}
 		if string(bt) != "null" {
 			paramFile = fmt.Sprintf("%s: %s", model.ParameterFieldName, string(bt))
 		}
 	}
 	if err := bi.AddFile(model.ParameterFieldName, paramFile); err != nil {
-		return errors.WithMessagef(err, "invalid parameter of trait %s", td.name)
+		return nil, errors.WithMessagef(err, "invalid parameter of trait %s", td.name)
 	}
-	if err := bi.AddFile("context", ctx.ExtendedContextFile()); err != nil {
-		return errors.WithMessagef(err, "invalid context of trait %s", td.name)
+	if err := bi.AddFile("context", contextFile); err != nil {
+		return nil, errors.WithMessagef(err, "invalid context of trait %s", td.name)
 	}
 
 	inst, err := td.pd.ImportPackagesAndBuildInstance(bi)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := inst.Value().Validate(); err != nil {
-		return errors.WithMessagef(err, "invalid template of trait %s after merge with parameter and context", td.name)
+		return nil, errors.WithMessagef(err, "invalid template of trait %s after merge with parameter and context", td.name)
+	}
+	if err := guardCtx.Err(); err != nil {
+		return nil, err
 	}
+	var processed bool
 	processing := inst.Lookup("processing")
 	if processing.Exists() {
-		if inst, err = task.Process(inst); err != nil {
-			return errors.WithMessagef(err, "invalid process of trait %s", td.name)
+		processed = true
+		if inst, err = task.Process(guardCtx, inst); err != nil {
+			if errors.Is(err, task.ErrProcessingNotAllowed) {
+				return nil, &RenderError{Definition: "trait " + td.name, Reason: err.Error()}
+			}
+			return nil, errors.WithMessagef(err, "invalid process of trait %s", td.name)
 		}
 	}
+	size := &outputSizeCounter{name: "trait " + td.name}
+	rendered := &renderedTrait{auxiliaryPatches: map[string]model.Instance{}, processed: processed}
 	outputs := inst.Lookup(OutputsFieldName)
 	if outputs.Exists() {
 		st, err := outputs.Struct()
 		if err != nil {
-			return errors.WithMessagef(err, "invalid outputs of trait %s", td.name)
+			return nil, errors.WithMessagef(err, "invalid outputs of trait %s", td.name)
 		}
 		for i := 0; i < st.Len(); i++ {
 			fieldInfo := st.Field(i)
 			if fieldInfo.IsDefinition || fieldInfo.IsHidden || fieldInfo.IsOptional {
 				continue
 			}
+			if err := guardCtx.Err(); err != nil {
+				return nil, err
+			}
 			other, err := model.NewOther(fieldInfo.Value)
 			if err != nil {
-				return errors.WithMessagef(err, "invalid outputs(resource=%s) of trait %s", fieldInfo.Name, td.name)
+				return nil, errors.WithMessagef(err, "invalid outputs(resource=%s) of trait %s", fieldInfo.Name, td.name)
 			}
-			if err := ctx.AppendAuxiliaries(process.Auxiliary{Ins: other, Type: td.name, Name: fieldInfo.Name}); err != nil {
-				return err
+			if err := size.add(other); err != nil {
+				return nil, err
 			}
+			rendered.auxiliaries = append(rendered.auxiliaries, process.Auxiliary{Ins: other, Type: td.name, Name: fieldInfo.Name})
 		}
 	}
 
 	patcher := inst.Lookup(PatchFieldName)
 	if patcher.Exists() {
-		base, auxiliaries := ctx.Output()
 		p, err := model.NewOther(patcher)
 		if err != nil {
-			return errors.WithMessagef(err, "invalid patch of trait %s", td.name)
+			return nil, errors.WithMessagef(err, "invalid patch of trait %s", td.name)
 		}
-		if err := base.Unify(p); err != nil {
-			return errors.WithMessagef(err, "invalid patch trait %s into workload", td.name)
+		if err := size.add(p); err != nil {
+			return nil, err
 		}
+		rendered.patch = p
 
-		for _, auxiliary := range auxiliaries {
+		for _, auxiliary := range append(auxiliaries, rendered.auxiliaries...) {
 			target := patcher.Lookup("context", model.OutputsFieldName, auxiliary.Name)
 			if target.Exists() {
 				t, err := model.NewOther(target)
 				if err != nil {
-					return errors.WithMessagef(err, "trait=%s, to=%s, invalid trait patch", td.name, auxiliary.Name)
-				}
-				if err := auxiliary.Ins.Unify(t); err != nil {
-					return errors.WithMessagef(err, "trait=%s, to=%s, invalid patch trait into auxiliary workload", td.name, auxiliary.Name)
+					return nil, errors.WithMessagef(err, "trait=%s, to=%s, invalid trait patch", td.name, auxiliary.Name)
 				}
+				rendered.auxiliaryPatches[auxiliary.Name] = t
+				rendered.size += len(t.String())
 			}
 		}
 	}
-
-	return nil
+	rendered.size += size.size
+	return rendered, nil
 }
 
 // GetCommonLabels will convert context based labels to OAM standard labels
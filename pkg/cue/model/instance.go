@@ -120,6 +120,14 @@ func NewOther(v cue.Value) (Instance, error) {
 	}, nil
 }
 
+// Copy returns a copy of the instance, unifying the copy does not affect the original instance
+func Copy(ins Instance) Instance {
+	return &instance{
+		v:    ins.String(),
+		base: ins.IsBase(),
+	}
+}
+
 func openPrint(v cue.Value) (string, error) {
 	sysopts := []cue.Option{cue.All(), cue.DisallowCycles(true), cue.ResolveReferences(true), cue.Docs(true)}
 	f, err := sets.ToFile(v.Syntax(sysopts...))
@@ -46,4 +46,16 @@ const (
 	ComponentRevisionPlaceHolder = "KUBEVELA_COMPONENT_REVISION_PLACEHOLDER"
 	// ContextDataArtifacts is used to store unstructured resources of components
 	ContextDataArtifacts = "artifacts"
+	// ContextCluster is the name of the cluster the resources are dispatched to, empty for the local cluster
+	ContextCluster = "cluster"
+	// ContextEnvName is the name of the env in the envbinding policy the resources are rendered for
+	ContextEnvName = "envName"
+	// ContextAppLabels is the labels of the app
+	ContextAppLabels = "appLabels"
+	// ContextAppAnnotations is the annotations of the app
+	ContextAppAnnotations = "appAnnotations"
+	// ContextStepName is the name of the workflow step which renders the resources
+	ContextStepName = "stepName"
+	// ContextStepType is the type of the workflow step
+	ContextStepType = "stepType"
 )
@@ -128,3 +128,11 @@ func (nwk *nodewalker) Pos() []string {
 func (nwk *nodewalker) Tags() map[string]string {
 	return nwk.tags
 }
+
+// Walk walks the fields of the node in depth-first order, the process is called with each node visited and the labels
+// of the fields enclosing it
+func Walk(node ast.Node, process func(node ast.Node, pos []string)) {
+	newWalker(func(n ast.Node, ctx walkCtx) {
+		process(n, ctx.Pos())
+	}).walk(node)
+}
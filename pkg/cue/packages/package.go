@@ -53,6 +53,8 @@ type PackageDiscover struct {
 	pkgKinds            map[string][]VersionKind
 	mutex               sync.RWMutex
 	client              *rest.RESTClient
+	// generation is increased every time the built-in packages change
+	generation int64
 }
 
 // VersionKind contains the resource metadata and reference name
@@ -117,6 +119,16 @@ func (pd *PackageDiscover) ImportPackagesAndBuildInstance(bi *build.Instance) (i
 	return cueInst, err
 }
 
+// Generation returns the generation of the built-in packages, it's increased every time the packages change
+func (pd *PackageDiscover) Generation() int64 {
+	if pd == nil {
+		return 0
+	}
+	pd.mutex.RLock()
+	defer pd.mutex.RUnlock()
+	return pd.generation
+}
+
 // ListPackageKinds list packages and their kinds
 func (pd *PackageDiscover) ListPackageKinds() map[string][]VersionKind {
 	pd.mutex.RLock()
@@ -156,6 +168,7 @@ func (pd *PackageDiscover) Exist(gvk metav1.GroupVersionKind) bool {
 func (pd *PackageDiscover) mount(pkg *pkgInstance, pkgKinds []VersionKind) {
 	pd.mutex.Lock()
 	defer pd.mutex.Unlock()
+	pd.generation++
 	if pkgKinds == nil {
 		pkgKinds = []VersionKind{}
 	}
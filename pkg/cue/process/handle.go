@@ -39,6 +39,7 @@ type Context interface {
 	ExtendedContextFile() string
 	BaseContextLabels() map[string]string
 	SetParameters(params map[string]interface{})
+	SetRuntimeInfo(info RuntimeInfo)
 	PushData(key string, data interface{})
 	GetCtx() context.Context
 }
@@ -80,11 +81,27 @@ type templateContext struct {
 
 	components []common.ApplicationComponent
 
+	runtimeInfo RuntimeInfo
+
 	data map[string]interface{}
 
 	ctx context.Context
 }
 
+// RuntimeInfo is the information about where and by which workflow step the resources are rendered, it's exposed to
+// the templates in the context besides the basic information of the application
+type RuntimeInfo struct {
+	// Cluster is the name of the cluster the resources are dispatched to, empty for the local cluster
+	Cluster string
+	// EnvName is the name of the env in the envbinding policy the resources are rendered for
+	EnvName string
+	// StepName is the name of the workflow step which renders the resources
+	StepName string
+	// AppLabels and AppAnnotations are the metadata of the application
+	AppLabels      map[string]string
+	AppAnnotations map[string]string
+}
+
 // RequiredSecrets is used to store all secret names which are generated by cloud resource components and required by current component
 type RequiredSecrets struct {
 	Namespace   string
@@ -151,6 +168,11 @@ func (ctx *templateContext) SetParameters(params map[string]interface{}) {
 	ctx.parameters = params
 }
 
+// SetRuntimeInfo sets the runtime information of templateContext
+func (ctx *templateContext) SetRuntimeInfo(info RuntimeInfo) {
+	ctx.runtimeInfo = info
+}
+
 // SetBase set templateContext base model
 func (ctx *templateContext) SetBase(base model.Instance) error {
 	for _, hook := range ctx.baseHooks {
@@ -184,6 +206,11 @@ func (ctx *templateContext) BaseContextFile() string {
 	buff += fmt.Sprintf(model.ContextAppRevisionNum+": %d\n", revNum)
 	buff += fmt.Sprintf(model.ContextNamespace+": \"%s\"\n", ctx.namespace)
 	buff += fmt.Sprintf(model.ContextCompRevisionName+": \"%s\"\n", model.ComponentRevisionPlaceHolder)
+	buff += fmt.Sprintf(model.ContextCluster+": %s\n", marshalString(ctx.runtimeInfo.Cluster))
+	buff += fmt.Sprintf(model.ContextEnvName+": %s\n", marshalString(ctx.runtimeInfo.EnvName))
+	buff += fmt.Sprintf(model.ContextStepName+": %s\n", marshalString(ctx.runtimeInfo.StepName))
+	buff += fmt.Sprintf(model.ContextAppLabels+": %s\n", marshalStringMap(ctx.runtimeInfo.AppLabels))
+	buff += fmt.Sprintf(model.ContextAppAnnotations+": %s\n", marshalStringMap(ctx.runtimeInfo.AppAnnotations))
 
 	if ctx.base != nil {
 		buff += fmt.Sprintf(model.OutputFieldName+": %s\n", structMarshal(ctx.base.String()))
@@ -292,6 +319,19 @@ func (ctx *templateContext) GetCtx() context.Context {
 	return context.TODO()
 }
 
+func marshalString(v string) string {
+	bt, _ := json.Marshal(v)
+	return string(bt)
+}
+
+func marshalStringMap(m map[string]string) string {
+	if m == nil {
+		return "{}"
+	}
+	bt, _ := json.Marshal(m)
+	return string(bt)
+}
+
 func structMarshal(v string) string {
 	skip := false
 	v = strings.TrimFunc(v, func(r rune) bool {
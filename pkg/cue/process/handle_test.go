@@ -101,6 +101,12 @@ image: "myserver"
 	ctx.SetParameters(targetParams)
 	ctx.PushData(model.ContextDataArtifacts, targetData)
 	ctx.PushData("arbitraryData", targetArbitraryData)
+	ctx.SetRuntimeInfo(RuntimeInfo{
+		Cluster:   "prod-us",
+		EnvName:   "prod",
+		StepName:  "deploy-prod",
+		AppLabels: map[string]string{"team": "shop"},
+	})
 
 	ctxInst, err := r.Compile("-", ctx.ExtendedContextFile())
 	if err != nil {
@@ -147,4 +153,24 @@ image: "myserver"
 	arbitraryData, err := ctxInst.Lookup("context", "arbitraryData").MarshalJSON()
 	assert.Equal(t, nil, err)
 	assert.Equal(t, "{\"bool\":false,\"string\":\"mytxt\",\"int\":10,\"map\":{\"key\":\"value\"},\"slice\":[\"str1\",\"str2\",\"str3\"]}", string(arbitraryData))
+
+	cluster, err := ctxInst.Lookup("context", model.ContextCluster).String()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "prod-us", cluster)
+
+	envName, err := ctxInst.Lookup("context", model.ContextEnvName).String()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "prod", envName)
+
+	stepName, err := ctxInst.Lookup("context", model.ContextStepName).String()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "deploy-prod", stepName)
+
+	appLabels, err := ctxInst.Lookup("context", model.ContextAppLabels).MarshalJSON()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"team":"shop"}`, string(appLabels))
+
+	appAnnotations, err := ctxInst.Lookup("context", model.ContextAppAnnotations).MarshalJSON()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{}`, string(appAnnotations))
 }
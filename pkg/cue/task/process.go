@@ -17,23 +17,45 @@ limitations under the License.
 package task
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 
 	"cuelang.org/go/cue"
 
 	"github.com/oam-dev/kubevela/pkg/builtin"
+	httptask "github.com/oam-dev/kubevela/pkg/builtin/http"
 	"github.com/oam-dev/kubevela/pkg/builtin/registry"
 )
 
+var (
+	// ProcessingDisabled disables the http processing in the templates of definitions globally
+	ProcessingDisabled = false
+	// ProcessingAllowedHosts is the list of hosts the http processing in the templates of definitions can request,
+	// "*.example.com" matches all the sub domains of example.com. All hosts are allowed if the list is empty.
+	ProcessingAllowedHosts []string
+)
+
+// ErrProcessingNotAllowed is returned if the http processing is disabled or requests a host out of the allowlist
+var ErrProcessingNotAllowed = errors.New("processing is not allowed")
+
 // Process processing the http task
-func Process(inst *cue.Instance) (*cue.Instance, error) {
+func Process(ctx context.Context, inst *cue.Instance) (*cue.Instance, error) {
+	if ProcessingDisabled {
+		return nil, fmt.Errorf("%w: processing is disabled", ErrProcessingNotAllowed)
+	}
 	taskVal := inst.Lookup("processing", "http")
 	if !taskVal.Exists() {
 		return inst, errors.New("there is no http in processing")
 	}
-	resp, err := exec(taskVal)
+	if err := checkProcessingHost(taskVal); err != nil {
+		return nil, err
+	}
+	// the redirects are checked against the allowlist as well
+	resp, err := exec(httptask.WithURLCheck(ctx, checkProcessingURL), taskVal)
 	if err != nil {
 		return nil, fmt.Errorf("fail to exec http task, %w", err)
 	}
@@ -45,8 +67,36 @@ func Process(inst *cue.Instance) (*cue.Instance, error) {
 	return appInst, nil
 }
 
-func exec(v cue.Value) (map[string]interface{}, error) {
-	got, err := builtin.RunTaskByKey("http", cue.Value{}, &registry.Meta{Obj: v})
+func checkProcessingHost(v cue.Value) error {
+	if len(ProcessingAllowedHosts) == 0 {
+		return nil
+	}
+	rawURL, err := v.Lookup("url").String()
+	if err != nil {
+		return fmt.Errorf("invalid url of http task, %w", err)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url of http task, %w", err)
+	}
+	return checkProcessingURL(u)
+}
+
+func checkProcessingURL(u *url.URL) error {
+	if len(ProcessingAllowedHosts) == 0 {
+		return nil
+	}
+	host := u.Hostname()
+	for _, allowed := range ProcessingAllowedHosts {
+		if host == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %q is not in the allowlist", ErrProcessingNotAllowed, host)
+}
+
+func exec(ctx context.Context, v cue.Value) (map[string]interface{}, error) {
+	got, err := builtin.RunTaskByKey("http", cue.Value{}, &registry.Meta{Context: ctx, Obj: v})
 	if err != nil {
 		return nil, err
 	}
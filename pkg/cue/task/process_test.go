@@ -17,11 +17,14 @@ limitations under the License.
 package task
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/oam-dev/kubevela/pkg/cue/model"
@@ -73,7 +76,7 @@ func TestProcess(t *testing.T) {
 		"serviceURL": "http://127.0.0.1:8090/api/v1/token?val=test-token",
 	}, model.ParameterFieldName)
 
-	inst, err := Process(taskTemplate)
+	inst, err := Process(context.Background(), taskTemplate)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -82,6 +85,62 @@ func TestProcess(t *testing.T) {
 	assert.Equal(t, "{\"data\":\"test-token\"}", data)
 }
 
+func TestProcessNotAllowed(t *testing.T) {
+	r := cue.Runtime{}
+	taskTemplate, err := r.Compile("", TaskTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskTemplate, _ = taskTemplate.Fill(map[string]interface{}{
+		"serviceURL": "http://127.0.0.1:8090/api/v1/token?val=test-token",
+	}, model.ParameterFieldName)
+
+	defer func(hosts []string) { ProcessingAllowedHosts = hosts }(ProcessingAllowedHosts)
+	ProcessingAllowedHosts = []string{"*.example.com"}
+	_, err = Process(context.Background(), taskTemplate)
+	assert.Equal(t, true, errors.Is(err, ErrProcessingNotAllowed))
+	assert.Equal(t, `processing is not allowed: host "127.0.0.1" is not in the allowlist`, err.Error())
+
+	defer func(disabled bool) { ProcessingDisabled = disabled }(ProcessingDisabled)
+	ProcessingDisabled = true
+	_, err = Process(context.Background(), taskTemplate)
+	assert.Equal(t, true, errors.Is(err, ErrProcessingNotAllowed))
+}
+
+func TestProcessRedirectNotAllowed(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"token":"redirected"}`))
+	}))
+	defer target.Close()
+	targetURL, _ := url.Parse(target.URL)
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://localhost:"+targetURL.Port()+"/api/v1/token", http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	r := cue.Runtime{}
+	taskTemplate, err := r.Compile("", TaskTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskTemplate, _ = taskTemplate.Fill(map[string]interface{}{
+		"serviceURL": redirector.URL + "/api/v1/token",
+	}, model.ParameterFieldName)
+
+	defer func(hosts []string) { ProcessingAllowedHosts = hosts }(ProcessingAllowedHosts)
+	ProcessingAllowedHosts = []string{"127.0.0.1"}
+	_, err = Process(context.Background(), taskTemplate)
+	assert.Equal(t, true, errors.Is(err, ErrProcessingNotAllowed))
+
+	ProcessingAllowedHosts = []string{"127.0.0.1", "localhost"}
+	inst, err := Process(context.Background(), taskTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := cueJson.Marshal(inst.Lookup("output"))
+	assert.Equal(t, "{\"data\":\"redirected\"}", data)
+}
+
 func NewMock() *httptest.Server {
 	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" {
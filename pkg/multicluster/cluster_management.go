@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+	errors2 "github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// NewClusterSecretFromKubeConfig build the cluster-gateway credential secret for the current-context of the given kubeconfig.
+// If clusterName is empty, the cluster name in the kubeconfig will be used.
+func NewClusterSecretFromKubeConfig(config *clientcmdapi.Config, clusterName string) (*v1.Secret, error) {
+	if len(config.CurrentContext) == 0 {
+		return nil, fmt.Errorf("current-context is not set")
+	}
+	ctx, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("current-context %s not found", config.CurrentContext)
+	}
+	cluster, ok := config.Clusters[ctx.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s not found", ctx.Cluster)
+	}
+	authInfo, ok := config.AuthInfos[ctx.AuthInfo]
+	if !ok {
+		return nil, fmt.Errorf("authInfo %s not found", ctx.AuthInfo)
+	}
+	if clusterName == "" {
+		clusterName = ctx.Cluster
+	}
+	if clusterName == ClusterLocalName {
+		return nil, fmt.Errorf("cannot use `%s` as cluster name, it is reserved as the local cluster", ClusterLocalName)
+	}
+
+	var credentialType v1alpha1.CredentialType
+	data := map[string][]byte{
+		"endpoint": []byte(cluster.Server),
+		"ca.crt":   cluster.CertificateAuthorityData,
+	}
+	if len(authInfo.Token) > 0 {
+		credentialType = v1alpha1.CredentialTypeServiceAccountToken
+		data["token"] = []byte(authInfo.Token)
+	} else {
+		credentialType = v1alpha1.CredentialTypeX509Certificate
+		data["tls.crt"] = authInfo.ClientCertificateData
+		data["tls.key"] = authInfo.ClientKeyData
+	}
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: ClusterGatewaySecretNamespace,
+			Labels: map[string]string{
+				v1alpha1.LabelKeyClusterCredentialType: string(credentialType),
+			},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: data,
+	}, nil
+}
+
+// EnsureClusterNotExists check whether the cluster secret with the given name already exists
+func EnsureClusterNotExists(ctx context.Context, c client.Client, clusterName string) error {
+	secret := &v1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Name: clusterName, Namespace: ClusterGatewaySecretNamespace}, secret)
+	if err == nil {
+		return fmt.Errorf("cluster %s already exists", clusterName)
+	}
+	if !errors.IsNotFound(err) {
+		return errors2.Wrapf(err, "failed to check duplicate cluster secret")
+	}
+	return nil
+}
+
+// EnsureResourceTrackerCRDInstalled copy the resourcetracker crd from the hub cluster to the managed cluster if not exists
+func EnsureResourceTrackerCRDInstalled(ctx context.Context, c client.Client, clusterName string) error {
+	remoteCtx := ContextWithClusterName(ctx, clusterName)
+	crdName := types.NamespacedName{Name: "resourcetrackers." + v1beta1.Group}
+	if err := c.Get(remoteCtx, crdName, &apiextensionsv1.CustomResourceDefinition{}); err != nil {
+		if !errors.IsNotFound(err) {
+			return errors2.Wrapf(err, "failed to check resourcetracker crd in cluster %s", clusterName)
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err = c.Get(ctx, crdName, crd); err != nil {
+			return errors2.Wrapf(err, "failed to get resourcetracker crd in hub cluster")
+		}
+		crd.ObjectMeta = metav1.ObjectMeta{
+			Name:        crdName.Name,
+			Annotations: crd.Annotations,
+			Labels:      crd.Labels,
+		}
+		if err = c.Create(remoteCtx, crd); err != nil {
+			return errors2.Wrapf(err, "failed to create resourcetracker crd in cluster %s", clusterName)
+		}
+	}
+	return nil
+}
+
+// JoinClusterByKubeConfig add the cluster declared in the current-context of the kubeconfig to the managed clusters
+func JoinClusterByKubeConfig(ctx context.Context, c client.Client, config *clientcmdapi.Config, clusterName string) (*v1.Secret, error) {
+	secret, err := NewClusterSecretFromKubeConfig(config, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if err := EnsureClusterNotExists(ctx, c, secret.Name); err != nil {
+		return nil, errors2.Wrapf(err, "cannot use cluster name %s", secret.Name)
+	}
+	if err := c.Create(ctx, secret); err != nil {
+		return nil, errors2.Wrapf(err, "failed to add cluster to kubernetes")
+	}
+	if err := EnsureResourceTrackerCRDInstalled(ctx, c, secret.Name); err != nil {
+		_ = c.Delete(ctx, secret)
+		return nil, errors2.Wrapf(err, "failed to ensure resourcetracker crd installed in cluster %s", secret.Name)
+	}
+	return secret, nil
+}
+
+// ErrInvalidClusterSecret the secret is not a cluster-gateway credential secret as the credential type label is not set
+var ErrInvalidClusterSecret = errors2.New("cluster credential type label is not set")
+
+// GetClusterSecret get the cluster-gateway credential secret of the managed cluster
+func GetClusterSecret(ctx context.Context, c client.Client, clusterName string) (*v1.Secret, error) {
+	secret := &v1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: ClusterGatewaySecretNamespace, Name: clusterName}, secret); err != nil {
+		return nil, err
+	}
+	if secret.GetLabels()[v1alpha1.LabelKeyClusterCredentialType] == "" {
+		return nil, errors2.WithMessagef(ErrInvalidClusterSecret, "invalid cluster secret %s: label %s", clusterName, v1alpha1.LabelKeyClusterCredentialType)
+	}
+	return secret, nil
+}
+
+// ListClusterSecrets list the cluster-gateway credential secrets of all managed clusters
+func ListClusterSecrets(ctx context.Context, c client.Client) ([]v1.Secret, error) {
+	secrets := &v1.SecretList{}
+	if err := c.List(ctx, secrets, client.HasLabels{v1alpha1.LabelKeyClusterCredentialType}, client.InNamespace(ClusterGatewaySecretNamespace)); err != nil {
+		return nil, errors2.Wrapf(err, "failed to get cluster secrets")
+	}
+	return secrets.Items, nil
+}
@@ -61,6 +61,10 @@ const (
 
 	// LabelAddonsName records the name of initializer stored in configMap
 	LabelAddonsName = "addons.oam.dev/type"
+
+	// LabelAppPriority is the priority of the application in the queue of the application controller, available
+	// options: high, normal and low. It can also be set by AnnotationAppPriority, the label takes precedence.
+	LabelAppPriority = "app.oam.dev/priority"
 )
 
 const (
@@ -122,4 +126,22 @@ const (
 
 	// AnnotationAddonsName records the name of initializer stored in configMap
 	AnnotationAddonsName = "addons.oam.dev/name"
+
+	// AnnotationAppPriority is the priority of the application in the queue of the application controller, available
+	// options: high, normal and low. It's overridden by LabelAppPriority.
+	AnnotationAppPriority = "app.oam.dev/priority"
+
+	// AnnotationAppRollbackRevision is the name of the ApplicationRevision the application is rolled back to
+	AnnotationAppRollbackRevision = "app.oam.dev/rollback-revision"
+
+	// AnnotationAppRollbackGeneration records the generation of the application when it's rolled back,
+	// the rollback is ignored once the generation is changed
+	AnnotationAppRollbackGeneration = "app.oam.dev/rollback-generation"
+
+	// AnnotationApplyOrder is the integer order to apply the resource in the manifests dispatched together, the
+	// resources with lower order are applied first, the order of resources without it is 0
+	AnnotationApplyOrder = "app.oam.dev/apply-order"
+
+	// AnnotationSkipCompatibilityCheck skips the parameter compatibility check of the definition webhook if it's "true"
+	AnnotationSkipCompatibilityCheck = "definition.oam.dev/skip-compatibility-check"
 )
@@ -133,6 +133,12 @@ const (
 	// TerraformDef describes a workload refer to Terraform
 	TerraformDef WorkloadType = "TerraformDef"
 
+	// KustomizeDef describes a workload refer to Kustomize
+	KustomizeDef WorkloadType = "KustomizeDef"
+
+	// K8sObjectsDef describes a workload refer to raw K8s objects
+	K8sObjectsDef WorkloadType = "K8sObjectsDef"
+
 	// ReferWorkload describe an existing workload
 	ReferWorkload WorkloadType = "ReferWorkload"
 )
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// ErrFmtTraitConflict is the error of two traits conflicting with each other by a conflictsWith rule
+	ErrFmtTraitConflict = "conflict(rule: %q) between traits (%q and %q) of component %q is detected"
+	// ErrFmtTraitConflictWithAll is the error of a trait conflicting with all other traits applied to the component
+	ErrFmtTraitConflictWithAll = "trait %q of component %q conflicts with all other traits"
+	// ErrFmtInvalidLabelSelector is the error of a conflictsWith rule with an invalid label selector
+	ErrFmtInvalidLabelSelector = "labelSelector in conflict rule (%q) is invalid for %w"
+
+	labelSelectorConflictRulePrefix = "labelSelector:"
+)
+
+// ConflictingTrait is a trait applied to a component, described by the fields of its TraitDefinition which the
+// conflictsWith rules are checked against
+type ConflictingTrait struct {
+	// Name is the name of the TraitDefinition
+	Name string
+	// CRDName is the name of the CRD referenced by the TraitDefinition
+	CRDName string
+	// Labels are the labels of the TraitDefinition
+	Labels map[string]string
+	// ConflictsWith are the conflict rules of the TraitDefinition
+	ConflictsWith []string
+}
+
+// ValidateTraitConflicts validates whether the traits applied to the component conflict with each other according
+// to the conflictsWith rules of their TraitDefinitions. A rule matches the other traits by
+//   - "*", any other trait
+//   - "*.<group>", the API group of the CRD
+//   - "labelSelector:<selector>", the labels of the TraitDefinition
+//   - the name of the TraitDefinition or of the CRD
+func ValidateTraitConflicts(compName string, traits []ConflictingTrait) error {
+	for i, owner := range traits {
+		for _, rule := range owner.ConflictsWith {
+			if rule == "*" {
+				// '*' means this trait conflicts with all other ones, validation fails unless there's only one trait
+				if len(traits) > 1 {
+					return fmt.Errorf(ErrFmtTraitConflictWithAll, owner.Name, compName)
+				}
+				continue
+			}
+			var selector labels.Selector
+			if strings.HasPrefix(rule, labelSelectorConflictRulePrefix) {
+				var err error
+				if selector, err = labels.Parse(strings.TrimPrefix(rule, labelSelectorConflictRulePrefix)); err != nil {
+					return fmt.Errorf(ErrFmtInvalidLabelSelector, rule, err)
+				}
+			}
+			for j, t := range traits {
+				if i == j {
+					continue
+				}
+				// TODO(roywang) consider a CRD group could have multiple versions
+				// and maybe we need to specify the minimum version here in the future
+				if t.Name == rule ||
+					(t.CRDName != "" && t.CRDName == rule) ||
+					(strings.HasPrefix(rule, "*.") && t.CRDName != "" && schema.ParseGroupResource(t.CRDName).Group == rule[2:]) ||
+					(selector != nil && selector.Matches(labels.Set(t.Labels))) {
+					return fmt.Errorf(ErrFmtTraitConflict, rule, owner.Name, t.Name, compName)
+				}
+			}
+		}
+	}
+	return nil
+}
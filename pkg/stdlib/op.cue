@@ -80,6 +80,7 @@ import (
 	app:        string
 	namespace:  string
 	_namespace: namespace
+	_env:       env
 
 	envBinding: kube.#Read & {
 		value: {
@@ -115,6 +116,7 @@ import (
 		for key, comp in components {
 			"\(key)": #ApplyComponent & {
 				value: comp
+				env:   _env
 				if patchedApp.metadata.labels != _|_ && patchedApp.metadata.labels["cluster.oam.dev/clusterName"] != _|_ {
 					cluster: patchedApp.metadata.labels["cluster.oam.dev/clusterName"]
 				}
@@ -2,6 +2,8 @@
 	#provider: "oam"
 	#do:       "component-apply"
 	cluster:   *"" | string
+	env:       *"" | string
+	step:      *context.stepName | string
 	value: {...}
 	patch?: {...}
 	...
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// DefaultFieldManager is the default field manager of the server-side apply
+const DefaultFieldManager = "kubevela"
+
+// NewServerSideApplicator creates an Applicator that applies state to an object by server-side apply.
+// The fields of the desired object are owned by the fieldManager, if forceConflicts is true, the conflicting
+// fields owned by other managers are taken over, otherwise the apply fails on conflicts.
+func NewServerSideApplicator(c client.Client, fieldManager string, forceConflicts bool) *ServerSideApplicator {
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+	return &ServerSideApplicator{
+		c:              c,
+		fieldManager:   fieldManager,
+		forceConflicts: forceConflicts,
+	}
+}
+
+// ServerSideApplicator implements Applicator by server-side apply. Unlike APIApplicator, the last-applied-state is
+// tracked by the managed fields of the object instead of the annotation, so the size of the object is not limited by
+// the annotation and the fields managed by others, such as replicas set by HPA, are not overridden.
+type ServerSideApplicator struct {
+	c              client.Client
+	fieldManager   string
+	forceConflicts bool
+}
+
+// Apply applies new state to an object or create it if not exist. The last-applied-state annotation left by
+// APIApplicator is removed from the existing object, so the object can be switched to server-side apply.
+func (a *ServerSideApplicator) Apply(ctx context.Context, desired client.Object, ao ...ApplyOption) error {
+	// server-side apply requires the name of the object
+	if desired.GetName() == "" && desired.GetGenerateName() != "" {
+		if err := executeApplyOptions(ctx, nil, desired, ao); err != nil {
+			return err
+		}
+		loggingApply("creating object", desired)
+		return errors.Wrap(a.c.Create(ctx, desired), "cannot create object")
+	}
+	if err := a.setGroupVersionKind(desired); err != nil {
+		return err
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(desired.GetObjectKind().GroupVersionKind())
+	err := a.c.Get(ctx, types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, existing)
+	switch {
+	case kerrors.IsNotFound(err):
+		err = executeApplyOptions(ctx, nil, desired, ao)
+	case err != nil:
+		return errors.Wrap(err, "cannot get object")
+	default:
+		err = executeApplyOptions(ctx, existing, desired, ao)
+		if err == nil {
+			err = a.removeLastAppliedConfigAnnotation(ctx, existing)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	removeAnnotation(desired, oam.AnnotationLastAppliedConfig)
+	// the managed fields and the resource version are not allowed or not expected in the apply patch
+	desired.SetManagedFields(nil)
+	desired.SetResourceVersion("")
+	opts := []client.PatchOption{client.FieldOwner(a.fieldManager)}
+	if a.forceConflicts {
+		opts = append(opts, client.ForceOwnership)
+	}
+	loggingApply("server-side applying object", desired)
+	return errors.Wrap(a.c.Patch(ctx, desired, client.Apply, opts...), "cannot server-side apply object")
+}
+
+// setGroupVersionKind set the apiVersion and kind required by the apply patch for the typed object
+func (a *ServerSideApplicator) setGroupVersionKind(obj client.Object) error {
+	if !obj.GetObjectKind().GroupVersionKind().Empty() {
+		return nil
+	}
+	gvk, err := apiutil.GVKForObject(obj, a.c.Scheme())
+	if err != nil {
+		return errors.Wrap(err, "cannot get the GroupVersionKind of object")
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return nil
+}
+
+// removeLastAppliedConfigAnnotation migrate the object applied by APIApplicator by removing the annotation
+func (a *ServerSideApplicator) removeLastAppliedConfigAnnotation(ctx context.Context, existing client.Object) error {
+	if _, ok := existing.GetAnnotations()[oam.AnnotationLastAppliedConfig]; !ok {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{oam.AnnotationLastAppliedConfig: nil},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	loggingApply("removing last-applied-configuration annotation", existing)
+	return errors.Wrap(a.c.Patch(ctx, existing, client.RawPatch(types.MergePatchType, patch)), "cannot remove last-applied-configuration annotation")
+}
+
+func removeAnnotation(obj runtime.Object, key string) {
+	annots, _ := metadataAccessor.Annotations(obj)
+	if _, ok := annots[key]; !ok {
+		return
+	}
+	delete(annots, key)
+	if len(annots) == 0 {
+		annots = nil
+	}
+	_ = metadataAccessor.SetAnnotations(obj, annots)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestServerSideApplicator(t *testing.T) {
+	deployGVK := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	newDesired := func() client.Object {
+		d := &appsv1.Deployment{}
+		d.SetGroupVersionKind(deployGVK)
+		d.SetName("web")
+		d.SetNamespace("default")
+		d.SetResourceVersion("1")
+		d.SetAnnotations(map[string]string{oam.AnnotationLastAppliedConfig: "{}"})
+		return d
+	}
+	type patchCall struct {
+		patchType types.PatchType
+		force     bool
+		owner     string
+	}
+
+	cases := map[string]struct {
+		reason    string
+		existing  map[string]string
+		getErr    error
+		patchErr  error
+		force     bool
+		ao        []ApplyOption
+		want      error
+		wantCalls []patchCall
+	}{
+		"GetError": {
+			reason: "An error should be returned if cannot get the existing object",
+			getErr: errFake,
+			want:   errors.Wrap(errFake, "cannot get object"),
+		},
+		"CreateByApply": {
+			reason:    "The object should be created by the apply patch if it does not exist",
+			getErr:    kerrors.NewNotFound(schema.GroupResource{}, "web"),
+			wantCalls: []patchCall{{patchType: types.ApplyPatchType, owner: DefaultFieldManager}},
+		},
+		"ApplyOptionError": {
+			reason:   "An error should be returned if cannot apply ApplyOption",
+			existing: map[string]string{},
+			ao: []ApplyOption{func(_ context.Context, _, _ runtime.Object) error {
+				return errFake
+			}},
+			want: errors.Wrap(errFake, "cannot apply ApplyOption"),
+		},
+		"MigrateFromClientSideApply": {
+			reason:   "The last-applied-configuration annotation should be removed before the first server-side apply",
+			existing: map[string]string{oam.AnnotationLastAppliedConfig: "{}"},
+			force:    true,
+			wantCalls: []patchCall{
+				{patchType: types.MergePatchType},
+				{patchType: types.ApplyPatchType, owner: DefaultFieldManager, force: true},
+			},
+		},
+		"PatchError": {
+			reason:    "An error should be returned if the apply patch failed",
+			existing:  map[string]string{},
+			patchErr:  errFake,
+			want:      errors.Wrap(errFake, "cannot server-side apply object"),
+			wantCalls: []patchCall{{patchType: types.ApplyPatchType, owner: DefaultFieldManager}},
+		},
+	}
+
+	for caseName, tc := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			var calls []patchCall
+			c := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					if tc.getErr != nil {
+						return tc.getErr
+					}
+					obj.(*unstructured.Unstructured).SetAnnotations(tc.existing)
+					return nil
+				},
+				MockPatch: func(_ context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					po := &client.PatchOptions{}
+					po.ApplyOptions(opts)
+					call := patchCall{patchType: patch.Type(), force: po.Force != nil && *po.Force, owner: po.FieldManager}
+					calls = append(calls, call)
+					if patch.Type() == types.ApplyPatchType {
+						if _, ok := obj.GetAnnotations()[oam.AnnotationLastAppliedConfig]; ok {
+							t.Errorf("the last-applied-configuration annotation should not be applied")
+						}
+						if obj.GetResourceVersion() != "" {
+							t.Errorf("the resource version should not be applied")
+						}
+						return tc.patchErr
+					}
+					return nil
+				},
+			}
+			a := NewServerSideApplicator(c, "", tc.force)
+			result := a.Apply(ctx, newDesired(), tc.ao...)
+			if diff := cmp.Diff(tc.want, result, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want , +got \n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.wantCalls, calls, cmp.AllowUnexported(patchCall{})); diff != "" {
+				t.Errorf("\n%s\nPatch calls: -want , +got \n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package application
 import (
 	"context"
 	"fmt"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -27,6 +28,7 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/dispatch"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/pkg/webhook/common/rollout"
@@ -57,6 +59,8 @@ func (h *ValidatingHandler) ValidateCreate(ctx context.Context, app *v1beta1.App
 		componentErrs = append(componentErrs, rollout.ValidateCreate(h.Client, app.Spec.RolloutPlan, field.NewPath("rolloutPlan"))...)
 	}
 	componentErrs = append(componentErrs, h.validateExternalRevisionName(ctx, app)...)
+	componentErrs = append(componentErrs, validateGarbageCollectPolicy(app)...)
+	componentErrs = append(componentErrs, validatePreDelete(app)...)
 	return componentErrs
 }
 
@@ -116,3 +120,29 @@ func (h *ValidatingHandler) validateExternalRevisionName(ctx context.Context, ap
 	}
 	return componentErrs
 }
+
+// validateGarbageCollectPolicy validates the rules of the garbage-collect policy, as they are parsed again when the
+// application is deleted
+func validateGarbageCollectPolicy(app *v1beta1.Application) field.ErrorList {
+	if _, err := dispatch.ParseGarbageCollectRules(app.Spec.Policies); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("policies"), app, err.Error())}
+	}
+	return nil
+}
+
+// validatePreDelete validates the timeout of the pre-delete workflow, which is only used when the application is
+// deleted
+func validatePreDelete(app *v1beta1.Application) field.ErrorList {
+	if app.Spec.PreDelete == nil || app.Spec.PreDelete.Timeout == "" {
+		return nil
+	}
+	path := field.NewPath("spec", "preDelete", "timeout")
+	timeout, err := time.ParseDuration(app.Spec.PreDelete.Timeout)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, app.Spec.PreDelete.Timeout, err.Error())}
+	}
+	if timeout <= 0 {
+		return field.ErrorList{field.Invalid(path, app.Spec.PreDelete.Timeout, "must be positive")}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestValidateGarbageCollectPolicy(t *testing.T) {
+	cases := map[string]struct {
+		properties string
+		valid      bool
+	}{
+		"valid": {
+			properties: `{"rules":[{"selector":{"traitTypes":["expose"]},"strategy":"delete-after-delay","delay":"1h"}]}`,
+			valid:      true,
+		},
+		"unknown strategy": {
+			properties: `{"rules":[{"strategy":"unknown"}]}`,
+		},
+		"invalid delay": {
+			properties: `{"rules":[{"strategy":"delete-after-delay","delay":"1 hour"}]}`,
+		},
+		"malformed properties": {
+			properties: `{"rules":"never"}`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			app := &v1beta1.Application{Spec: v1beta1.ApplicationSpec{Policies: []v1beta1.AppPolicy{{
+				Name:       "gc",
+				Type:       "garbage-collect",
+				Properties: runtime.RawExtension{Raw: []byte(tc.properties)},
+			}}}}
+			errs := validateGarbageCollectPolicy(app)
+			if tc.valid {
+				assert.Empty(t, errs)
+				return
+			}
+			assert.Len(t, errs, 1)
+			assert.Equal(t, "policies", errs[0].Field)
+		})
+	}
+}
+
+func TestValidatePreDelete(t *testing.T) {
+	app := &v1beta1.Application{}
+	assert.Empty(t, validatePreDelete(app))
+	app.Spec.PreDelete = &v1beta1.PreDeleteWorkflow{Timeout: "5m"}
+	assert.Empty(t, validatePreDelete(app))
+	for _, timeout := range []string{"five minutes", "0s", "-1m"} {
+		app.Spec.PreDelete.Timeout = timeout
+		errs := validatePreDelete(app)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "spec.preDelete.timeout", errs[0].Field)
+	}
+}
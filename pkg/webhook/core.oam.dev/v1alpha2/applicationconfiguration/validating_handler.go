@@ -23,7 +23,6 @@ import (
 	"strings"
 
 	admissionv1 "k8s.io/api/admission/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -47,12 +46,6 @@ const (
 
 	errFmtUnappliableTrait = "the trait %q cannot apply to workload %q of component %q (appliable: %q)"
 
-	errFmtTraitConflict = "conflict(rule: %q) between traits (%q and %q) of component %q is detected"
-
-	errFmtTraitConflictWithAll = "trait %q of component %q conflicts with all other traits"
-
-	errFmtInvalidLabelSelector = "labelSelector in conflict rule (%q) is invalid for %w"
-
 	// WorkloadNamePath indicates field path of workload name
 	WorkloadNamePath = "metadata.name"
 )
@@ -247,59 +240,19 @@ func ValidateTraitConflictFn(_ context.Context, v ValidatingAppConfig) []error {
 	klog.Info("validate trait conflicts ", "appconfig name:", v.appConfig.Name)
 	allErrs := make([]error, 0)
 	for _, comp := range v.validatingComps {
-		allConflictRules := map[string][]string{}
-		// collect conflicts rules of all traits applied to this workload
+		traits := make([]util.ConflictingTrait, 0, len(comp.validatingTraits))
 		for _, trait := range comp.validatingTraits {
-			allConflictRules[trait.traitDefinition.Name] = trait.traitDefinition.Spec.ConflictsWith
+			traits = append(traits, util.ConflictingTrait{
+				Name: trait.traitDefinition.Name,
+				// according to OAM convention, Spec.Reference.Name in traitDefinition is CRD name
+				CRDName:       trait.traitDefinition.Spec.Reference.Name,
+				Labels:        trait.traitDefinition.Labels,
+				ConflictsWith: trait.traitDefinition.Spec.ConflictsWith,
+			})
 		}
-
-		for rulesOwner, rules := range allConflictRules {
-			if len(rules) == 0 {
-				// empty rules means this trait can work with any other ones
-				continue
-			}
-			for _, rule := range rules {
-				if rule == "*" && len(comp.validatingTraits) != 1 {
-					// '*' means this trait conflicts with all other ones
-					// validation fails unless there's only one trait
-					allErrs = append(allErrs, fmt.Errorf(errFmtTraitConflictWithAll, rulesOwner, comp.compName))
-					return allErrs
-				}
-			}
-			// validate each rule on each trait
-			for _, rule := range rules {
-				var ruleLabelSelector labels.Selector
-				var err error
-				if strings.HasPrefix(rule, "labelSelector:") {
-					ruleLabelSelector, err = labels.Parse(rule[len("labelSelector:"):])
-					if err != nil {
-						validationErr := fmt.Errorf(errFmtInvalidLabelSelector, rule, err)
-						allErrs = append(allErrs, validationErr)
-						return allErrs
-					}
-				}
-				for _, trait := range comp.validatingTraits {
-					traitDefName := trait.traitDefinition.Name
-					if traitDefName == rulesOwner {
-						// skip self-check
-						continue
-					}
-					// TODO(roywang) consider a CRD group could have multiple versions
-					// and maybe we need to specify the minimum version here in the future
-					// according to OAM convention, Spec.Reference.Name in traitDefinition is CRD name
-					traitCRDName := trait.traitDefinition.Spec.Reference.Name
-					traitGroup := schema.ParseGroupResource(traitCRDName).Group
-					traitLabelSet := labels.Set(trait.traitDefinition.Labels)
-					if (strings.HasPrefix(rule, "*.") && traitGroup == rule[2:]) || // API group conflict
-						traitCRDName == rule || // CRD name conflict
-						traitDefName == rule || // trait definition name conflict
-						(ruleLabelSelector != nil && ruleLabelSelector.Matches(traitLabelSet)) { // labels conflict
-						err := fmt.Errorf(errFmtTraitConflict, rule, rulesOwner, traitDefName, comp.compName)
-						allErrs = append(allErrs, err)
-						return allErrs
-					}
-				}
-			}
+		if err := util.ValidateTraitConflicts(comp.compName, traits); err != nil {
+			allErrs = append(allErrs, err)
+			return allErrs
 		}
 	}
 	return allErrs
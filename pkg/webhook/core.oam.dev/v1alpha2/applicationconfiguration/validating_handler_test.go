@@ -28,6 +28,7 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
 )
 
 var (
@@ -408,7 +409,7 @@ func TestValidateTraitConflictFn(t *testing.T) {
 					Name: traitDefName2,
 				},
 			},
-			want: []error{fmt.Errorf(errFmtTraitConflictWithAll, traitDefName1, compName)},
+			want: []error{fmt.Errorf(util.ErrFmtTraitConflictWithAll, traitDefName1, compName)},
 		},
 		{
 			caseName:      "'*' conflict rule (no conflict if only one trait)",
@@ -433,7 +434,7 @@ func TestValidateTraitConflictFn(t *testing.T) {
 					},
 				},
 			},
-			want: []error{fmt.Errorf(errFmtTraitConflict, "*.example.com", traitDefName1, traitDefName2, compName)},
+			want: []error{fmt.Errorf(util.ErrFmtTraitConflict, "*.example.com", traitDefName1, traitDefName2, compName)},
 		},
 		{
 			caseName:      "TraitDefinition name conflict",
@@ -443,7 +444,7 @@ func TestValidateTraitConflictFn(t *testing.T) {
 					Name: traitDefName2,
 				},
 			},
-			want: []error{fmt.Errorf(errFmtTraitConflict, traitDefName2, traitDefName1, traitDefName2, compName)},
+			want: []error{fmt.Errorf(util.ErrFmtTraitConflict, traitDefName2, traitDefName1, traitDefName2, compName)},
 		},
 		{
 			caseName:      "CRD name conflict",
@@ -458,7 +459,7 @@ func TestValidateTraitConflictFn(t *testing.T) {
 					},
 				},
 			},
-			want: []error{fmt.Errorf(errFmtTraitConflict, "foo.example.com", traitDefName1, traitDefName2, compName)},
+			want: []error{fmt.Errorf(util.ErrFmtTraitConflict, "foo.example.com", traitDefName1, traitDefName2, compName)},
 		},
 		{
 			caseName:      "LabelSelector conflict",
@@ -469,7 +470,7 @@ func TestValidateTraitConflictFn(t *testing.T) {
 					Labels: map[string]string{"foo": "bar"},
 				},
 			},
-			want: []error{fmt.Errorf(errFmtTraitConflict, "labelSelector:foo=bar", traitDefName1, traitDefName2, compName)},
+			want: []error{fmt.Errorf(util.ErrFmtTraitConflict, "labelSelector:foo=bar", traitDefName1, traitDefName2, compName)},
 		},
 		{
 			caseName:      "LabelSelector invalid error",
@@ -480,7 +481,7 @@ func TestValidateTraitConflictFn(t *testing.T) {
 					Labels: map[string]string{"foo": "bar"},
 				},
 			},
-			want: []error{fmt.Errorf(errFmtInvalidLabelSelector, "labelSelector:,,,",
+			want: []error{fmt.Errorf(util.ErrFmtInvalidLabelSelector, "labelSelector:,,,",
 				fmt.Errorf("found ',', expected: !, identifier, or 'end of string'"))},
 		},
 	}
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/pkg/errors"
 	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
@@ -31,6 +33,8 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
@@ -46,6 +50,10 @@ type ValidatingHandler struct {
 	// Decoder decodes object
 	Decoder *admission.Decoder
 	Client  client.Client
+
+	// PackageDiscover and CompatibilityCheck are used to check whether the parameter changes break existing applications
+	PackageDiscover    *packages.PackageDiscover
+	CompatibilityCheck bool
 }
 
 var _ inject.Client = &ValidatingHandler{}
@@ -86,10 +94,39 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 				return admission.Denied(err.Error())
 			}
 		}
+		if req.Operation == admissionv1.Update && webhookutils.NeedCompatibilityCheck(h.CompatibilityCheck, obj) {
+			if err = h.validateCompatibility(ctx, req, obj); err != nil {
+				return admission.Denied(err.Error())
+			}
+		}
 	}
 	return admission.ValidationResponse(true, "")
 }
 
+// validateCompatibility denies the parameter changes which break existing applications, the check is skipped for Helm
+// components whose schemas are fetched from the charts. The update is denied if the schema of the new definition
+// cannot be generated, and the check is skipped if the schema of the old one cannot, as there is nothing to compare.
+func (h *ValidatingHandler) validateCompatibility(ctx context.Context, req admission.Request, obj *v1beta1.ComponentDefinition) error {
+	old := &v1beta1.ComponentDefinition{}
+	if err := h.Decoder.DecodeRaw(req.OldObject, old); err != nil {
+		return errors.Wrap(err, "cannot decode the old definition to check the compatibility")
+	}
+	oldDef, newDef := utils.NewCapabilityComponentDef(old), utils.NewCapabilityComponentDef(obj)
+	if oldDef.WorkloadType == util.HELMDef || newDef.WorkloadType == util.HELMDef {
+		return nil
+	}
+	newSchema, err := newDef.GenerateOpenAPISchema(ctx, h.PackageDiscover, obj.Name)
+	if err != nil {
+		return errors.WithMessage(err, "cannot check the compatibility")
+	}
+	oldSchema, err := oldDef.GenerateOpenAPISchema(ctx, h.PackageDiscover, old.Name)
+	if err != nil {
+		klog.ErrorS(err, "Skip compatibility check as the schema of the old definition cannot be generated", "name", obj.Name)
+		return nil
+	}
+	return webhookutils.ValidateSchemaCompatibility(ctx, h.Client, obj, oldSchema, newSchema)
+}
+
 var _ admission.DecoderInjector = &ValidatingHandler{}
 
 // InjectDecoder injects the decoder into the ValidatingHandler
@@ -102,7 +139,9 @@ func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
 func RegisterValidatingHandler(mgr manager.Manager, args controller.Args) {
 	server := mgr.GetWebhookServer()
 	server.Register("/validating-core-oam-dev-v1beta1-componentdefinitions", &webhook.Admission{Handler: &ValidatingHandler{
-		Mapper: args.DiscoveryMapper,
+		Mapper:             args.DiscoveryMapper,
+		PackageDiscover:    args.PackageDiscover,
+		CompatibilityCheck: args.DefinitionCompatibilityCheck,
 	}})
 }
 
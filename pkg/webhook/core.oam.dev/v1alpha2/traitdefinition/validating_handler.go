@@ -33,6 +33,8 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
@@ -55,6 +57,10 @@ type ValidatingHandler struct {
 	Decoder *admission.Decoder
 	// Validators validate objects
 	Validators []TraitDefValidator
+
+	// PackageDiscover and CompatibilityCheck are used to check whether the parameter changes break existing applications
+	PackageDiscover    *packages.PackageDiscover
+	CompatibilityCheck bool
 }
 
 // TraitDefValidator validate trait definition
@@ -99,11 +105,38 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 				return admission.Denied(err.Error())
 			}
 		}
+		if req.Operation == admissionv1.Update && webhookutils.NeedCompatibilityCheck(h.CompatibilityCheck, obj) {
+			if err := h.validateCompatibility(ctx, req, obj); err != nil {
+				klog.Info("compatibility check failed ", " name: ", obj.Name, " errMsg: ", err.Error())
+				return admission.Denied(err.Error())
+			}
+		}
 		klog.Info("validation passed ", " name: ", obj.Name, " operation: ", string(req.Operation))
 	}
 	return admission.ValidationResponse(true, "")
 }
 
+// validateCompatibility denies the parameter changes which break existing applications. The update is denied if the
+// schema of the new definition cannot be generated, and the check is skipped if the schema of the old one cannot, as
+// there is nothing to compare.
+func (h *ValidatingHandler) validateCompatibility(ctx context.Context, req admission.Request, obj *v1beta1.TraitDefinition) error {
+	old := &v1beta1.TraitDefinition{}
+	if err := h.Decoder.DecodeRaw(req.OldObject, old); err != nil {
+		return errors.Wrap(err, "cannot decode the old definition to check the compatibility")
+	}
+	oldDef, newDef := utils.NewCapabilityTraitDef(old), utils.NewCapabilityTraitDef(obj)
+	newSchema, err := newDef.GenerateOpenAPISchema(h.PackageDiscover, obj.Name)
+	if err != nil {
+		return errors.WithMessage(err, "cannot check the compatibility")
+	}
+	oldSchema, err := oldDef.GenerateOpenAPISchema(h.PackageDiscover, old.Name)
+	if err != nil {
+		klog.Info("skip compatibility check as the schema of the old definition cannot be generated ", " name: ", obj.Name, " errMsg: ", err.Error())
+		return nil
+	}
+	return webhookutils.ValidateSchemaCompatibility(ctx, h.Client, obj, oldSchema, newSchema)
+}
+
 var _ inject.Client = &ValidatingHandler{}
 
 // InjectClient injects the client into the ValidatingHandler
@@ -124,9 +157,12 @@ func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
 func RegisterValidatingHandler(mgr manager.Manager, args controller.Args) {
 	server := mgr.GetWebhookServer()
 	server.Register("/validating-core-oam-dev-v1alpha2-traitdefinitions", &webhook.Admission{Handler: &ValidatingHandler{
-		Mapper: args.DiscoveryMapper,
+		Mapper:             args.DiscoveryMapper,
+		PackageDiscover:    args.PackageDiscover,
+		CompatibilityCheck: args.DefinitionCompatibilityCheck,
 		Validators: []TraitDefValidator{
 			TraitDefValidatorFn(ValidateDefinitionReference),
+			TraitDefValidatorFn(ValidateTraitDependencies),
 			// add more validators here
 		},
 	}})
@@ -154,3 +190,19 @@ func ValidateDefinitionReference(_ context.Context, td v1beta1.TraitDefinition)
 	}
 	return nil
 }
+
+// ValidateTraitDependencies validates whether the dependsOn of the trait definition is consistent with itself,
+// a trait cannot depend on itself or a trait it conflicts with
+func ValidateTraitDependencies(_ context.Context, td v1beta1.TraitDefinition) error {
+	for _, dep := range td.Spec.DependsOn {
+		if dep == td.Name {
+			return errors.Errorf("trait %q cannot depend on itself", td.Name)
+		}
+		for _, rule := range td.Spec.ConflictsWith {
+			if rule == dep || rule == "*" {
+				return errors.Errorf("trait %q cannot both depend on and conflict with trait %q", td.Name, dep)
+			}
+		}
+	}
+	return nil
+}
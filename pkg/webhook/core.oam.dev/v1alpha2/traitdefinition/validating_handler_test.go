@@ -30,9 +30,14 @@ import (
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	utilscommon "github.com/oam-dev/kubevela/pkg/utils/common"
 )
 
 var handler ValidatingHandler
@@ -138,4 +143,89 @@ var _ = Describe("Test TraitDefinition validating handler", func() {
 			Expect(resp.Result.Reason).Should(Equal(metav1.StatusReason("mock validator error")))
 		})
 	})
+
+	Context("Test compatibility check of update operation admission request", func() {
+		newTraitDef := func(parameter string, annotations map[string]string) []byte {
+			def := v1beta1.TraitDefinition{
+				TypeMeta:   metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.TraitDefinitionKind},
+				ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default", Annotations: annotations},
+				Spec: v1beta1.TraitDefinitionSpec{Schematic: &common.Schematic{CUE: &common.CUE{
+					Template: "patch: spec: replicas: parameter.replicas\n" + parameter,
+				}}},
+			}
+			raw, _ := json.Marshal(def)
+			return raw
+		}
+		oldRaw := newTraitDef("parameter: replicas: *1 | int", nil)
+		newRaw := newTraitDef("parameter: {\n\treplicas: *1 | int\n\tzone: string\n}", nil)
+
+		BeforeEach(func() {
+			app := &v1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec: v1beta1.ApplicationSpec{Components: []common.ApplicationComponent{{
+					Name: "comp", Type: "worker",
+					Traits: []common.ApplicationTrait{{Type: "scaler", Properties: runtime.RawExtension{Raw: []byte(`{"replicas":2}`)}}},
+				}}},
+			}
+			handler.Client = fake.NewClientBuilder().WithScheme(utilscommon.Scheme).WithObjects(app).Build()
+			handler.PackageDiscover = &packages.PackageDiscover{}
+			handler.CompatibilityCheck = true
+			handler.Validators = nil
+		})
+
+		It("Test breaking changes denied", func() {
+			req = admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Resource:  reqResource,
+					Object:    runtime.RawExtension{Raw: newRaw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+				},
+			}
+			resp := handler.Handle(context.TODO(), req)
+			Expect(resp.Allowed).Should(BeFalse())
+			Expect(string(resp.Result.Reason)).Should(ContainSubstring("default/app (component comp): zone is required"))
+		})
+
+		It("Test compatible changes or skipped check allowed", func() {
+			req = admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Resource:  reqResource,
+					Object:    runtime.RawExtension{Raw: newTraitDef("parameter: {\n\treplicas: *1 | int\n\tzone?: string\n}", nil)},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+				},
+			}
+			Expect(handler.Handle(context.TODO(), req).Allowed).Should(BeTrue())
+
+			req.Object = runtime.RawExtension{Raw: newTraitDef("parameter: {\n\treplicas: *1 | int\n\tzone: string\n}",
+				map[string]string{oam.AnnotationSkipCompatibilityCheck: "true"})}
+			Expect(handler.Handle(context.TODO(), req).Allowed).Should(BeTrue())
+
+			handler.CompatibilityCheck = false
+			req.Object = runtime.RawExtension{Raw: newRaw}
+			Expect(handler.Handle(context.TODO(), req).Allowed).Should(BeTrue())
+		})
+
+		It("Test the schema of the new definition cannot be generated", func() {
+			req = admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Resource:  reqResource,
+					Object:    runtime.RawExtension{Raw: newTraitDef("parameter: {\n\treplicas: *1 | \n}", nil)},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+				},
+			}
+			resp := handler.Handle(context.TODO(), req)
+			Expect(resp.Allowed).Should(BeFalse())
+			Expect(string(resp.Result.Reason)).Should(ContainSubstring("cannot check the compatibility"))
+
+			// nothing to compare with if the schema of the old definition cannot be generated
+			req.Object, req.OldObject = req.OldObject, req.Object
+			Expect(handler.Handle(context.TODO(), req).Allowed).Should(BeTrue())
+
+			req.OldObject = runtime.RawExtension{Raw: []byte("invalid")}
+			Expect(handler.Handle(context.TODO(), req).Allowed).Should(BeFalse())
+		})
+	})
 })
@@ -24,7 +24,9 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 )
 
@@ -74,6 +76,44 @@ func TestValidateDefinitionReference(t *testing.T) {
 	}
 }
 
+func TestValidateTraitDependencies(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		spec   v1beta1.TraitDefinitionSpec
+		want   error
+	}{
+		"NoDependency": {
+			reason: "No error should be returned if dependsOn is omitted",
+			spec:   v1beta1.TraitDefinitionSpec{ConflictsWith: []string{"*"}},
+			want:   nil,
+		},
+		"ValidDependency": {
+			reason: "No error should be returned if the dependencies are consistent",
+			spec:   v1beta1.TraitDefinitionSpec{DependsOn: []string{"expose"}, ConflictsWith: []string{"hpa"}},
+			want:   nil,
+		},
+		"DependOnItself": {
+			reason: "An error should be returned if the trait depends on itself",
+			spec:   v1beta1.TraitDefinitionSpec{DependsOn: []string{"scaler"}},
+			want:   errors.New(`trait "scaler" cannot depend on itself`),
+		},
+		"DependOnConflict": {
+			reason: "An error should be returned if the trait depends on a trait it conflicts with",
+			spec:   v1beta1.TraitDefinitionSpec{DependsOn: []string{"hpa"}, ConflictsWith: []string{"hpa"}},
+			want:   errors.New(`trait "scaler" cannot both depend on and conflict with trait "hpa"`),
+		},
+	}
+	for caseName, tc := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			td := v1beta1.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "scaler"}, Spec: tc.spec}
+			err := ValidateTraitDependencies(context.Background(), td)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateTraitDependencies: -want , +got \n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func traitDefStringWithTemplate(t string) string {
 	return fmt.Sprintf(`
 apiVersion: core.oam.dev/v1alpha2
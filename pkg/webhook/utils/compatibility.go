@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// NeedCompatibilityCheck checks whether the parameter compatibility check is required for the definition
+func NeedCompatibilityCheck(enabled bool, def client.Object) bool {
+	return enabled && def.GetAnnotations()[oam.AnnotationSkipCompatibilityCheck] != "true"
+}
+
+// ValidateSchemaCompatibility validates whether the parameter schema changes of the definition break the existing
+// applications, the broken applications are listed in the error
+func ValidateSchemaCompatibility(ctx context.Context, cli client.Client, def client.Object, oldSchema, newSchema []byte) error {
+	changes, err := utils.DiffOpenAPISchema(oldSchema, newSchema)
+	if err != nil {
+		return err
+	}
+	impacts, err := utils.FindBrokenApplications(ctx, cli, def, changes)
+	if err != nil {
+		return err
+	}
+	if len(impacts) == 0 {
+		return nil
+	}
+	apps := make([]string, 0, len(impacts))
+	for _, impact := range impacts {
+		apps = append(apps, impact.String())
+	}
+	return fmt.Errorf("the parameter changes of %s break %d application(s): %s. Set the annotation %s to \"true\" to skip the check",
+		def.GetName(), len(impacts), strings.Join(apps, ", "), oam.AnnotationSkipCompatibilityCheck)
+}
@@ -38,8 +38,8 @@ const (
 	ProviderName = "oam"
 )
 
-// ComponentApply apply oam component.
-type ComponentApply func(comp common.ApplicationComponent, patcher *value.Value, clusterName string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error)
+// ComponentApply apply oam component, the cluster, env and step are exposed to the templates in the context.
+type ComponentApply func(comp common.ApplicationComponent, patcher *value.Value, clusterName string, envName string, stepName string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error)
 
 type provider struct {
 	apply ComponentApply
@@ -62,7 +62,15 @@ func (p *provider) ApplyComponent(ctx wfContext.Context, v *value.Value, act wfT
 	if err != nil {
 		clusterName = ""
 	}
-	workload, traits, healthy, err := p.apply(comp, patcher, clusterName)
+	envName, err := v.GetString("env")
+	if err != nil {
+		envName = ""
+	}
+	stepName, err := v.GetString("step")
+	if err != nil {
+		stepName = ""
+	}
+	workload, traits, healthy, err := p.apply(comp, patcher, clusterName, envName, stepName)
 	if err != nil {
 		return err
 	}
@@ -115,7 +115,7 @@ func TestLoadComponent(t *testing.T) {
 
 var testHealthy bool
 
-func simpleComponentApplyForTest(comp common.ApplicationComponent, _ *value.Value, _ string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error) {
+func simpleComponentApplyForTest(comp common.ApplicationComponent, _ *value.Value, _, _, _ string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error) {
 	workload := new(unstructured.Unstructured)
 	workload.UnmarshalJSON([]byte(`{
   "apiVersion": "v1",
@@ -161,3 +161,30 @@ func (act *mockAction) Wait(message string) {
 	act.phase = "Wait"
 	act.message = message
 }
+
+func TestApplyComponentRuntimeInfo(t *testing.T) {
+	var cluster, env, step string
+	p := &provider{
+		apply: func(comp common.ApplicationComponent, patcher *value.Value, clusterName string, envName string, stepName string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error) {
+			cluster, env, step = clusterName, envName, stepName
+			return nil, nil, true, nil
+		},
+	}
+	v, err := value.NewValue(`
+import "vela/op"
+
+apply: op.#ApplyComponent & {
+	value: {name: "comp"}
+	cluster: "prod-us"
+	env: "prod"
+}
+context: stepName: "deploy-prod"
+`, nil, `context: stepName: "deploy-prod"`)
+	assert.NilError(t, err)
+	apply, err := v.LookupValue("apply")
+	assert.NilError(t, err)
+	assert.NilError(t, p.ApplyComponent(nil, apply, &mockAction{}))
+	assert.Equal(t, cluster, "prod-us")
+	assert.Equal(t, env, "prod")
+	assert.Equal(t, step, "deploy-prod")
+}
@@ -26,6 +26,7 @@ import (
 
 	"cuelang.org/go/cue"
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
@@ -176,7 +177,7 @@ func (t *TaskLoader) makeTaskGenerator(templ string) (wfTypes.TaskGenerator, err
 				paramFile = fmt.Sprintf(model.ParameterFieldName+": {%s}\n", ps)
 			}
 
-			taskv, err := t.makeValue(ctx, strings.Join([]string{templ, paramFile}, "\n"), genOpt.ID)
+			taskv, err := t.makeValue(ctx, strings.Join([]string{templ, paramFile}, "\n"), genOpt.ID, wfStep)
 			if err != nil {
 				exec.err(err, StatusReasonRendering)
 				return exec.status(), exec.operation(), nil
@@ -200,7 +201,7 @@ func (t *TaskLoader) makeTaskGenerator(templ string) (wfTypes.TaskGenerator, err
 	}, nil
 }
 
-func (t *TaskLoader) makeValue(ctx wfContext.Context, templ string, id string) (*value.Value, error) {
+func (t *TaskLoader) makeValue(ctx wfContext.Context, templ string, id string, wfStep v1beta1.WorkflowStep) (*value.Value, error) {
 	var contextTempl string
 	meta, _ := ctx.GetVar(wfTypes.ContextKeyMetadata)
 	if meta != nil {
@@ -209,11 +210,32 @@ func (t *TaskLoader) makeValue(ctx wfContext.Context, templ string, id string) (
 			return nil, err
 		}
 		contextTempl = fmt.Sprintf("\ncontext: {%s}\ncontext: stepSessionID: \"%s\"", ms, id)
+		appMeta := metav1.ObjectMeta{}
+		if err := meta.UnmarshalTo(&appMeta); err != nil {
+			return nil, err
+		}
+		stepContext, err := json.Marshal(map[string]interface{}{
+			model.ContextStepName:       wfStep.Name,
+			model.ContextStepType:       wfStep.Type,
+			model.ContextAppLabels:      nonNilMap(appMeta.Labels),
+			model.ContextAppAnnotations: nonNilMap(appMeta.Annotations),
+		})
+		if err != nil {
+			return nil, err
+		}
+		contextTempl += fmt.Sprintf("\ncontext: %s", string(stepContext))
 	}
 
 	return value.NewValue(templ+contextTempl, t.pd, contextTempl, value.ProcessScript, value.TagFieldOrder)
 }
 
+func nonNilMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
 type executor struct {
 	handlers providers.Providers
 
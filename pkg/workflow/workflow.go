@@ -36,6 +36,13 @@ type workflow struct {
 	app     *oamcore.Application
 	cli     client.Client
 	dagMode bool
+
+	// status points to the field of the application status which records the workflow status
+	status **common.WorkflowStatus
+	// contextName is the name used to store the workflow context
+	contextName string
+	// preDelete is true if the workflow runs the pre-delete steps, it doesn't touch the services of the application
+	preDelete bool
 }
 
 // NewWorkflow returns a Workflow implementation.
@@ -45,9 +52,26 @@ func NewWorkflow(app *oamcore.Application, cli client.Client, mode common.Workfl
 		dagMode = true
 	}
 	return &workflow{
-		app:     app,
-		cli:     cli,
-		dagMode: dagMode,
+		app:         app,
+		cli:         cli,
+		dagMode:     dagMode,
+		status:      &app.Status.Workflow,
+		contextName: app.Name,
+	}
+}
+
+// NewPreDeleteWorkflow returns a Workflow which executes the pre-delete steps of the application, its status is
+// recorded in the pre-delete status of the application.
+func NewPreDeleteWorkflow(app *oamcore.Application, cli client.Client) Workflow {
+	if app.Status.PreDelete == nil {
+		app.Status.PreDelete = &common.PreDeleteStatus{}
+	}
+	return &workflow{
+		app:         app,
+		cli:         cli,
+		status:      &app.Status.PreDelete.Workflow,
+		contextName: app.Name + "-pre-delete",
+		preDelete:   true,
 	}
 }
 
@@ -61,21 +85,23 @@ func (w *workflow) ExecuteSteps(ctx context.Context, appRev *oamcore.Application
 		return common.WorkflowStateFinished, nil
 	}
 
-	if w.app.Status.Workflow == nil || w.app.Status.Workflow.AppRevision != revAndSpecHash {
-		w.app.Status.Workflow = &common.WorkflowStatus{
+	if *w.status == nil || (*w.status).AppRevision != revAndSpecHash {
+		*w.status = &common.WorkflowStatus{
 			AppRevision: revAndSpecHash,
 			Mode:        common.WorkflowModeStep,
 		}
 		if w.dagMode {
-			w.app.Status.Workflow.Mode = common.WorkflowModeDAG
+			(*w.status).Mode = common.WorkflowModeDAG
 		}
 
-		// clean recorded resources info.
-		w.app.Status.Services = nil
-		w.app.Status.AppliedResources = nil
+		if !w.preDelete {
+			// clean recorded resources info.
+			w.app.Status.Services = nil
+			w.app.Status.AppliedResources = nil
+		}
 	}
 
-	wfStatus := w.app.Status.Workflow
+	wfStatus := *w.status
 	allTasksDone := w.allDone(taskRunners)
 	if wfStatus.Terminated {
 		return common.WorkflowStateTerminated, nil
@@ -91,7 +117,7 @@ func (w *workflow) ExecuteSteps(ctx context.Context, appRev *oamcore.Application
 		wfCtx wfContext.Context
 	)
 
-	wfCtx, err = w.makeContext(w.app.Name)
+	wfCtx, err = w.makeContext(w.contextName)
 	if err != nil {
 		return common.WorkflowStateExecuting, err
 	}
@@ -118,7 +144,7 @@ func (w *workflow) ExecuteSteps(ctx context.Context, appRev *oamcore.Application
 }
 
 func (w *workflow) allDone(taskRunners []wfTypes.TaskRunner) bool {
-	status := w.app.Status.Workflow
+	status := *w.status
 	for _, t := range taskRunners {
 		done := false
 		for _, ss := range status.Steps {
@@ -135,7 +161,7 @@ func (w *workflow) allDone(taskRunners []wfTypes.TaskRunner) bool {
 }
 
 func (w *workflow) makeContext(appName string) (wfCtx wfContext.Context, err error) {
-	wfStatus := w.app.Status.Workflow
+	wfStatus := *w.status
 	if wfStatus.ContextBackend != nil {
 		wfCtx, err = wfContext.LoadContext(w.cli, w.app.Namespace, appName)
 		if err != nil {
@@ -108,12 +108,12 @@ func (l *LiveDiffOption) Diff(ctx context.Context, app *v1beta1.Application, app
 	if err != nil {
 		return nil, errors.WithMessagef(err, "cannot generate diff manifest for AppRevision %q", appRevision.Name)
 	}
-	diffResult := l.calculateDiff(oldManifest, newManifest)
+	diffResult := calculateDiff(oldManifest, newManifest)
 	return diffResult, nil
 }
 
 // calculateDiff calculate diff between two application and their sub-resources
-func (l *LiveDiffOption) calculateDiff(oldApp, newApp *manifest) *DiffEntry {
+func calculateDiff(oldApp, newApp *manifest) *DiffEntry {
 	emptyManifest := &manifest{}
 	r := &DiffEntry{
 		Name: oldApp.Name,
@@ -322,7 +322,7 @@ func generateManifestFromAppRevision(parser *appfile.Parser, appRevision *v1beta
 	if err != nil {
 		return nil, err
 	}
-	comps, err := af.GenerateComponentManifests()
+	comps, err := af.GenerateComponentManifests(context.Background())
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +67,7 @@ func (d *Option) ExecuteDryRun(ctx context.Context, app *v1beta1.Application) ([
 	if appFile.Namespace == "" {
 		appFile.Namespace = corev1.NamespaceDefault
 	}
-	comps, err := appFile.GenerateComponentManifests()
+	comps, err := appFile.GenerateComponentManifests(ctx)
 	if err != nil {
 		return nil, errors.WithMessage(err, "cannot generate AppConfig and Components")
 	}
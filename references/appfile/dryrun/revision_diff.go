@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+)
+
+// NewRevisionDiffOption creates a revision-diff option
+func NewRevisionDiffOption(c client.Client, dm discoverymapper.DiscoveryMapper, pd *packages.PackageDiscover) *RevisionDiffOption {
+	return &RevisionDiffOption{Client: c, Parser: appfile.NewApplicationParser(c, dm, pd)}
+}
+
+// RevisionDiffOption contains options for comparing two AppRevisions of an
+// application. The manifests of each AppRevision are rendered with the
+// definitions stored in itself.
+type RevisionDiffOption struct {
+	Client client.Client
+	Parser *appfile.Parser
+}
+
+// GetRevision gets the AppRevision of the application, the revision can be
+// either the revision number or the name of the AppRevision
+func (r *RevisionDiffOption) GetRevision(ctx context.Context, namespace, appName, revision string) (*v1beta1.ApplicationRevision, error) {
+	revName := revision
+	if num, err := strconv.ParseInt(revision, 10, 64); err == nil {
+		revName = utils.ConstructRevisionName(appName, num)
+	}
+	appRev := &v1beta1.ApplicationRevision{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: revName}, appRev); err != nil {
+		return nil, errors.Wrapf(err, "cannot get application revision %q", revName)
+	}
+	if appRev.GetLabels()[oam.LabelAppName] != appName {
+		return nil, errors.Errorf("application revision %q does not belong to application %q", revName, appName)
+	}
+	return appRev, nil
+}
+
+// Diff calculates diff between the application spec and the rendered
+// manifests of two AppRevisions, the manifests are grouped by component.
+func (r *RevisionDiffOption) Diff(from, to *v1beta1.ApplicationRevision) (*DiffEntry, error) {
+	oldManifest, err := generateManifestFromAppRevision(r.Parser, cleanRevisionApplication(from))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "cannot generate diff manifest for AppRevision %q", from.Name)
+	}
+	newManifest, err := generateManifestFromAppRevision(r.Parser, cleanRevisionApplication(to))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "cannot generate diff manifest for AppRevision %q", to.Name)
+	}
+	return calculateDiff(oldManifest, newManifest), nil
+}
+
+// cleanRevisionApplication removes the metadata of the application stored in
+// the AppRevision except labels and annotations, as fields like generation
+// always differ between revisions.
+func cleanRevisionApplication(appRevision *v1beta1.ApplicationRevision) *v1beta1.ApplicationRevision {
+	appRev := appRevision.DeepCopy()
+	meta := appRev.Spec.Application.ObjectMeta
+	appRev.Spec.Application.ObjectMeta = metav1.ObjectMeta{
+		Labels:      meta.Labels,
+		Annotations: meta.Annotations,
+	}
+	return appRev
+}
+
+// RevisionDiffRange fills the default revisions to compare, by default the
+// latest revision is compared with the one before it
+func RevisionDiffRange(app *v1beta1.Application, from, to string) (string, string, error) {
+	if to == "" {
+		if app.Status.LatestRevision == nil {
+			return "", "", fmt.Errorf("the application %q has no revision in the cluster", app.Name)
+		}
+		to = app.Status.LatestRevision.Name
+	}
+	if from == "" {
+		num, err := strconv.Atoi(to)
+		if err != nil {
+			if num, err = utils.ExtractRevision(to); err != nil {
+				return "", "", errors.Wrapf(err, "invalid revision %q", to)
+			}
+		}
+		if num <= 1 {
+			return "", "", fmt.Errorf("there is no revision before %q to compare with", to)
+		}
+		from = strconv.Itoa(num - 1)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	commontypes "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestRevisionDiff(t *testing.T) {
+	r := require.New(t)
+	v1 := new(v1beta1.ApplicationRevision)
+	r.NoError(yaml.Unmarshal([]byte(readDataFromFile("./testdata/revision-diff-v1.yaml")), v1))
+
+	v2 := v1.DeepCopy()
+	v2.Name = "revdiff-demo-v2"
+	v2.Spec.Application.Generation = 2
+	comps := v2.Spec.Application.Spec.Components
+	comps[0].Traits = nil
+	comps[1].Properties = runtime.RawExtension{Raw: []byte(`{"image":"redis:6"}`)}
+
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(v1, v2).Build()
+	opt := NewRevisionDiffOption(cli, nil, &packages.PackageDiscover{})
+	ctx := context.Background()
+
+	from, err := opt.GetRevision(ctx, "default", "revdiff-demo", "1")
+	r.NoError(err)
+	to, err := opt.GetRevision(ctx, "default", "revdiff-demo", "revdiff-demo-v2")
+	r.NoError(err)
+	_, err = opt.GetRevision(ctx, "default", "revdiff-demo", "3")
+	r.Error(err)
+	_, err = opt.GetRevision(ctx, "default", "other", "revdiff-demo-v2")
+	r.Error(err)
+
+	diffResult, err := opt.Diff(from, to)
+	r.NoError(err)
+	buff := &bytes.Buffer{}
+	NewReportDiffOption(-1, buff).PrintDiffReport(diffResult)
+	report := buff.String()
+	r.Contains(report, "Application (revdiff-demo) has been modified(*)")
+	r.Contains(report, "Component (web) has no change")
+	r.Contains(report, "Component (web) / Trait (myscaler/scaler) has been removed(-)")
+	r.Contains(report, "Component (cache) has been modified(*)")
+	r.NotContains(report, "generation")
+
+	diffResult, err = opt.Diff(from, from)
+	r.NoError(err)
+	r.Equal(NoDiff, diffResult.DiffType)
+}
+
+func TestRevisionDiffRange(t *testing.T) {
+	r := require.New(t)
+	app := &v1beta1.Application{}
+	app.Name = "my-app"
+
+	_, _, err := RevisionDiffRange(app, "", "")
+	r.Error(err)
+
+	from, to, err := RevisionDiffRange(app, "2", "5")
+	r.NoError(err)
+	r.Equal("2", from)
+	r.Equal("5", to)
+
+	from, to, err = RevisionDiffRange(app, "", "5")
+	r.NoError(err)
+	r.Equal("4", from)
+	r.Equal("5", to)
+
+	app.Status.LatestRevision = &commontypes.Revision{Name: "my-app-v3", Revision: 3}
+	from, to, err = RevisionDiffRange(app, "", "")
+	r.NoError(err)
+	r.Equal("2", from)
+	r.Equal("my-app-v3", to)
+
+	_, _, err = RevisionDiffRange(app, "", "my-app-v1")
+	r.Error(err)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore/kubeapi"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/audit"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+const (
+	// AuditMethodCLI the method of the audit records of the CLI operations
+	AuditMethodCLI = "CLI"
+	// AuditDatastoreEnvName is the name of the environment variable of the namespace of the kubeapi datastore of the
+	// api server, it matches the --datastore-database flag of the api server
+	AuditDatastoreEnvName = "VELA_DATASTORE_DATABASE"
+	// defaultAuditDatastore the default namespace of the kubeapi datastore of the api server
+	defaultAuditDatastore = "kubevela"
+)
+
+// auditedCommands the commands change the cluster, they are audited like the mutating requests of the api server
+var auditedCommands = map[string]bool{
+	"vela init":               true,
+	"vela up":                 true,
+	"vela delete":             true,
+	"vela pause":              true,
+	"vela unpause":            true,
+	"vela rollback":           true,
+	"vela workflow suspend":   true,
+	"vela workflow resume":    true,
+	"vela workflow terminate": true,
+	"vela workflow restart":   true,
+	"vela cap install":        true,
+	"vela cap uninstall":      true,
+	"vela def apply":          true,
+	"vela def del":            true,
+	"vela addon enable":       true,
+	"vela addon disable":      true,
+	"vela cluster join":       true,
+	"vela cluster rename":     true,
+	"vela cluster detach":     true,
+}
+
+// auditCommands wraps the audited commands in the command tree to record the operations
+func auditCommands(cmd *cobra.Command, c common.Args) {
+	for _, sub := range cmd.Commands() {
+		auditCommands(sub, c)
+	}
+	if !auditedCommands[cmd.CommandPath()] || cmd.RunE == nil {
+		return
+	}
+	runE := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		start := time.Now()
+		err := runE(cmd, args)
+		recordOperation(cmd, c, newOperationRecord(cmd, args, start, err))
+		return err
+	}
+}
+
+// newOperationRecord creates the audit record of the operation, the first argument is recorded as the resource name
+func newOperationRecord(cmd *cobra.Command, args []string, start time.Time, err error) *model.AuditRecord {
+	record := audit.NewRecord(AuditMethodCLI, cmd.CommandPath(), start)
+	record.ResourceType = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	if len(args) > 0 {
+		record.ResourceName = args[0]
+		if data, marshalErr := json.Marshal(args); marshalErr == nil {
+			audit.HashBody(record, data, false)
+		}
+	}
+	record.User = currentOperator()
+	record.Outcome = audit.OutcomeSuccess
+	if err != nil {
+		record.Outcome = audit.OutcomeFailure
+	}
+	record.LatencyMilliseconds = time.Since(start).Milliseconds()
+	return record
+}
+
+// auditDatastore returns the namespace of the kubeapi datastore of the api server
+func auditDatastore() string {
+	if ns := os.Getenv(AuditDatastoreEnvName); ns != "" {
+		return ns
+	}
+	return defaultAuditDatastore
+}
+
+// recordOperation persists the record to the datastore of the api server, the record is skipped if the datastore
+// namespace doesn't exist. The failures never fail the operation.
+func recordOperation(cmd *cobra.Command, c common.Args, record *model.AuditRecord) {
+	ctx := context.Background()
+	k8sClient, err := c.GetClient()
+	if err != nil {
+		return
+	}
+	if err := k8sClient.Get(ctx, ktypes.NamespacedName{Name: auditDatastore()}, &corev1.Namespace{}); err != nil {
+		return
+	}
+	if err := writeOperationRecord(ctx, k8sClient, record); err != nil {
+		cmd.PrintErrf("Warning: failed to record the audit record of %q: %s\n", record.Path, err.Error())
+	}
+}
+
+func writeOperationRecord(ctx context.Context, k8sClient client.Client, record *model.AuditRecord) error {
+	ds, err := kubeapi.New(ctx, datastore.Config{Type: "kubeapi", Database: auditDatastore()}, k8sClient)
+	if err != nil {
+		return err
+	}
+	return ds.Add(ctx, model.AuditRecordKind, record)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore/kubeapi"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/audit"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestOperationRecord(t *testing.T) {
+	root := &cobra.Command{Use: "vela"}
+	addon := &cobra.Command{Use: "addon"}
+	enable := &cobra.Command{Use: "enable"}
+	root.AddCommand(addon)
+	addon.AddCommand(enable)
+
+	record := newOperationRecord(enable, []string{"fluxcd"}, time.Now(), errors.New("failed"))
+	assert.Equal(t, AuditMethodCLI, record.Method)
+	assert.Equal(t, "vela addon enable", record.Path)
+	assert.Equal(t, "addon enable", record.ResourceType)
+	assert.Equal(t, "fluxcd", record.ResourceName)
+	assert.Equal(t, audit.OutcomeFailure, record.Outcome)
+	assert.NotEmpty(t, record.RequestBodyHash)
+
+	assert.Equal(t, currentOperator(), record.User)
+
+	require.NoError(t, os.Setenv(AuditDatastoreEnvName, "vela-system"))
+	defer os.Unsetenv(AuditDatastoreEnvName)
+	ctx := context.Background()
+	k8sClient := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	require.NoError(t, writeOperationRecord(ctx, k8sClient, record))
+	ds, err := kubeapi.New(ctx, datastore.Config{Type: "kubeapi", Database: "vela-system"}, k8sClient)
+	require.NoError(t, err)
+	stored := &model.AuditRecord{}
+	require.NoError(t, ds.Get(ctx, model.AuditRecordKind, record.Name, stored))
+	assert.Equal(t, record.Path, stored.Path)
+}
@@ -83,6 +83,10 @@ func NewCommand() *cobra.Command {
 		NewListCommand(commandArgs, ioStream),
 		NewDeleteCommand(commandArgs, ioStream),
 		NewAppStatusCommand(commandArgs, ioStream),
+		NewPauseCommand(commandArgs, ioStream),
+		NewUnpauseCommand(commandArgs, ioStream),
+		NewRollbackCommand(commandArgs, ioStream),
+		NewRevisionCommand(commandArgs, ioStream),
 		NewExecCommand(commandArgs, ioStream),
 		NewPortForwardCommand(commandArgs, ioStream),
 		NewLogsCommand(commandArgs, ioStream),
@@ -121,6 +125,8 @@ func NewCommand() *cobra.Command {
 		NewHelpCommand(),
 	)
 
+	auditCommands(cmds, commandArgs)
+
 	// this is for mute klog
 	fset := flag.NewFlagSet("logs", flag.ContinueOnError)
 	klog.InitFlags(fset)
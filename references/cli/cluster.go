@@ -24,15 +24,12 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
-	v13 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
-	errors2 "k8s.io/apimachinery/pkg/api/errors"
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types2 "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
-	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam"
@@ -96,12 +93,12 @@ func NewClusterListCommand(c *common.Args) *cobra.Command {
 		Long:    "list child clusters managed by KubeVela",
 		Args:    cobra.ExactValidArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			secrets := v1.SecretList{}
-			if err := c.Client.List(context.Background(), &secrets, client.HasLabels{v1alpha12.LabelKeyClusterCredentialType}, client.InNamespace(multicluster.ClusterGatewaySecretNamespace)); err != nil {
-				return errors.Wrapf(err, "failed to get cluster secrets")
+			secrets, err := multicluster.ListClusterSecrets(context.Background(), c.Client)
+			if err != nil {
+				return err
 			}
 			table := newUITable().AddRow("CLUSTER", "TYPE", "ENDPOINT")
-			for _, secret := range secrets.Items {
+			for _, secret := range secrets {
 				table.AddRow(secret.Name, secret.GetLabels()[v1alpha12.LabelKeyClusterCredentialType], string(secret.Data["endpoint"]))
 			}
 			if len(table.Rows) == 1 {
@@ -115,42 +112,6 @@ func NewClusterListCommand(c *common.Args) *cobra.Command {
 	return cmd
 }
 
-func ensureClusterNotExists(c client.Client, clusterName string) error {
-	secret := &v1.Secret{}
-	err := c.Get(context.Background(), types2.NamespacedName{Name: clusterName, Namespace: multicluster.ClusterGatewaySecretNamespace}, secret)
-	if err == nil {
-		return fmt.Errorf("cluster %s already exists", clusterName)
-	}
-	if !errors2.IsNotFound(err) {
-		return errors.Wrapf(err, "failed to check duplicate cluster secret")
-	}
-	return nil
-}
-
-func ensureResourceTrackerCRDInstalled(c client.Client, clusterName string) error {
-	ctx := context.Background()
-	remoteCtx := multicluster.ContextWithClusterName(ctx, clusterName)
-	crdName := types2.NamespacedName{Name: "resourcetrackers." + v1beta1.Group}
-	if err := c.Get(remoteCtx, crdName, &v13.CustomResourceDefinition{}); err != nil {
-		if !errors2.IsNotFound(err) {
-			return errors.Wrapf(err, "failed to check resourcetracker crd in cluster %s", clusterName)
-		}
-		crd := &v13.CustomResourceDefinition{}
-		if err = c.Get(ctx, crdName, crd); err != nil {
-			return errors.Wrapf(err, "failed to get resourcetracker crd in hub cluster")
-		}
-		crd.ObjectMeta = v12.ObjectMeta{
-			Name:        crdName.Name,
-			Annotations: crd.Annotations,
-			Labels:      crd.Labels,
-		}
-		if err = c.Create(remoteCtx, crd); err != nil {
-			return errors.Wrapf(err, "failed to create resourcetracker crd in cluster %s", clusterName)
-		}
-	}
-	return nil
-}
-
 // NewClusterJoinCommand create command to help user join cluster to multicluster management
 func NewClusterJoinCommand(c *common.Args) *cobra.Command {
 	cmd := &cobra.Command{
@@ -165,69 +126,17 @@ func NewClusterJoinCommand(c *common.Args) *cobra.Command {
 			if err != nil {
 				return errors.Wrapf(err, "failed to get kubeconfig")
 			}
-			if len(config.CurrentContext) == 0 {
-				return fmt.Errorf("current-context is not set")
-			}
-			ctx, ok := config.Contexts[config.CurrentContext]
-			if !ok {
-				return fmt.Errorf("current-context %s not found", config.CurrentContext)
-			}
-			cluster, ok := config.Clusters[ctx.Cluster]
-			if !ok {
-				return fmt.Errorf("cluster %s not found", ctx.Cluster)
-			}
-			authInfo, ok := config.AuthInfos[ctx.AuthInfo]
-			if !ok {
-				return fmt.Errorf("authInfo %s not found", ctx.AuthInfo)
-			}
 
 			// get ClusterName from flag or config
 			clusterName, err := cmd.Flags().GetString(FlagClusterName)
 			if err != nil {
 				return errors.Wrapf(err, "failed to get cluster name flag")
 			}
-			if clusterName == "" {
-				clusterName = ctx.Cluster
-			}
-			if clusterName == multicluster.ClusterLocalName {
-				return fmt.Errorf("cannot use `%s` as cluster name, it is reserved as the local cluster", multicluster.ClusterLocalName)
-			}
-
-			if err := ensureClusterNotExists(c.Client, clusterName); err != nil {
-				return errors.Wrapf(err, "cannot use cluster name %s", clusterName)
-			}
-			var credentialType v1alpha12.CredentialType
-			data := map[string][]byte{
-				"endpoint": []byte(cluster.Server),
-				"ca.crt":   cluster.CertificateAuthorityData,
-			}
-			if len(authInfo.Token) > 0 {
-				credentialType = v1alpha12.CredentialTypeServiceAccountToken
-				data["token"] = []byte(authInfo.Token)
-			} else {
-				credentialType = v1alpha12.CredentialTypeX509Certificate
-				data["tls.crt"] = authInfo.ClientCertificateData
-				data["tls.key"] = authInfo.ClientKeyData
-			}
-			secret := &v1.Secret{
-				ObjectMeta: v12.ObjectMeta{
-					Name:      clusterName,
-					Namespace: multicluster.ClusterGatewaySecretNamespace,
-					Labels: map[string]string{
-						v1alpha12.LabelKeyClusterCredentialType: string(credentialType),
-					},
-				},
-				Type: v1.SecretTypeOpaque,
-				Data: data,
-			}
-			if err := c.Client.Create(context.Background(), secret); err != nil {
-				return errors.Wrapf(err, "failed to add cluster to kubernetes")
-			}
-			if err := ensureResourceTrackerCRDInstalled(c.Client, clusterName); err != nil {
-				_ = c.Client.Delete(context.Background(), secret)
-				return errors.Wrapf(err, "failed to ensure resourcetracker crd installed in cluster %s", clusterName)
+			secret, err := multicluster.JoinClusterByKubeConfig(context.Background(), c.Client, config, clusterName)
+			if err != nil {
+				return err
 			}
-			cmd.Printf("Successfully add cluster %s, endpoint: %s.\n", clusterName, cluster.Server)
+			cmd.Printf("Successfully add cluster %s, endpoint: %s.\n", secret.Name, string(secret.Data["endpoint"]))
 			return nil
 		},
 	}
@@ -278,7 +187,7 @@ func NewClusterRenameCommand(c *common.Args) *cobra.Command {
 			if err != nil {
 				return errors.Wrapf(err, "cluster %s is not mutable now", oldClusterName)
 			}
-			if err := ensureClusterNotExists(c.Client, newClusterName); err != nil {
+			if err := multicluster.EnsureClusterNotExists(context.Background(), c.Client, newClusterName); err != nil {
 				return errors.Wrapf(err, "cannot set cluster name to %s", newClusterName)
 			}
 			if err := c.Client.Delete(context.Background(), clusterSecret); err != nil {
@@ -40,6 +40,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
 	"github.com/oam-dev/kubevela/pkg/cue/model"
 	"github.com/oam-dev/kubevela/pkg/cue/model/sets"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
@@ -67,6 +68,14 @@ const (
 	HelmChartNamespacePlaceholder = "###HELM_NAMESPACE###"
 	// HelmChartFormatEnvName is the name of the environment variable to enable render helm chart format YAML
 	HelmChartFormatEnvName = "AS_HELM_CHART"
+	// FlagStrict command flag to treat warnings as errors
+	FlagStrict = "strict"
+	// FlagJUnitReport command flag to specify the file to write the JUnit report
+	FlagJUnitReport = "junit-report"
+	// FlagFromRevision command flag to specify the definition revision to compare from
+	FlagFromRevision = "from"
+	// FlagToRevision command flag to specify the definition revision to compare to
+	FlagToRevision = "to"
 )
 
 // DefinitionCommandGroup create the command group for `vela def` command to manage definitions
@@ -89,6 +98,8 @@ func DefinitionCommandGroup(c common.Args) *cobra.Command {
 		NewDefinitionDelCommand(c),
 		NewDefinitionInitCommand(c),
 		NewDefinitionValidateCommand(c),
+		NewDefinitionTestCommand(c),
+		NewDefinitionDiffSchemaCommand(c),
 	)
 	return cmd
 }
@@ -731,26 +742,229 @@ func NewDefinitionDelCommand(c common.Args) *cobra.Command {
 
 // NewDefinitionValidateCommand create the `vela def vet` command to help user validate the definition
 func NewDefinitionValidateCommand(c common.Args) *cobra.Command {
+	var rules []string
+	for _, rule := range common2.LintRules {
+		rules = append(rules, fmt.Sprintf("* %s (%s): %s", rule.ID, rule.Severity, rule.Description))
+	}
 	cmd := &cobra.Command{
 		Use:   "vet DEFINITION.cue",
 		Short: "Validate definition",
-		Long: "Validate definition file by checking whether it has the valid cue format with fields set correctly\n" +
-			"* Currently, this command only checks the cue format. This function is still working in progress and we will support more functional validation mechanism in the future.",
+		Long: "Validate definition file by checking whether it has the valid cue format with fields set correctly, then lint it with the rules below\n" +
+			strings.Join(rules, "\n") + "\n" +
+			"Issues can be suppressed by a comment on the same line or the line before, e.g. // " + common2.LintIgnoreTag + "unused-parameter,parameter-usage. " +
+			"The validation fails if there is any error, or any warning in the strict mode.",
 		Example: "# Command below will validate the my-def.cue file.\n" +
-			"> vela def vet my-def.cue",
+			"> vela def vet my-def.cue\n" +
+			"# Command below will fail the validation if there is any warning.\n" +
+			"> vela def vet my-def.cue --strict",
 		Args: cobra.ExactValidArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			strict, err := cmd.Flags().GetBool(FlagStrict)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get `%s`", FlagStrict)
+			}
 			cueBytes, err := os.ReadFile(args[0])
 			if err != nil {
 				return errors.Wrapf(err, "failed to read %s", args[0])
 			}
-			def := common2.Definition{Unstructured: unstructured.Unstructured{}}
-			if err := def.FromCUEString(string(cueBytes), c.Config); err != nil {
+			issues, err := common2.LintDefinition(string(cueBytes), c.Config)
+			if err != nil {
 				return errors.Wrapf(err, "failed to parse CUE")
 			}
+			failed := 0
+			for _, issue := range issues {
+				cmd.Printf("%s:%s\n", args[0], issue)
+				if issue.Severity == common2.LintSeverityError || strict {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return errors.Errorf("validation failed with %d issue(s)", failed)
+			}
 			cmd.Println("Validation succeed.")
 			return nil
 		},
 	}
+	cmd.Flags().BoolP(FlagStrict, "", false, "Fail the validation on warnings as well as errors.")
+	return cmd
+}
+
+// findDefinitionTestFiles returns the test files of the definition cue file, or all the test files in the directory
+func findDefinitionTestFiles(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get input %s", path)
+	}
+	if !fi.IsDir() {
+		if strings.HasSuffix(path, common2.DefinitionTestFileSuffix) {
+			return []string{path}, nil
+		}
+		testFile := strings.TrimSuffix(path, ".cue") + common2.DefinitionTestFileSuffix
+		if _, err := os.Stat(testFile); err != nil {
+			return nil, errors.Wrapf(err, "failed to find the test file of %s", path)
+		}
+		return []string{testFile}, nil
+	}
+	var testFiles []string
+	dir, err := os.ReadDir(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read directory %s", path)
+	}
+	for _, file := range dir {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), common2.DefinitionTestFileSuffix) {
+			testFiles = append(testFiles, filepath.Join(path, file.Name()))
+		}
+	}
+	return testFiles, nil
+}
+
+// NewDefinitionTestCommand create the `vela def test` command to help user test the definition offline
+func NewDefinitionTestCommand(c common.Args) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test DEFINITION.cue",
+		Short: "Test definition",
+		Long: "Test ComponentDefinition or TraitDefinition offline with the table-driven test file placed next to it, e.g. my-def_test.yaml for my-def.cue.\n" +
+			"Each test case renders the definition with the given parameter, context and base workload, then checks the expected output, outputs, patch or error. " +
+			"Only the fields set in the expected results are checked. If a directory is used as input, all test files in the directory will be run.",
+		Example: "# Command below will run the test cases in my-webservice_test.yaml against my-webservice.cue.\n" +
+			"> vela def test my-webservice.cue\n" +
+			"# Command below will run all the test files in the ./defs/cue/ directory and write the JUnit report to report.xml.\n" +
+			"> vela def test ./defs/cue/ --junit-report report.xml",
+		Args: cobra.ExactValidArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			junitReport, err := cmd.Flags().GetString(FlagJUnitReport)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get `%s`", FlagJUnitReport)
+			}
+			testFiles, err := findDefinitionTestFiles(args[0])
+			if err != nil {
+				return err
+			}
+			var results []*common2.DefinitionTestSuiteResult
+			total, failed := 0, 0
+			for _, testFile := range testFiles {
+				suite, defFile, err := common2.LoadDefinitionTestSuite(testFile)
+				if err != nil {
+					return err
+				}
+				result, err := common2.RunDefinitionTests(defFile, suite)
+				if err != nil {
+					return err
+				}
+				results = append(results, result)
+				cmd.Printf("--- %s ---\n", filepath.Base(defFile))
+				for _, r := range result.Results {
+					total++
+					if len(r.Failures) == 0 {
+						cmd.Printf("PASS %s\n", r.Name)
+						continue
+					}
+					failed++
+					cmd.Printf("FAIL %s\n", r.Name)
+					for _, failure := range r.Failures {
+						cmd.Printf("    %s\n", failure)
+					}
+				}
+			}
+			if junitReport != "" {
+				var b bytes.Buffer
+				if err := common2.WriteJUnitReport(&b, results); err != nil {
+					return errors.Wrapf(err, "failed to generate the JUnit report")
+				}
+				if err := os.WriteFile(junitReport, b.Bytes(), 0600); err != nil {
+					return errors.Wrapf(err, "failed to write the JUnit report to file %s", junitReport)
+				}
+			}
+			if failed > 0 {
+				return errors.Errorf("%d of %d test cases failed", failed, total)
+			}
+			cmd.Printf("All %d test cases passed.\n", total)
+			return nil
+		},
+	}
+	cmd.Flags().StringP(FlagJUnitReport, "", "", "Specify the file to write the test results in JUnit XML format.")
+	return cmd
+}
+
+// NewDefinitionDiffSchemaCommand create the `vela def diff-schema` command to help user find the breaking changes of
+// the definition parameter
+func NewDefinitionDiffSchemaCommand(c common.Args) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff-schema OLD.cue NEW.cue | diff-schema NAME --from REVISION [--to REVISION]",
+		Short: "Compare the parameter schemas of definitions",
+		Long: "Compare the parameter schemas of two ComponentDefinition or TraitDefinition cue files, or two revisions of the definition in the cluster, " +
+			"and classify the changes as breaking or non-breaking. Breaking changes are required-field-added, field-required, field-removed, " +
+			"type-narrowed and default-changed. The command fails if there is any breaking change.",
+		Example: "# Command below will compare the parameter of my-trait-v2.cue with my-trait-v1.cue.\n" +
+			"> vela def diff-schema my-trait-v1.cue my-trait-v2.cue\n" +
+			"# Command below will compare the revision 1 and 2 of the trait scaler in the vela-system namespace.\n" +
+			"> vela def diff-schema scaler --from 1 --to 2\n" +
+			"# Command below will compare the revision 1 of webservice in the default namespace with its latest revision.\n" +
+			"> vela def diff-schema webservice --from 1 -n default",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var oldSchema, newSchema []byte
+			var err error
+			if len(args) == 2 {
+				if oldSchema, err = common2.GetDefinitionSchemaFromFile(context.Background(), args[0]); err != nil {
+					return err
+				}
+				if newSchema, err = common2.GetDefinitionSchemaFromFile(context.Background(), args[1]); err != nil {
+					return err
+				}
+			} else {
+				from, err := cmd.Flags().GetInt64(FlagFromRevision)
+				if err != nil {
+					return errors.Wrapf(err, "failed to get `%s`", FlagFromRevision)
+				}
+				to, err := cmd.Flags().GetInt64(FlagToRevision)
+				if err != nil {
+					return errors.Wrapf(err, "failed to get `%s`", FlagToRevision)
+				}
+				if from <= 0 {
+					return errors.Errorf("the revision to compare from must be specified by --%s", FlagFromRevision)
+				}
+				namespace, err := cmd.Flags().GetString(FlagNamespace)
+				if err != nil {
+					return errors.Wrapf(err, "failed to get `%s`", FlagNamespace)
+				}
+				k8sClient, err := c.GetClient()
+				if err != nil {
+					return errors.Wrapf(err, "failed to get k8s client")
+				}
+				if oldSchema, err = common2.GetDefinitionRevisionSchema(context.Background(), k8sClient, namespace, args[0], from); err != nil {
+					return err
+				}
+				if newSchema, err = common2.GetDefinitionRevisionSchema(context.Background(), k8sClient, namespace, args[0], to); err != nil {
+					return err
+				}
+			}
+			changes, err := utils.DiffOpenAPISchema(oldSchema, newSchema)
+			if err != nil {
+				return err
+			}
+			if len(changes) == 0 {
+				cmd.Println("No changes found.")
+				return nil
+			}
+			breaking := 0
+			for _, change := range changes {
+				level := "non-breaking"
+				if change.Breaking {
+					level = "breaking"
+					breaking++
+				}
+				cmd.Printf("%s %s\n", level, change)
+			}
+			if breaking > 0 {
+				return errors.Errorf("found %d breaking change(s)", breaking)
+			}
+			cmd.Println("No breaking changes found.")
+			return nil
+		},
+	}
+	cmd.Flags().Int64P(FlagFromRevision, "", 0, "Specify the definition revision to compare from.")
+	cmd.Flags().Int64P(FlagToRevision, "", 0, "Specify the definition revision to compare to. If empty, the latest revision will be used.")
+	cmd.Flags().StringP(FlagNamespace, "n", types.DefaultKubeVelaNS, "Specify which namespace the definition locates.")
 	return cmd
 }
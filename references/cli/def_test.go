@@ -17,6 +17,7 @@ limitations under the License.
 package cli
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -27,13 +28,16 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	common3 "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	types2 "github.com/oam-dev/kubevela/apis/types"
 	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
 	"github.com/oam-dev/kubevela/references/common"
 )
@@ -363,6 +367,12 @@ func TestNewDefinitionVetCommand(t *testing.T) {
 	if err := cmd.Execute(); err != nil {
 		t.Fatalf("unexpeced error when executing vet command: %v", err)
 	}
+	// the parameter replicas is not used by the template
+	cmd.SetArgs([]string{traitFilename, "--strict"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expect validation failed on warnings but error not found")
+	}
+	cmd.SetArgs([]string{traitFilename, "--strict=false"})
 	bs, err := os.ReadFile(traitFilename)
 	if err != nil {
 		t.Fatalf("failed to read trait file %s: %v", traitFilename, err)
@@ -375,3 +385,110 @@ func TestNewDefinitionVetCommand(t *testing.T) {
 		t.Fatalf("expect validation failed but error not found")
 	}
 }
+
+func TestNewDefinitionTestCommand(t *testing.T) {
+	c := initArgs()
+	cmd := NewDefinitionTestCommand(c)
+	initCommand(cmd)
+	dirname := createLocalTraits(t)
+	defer removeDir(dirname, t)
+	testFile := filepath.Join(dirname, "trait-0"+common.DefinitionTestFileSuffix)
+	s := `tests:
+- name: default-replicas
+  base: {apiVersion: apps/v1, kind: Deployment, spec: {}}
+  patch: {spec: {replicas: 1}}
+- name: patch-default-replicas
+  base: {apiVersion: apps/v1, kind: Deployment, spec: {}}
+  parameter: {replicas: 3}
+  patch: {spec: {replicas: 1}}
+- name: invalid-replicas
+  parameter: {replicas: "3"}
+  error: "conflicting values"
+`
+	if err := os.WriteFile(testFile, []byte(s), 0600); err != nil {
+		t.Fatalf("failed to write test file %s: %v", testFile, err)
+	}
+	report := filepath.Join(dirname, "report.xml")
+	cmd.SetArgs([]string{filepath.Join(dirname, "trait-0.cue"), "--junit-report", report})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpeced error when executing test command: %v", err)
+	}
+	if _, err := os.Stat(report); err != nil {
+		t.Fatalf("failed to write the JUnit report: %v", err)
+	}
+
+	s = strings.ReplaceAll(s, "patch: {spec: {replicas: 1}}", "patch: {spec: {replicas: 2}}")
+	if err := os.WriteFile(testFile, []byte(s), 0600); err != nil {
+		t.Fatalf("failed to write test file %s: %v", testFile, err)
+	}
+	cmd.SetArgs([]string{dirname})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expect test failed but error not found")
+	}
+	// no test file for trait-1
+	cmd.SetArgs([]string{filepath.Join(dirname, "trait-1.cue")})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expect test file not found but error not found")
+	}
+}
+
+func TestNewDefinitionDiffSchemaCommand(t *testing.T) {
+	c := initArgs()
+	cmd := NewDefinitionDiffSchemaCommand(c)
+	initCommand(cmd)
+	dirname := createLocalTraits(t)
+	defer removeDir(dirname, t)
+	oldFile, newFile := filepath.Join(dirname, "trait-0.cue"), filepath.Join(dirname, "trait-1.cue")
+	cmd.SetArgs([]string{oldFile, newFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpeced error when executing diff-schema command: %v", err)
+	}
+
+	s, err := os.ReadFile(newFile)
+	if err != nil {
+		t.Fatalf("failed to read trait file %s: %v", newFile, err)
+	}
+	// change the default replicas and add a required zone
+	s = bytes.ReplaceAll(s, []byte("replicas: *1 | int\n        }\n}"), []byte("replicas: *2 | int\n                zone: string\n        }\n}"))
+	if err = os.WriteFile(newFile, s, 0600); err != nil {
+		t.Fatalf("failed to write trait file %s: %v", newFile, err)
+	}
+	buffer := bytes.NewBuffer(nil)
+	cmd.SetOut(buffer)
+	cmd.SetArgs([]string{oldFile, newFile})
+	if err = cmd.Execute(); err == nil {
+		t.Fatalf("expect breaking changes found but error not found")
+	}
+	for _, change := range []string{"breaking [default-changed] replicas", "breaking [required-field-added] zone"} {
+		if !strings.Contains(buffer.String(), change) {
+			t.Fatalf("expect change %q found in output: %s", change, buffer.String())
+		}
+	}
+
+	// compare the schemas of the definition revisions in the cluster, the config is set to use the fake client
+	c.Config = &rest.Config{}
+	cmd = NewDefinitionDiffSchemaCommand(c)
+	initCommand(cmd)
+	for name, schema := range map[string]string{
+		"schema-scaler-v1": `{"properties":{"replicas":{"default":1,"type":"integer"}},"required":["replicas"],"type":"object"}`,
+		"schema-scaler-v2": `{"properties":{"replicas":{"default":1,"type":"integer"},"zone":{"type":"string"}},"required":["replicas"],"type":"object"}`,
+	} {
+		if err = c.Client.Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: VelaTestNamespace},
+			Data:       map[string]string{types2.OpenapiV3JSONSchema: schema},
+		}); err != nil {
+			t.Fatalf("failed to create schema configmap: %v", err)
+		}
+	}
+	cmd.SetArgs([]string{"scaler", "--from", "1", "--to", "2", "-n", VelaTestNamespace})
+	if err = cmd.Execute(); err != nil {
+		t.Fatalf("unexpeced error when executing diff-schema command: %v", err)
+	}
+	// the latest revision is not found
+	cmd = NewDefinitionDiffSchemaCommand(c)
+	initCommand(cmd)
+	cmd.SetArgs([]string{"scaler", "--from", "1", "-n", VelaTestNamespace})
+	if err = cmd.Execute(); err == nil {
+		t.Fatalf("expect schema not found but error not found")
+	}
+}
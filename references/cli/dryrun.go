@@ -30,6 +30,8 @@ import (
 	"sigs.k8s.io/yaml"
 
 	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/appfile/helm"
+	"github.com/oam-dev/kubevela/pkg/appfile/kustomize"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
@@ -76,6 +78,9 @@ func NewDryRunCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 			if err != nil {
 				return err
 			}
+			// the kustomizations and charts are rendered on the user's own machine, so they can be loaded from local paths
+			kustomize.LocalPathAllowed = true
+			helm.LocalChartAllowed = true
 			buff, err := DryRunApplication(o, c, velaEnv.Namespace)
 			if err != nil {
 				return err
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/appfile"
+)
+
+// NewPauseCommand create `pause` command
+func NewPauseCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	var reason, by string
+	cmd := &cobra.Command{
+		Use:   "pause APP_NAME",
+		Short: "Pause the reconciliation of an application",
+		Long: "Pause the reconciliation of an application, KubeVela stops applying resources, running workflow and " +
+			"garbage collection of the application until it's unpaused, but the health status is still updated.",
+		Example: `vela pause my-app --reason "incident #42"`,
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify application name")
+			}
+			env, err := GetFlagEnvOrCurrent(cmd, c)
+			if err != nil {
+				return err
+			}
+			app, err := appfile.LoadApplication(env.Namespace, args[0], c)
+			if err != nil {
+				return err
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			if by == "" {
+				by = currentOperator()
+			}
+			if err := pauseApplication(kubecli, app, by, reason); err != nil {
+				return err
+			}
+			ioStreams.Infof("Successfully paused application: %s\n", app.Name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&reason, "reason", "r", "", "the reason why the application is paused")
+	cmd.Flags().StringVar(&by, "by", "", "who paused the application, the user of the current kubeconfig context by default")
+	return cmd
+}
+
+// NewUnpauseCommand create `unpause` command
+func NewUnpauseCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:     "unpause APP_NAME",
+		Short:   "Resume the reconciliation of a paused application",
+		Long:    "Resume the reconciliation of a paused application.",
+		Example: "vela unpause my-app",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify application name")
+			}
+			env, err := GetFlagEnvOrCurrent(cmd, c)
+			if err != nil {
+				return err
+			}
+			app, err := appfile.LoadApplication(env.Namespace, args[0], c)
+			if err != nil {
+				return err
+			}
+			if app.Spec.Pause == nil {
+				ioStreams.Infof("the application is not paused\n")
+				return nil
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			if err := unpauseApplication(kubecli, app); err != nil {
+				return err
+			}
+			ioStreams.Infof("Successfully unpaused application: %s\n", app.Name)
+			return nil
+		},
+	}
+}
+
+func pauseApplication(kubecli client.Client, app *v1beta1.Application, by, reason string) error {
+	patch := client.MergeFrom(app.DeepCopy())
+	now := metav1.Now()
+	app.Spec.Pause = &v1beta1.ApplicationPause{By: by, Reason: reason, Since: &now}
+	return kubecli.Patch(context.TODO(), app, patch)
+}
+
+func unpauseApplication(kubecli client.Client, app *v1beta1.Application) error {
+	patch := client.MergeFrom(app.DeepCopy())
+	app.Spec.Pause = nil
+	return kubecli.Patch(context.TODO(), app, patch)
+}
+
+// currentOperator returns the user of the current kubeconfig context, or the user of the OS if it's unknown
+func currentOperator() string {
+	rawConfig, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+	if err == nil {
+		if ctx, ok := rawConfig.Contexts[rawConfig.CurrentContext]; ok && ctx.AuthInfo != "" {
+			return ctx.AuthInfo
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+)
+
+func TestPauseAndUnpause(t *testing.T) {
+	c := initArgs()
+	ioStream := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	ctx := context.TODO()
+	r := require.New(t)
+
+	cmd := NewPauseCommand(c, ioStream)
+	initCommand(cmd)
+	r.Equal(fmt.Errorf("must specify application name"), cmd.Execute())
+
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pause-app",
+			Namespace:   "default",
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: workflowSpec,
+	}
+	r.NoError(c.Client.Create(ctx, app))
+
+	cmd = NewPauseCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{app.Name, "--reason", "incident", "--by", "alice"})
+	r.NoError(cmd.Execute())
+
+	paused := &v1beta1.Application{}
+	r.NoError(c.Client.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, paused))
+	r.NotNil(paused.Spec.Pause)
+	r.Equal("alice", paused.Spec.Pause.By)
+	r.Equal("incident", paused.Spec.Pause.Reason)
+	r.NotNil(paused.Spec.Pause.Since)
+	r.Equal(workflowSpec.Components, paused.Spec.Components)
+
+	cmd = NewUnpauseCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{app.Name})
+	r.NoError(cmd.Execute())
+
+	unpaused := &v1beta1.Application{}
+	r.NoError(c.Client.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, unpaused))
+	r.Nil(unpaused.Spec.Pause)
+	r.Equal(map[string]string{"foo": "bar"}, unpaused.Annotations)
+}
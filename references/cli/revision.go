@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/appfile"
+	"github.com/oam-dev/kubevela/references/appfile/dryrun"
+)
+
+// NewRevisionCommand create `revision` command
+func NewRevisionCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revision",
+		Short: "Manage the revisions of an application",
+		Long:  "Manage the revisions of an application",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+	}
+	cmd.AddCommand(NewRevisionDiffCommand(c, ioStreams))
+	return cmd
+}
+
+// NewRevisionDiffCommand create `revision diff` command
+func NewRevisionDiffCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	var from, to string
+	var ctxLines int
+	cmd := &cobra.Command{
+		Use:   "diff APP_NAME",
+		Short: "Show the differences between two revisions of an application",
+		Long: "Show the differences between two revisions of an application, including the application spec and the " +
+			"component and trait manifests rendered with the definitions stored in each revision.",
+		Example: "vela revision diff my-app --from 3 --to 5",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify application name")
+			}
+			env, err := GetFlagEnvOrCurrent(cmd, c)
+			if err != nil {
+				return err
+			}
+			app, err := appfile.LoadApplication(env.Namespace, args[0], c)
+			if err != nil {
+				return err
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			dm, err := c.GetDiscoveryMapper()
+			if err != nil {
+				return err
+			}
+			pd, err := c.GetPackageDiscover()
+			if err != nil {
+				return err
+			}
+			buff, err := RevisionDiffApplication(dryrun.NewRevisionDiffOption(kubecli, dm, pd), app, from, to, ctxLines)
+			if err != nil {
+				return err
+			}
+			ioStreams.Info(buff.String())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "the revision number or name to compare from, the revision before --to by default")
+	cmd.Flags().StringVar(&to, "to", "", "the revision number or name to compare to, the latest revision by default")
+	cmd.Flags().IntVarP(&ctxLines, "context", "c", -1, "output number lines of context around changes, by default show all unchanged lines")
+	return cmd
+}
+
+// RevisionDiffApplication returns the diff report between two revisions of the application
+func RevisionDiffApplication(opt *dryrun.RevisionDiffOption, app *v1beta1.Application, from, to string, ctxLines int) (bytes.Buffer, error) {
+	var buff = bytes.Buffer{}
+	from, to, err := dryrun.RevisionDiffRange(app, from, to)
+	if err != nil {
+		return buff, err
+	}
+	ctx := context.Background()
+	fromRev, err := opt.GetRevision(ctx, app.Namespace, app.Name, from)
+	if err != nil {
+		return buff, err
+	}
+	toRev, err := opt.GetRevision(ctx, app.Namespace, app.Name, to)
+	if err != nil {
+		return buff, err
+	}
+	diffResult, err := opt.Diff(fromRev, toRev)
+	if err != nil {
+		return buff, errors.WithMessage(err, "cannot calculate diff")
+	}
+	dryrun.NewReportDiffOption(ctxLines, &buff).PrintDiffReport(diffResult)
+	return buff, nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/appfile"
+)
+
+// NewRollbackCommand create `rollback` command
+func NewRollbackCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	var revision string
+	cmd := &cobra.Command{
+		Use:   "rollback APP_NAME",
+		Short: "Rollback an application to a previous revision",
+		Long: "Rollback an application to a previous revision, the manifests and workflow stored in the revision are " +
+			"applied again. The application keeps rolled back until its spec is changed.",
+		Example: "vela rollback my-app --revision 2",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify application name")
+			}
+			env, err := GetFlagEnvOrCurrent(cmd, c)
+			if err != nil {
+				return err
+			}
+			app, err := appfile.LoadApplication(env.Namespace, args[0], c)
+			if err != nil {
+				return err
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			revName, err := getRollbackRevisionName(kubecli, app, revision)
+			if err != nil {
+				return err
+			}
+			if err := rollbackApplication(kubecli, app, revName); err != nil {
+				return err
+			}
+			ioStreams.Infof("Successfully rolled back application %s to revision %s\n", app.Name, revName)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&revision, "revision", "", "the revision number or name to roll back to, the previous revision by default")
+	return cmd
+}
+
+// getRollbackRevisionName returns the name of the revision to roll back to. The revision can be specified by its number
+// or name, otherwise the newest revision older than the one currently in use is chosen.
+func getRollbackRevisionName(kubecli client.Client, app *v1beta1.Application, revision string) (string, error) {
+	ctx := context.TODO()
+	if revision != "" {
+		revName := revision
+		if num, err := strconv.ParseInt(revision, 10, 64); err == nil {
+			revName = utils.ConstructRevisionName(app.Name, num)
+		}
+		appRev := &v1beta1.ApplicationRevision{}
+		if err := kubecli.Get(ctx, client.ObjectKey{Namespace: app.Namespace, Name: revName}, appRev); err != nil {
+			return "", errors.Wrapf(err, "cannot get application revision %q", revName)
+		}
+		if appRev.Labels[oam.LabelAppName] != app.Name {
+			return "", errors.Errorf("application revision %q does not belong to application %q", revName, app.Name)
+		}
+		return revName, nil
+	}
+
+	current := ""
+	if app.Status.Rollback != nil {
+		current = app.Status.Rollback.Revision
+	} else if app.Status.LatestRevision != nil {
+		current = app.Status.LatestRevision.Name
+	}
+	if current == "" {
+		return "", fmt.Errorf("the application %q has no revision in the cluster", app.Name)
+	}
+	currentNum, err := utils.ExtractRevision(current)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid revision name %q", current)
+	}
+	revList := &v1beta1.ApplicationRevisionList{}
+	if err := kubecli.List(ctx, revList, client.InNamespace(app.Namespace),
+		client.MatchingLabels{oam.LabelAppName: app.Name}); err != nil {
+		return "", errors.Wrap(err, "cannot list application revisions")
+	}
+	target, targetNum := "", 0
+	for _, rev := range revList.Items {
+		num, err := utils.ExtractRevision(rev.Name)
+		if err != nil {
+			continue
+		}
+		if num < currentNum && num > targetNum {
+			target, targetNum = rev.Name, num
+		}
+	}
+	if target == "" {
+		return "", fmt.Errorf("there is no revision older than %q to roll back to", current)
+	}
+	return target, nil
+}
+
+// rollbackApplication marks the application to be rolled back to the revision. The rollback is bound to the current
+// generation of the application, so that it ends once the spec is changed.
+func rollbackApplication(kubecli client.Client, app *v1beta1.Application, revName string) error {
+	patch := client.MergeFrom(app.DeepCopy())
+	annotations := app.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[oam.AnnotationAppRollbackRevision] = revName
+	annotations[oam.AnnotationAppRollbackGeneration] = strconv.FormatInt(app.Generation, 10)
+	app.SetAnnotations(annotations)
+	return kubecli.Patch(context.TODO(), app, patch)
+}
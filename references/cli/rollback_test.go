@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+)
+
+func TestRollback(t *testing.T) {
+	c := initArgs()
+	ioStream := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	ctx := context.TODO()
+	r := require.New(t)
+
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "rollback-app",
+			Namespace:  "default",
+			Generation: 3,
+		},
+		Spec: workflowSpec,
+		Status: common.AppStatus{
+			LatestRevision: &common.Revision{Name: "rollback-app-v3", Revision: 3},
+		},
+	}
+	r.NoError(c.Client.Create(ctx, app))
+	for _, name := range []string{"rollback-app-v1", "rollback-app-v2", "rollback-app-v3", "other-app-v2"} {
+		appName := "rollback-app"
+		if name == "other-app-v2" {
+			appName = "other-app"
+		}
+		r.NoError(c.Client.Create(ctx, &v1beta1.ApplicationRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{oam.LabelAppName: appName},
+			},
+		}))
+	}
+
+	// roll back to the previous revision by default
+	revName, err := getRollbackRevisionName(c.Client, app, "")
+	r.NoError(err)
+	r.Equal("rollback-app-v2", revName)
+
+	// the previous revision of the one rolled back to
+	app.Status.Rollback = &common.AppRollbackStatus{Revision: "rollback-app-v2", Generation: 3}
+	revName, err = getRollbackRevisionName(c.Client, app, "")
+	r.NoError(err)
+	r.Equal("rollback-app-v1", revName)
+
+	app.Status.Rollback = &common.AppRollbackStatus{Revision: "rollback-app-v1", Generation: 3}
+	_, err = getRollbackRevisionName(c.Client, app, "")
+	r.Error(err)
+
+	_, err = getRollbackRevisionName(c.Client, app, "5")
+	r.Error(err)
+	_, err = getRollbackRevisionName(c.Client, app, "other-app-v2")
+	r.Error(err)
+
+	cmd := NewRollbackCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{app.Name, "--revision", "1"})
+	r.NoError(cmd.Execute())
+
+	rolledBack := &v1beta1.Application{}
+	r.NoError(c.Client.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, rolledBack))
+	r.Equal("rollback-app-v1", rolledBack.Annotations[oam.AnnotationAppRollbackRevision])
+	r.Equal("3", rolledBack.Annotations[oam.AnnotationAppRollbackGeneration])
+}
@@ -0,0 +1,518 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/model"
+	"github.com/oam-dev/kubevela/pkg/cue/model/sets"
+)
+
+// LintSeverity is the severity level of a lint issue
+type LintSeverity string
+
+const (
+	// LintSeverityError means the definition doesn't work as expected
+	LintSeverityError LintSeverity = "error"
+	// LintSeverityWarning means the definition works but could be improved
+	LintSeverityWarning LintSeverity = "warning"
+)
+
+// LintIgnoreTag is the comment tag to suppress the lint issues on the line of the comment and the next line,
+// e.g. // +vet:ignore=unused-parameter,parameter-usage
+const LintIgnoreTag = "+vet:ignore="
+
+// LintIssue is an issue found by a lint rule
+type LintIssue struct {
+	RuleID   string
+	Severity LintSeverity
+	Line     int
+	Message  string
+}
+
+// String returns the issue in the format of LINE: [SEVERITY] RULE: MESSAGE
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%d: [%s] %s: %s", i.Line, i.Severity, i.RuleID, i.Message)
+}
+
+// LintRule checks the definition and reports the issues found
+type LintRule struct {
+	ID          string
+	Severity    LintSeverity
+	Description string
+	check       func(lc *lintContext) []lintFinding
+}
+
+type lintFinding struct {
+	pos     token.Pos
+	message string
+}
+
+// lintContext is the definition to lint, the template is the AST parsed from the original cue file so that the
+// positions of the findings match the file
+type lintContext struct {
+	def      *Definition
+	template *ast.StructLit
+}
+
+// LintRules are the rules to lint definitions
+var LintRules = []LintRule{
+	{
+		ID:          "parameter-usage",
+		Severity:    LintSeverityWarning,
+		Description: "parameter fields should have descriptions or +usage comments",
+		check:       checkParameterUsage,
+	},
+	{
+		ID:          "unused-parameter",
+		Severity:    LintSeverityWarning,
+		Description: "parameter fields should be used by the template",
+		check:       checkUnusedParameter,
+	},
+	{
+		ID:          "output-gvk",
+		Severity:    LintSeverityError,
+		Description: "resources in output and outputs must have apiVersion and kind",
+		check:       checkOutputGVK,
+	},
+	{
+		ID:          "context-output-field",
+		Severity:    LintSeverityError,
+		Description: "traits should only reference the context.output fields of the workloads they apply to",
+		check:       checkContextOutputField,
+	},
+	{
+		ID:          "health-policy",
+		Severity:    LintSeverityWarning,
+		Description: "ComponentDefinitions producing a Deployment should have a health policy",
+		check:       checkHealthPolicy,
+	},
+}
+
+// LintDefinition validates the definition in cue format and then checks it with the lint rules, the issues are sorted
+// by line. Issues can be suppressed by the LintIgnoreTag comment.
+func LintDefinition(cueString string, config *rest.Config) ([]LintIssue, error) {
+	def := &Definition{Unstructured: unstructured.Unstructured{}}
+	if err := def.FromCUEString(cueString, config); err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseFile("-", cueString, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	lc := &lintContext{def: def, template: &ast.StructLit{}}
+	if field := lookupField(f.Decls, "template"); field != nil {
+		if st, ok := field.Value.(*ast.StructLit); ok {
+			lc.template = st
+		}
+	}
+
+	ignored := findLintIgnoredRules(cueString)
+	var issues []LintIssue
+	for _, rule := range LintRules {
+		for _, finding := range rule.check(lc) {
+			line := finding.pos.Line()
+			if ignored[line][rule.ID] {
+				continue
+			}
+			issues = append(issues, LintIssue{RuleID: rule.ID, Severity: rule.Severity, Line: line, Message: finding.message})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+// findLintIgnoredRules returns the rules ignored by each line
+func findLintIgnoredRules(cueString string) map[int]map[string]bool {
+	ignored := map[int]map[string]bool{}
+	for i, line := range strings.Split(cueString, "\n") {
+		idx := strings.Index(line, LintIgnoreTag)
+		if idx < 0 || !strings.Contains(line[:idx], "//") {
+			continue
+		}
+		fields := strings.Fields(line[idx+len(LintIgnoreTag):])
+		if len(fields) == 0 {
+			continue
+		}
+		// the comment applies to its own line and the next line
+		for _, lineNum := range []int{i + 1, i + 2} {
+			if ignored[lineNum] == nil {
+				ignored[lineNum] = map[string]bool{}
+			}
+			for _, id := range strings.Split(fields[0], ",") {
+				ignored[lineNum][id] = true
+			}
+		}
+	}
+	return ignored
+}
+
+func fieldLabel(field *ast.Field) string {
+	switch l := field.Label.(type) {
+	case *ast.Ident:
+		return l.Name
+	case *ast.BasicLit:
+		if s, err := strconv.Unquote(l.Value); err == nil {
+			return s
+		}
+		return l.Value
+	default:
+		return ""
+	}
+}
+
+func lookupField(decls []ast.Decl, label string) *ast.Field {
+	for _, decl := range decls {
+		if field, ok := decl.(*ast.Field); ok && fieldLabel(field) == label {
+			return field
+		}
+	}
+	return nil
+}
+
+// structFields returns the fields of the struct, including the ones in the conditional comprehensions
+func structFields(expr ast.Expr) []*ast.Field {
+	st, ok := expr.(*ast.StructLit)
+	if !ok {
+		if call, isCall := expr.(*ast.CallExpr); isCall && len(call.Args) == 1 {
+			if fun, isIdent := call.Fun.(*ast.Ident); isIdent && fun.Name == "close" {
+				return structFields(call.Args[0])
+			}
+		}
+		return nil
+	}
+	var fields []*ast.Field
+	for _, elt := range st.Elts {
+		switch e := elt.(type) {
+		case *ast.Field:
+			fields = append(fields, e)
+		case *ast.Comprehension:
+			fields = append(fields, structFields(e.Value)...)
+		case *ast.EmbedDecl:
+			fields = append(fields, structFields(e.Expr)...)
+		default:
+		}
+	}
+	return fields
+}
+
+func isRegularField(field *ast.Field) bool {
+	label := fieldLabel(field)
+	return label != "" && !strings.HasPrefix(label, "#") && !strings.HasPrefix(label, "_")
+}
+
+func checkParameterUsage(lc *lintContext) []lintFinding {
+	parameter := lookupField(lc.template.Elts, model.ParameterFieldName)
+	if parameter == nil {
+		return nil
+	}
+	var findings []lintFinding
+	sets.Walk(parameter.Value, func(node ast.Node, pos []string) {
+		field, ok := node.(*ast.Field)
+		if !ok || !isRegularField(field) || len(field.Comments()) > 0 {
+			return
+		}
+		name := strings.Join(append(append([]string{}, pos...), fieldLabel(field)), ".")
+		findings = append(findings, lintFinding{
+			pos:     field.Pos(),
+			message: fmt.Sprintf("parameter %q has no description, add a \"// +usage=\" comment", name),
+		})
+	})
+	return findings
+}
+
+func checkUnusedParameter(lc *lintContext) []lintFinding {
+	parameter := lookupField(lc.template.Elts, model.ParameterFieldName)
+	if parameter == nil {
+		return nil
+	}
+	used := map[string]bool{}
+	usedAll := false
+	var visit func(node ast.Node) bool
+	visit = func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.Field:
+			// labels are not references
+			if n.Value != nil {
+				ast.Walk(n.Value, visit, nil)
+			}
+			return false
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok && x.Name == model.ParameterFieldName {
+				used[n.Sel.Name] = true
+				return false
+			}
+		case *ast.IndexExpr:
+			if x, ok := n.X.(*ast.Ident); ok && x.Name == model.ParameterFieldName {
+				if lit, ok := n.Index.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					if s, err := strconv.Unquote(lit.Value); err == nil {
+						used[s] = true
+						return false
+					}
+				}
+			}
+		case *ast.Ident:
+			if n.Name == model.ParameterFieldName {
+				usedAll = true
+			}
+		default:
+		}
+		return true
+	}
+	for _, elt := range lc.template.Elts {
+		if elt == parameter {
+			continue
+		}
+		ast.Walk(elt, visit, nil)
+	}
+	if usedAll {
+		return nil
+	}
+	var findings []lintFinding
+	for _, field := range structFields(parameter.Value) {
+		if name := fieldLabel(field); isRegularField(field) && !used[name] {
+			findings = append(findings, lintFinding{pos: field.Pos(), message: fmt.Sprintf("parameter %q is not used", name)})
+		}
+	}
+	return findings
+}
+
+func checkResourceGVK(field *ast.Field, name string) []lintFinding {
+	if _, ok := field.Value.(*ast.StructLit); !ok {
+		// the resource is not a struct literal, e.g. a reference, skip it
+		return nil
+	}
+	labels := map[string]bool{}
+	for _, f := range structFields(field.Value) {
+		labels[fieldLabel(f)] = true
+	}
+	var missing []string
+	for _, key := range []string{"apiVersion", "kind"} {
+		if !labels[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []lintFinding{{pos: field.Pos(), message: fmt.Sprintf("%s is missing %s", name, strings.Join(missing, " and "))}}
+}
+
+func checkOutputGVK(lc *lintContext) []lintFinding {
+	var findings []lintFinding
+	if output := lookupField(lc.template.Elts, model.OutputFieldName); output != nil {
+		findings = append(findings, checkResourceGVK(output, model.OutputFieldName)...)
+	}
+	if outputs := lookupField(lc.template.Elts, model.OutputsFieldName); outputs != nil {
+		for _, field := range structFields(outputs.Value) {
+			if isRegularField(field) {
+				findings = append(findings, checkResourceGVK(field, model.OutputsFieldName+"."+fieldLabel(field))...)
+			}
+		}
+	}
+	return findings
+}
+
+// knownWorkloadTypes are the Go types of the workloads which traits can apply to, including the ones produced by the
+// built-in ComponentDefinitions
+var knownWorkloadTypes = map[string]reflect.Type{
+	"deployments.apps":  reflect.TypeOf(appsv1.Deployment{}),
+	"statefulsets.apps": reflect.TypeOf(appsv1.StatefulSet{}),
+	"daemonsets.apps":   reflect.TypeOf(appsv1.DaemonSet{}),
+	"jobs.batch":        reflect.TypeOf(batchv1.Job{}),
+	"cronjobs.batch":    reflect.TypeOf(batchv1.CronJob{}),
+	"webservice":        reflect.TypeOf(appsv1.Deployment{}),
+	"worker":            reflect.TypeOf(appsv1.Deployment{}),
+	"task":              reflect.TypeOf(batchv1.Job{}),
+}
+
+// referencePath returns the path of the reference expression, e.g. [context output spec replicas]
+func referencePath(expr ast.Expr) ([]string, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return []string{e.Name}, true
+	case *ast.SelectorExpr:
+		path, ok := referencePath(e.X)
+		if !ok {
+			return nil, false
+		}
+		return append(path, e.Sel.Name), true
+	case *ast.IndexExpr:
+		path, ok := referencePath(e.X)
+		if !ok {
+			return nil, false
+		}
+		lit, ok := e.Index.(*ast.BasicLit)
+		if !ok {
+			return nil, false
+		}
+		index := lit.Value
+		if lit.Kind == token.STRING {
+			s, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return nil, false
+			}
+			index = s
+		}
+		return append(path, index), true
+	default:
+		return nil, false
+	}
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// hasJSONPath checks whether the path of json fields exists in the type, the types with custom json encoding are
+// treated as any value
+func hasJSONPath(t reflect.Type, path []string) bool {
+	for len(path) > 0 {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+			return true
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			ft, ok := jsonFieldType(t, path[0])
+			if !ok {
+				return false
+			}
+			t = ft
+		case reflect.Slice, reflect.Array:
+			if _, err := strconv.Atoi(path[0]); err != nil {
+				return false
+			}
+			t = t.Elem()
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Interface:
+			return true
+		default:
+			return false
+		}
+		path = path[1:]
+	}
+	return true
+}
+
+func jsonFieldType(t reflect.Type, name string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		if f.Anonymous && (tag[0] == "" || (len(tag) > 1 && tag[1] == "inline")) {
+			if ft, ok := jsonFieldType(f.Type, name); ok {
+				return ft, true
+			}
+			continue
+		}
+		if tag[0] == name {
+			return f.Type, true
+		}
+	}
+	return nil, false
+}
+
+func checkContextOutputField(lc *lintContext) []lintFinding {
+	if lc.def.GetKind() != v1beta1.TraitDefinitionKind {
+		return nil
+	}
+	appliesTo, _, _ := unstructured.NestedStringSlice(lc.def.Object, "spec", "appliesToWorkloads")
+	var types []reflect.Type
+	var kinds []string
+	for _, workload := range appliesTo {
+		t, ok := knownWorkloadTypes[workload]
+		if !ok {
+			// the fields of the unknown workloads can't be checked
+			return nil
+		}
+		types = append(types, t)
+		kinds = append(kinds, t.Name())
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	var findings []lintFinding
+	ast.Walk(lc.template, func(node ast.Node) bool {
+		expr, ok := node.(ast.Expr)
+		if !ok {
+			return true
+		}
+		path, ok := referencePath(expr)
+		if !ok || len(path) < 3 || path[0] != "context" || path[1] != model.OutputFieldName {
+			return true
+		}
+		for _, t := range types {
+			if hasJSONPath(t, path[2:]) {
+				return false
+			}
+		}
+		findings = append(findings, lintFinding{
+			pos:     expr.Pos(),
+			message: fmt.Sprintf("%s is not a field of %s", strings.Join(path, "."), strings.Join(kinds, " or ")),
+		})
+		return false
+	}, nil)
+	return findings
+}
+
+func checkHealthPolicy(lc *lintContext) []lintFinding {
+	if lc.def.GetKind() != v1beta1.ComponentDefinitionKind {
+		return nil
+	}
+	output := lookupField(lc.template.Elts, model.OutputFieldName)
+	if output == nil {
+		return nil
+	}
+	kind := lookupField(structFieldsAsDecls(output.Value), "kind")
+	if kind == nil {
+		return nil
+	}
+	if lit, ok := kind.Value.(*ast.BasicLit); !ok || lit.Value != `"Deployment"` {
+		return nil
+	}
+	if healthPolicy, _, _ := unstructured.NestedString(lc.def.Object, "spec", "status", "healthPolicy"); healthPolicy != "" {
+		return nil
+	}
+	return []lintFinding{{pos: output.Pos(), message: "the output is a Deployment but there is no health policy in status.healthPolicy"}}
+}
+
+func structFieldsAsDecls(expr ast.Expr) []ast.Decl {
+	fields := structFields(expr)
+	decls := make([]ast.Decl, 0, len(fields))
+	for _, f := range fields {
+		decls = append(decls, f)
+	}
+	return decls
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintDefinition(t *testing.T) {
+	r := require.New(t)
+	component := `webservice: {
+	type: "component"
+	attributes: workload: definition: {
+		apiVersion: "apps/v1"
+		kind:       "Deployment"
+	}
+}
+template: {
+	output: {
+		apiVersion: "apps/v1"
+		kind:       "Deployment"
+		spec: replicas: parameter.replicas
+	}
+	outputs: {
+		service: {
+			metadata: name: context.name
+		}
+		if parameter["expose"] {
+			ingress: {apiVersion: "networking.k8s.io/v1"}
+		}
+	}
+	parameter: {
+		// +usage=The number of replicas
+		replicas: *1 | int
+		// +usage=Whether to expose the service
+		expose: *false | bool
+		image: string
+		// +vet:ignore=unused-parameter,parameter-usage
+		debug: *false | bool
+	}
+}
+`
+	issues, err := LintDefinition(component, nil)
+	r.NoError(err)
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	r.Equal([]string{
+		`9: [warning] health-policy: the output is a Deployment but there is no health policy in status.healthPolicy`,
+		`15: [error] output-gvk: outputs.service is missing apiVersion and kind`,
+		`19: [error] output-gvk: outputs.ingress is missing kind`,
+		`27: [warning] parameter-usage: parameter "image" has no description, add a "// +usage=" comment`,
+		`27: [warning] unused-parameter: parameter "image" is not used`,
+	}, got)
+
+	trait := `scaler: {
+	type: "trait"
+	attributes: appliesToWorkloads: ["deployments.apps", "statefulsets.apps"]
+}
+template: {
+	patch: spec: replicas: parameter.replicas
+	outputs: pdb: {
+		apiVersion: "policy/v1beta1"
+		kind:       "PodDisruptionBudget"
+		spec: selector: matchLabels: context.output.spec.selector.matchLabels
+		spec: minAvailable: context.output.spec.replica
+		metadata: name: context.output.spec.template.spec.containers[0].name
+		metadata: annotations: context.output.metadata.annotations["app.oam.dev/name"]
+		spec: maxUnavailable: context.output.spec.strategy.rollingUpdate.maxSurge
+	}
+	parameter: {
+		// +usage=The number of replicas
+		replicas: *1 | int
+	}
+}
+`
+	issues, err = LintDefinition(trait, nil)
+	r.NoError(err)
+	r.Len(issues, 1)
+	r.Equal("context-output-field", issues[0].RuleID)
+	r.Equal(11, issues[0].Line)
+	r.Equal("context.output.spec.replica is not a field of Deployment or StatefulSet", issues[0].Message)
+
+	_, err = LintDefinition("template: {}", nil)
+	r.Error(err)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+)
+
+// GetDefinitionSchemaFromFile generates the OpenAPI v3 schema of the parameter of the ComponentDefinition or
+// TraitDefinition in the cue file
+func GetDefinitionSchemaFromFile(ctx context.Context, path string) ([]byte, error) {
+	cueBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+	def := &Definition{Unstructured: unstructured.Unstructured{}}
+	if err := def.FromCUEString(string(cueBytes), nil); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	pd := &packages.PackageDiscover{}
+	switch def.GetKind() {
+	case v1beta1.ComponentDefinitionKind:
+		componentDef := &v1beta1.ComponentDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(def.Object, componentDef); err != nil {
+			return nil, errors.Wrapf(err, "invalid ComponentDefinition in %s", path)
+		}
+		capability := utils.NewCapabilityComponentDef(componentDef)
+		return capability.GenerateOpenAPISchema(ctx, pd, componentDef.Name)
+	case v1beta1.TraitDefinitionKind:
+		traitDef := &v1beta1.TraitDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(def.Object, traitDef); err != nil {
+			return nil, errors.Wrapf(err, "invalid TraitDefinition in %s", path)
+		}
+		capability := utils.NewCapabilityTraitDef(traitDef)
+		return capability.GenerateOpenAPISchema(pd, traitDef.Name)
+	default:
+		return nil, errors.Errorf("the schema of %s is not supported, only ComponentDefinition and TraitDefinition are supported", def.GetKind())
+	}
+}
+
+// GetDefinitionRevisionSchema gets the OpenAPI v3 schema of the parameter of the definition revision from the ConfigMap
+// stored by the definition controller, the schema of the latest revision is returned if the revision is 0
+func GetDefinitionRevisionSchema(ctx context.Context, c client.Reader, namespace, name string, revision int64) ([]byte, error) {
+	cmName := fmt.Sprintf("%s%s", types.CapabilityConfigMapNamePrefix, name)
+	if revision > 0 {
+		cmName = fmt.Sprintf("%s%s-v%d", types.CapabilityConfigMapNamePrefix, name, revision)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: cmName}, cm); err != nil {
+		return nil, errors.Wrapf(err, "failed to get the schema of %s in ConfigMap %s/%s", name, namespace, cmName)
+	}
+	schema, ok := cm.Data[types.OpenapiV3JSONSchema]
+	if !ok {
+		return nil, errors.Errorf("no schema found in ConfigMap %s/%s", namespace, cmName)
+	}
+	return []byte(schema), nil
+}
@@ -0,0 +1,422 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/definition"
+	"github.com/oam-dev/kubevela/pkg/cue/model"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/cue/process"
+)
+
+// DefinitionTestFileSuffix is the suffix of the test file placed next to the definition cue file,
+// e.g. the tests of webservice.cue are in webservice_test.yaml
+const DefinitionTestFileSuffix = "_test.yaml"
+
+// DefinitionTestSuite is the table-driven test file of a definition
+type DefinitionTestSuite struct {
+	// Definition is the path of the definition cue file relative to the test file,
+	// it's inferred from the name of the test file if empty
+	Definition string               `json:"definition,omitempty"`
+	Tests      []DefinitionTestCase `json:"tests"`
+}
+
+// DefinitionTestContext is the context to render the definition with
+type DefinitionTestContext struct {
+	Name        string `json:"name,omitempty"`
+	AppName     string `json:"appName,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	AppRevision string `json:"appRevision,omitempty"`
+	// Cluster, EnvName, StepName, AppLabels and AppAnnotations are the runtime information of the rendering
+	Cluster        string            `json:"cluster,omitempty"`
+	EnvName        string            `json:"envName,omitempty"`
+	StepName       string            `json:"stepName,omitempty"`
+	AppLabels      map[string]string `json:"appLabels,omitempty"`
+	AppAnnotations map[string]string `json:"appAnnotations,omitempty"`
+	// Data is the extra data pushed into the context
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// DefinitionTestCase renders the definition with the parameter and context, then checks the results.
+// The expected results only need to contain the fields to check, the other rendered fields are ignored.
+type DefinitionTestCase struct {
+	Name      string                 `json:"name"`
+	Context   DefinitionTestContext  `json:"context,omitempty"`
+	Parameter map[string]interface{} `json:"parameter,omitempty"`
+	// Base is the workload to apply the trait to, only for TraitDefinition
+	Base map[string]interface{} `json:"base,omitempty"`
+
+	// Output is the expected workload rendered by the ComponentDefinition
+	Output map[string]interface{} `json:"output,omitempty"`
+	// Outputs are the expected auxiliary resources rendered by the definition
+	Outputs map[string]map[string]interface{} `json:"outputs,omitempty"`
+	// Patch is the expected base workload after patched by the TraitDefinition
+	Patch map[string]interface{} `json:"patch,omitempty"`
+	// Error is the expected substring of the rendering error
+	Error string `json:"error,omitempty"`
+}
+
+// DefinitionTestResult is the result of a test case
+type DefinitionTestResult struct {
+	Name     string
+	Duration time.Duration
+	Failures []string
+}
+
+// DefinitionTestSuiteResult is the result of all the test cases of a definition
+type DefinitionTestSuiteResult struct {
+	// Name is the path of the definition cue file
+	Name    string
+	Results []DefinitionTestResult
+}
+
+// Failed returns the number of failed test cases
+func (r *DefinitionTestSuiteResult) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if len(result.Failures) > 0 {
+			failed++
+		}
+	}
+	return failed
+}
+
+// LoadDefinitionTestSuite loads the test file and returns the path of the definition it tests
+func LoadDefinitionTestSuite(testFile string) (*DefinitionTestSuite, string, error) {
+	bs, err := os.ReadFile(filepath.Clean(testFile))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to read %s", testFile)
+	}
+	suite := &DefinitionTestSuite{}
+	if err := yaml.Unmarshal(bs, suite); err != nil {
+		return nil, "", errors.Wrapf(err, "failed to parse %s", testFile)
+	}
+	defFile := strings.TrimSuffix(testFile, DefinitionTestFileSuffix) + ".cue"
+	if suite.Definition != "" {
+		defFile = filepath.Join(filepath.Dir(testFile), suite.Definition)
+	}
+	return suite, defFile, nil
+}
+
+// RunDefinitionTests renders the ComponentDefinition or TraitDefinition in the cue file offline with each test case
+func RunDefinitionTests(defFile string, suite *DefinitionTestSuite) (*DefinitionTestSuiteResult, error) {
+	cueBytes, err := os.ReadFile(filepath.Clean(defFile))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", defFile)
+	}
+	def := Definition{Unstructured: unstructured.Unstructured{}}
+	if err := def.FromCUEString(string(cueBytes), nil); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse CUE of %s", defFile)
+	}
+	template, _, err := unstructured.NestedString(def.Object, DefinitionTemplateKeys...)
+	if err != nil {
+		return nil, err
+	}
+	kind := def.GetKind()
+	if kind != v1beta1.ComponentDefinitionKind && kind != v1beta1.TraitDefinitionKind {
+		return nil, errors.Errorf("testing %s is not supported, only ComponentDefinition and TraitDefinition are supported", kind)
+	}
+
+	result := &DefinitionTestSuiteResult{Name: defFile}
+	for _, tc := range suite.Tests {
+		start := time.Now()
+		failures := runDefinitionTest(kind, def.GetName(), template, tc)
+		result.Results = append(result.Results, DefinitionTestResult{Name: tc.Name, Duration: time.Since(start), Failures: failures})
+	}
+	return result, nil
+}
+
+func newDefinitionTestContext(defName string, tc DefinitionTestCase) process.Context {
+	c := tc.Context
+	if c.Name == "" {
+		c.Name = defName
+	}
+	if c.AppName == "" {
+		c.AppName = "app"
+	}
+	if c.Namespace == "" {
+		c.Namespace = "default"
+	}
+	if c.AppRevision == "" {
+		c.AppRevision = c.AppName + "-v1"
+	}
+	ctx := process.NewContext(c.Namespace, c.Name, c.AppName, c.AppRevision)
+	ctx.SetRuntimeInfo(process.RuntimeInfo{
+		Cluster:        c.Cluster,
+		EnvName:        c.EnvName,
+		StepName:       c.StepName,
+		AppLabels:      c.AppLabels,
+		AppAnnotations: c.AppAnnotations,
+	})
+	for k, v := range c.Data {
+		ctx.PushData(k, v)
+	}
+	return ctx
+}
+
+// checkRenderError returns the failures caused by the rendering error, and whether the rest checks should be skipped
+func checkRenderError(err error, expected string) ([]string, bool) {
+	switch {
+	case err == nil && expected != "":
+		return []string{fmt.Sprintf("expected error containing %q, got none", expected)}, true
+	case err != nil && expected == "":
+		return []string{fmt.Sprintf("unexpected error: %v", err)}, true
+	case err != nil && !strings.Contains(err.Error(), expected):
+		return []string{fmt.Sprintf("expected error containing %q, got %v", expected, err)}, true
+	default:
+		return nil, err != nil
+	}
+}
+
+// renderedResult is the rendered results of a definition in JSON values
+type renderedResult struct {
+	base    interface{}
+	outputs map[string]interface{}
+}
+
+func compileInstance(ins model.Instance) (interface{}, error) {
+	bs, err := ins.Compile()
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(bs, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// collectRendered compiles the rendered base workload and the auxiliaries of the given type, all types if it's empty
+func collectRendered(ctx process.Context, auxiliaryType string) (*renderedResult, error) {
+	base, auxiliaries := ctx.Output()
+	result := &renderedResult{outputs: map[string]interface{}{}}
+	if base != nil {
+		v, err := compileInstance(base)
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid rendered output")
+		}
+		result.base = v
+	}
+	for _, auxiliary := range auxiliaries {
+		if auxiliaryType != "" && auxiliary.Type != auxiliaryType {
+			continue
+		}
+		v, err := compileInstance(auxiliary.Ins)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid rendered outputs(%s)", auxiliary.Name)
+		}
+		result.outputs[auxiliary.Name] = v
+	}
+	return result, nil
+}
+
+func renderComponentDefinition(defName, template string, tc DefinitionTestCase) (*renderedResult, error) {
+	ctx := newDefinitionTestContext(defName, tc)
+	if err := definition.NewWorkloadAbstractEngine(defName, &packages.PackageDiscover{}).Complete(ctx, template, tc.Parameter); err != nil {
+		return nil, err
+	}
+	return collectRendered(ctx, "")
+}
+
+func renderTraitDefinition(defName, template string, tc DefinitionTestCase) (*renderedResult, error) {
+	ctx := newDefinitionTestContext(defName, tc)
+	if tc.Base != nil {
+		bs, err := json.Marshal(tc.Base)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid base workload")
+		}
+		baseTemplate := fmt.Sprintf("%s: %s", model.OutputFieldName, string(bs))
+		if err := definition.NewWorkloadAbstractEngine("base", &packages.PackageDiscover{}).Complete(ctx, baseTemplate, nil); err != nil {
+			return nil, errors.WithMessage(err, "invalid base workload")
+		}
+	}
+	if err := definition.NewTraitAbstractEngine(defName, &packages.PackageDiscover{}).Complete(ctx, template, tc.Parameter); err != nil {
+		return nil, err
+	}
+	return collectRendered(ctx, defName)
+}
+
+func runDefinitionTest(kind, defName, template string, tc DefinitionTestCase) []string {
+	var rendered *renderedResult
+	var err error
+	expectedBase, basePath := tc.Output, model.OutputFieldName
+	if kind == v1beta1.ComponentDefinitionKind {
+		rendered, err = renderComponentDefinition(defName, template, tc)
+	} else {
+		if tc.Patch != nil && tc.Base == nil {
+			return []string{"the base workload is required to check the patch"}
+		}
+		expectedBase, basePath = tc.Patch, definition.PatchFieldName
+		rendered, err = renderTraitDefinition(defName, template, tc)
+	}
+	if failures, skip := checkRenderError(err, tc.Error); skip {
+		return failures
+	}
+
+	failures := checkRendered(basePath, expectedBase, rendered.base)
+	names := make([]string, 0, len(tc.Outputs))
+	for name := range tc.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		failures = append(failures, checkRendered(model.OutputsFieldName+"."+name, tc.Outputs[name], rendered.outputs[name])...)
+	}
+	return failures
+}
+
+func checkRendered(path string, expected map[string]interface{}, actual interface{}) []string {
+	if expected == nil {
+		return nil
+	}
+	if actual == nil {
+		return []string{fmt.Sprintf("%s: expected to be rendered, got <missing>", path)}
+	}
+	// normalize the numbers of the expected values as the rendered ones
+	bs, err := json.Marshal(expected)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: invalid expected result: %v", path, err)}
+	}
+	var want interface{}
+	if err := json.Unmarshal(bs, &want); err != nil {
+		return []string{fmt.Sprintf("%s: invalid expected result: %v", path, err)}
+	}
+	return diffFields(path, want, actual)
+}
+
+// diffFields returns the field-level differences between the expected and actual value, the fields absent in the
+// expected map are ignored while lists must be of the same length
+func diffFields(path string, expected, actual interface{}) []string {
+	switch want := expected.(type) {
+	case map[string]interface{}:
+		got, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, formatFieldValue(expected), formatFieldValue(actual))}
+		}
+		keys := make([]string, 0, len(want))
+		for k := range want {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var diffs []string
+		for _, k := range keys {
+			v, ok := got[k]
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("%s.%s: expected %s, got <missing>", path, k, formatFieldValue(want[k])))
+				continue
+			}
+			diffs = append(diffs, diffFields(path+"."+k, want[k], v)...)
+		}
+		return diffs
+	case []interface{}:
+		got, ok := actual.([]interface{})
+		if !ok || len(got) != len(want) {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, formatFieldValue(expected), formatFieldValue(actual))}
+		}
+		var diffs []string
+		for i := range want {
+			diffs = append(diffs, diffFields(fmt.Sprintf("%s[%d]", path, i), want[i], got[i])...)
+		}
+		return diffs
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, formatFieldValue(expected), formatFieldValue(actual))}
+		}
+		return nil
+	}
+}
+
+func formatFieldValue(v interface{}) string {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(bs)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// WriteJUnitReport writes the results of the definition tests in JUnit XML format
+func WriteJUnitReport(w io.Writer, results []*DefinitionTestSuiteResult) error {
+	report := junitTestSuites{}
+	for _, result := range results {
+		suite := junitTestSuite{Name: result.Name, Tests: len(result.Results), Failures: result.Failed()}
+		var total time.Duration
+		for _, r := range result.Results {
+			total += r.Duration
+			tc := junitTestCase{Name: r.Name, ClassName: result.Name, Time: fmt.Sprintf("%.3f", r.Duration.Seconds())}
+			if len(r.Failures) > 0 {
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("%d check(s) failed", len(r.Failures)),
+					Content: strings.Join(r.Failures, "\n"),
+				}
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+		}
+		suite.Time = fmt.Sprintf("%.3f", total.Seconds())
+		report.Suites = append(report.Suites, suite)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testComponentDefinition = `webservice: {
+	type: "component"
+	attributes: workload: definition: {
+		apiVersion: "apps/v1"
+		kind:       "Deployment"
+	}
+}
+template: {
+	output: {
+		apiVersion: "apps/v1"
+		kind:       "Deployment"
+		metadata: {
+			name: context.name
+			labels: {cluster: context.cluster, env: context.envName}
+		}
+		spec: {
+			replicas: parameter.replicas
+			template: spec: containers: [{name: context.name, image: parameter.image}]
+		}
+	}
+	outputs: service: {
+		apiVersion: "v1"
+		kind:       "Service"
+		metadata: name: context.appName + "-" + context.name
+	}
+	parameter: {
+		image:    string
+		replicas: *1 | int
+	}
+}
+`
+
+func TestRunDefinitionTests(t *testing.T) {
+	r := require.New(t)
+	dir := t.TempDir()
+	defFile := filepath.Join(dir, "webservice.cue")
+	r.NoError(os.WriteFile(defFile, []byte(testComponentDefinition), 0600))
+	testFile := filepath.Join(dir, "webservice"+DefinitionTestFileSuffix)
+	r.NoError(os.WriteFile(testFile, []byte(`tests:
+- name: pass
+  context: {name: web, appName: shop, cluster: prod-us, envName: prod}
+  parameter: {image: nginx}
+  output:
+    metadata: {name: web, labels: {cluster: prod-us, env: prod}}
+    spec: {replicas: 1, template: {spec: {containers: [{image: nginx}]}}}
+  outputs:
+    service: {kind: Service, metadata: {name: shop-web}}
+- name: mismatch
+  parameter: {image: nginx, replicas: 2}
+  output:
+    spec: {replicas: 3, selector: {}, template: {spec: {containers: []}}}
+  outputs:
+    ingress: {kind: Ingress}
+- name: missing-parameter
+  error: "incomplete value"
+`), 0600))
+
+	suite, gotDefFile, err := LoadDefinitionTestSuite(testFile)
+	r.NoError(err)
+	r.Equal(defFile, gotDefFile)
+	result, err := RunDefinitionTests(defFile, suite)
+	r.NoError(err)
+	r.Len(result.Results, 3)
+	r.Empty(result.Results[0].Failures)
+	r.Equal([]string{
+		`output.spec.replicas: expected 3, got 2`,
+		`output.spec.selector: expected {}, got <missing>`,
+		`output.spec.template.spec.containers: expected [], got [{"image":"nginx","name":"webservice"}]`,
+		`outputs.ingress: expected to be rendered, got <missing>`,
+	}, result.Results[1].Failures)
+	r.Empty(result.Results[2].Failures)
+	r.Equal(1, result.Failed())
+
+	var b bytes.Buffer
+	r.NoError(WriteJUnitReport(&b, []*DefinitionTestSuiteResult{result}))
+	r.Contains(b.String(), `<testsuite name="`+defFile+`" tests="3" failures="1"`)
+	r.Contains(b.String(), `<failure message="4 check(s) failed">`)
+}
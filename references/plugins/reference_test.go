@@ -436,3 +436,23 @@ variable "acl" {
 		}
 	}
 }
+
+func TestGenerateContext(t *testing.T) {
+	ref := &MarkdownReference{}
+	template := `
+output: {
+	metadata: {
+		name:      context.name
+		namespace: context.namespace
+		labels:    context.appLabels
+	}
+}
+`
+	got := ref.generateContext(template)
+	assert.Contains(t, got, "## Context")
+	assert.Contains(t, got, "\nname | ")
+	assert.Contains(t, got, "\nnamespace | ")
+	assert.Contains(t, got, "\nappLabels | ")
+	assert.NotContains(t, got, "\ncluster | ")
+	assert.Equal(t, "", ref.generateContext(`parameter: {replicas: int}`))
+}
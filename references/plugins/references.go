@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -58,6 +59,30 @@ const (
 	TerraformWriteConnectionSecretToRefType = "[writeConnectionSecretToRef](#writeConnectionSecretToRef)"
 )
 
+// ContextField describes a field of the `context` in CUE templates
+type ContextField struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// ContextFields are the fields of the `context` available to the CUE templates of components, traits and workflow steps
+var ContextFields = []ContextField{
+	{Name: model.ContextName, Type: "string", Description: "The name of the component"},
+	{Name: model.ContextAppName, Type: "string", Description: "The name of the application"},
+	{Name: model.ContextNamespace, Type: "string", Description: "The namespace of the application"},
+	{Name: model.ContextAppRevision, Type: "string", Description: "The revision name of the application"},
+	{Name: model.ContextAppRevisionNum, Type: "int", Description: "The revision number of the application"},
+	{Name: model.ContextAppLabels, Type: "map[string]string", Description: "The labels of the application"},
+	{Name: model.ContextAppAnnotations, Type: "map[string]string", Description: "The annotations of the application"},
+	{Name: model.ContextCluster, Type: "string", Description: "The name of the cluster the resources are dispatched to, empty for the local cluster"},
+	{Name: model.ContextEnvName, Type: "string", Description: "The name of the env in the envbinding policy the resources are rendered for, empty if no env is used"},
+	{Name: model.ContextStepName, Type: "string", Description: "The name of the workflow step which renders the resources"},
+	{Name: model.ContextStepType, Type: "string", Description: "The type of the workflow step, only for workflow steps"},
+	{Name: model.OutputFieldName, Type: "object", Description: "The workload rendered by the component, only for traits"},
+	{Name: model.OutputsFieldName, Type: "object", Description: "The auxiliary resources rendered by the component and traits, only for traits"},
+}
+
 // Int64Type is int64 type
 type Int64Type = int64
 
@@ -450,7 +475,12 @@ func (ref *MarkdownReference) CreateMarkdown(ctx context.Context, caps []types.C
 		// it's fine if the conflict info files not found
 		conflictWithAndMoreSection, _ := ref.generateConflictWithAndMore(capName, referenceSourcePath)
 
-		refContent = title + description + sample + conflictWithAndMoreSection + specification
+		var contextSection string
+		if c.Category == types.CUECategory {
+			contextSection = ref.generateContext(c.CueTemplate)
+		}
+
+		refContent = title + description + sample + conflictWithAndMoreSection + specification + contextSection
 		if _, err := f.WriteString(refContent); err != nil {
 			return err
 		}
@@ -661,6 +691,23 @@ func (ref *MarkdownReference) generateSample(capabilityName string) string {
 	return ""
 }
 
+// generateContext generates Section `Context` which lists the context fields used by the CUE template
+func (ref *MarkdownReference) generateContext(cueTemplate string) string {
+	var rows []string
+	for _, field := range ContextFields {
+		if regexp.MustCompile(`\bcontext\.` + field.Name + `\b`).MatchString(cueTemplate) {
+			rows = append(rows, fmt.Sprintf("%s | %s | %s", field.Name, field.Description, field.Type))
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	refContent := "\n\n## Context\n\nThe fields of `context` used by the template.\n\n"
+	refContent += "Name | Description | Type \n"
+	refContent += "------------ | ------------- | ------------- \n"
+	return refContent + strings.Join(rows, "\n") + "\n"
+}
+
 // generateConflictWithAndMore generates Section `Conflicts With` and more like `How xxx works` in reference docs
 func (ref *MarkdownReference) generateConflictWithAndMore(capabilityName string, referenceSourcePath string) (string, error) {
 	conflictWithFile, err := filepath.Abs(filepath.Join(referenceSourcePath, "conflictsWithAndMore", fmt.Sprintf("%s.md", capabilityName)))